@@ -0,0 +1,215 @@
+// mock-oidc 是用于本地测试第三方登录的简易 OIDC 提供方。
+// 授权接口不显示登录页面，直接以 login_hint（没有时使用 -email）指定的用户身份授权并重定向回应用。
+//
+// 用法：go run ./cmd/mock-oidc -addr :9999 -email alice@example.com
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-key"
+
+// grant 已签发、尚未兑换的授权码
+type grant struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	email         string
+	expiresAt     time.Time
+}
+
+type server struct {
+	issuer        string
+	email         string
+	emailVerified bool
+	key           *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]*grant
+}
+
+func main() {
+	addr := flag.String("addr", ":9999", "监听地址")
+	issuer := flag.String("issuer", "http://localhost:9999", "issuer，需与应用配置一致")
+	email := flag.String("email", "alice@example.com", "没有 login_hint 时使用的邮箱")
+	emailVerified := flag.Bool("email-verified", true, "ID 令牌中的 email_verified")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("❌ 生成签名密钥失败: %v", err)
+	}
+	s := &server{
+		issuer:        strings.TrimRight(*issuer, "/"),
+		email:         *email,
+		emailVerified: *emailVerified,
+		key:           key,
+		grants:        make(map[string]*grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+
+	log.Printf("🚀 mock OIDC 提供方启动: %s (issuer=%s)", *addr, s.issuer)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (s *server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "response_type=code and S256 code_challenge are required", http.StatusBadRequest)
+		return
+	}
+
+	email := q.Get("login_hint")
+	if email == "" {
+		email = s.email
+	}
+	code := randomString()
+
+	s.mu.Lock()
+	s.grants[code] = &grant{
+		clientID:      q.Get("client_id"),
+		redirectURI:   redirectURI.String(),
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		email:         email,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	log.Printf("✅ 授权 %s，重定向到 %s", email, redirectURI.Host)
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if user, _, ok := r.BasicAuth(); ok {
+		clientID = user
+	}
+
+	// 授权码只能使用一次
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	g := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	if g == nil || time.Now().After(g.expiresAt) || g.clientID != clientID || g.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	subject := sha256.Sum256([]byte(strings.ToLower(g.email)))
+	claims := jwt.MapClaims{
+		"iss":            s.issuer,
+		"sub":            "mock-" + hex.EncodeToString(subject[:8]),
+		"aud":            clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"email":          g.email,
+		"email_verified": s.emailVerified,
+		"name":           strings.SplitN(g.email, "@", 2)[0],
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(s.key)
+	if err != nil {
+		tokenError(w, "server_error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("❌ 生成随机数失败: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// purgeInterval 检查注销账户保留期的间隔
+const purgeInterval = time.Hour
+
+// startPurgeJob 在后台定期永久删除保留期已结束的注销账户，启动时先执行一次
+func startPurgeJob(accountService service.AccountService) {
+	go func() {
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			if n, err := accountService.PurgeDeleted(context.Background()); err != nil {
+				log.Printf("❌ 清理注销账户失败: %v", err)
+			} else if n > 0 {
+				log.Printf("✅ 已永久删除 %d 个注销账户", n)
+			}
+			<-ticker.C
+		}
+	}()
+}
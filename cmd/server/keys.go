@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/config"
+)
+
+// defaultKeyID 只配置 secret_key 时使用的密钥 ID
+const defaultKeyID = "default"
+
+// buildKeySet 根据配置创建JWT签名密钥集合
+func buildKeySet(cfg config.JWTConfig) (*auth.KeySet, error) {
+	// 未配置 keys 时兼容旧配置，使用单个 HS256 密钥
+	if len(cfg.Keys) == 0 {
+		key, err := auth.NewHMACKey(defaultKeyID, cfg.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewKeySet(defaultKeyID, key)
+	}
+
+	keys := make([]*auth.SigningKey, 0, len(cfg.Keys))
+	for _, kc := range cfg.Keys {
+		var key *auth.SigningKey
+		var err error
+		if kc.Algorithm == auth.AlgHS256 {
+			key, err = auth.NewHMACKey(kc.ID, kc.Secret)
+		} else {
+			key, err = auth.LoadKey(kc.ID, kc.Algorithm, kc.PrivateKeyFile, kc.PublicKeyFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return auth.NewKeySet(cfg.ActiveKeyID, keys...)
+}
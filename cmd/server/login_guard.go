@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/config"
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"gorm.io/gorm"
+)
+
+// 登录退避参数：第一次退避等待 1 秒，之后每次失败翻倍，最多 15 分钟
+const (
+	loginBaseDelay     = time.Second
+	loginMaxDelay      = 15 * time.Minute
+	loginAccountWindow = 24 * time.Hour
+	loginIPWindow      = time.Hour
+)
+
+// buildLoginGuard 根据配置创建登录暴力破解保护
+func buildLoginGuard(cfg config.LoginConfig, db *gorm.DB) *loginguard.Guard {
+	var store loginguard.Store
+	if cfg.ThrottleStore == "database" {
+		store = repository.NewLoginThrottleRepository(db)
+	} else {
+		store = loginguard.NewMemoryStore()
+	}
+
+	account := loginguard.Policy{
+		FreeAttempts:     cfg.FreeAttempts,
+		BaseDelay:        loginBaseDelay,
+		MaxDelay:         loginMaxDelay,
+		LockoutThreshold: cfg.LockoutThreshold,
+		LockoutDuration:  cfg.LockoutDuration(),
+		Window:           loginAccountWindow,
+	}
+	ip := loginguard.Policy{
+		FreeAttempts: cfg.IPFreeAttempts,
+		BaseDelay:    loginBaseDelay,
+		MaxDelay:     loginMaxDelay,
+		Window:       loginIPWindow,
+	}
+	return loginguard.New(store, account, ip)
+}
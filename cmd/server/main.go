@@ -3,10 +3,18 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/audit"
 	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/config"
 	"github.com/ljk20041215/nutrition-tracker/internal/handler"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/mail"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
 	"github.com/ljk20041215/nutrition-tracker/internal/repository"
 	"github.com/ljk20041215/nutrition-tracker/internal/service"
@@ -14,19 +22,49 @@ import (
 )
 
 func main() {
-	// 1. 数据库配置（确保与调试脚本一致）
-	host := "localhost"
-	port := "5432"
-	username := "postgres"
-	password := "ljk071311" // ⚠️ 确保这里和调试脚本用相同的密码
-	dbname := "nutrition_tracker"
+	// migrate 子命令：server migrate up|down|status|create
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	// user 子命令：server user role <email> <role>
+	if len(os.Args) > 1 && os.Args[1] == "user" {
+		runUser(os.Args[2:])
+		return
+	}
+	// mfa 子命令：server mfa disable <email>
+	if len(os.Args) > 1 && os.Args[1] == "mfa" {
+		runMFA(os.Args[2:])
+		return
+	}
 
 	log.Println("🔍 主程序启动 - 开始初始化")
 
+	// 1. 加载配置（配置文件 → 环境变量 → 命令行参数）
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+	log.Printf("✅ 配置加载成功 (mode=%s, port=%d)", cfg.Server.Mode, cfg.Server.Port)
+
+	keys, err := buildKeySet(cfg.JWT)
+	if err != nil {
+		log.Fatalf("❌ 加载JWT签名密钥失败: %v", err)
+	}
+	auth.Init(keys, cfg.JWT.AccessExpiry())
+	gin.SetMode(cfg.Server.Mode)
+
 	// 2. 初始化数据库
-	log.Printf("🔌 连接数据库: %s@%s:%s/%s", username, host, port, dbname)
+	switch {
+	case cfg.Database.Driver == database.DriverSQLite:
+		log.Printf("🔌 连接数据库: sqlite %s", cfg.Database.DSN)
+	case cfg.Database.DSN == "":
+		log.Printf("🔌 连接数据库: %s@%s:%d/%s", cfg.Database.Username, cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName)
+	default:
+		log.Println("🔌 连接数据库: 使用配置的 DSN")
+	}
 
-	err := database.Init(host, port, username, password, dbname)
+	err = database.Init(cfg.Database.Driver, cfg.Database.ConnectionString(), cfg.Database.AutoMigrate)
 	if err != nil {
 		log.Fatalf("❌ 数据库初始化失败: %v", err)
 	}
@@ -39,6 +77,12 @@ func main() {
 	}
 	log.Println("✅ 获取到有效的数据库实例")
 
+	// 注册审计日志回调，记录用户、目标、餐次、食物记录和食物的变更
+	if err := audit.Register(db); err != nil {
+		log.Fatalf("❌ 注册审计日志回调失败: %v", err)
+	}
+	log.Println("✅ 审计日志回调注册成功")
+
 	// 4. 测试数据库查询（确认连接可用）
 	var userCount int64
 	if err := db.Raw("SELECT COUNT(*) FROM users").Scan(&userCount).Error; err != nil {
@@ -87,14 +131,215 @@ func main() {
 	}
 	log.Println("✅ FoodRecordRepository 初始化成功")
 
+	// 初始化 ExerciseRecordRepository
+	log.Println("🔄 初始化 ExerciseRecordRepository...")
+	exerciseRepo := repository.NewExerciseRecordRepository(db)
+	if exerciseRepo == nil {
+		log.Fatal("❌ ExerciseRecordRepository 初始化失败")
+	}
+	log.Println("✅ ExerciseRecordRepository 初始化成功")
+
+	// 初始化 WaterRecordRepository
+	log.Println("🔄 初始化 WaterRecordRepository...")
+	waterRepo := repository.NewWaterRecordRepository(db)
+	if waterRepo == nil {
+		log.Fatal("❌ WaterRecordRepository 初始化失败")
+	}
+	log.Println("✅ WaterRecordRepository 初始化成功")
+
+	// 初始化 SessionRepository
+	log.Println("🔄 初始化 SessionRepository...")
+	sessionRepo := repository.NewSessionRepository(db)
+	if sessionRepo == nil {
+		log.Fatal("❌ SessionRepository 初始化失败")
+	}
+	log.Println("✅ SessionRepository 初始化成功")
+
+	// 初始化 RevokedTokenRepository
+	log.Println("🔄 初始化 RevokedTokenRepository...")
+	revokedRepo := repository.NewRevokedTokenRepository(db)
+	if revokedRepo == nil {
+		log.Fatal("❌ RevokedTokenRepository 初始化失败")
+	}
+	log.Println("✅ RevokedTokenRepository 初始化成功")
+
+	// 初始化 UserTokenRepository
+	log.Println("🔄 初始化 UserTokenRepository...")
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	if userTokenRepo == nil {
+		log.Fatal("❌ UserTokenRepository 初始化失败")
+	}
+	log.Println("✅ UserTokenRepository 初始化成功")
+
+	// 初始化 UserDataRepository
+	log.Println("🔄 初始化 UserDataRepository...")
+	userDataRepo := repository.NewUserDataRepository(db)
+	if userDataRepo == nil {
+		log.Fatal("❌ UserDataRepository 初始化失败")
+	}
+	log.Println("✅ UserDataRepository 初始化成功")
+
+	// 初始化 LoginAttemptRepository
+	log.Println("🔄 初始化 LoginAttemptRepository...")
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	if loginAttemptRepo == nil {
+		log.Fatal("❌ LoginAttemptRepository 初始化失败")
+	}
+	log.Println("✅ LoginAttemptRepository 初始化成功")
+
+	// 初始化 MFARepository
+	log.Println("🔄 初始化 MFARepository...")
+	mfaRepo := repository.NewMFARepository(db)
+	if mfaRepo == nil {
+		log.Fatal("❌ MFARepository 初始化失败")
+	}
+	log.Println("✅ MFARepository 初始化成功")
+
+	// 初始化 OIDCRepository
+	log.Println("🔄 初始化 OIDCRepository...")
+	oidcRepo := repository.NewOIDCRepository(db)
+	if oidcRepo == nil {
+		log.Fatal("❌ OIDCRepository 初始化失败")
+	}
+	log.Println("✅ OIDCRepository 初始化成功")
+
+	// 初始化 APIKeyRepository
+	log.Println("🔄 初始化 APIKeyRepository...")
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	if apiKeyRepo == nil {
+		log.Fatal("❌ APIKeyRepository 初始化失败")
+	}
+	log.Println("✅ APIKeyRepository 初始化成功")
+
+	// 初始化 HouseholdRepository
+	log.Println("🔄 初始化 HouseholdRepository...")
+	householdRepo := repository.NewHouseholdRepository(db)
+	if householdRepo == nil {
+		log.Fatal("❌ HouseholdRepository 初始化失败")
+	}
+	log.Println("✅ HouseholdRepository 初始化成功")
+
+	// 初始化 AuditLogRepository
+	log.Println("🔄 初始化 AuditLogRepository...")
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	if auditLogRepo == nil {
+		log.Fatal("❌ AuditLogRepository 初始化失败")
+	}
+	log.Println("✅ AuditLogRepository 初始化成功")
+
+	// 初始化 CoachRepository
+	log.Println("🔄 初始化 CoachRepository...")
+	coachRepo := repository.NewCoachRepository(db)
+	if coachRepo == nil {
+		log.Fatal("❌ CoachRepository 初始化失败")
+	}
+	log.Println("✅ CoachRepository 初始化成功")
+
+	// 初始化 MealCommentRepository
+	log.Println("🔄 初始化 MealCommentRepository...")
+	mealCommentRepo := repository.NewMealCommentRepository(db)
+	if mealCommentRepo == nil {
+		log.Fatal("❌ MealCommentRepository 初始化失败")
+	}
+	log.Println("✅ MealCommentRepository 初始化成功")
+
+	// 初始化登录暴力破解保护
+	log.Printf("🔄 初始化登录保护 (store=%s)...", cfg.Login.ThrottleStore)
+	loginGuard := buildLoginGuard(cfg.Login, db)
+	log.Println("✅ 登录保护初始化成功")
+
+	// 初始化邮件发送
+	log.Printf("🔄 初始化邮件发送 (driver=%s)...", cfg.Mail.Driver)
+	mailSender, err := mail.New(mail.Config{
+		Driver:   cfg.Mail.Driver,
+		From:     cfg.Mail.From,
+		FileDir:  cfg.Mail.FileDir,
+		SMTPHost: cfg.Mail.SMTPHost,
+		SMTPPort: cfg.Mail.SMTPPort,
+		Username: cfg.Mail.Username,
+		Password: cfg.Mail.Password,
+	})
+	if err != nil {
+		log.Fatalf("❌ 邮件发送初始化失败: %v", err)
+	}
+	log.Println("✅ 邮件发送初始化成功")
+
 	// 6. 初始化 Service
+	log.Println("🔄 初始化 SessionService...")
+	sessionService := service.NewSessionService(sessionRepo, revokedRepo, userRepo, cfg.JWT.RefreshExpiry())
+	if sessionService == nil {
+		log.Fatal("❌ SessionService 初始化失败")
+	}
+	log.Println("✅ SessionService 初始化成功")
+
+	log.Println("🔄 初始化 AccountService...")
+	accountService := service.NewAccountService(userRepo, userTokenRepo, userDataRepo, householdRepo, sessionService, mailSender, loginGuard,
+		cfg.Mail.BaseURL, cfg.Login.LockoutDuration(), cfg.Account.DeletionGrace())
+	if accountService == nil {
+		log.Fatal("❌ AccountService 初始化失败")
+	}
+	log.Println("✅ AccountService 初始化成功")
+
+	log.Println("🔄 初始化 MFAService...")
+	mfaService := service.NewMFAService(userRepo, mfaRepo, userTokenRepo, loginAttemptRepo, sessionService, accountService, loginGuard)
+	if mfaService == nil {
+		log.Fatal("❌ MFAService 初始化失败")
+	}
+	log.Println("✅ MFAService 初始化成功")
+
 	log.Println("🔄 初始化 UserService...")
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, loginAttemptRepo, sessionService, accountService, mfaService, loginGuard)
 	if userService == nil {
 		log.Fatal("❌ UserService 初始化失败")
 	}
 	log.Println("✅ UserService 初始化成功")
 
+	log.Println("🔄 初始化 HouseholdService...")
+	householdService := service.NewHouseholdService(householdRepo, userRepo, userService)
+	if householdService == nil {
+		log.Fatal("❌ HouseholdService 初始化失败")
+	}
+	log.Println("✅ HouseholdService 初始化成功")
+
+	// 初始化 CoachService
+	log.Println("🔄 初始化 CoachService...")
+	coachService := service.NewCoachService(coachRepo, userRepo)
+	if coachService == nil {
+		log.Fatal("❌ CoachService 初始化失败")
+	}
+	log.Println("✅ CoachService 初始化成功")
+
+	// 代为操作档案的授权：家庭中的受抚养成员和教练的客户
+	profileAuthorizer := service.NewProfileAuthorizer(householdService, coachService)
+
+	log.Printf("🔄 初始化 OIDCService (providers=%d)...", len(cfg.OIDC.Providers))
+	oidcService := service.NewOIDCService(buildOIDCRegistry(cfg.OIDC), oidcRepo, userRepo, loginAttemptRepo, sessionService, mfaService)
+	if oidcService == nil {
+		log.Fatal("❌ OIDCService 初始化失败")
+	}
+	log.Println("✅ OIDCService 初始化成功")
+
+	log.Println("🔄 初始化 APIKeyService...")
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo)
+	if apiKeyService == nil {
+		log.Fatal("❌ APIKeyService 初始化失败")
+	}
+	log.Println("✅ APIKeyService 初始化成功")
+
+	log.Println("🔄 初始化 AdminService...")
+	adminService := service.NewAdminService(userRepo, foodRepo, auditLogRepo, sessionService, mfaService, loginGuard)
+	if adminService == nil {
+		log.Fatal("❌ AdminService 初始化失败")
+	}
+	log.Println("✅ AdminService 初始化成功")
+
+	log.Println("🔄 初始化 UserDataService...")
+	userDataService := service.NewUserDataService(userDataRepo)
+	if userDataService == nil {
+		log.Fatal("❌ UserDataService 初始化失败")
+	}
+	log.Println("✅ UserDataService 初始化成功")
+
 	// 初始化 NutritionGoalService
 	log.Println("🔄 初始化 NutritionGoalService...")
 	goalService := service.NewNutritionGoalService(goalRepo, userRepo)
@@ -105,23 +350,103 @@ func main() {
 
 	// 初始化 MealRecordService
 	log.Println("🔄 初始化 MealRecordService...")
-	mealService := service.NewMealRecordService(mealRepo, userRepo)
+	mealService := service.NewMealRecordService(mealRepo, userRepo, foodRepo, householdService)
 	if mealService == nil {
 		log.Fatal("❌ MealRecordService 初始化失败")
 	}
 	log.Println("✅ MealRecordService 初始化成功")
 
+	// 初始化 MealCommentService
+	log.Println("🔄 初始化 MealCommentService...")
+	mealCommentService := service.NewMealCommentService(mealCommentRepo, mealRepo, userRepo, householdService)
+	if mealCommentService == nil {
+		log.Fatal("❌ MealCommentService 初始化失败")
+	}
+	log.Println("✅ MealCommentService 初始化成功")
+
 	// 初始化 FoodRecordService
 	log.Println("🔄 初始化 FoodRecordService...")
-	foodService := service.NewFoodRecordService(foodRecordRepo, mealRepo, userRepo, foodRepo)
+	foodService := service.NewFoodRecordService(foodRecordRepo, mealRepo, userRepo, foodRepo, householdService)
 	if foodService == nil {
 		log.Fatal("❌ FoodRecordService 初始化失败")
 	}
 	log.Println("✅ FoodRecordService 初始化成功")
 
+	// 初始化 FoodCatalogService
+	log.Println("🔄 初始化 FoodCatalogService...")
+	foodCatalogService := service.NewFoodCatalogService(foodRepo)
+	if foodCatalogService == nil {
+		log.Fatal("❌ FoodCatalogService 初始化失败")
+	}
+	log.Println("✅ FoodCatalogService 初始化成功")
+
+	// 初始化 DiaryExportService
+	log.Println("🔄 初始化 DiaryExportService...")
+	diaryExportService := service.NewDiaryExportService(foodRecordRepo, goalRepo, userRepo)
+	if diaryExportService == nil {
+		log.Fatal("❌ DiaryExportService 初始化失败")
+	}
+	log.Println("✅ DiaryExportService 初始化成功")
+
+	// 初始化 ReportService
+	log.Println("🔄 初始化 ReportService...")
+	reportService := service.NewReportService(foodRecordRepo, goalRepo, userRepo, auditLogRepo)
+	if reportService == nil {
+		log.Fatal("❌ ReportService 初始化失败")
+	}
+	log.Println("✅ ReportService 初始化成功")
+
+	// 初始化 ChartService
+	log.Println("🔄 初始化 ChartService...")
+	chartService := service.NewChartService(reportService)
+	if chartService == nil {
+		log.Fatal("❌ ChartService 初始化失败")
+	}
+	log.Println("✅ ChartService 初始化成功")
+
+	// 初始化 DiaryImportService
+	log.Println("🔄 初始化 DiaryImportService...")
+	diaryImportService := service.NewDiaryImportService(mealRepo, foodRecordRepo, foodRepo, userRepo)
+	if diaryImportService == nil {
+		log.Fatal("❌ DiaryImportService 初始化失败")
+	}
+	log.Println("✅ DiaryImportService 初始化成功")
+
+	// 初始化 ExerciseRecordService
+	log.Println("🔄 初始化 ExerciseRecordService...")
+	exerciseService := service.NewExerciseRecordService(exerciseRepo, userRepo, householdService)
+	if exerciseService == nil {
+		log.Fatal("❌ ExerciseRecordService 初始化失败")
+	}
+	log.Println("✅ ExerciseRecordService 初始化成功")
+
+	// 初始化 WaterRecordService
+	log.Println("🔄 初始化 WaterRecordService...")
+	waterService := service.NewWaterRecordService(waterRepo, foodRecordRepo, userRepo, householdService)
+	if waterService == nil {
+		log.Fatal("❌ WaterRecordService 初始化失败")
+	}
+	log.Println("✅ WaterRecordService 初始化成功")
+
+	// 初始化 SummaryService
+	log.Println("🔄 初始化 SummaryService...")
+	summaryService := service.NewSummaryService(foodRecordRepo, goalRepo, exerciseRepo, waterRepo, userRepo)
+	if summaryService == nil {
+		log.Fatal("❌ SummaryService 初始化失败")
+	}
+	log.Println("✅ SummaryService 初始化成功")
+
+	// 初始化 SuggestionService
+	log.Println("🔄 初始化 SuggestionService...")
+	suggestionService := service.NewSuggestionService(summaryService, foodRepo, foodRecordRepo)
+	if suggestionService == nil {
+		log.Fatal("❌ SuggestionService 初始化失败")
+	}
+	log.Println("✅ SuggestionService 初始化成功")
+
 	// 7. 初始化 Handler
 	log.Println("🔄 初始化 AuthHandler...")
-	authHandler := handler.NewAuthHandler(userService)
+	authHandler := handler.NewAuthHandler(userService, sessionService, accountService)
 	if authHandler == nil {
 		log.Fatal("❌ AuthHandler 初始化失败")
 	}
@@ -129,7 +454,7 @@ func main() {
 
 	// 8. 初始化 UserHandler
 	log.Println("🔄 初始化 UserHandler...")
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, accountService, userDataService)
 	if userHandler == nil {
 		log.Fatal("❌ UserHandler 初始化失败")
 	}
@@ -159,24 +484,190 @@ func main() {
 	}
 	log.Println("✅ FoodRecordHandler 初始化成功")
 
+	// 初始化 FoodCatalogHandler
+	log.Println("🔄 初始化 FoodCatalogHandler...")
+	foodCatalogHandler := handler.NewFoodCatalogHandler(foodCatalogService)
+	if foodCatalogHandler == nil {
+		log.Fatal("❌ FoodCatalogHandler 初始化失败")
+	}
+	log.Println("✅ FoodCatalogHandler 初始化成功")
+
+	// 初始化 DiaryExportHandler
+	log.Println("🔄 初始化 DiaryExportHandler...")
+	diaryExportHandler := handler.NewDiaryExportHandler(diaryExportService)
+	if diaryExportHandler == nil {
+		log.Fatal("❌ DiaryExportHandler 初始化失败")
+	}
+	log.Println("✅ DiaryExportHandler 初始化成功")
+
+	// 初始化 ReportHandler
+	log.Println("🔄 初始化 ReportHandler...")
+	reportHandler := handler.NewReportHandler(reportService)
+	if reportHandler == nil {
+		log.Fatal("❌ ReportHandler 初始化失败")
+	}
+	log.Println("✅ ReportHandler 初始化成功")
+
+	// 初始化 ChartHandler
+	log.Println("🔄 初始化 ChartHandler...")
+	chartHandler := handler.NewChartHandler(chartService)
+	if chartHandler == nil {
+		log.Fatal("❌ ChartHandler 初始化失败")
+	}
+	log.Println("✅ ChartHandler 初始化成功")
+
+	// 初始化 DiaryImportHandler
+	log.Println("🔄 初始化 DiaryImportHandler...")
+	diaryImportHandler := handler.NewDiaryImportHandler(diaryImportService)
+	if diaryImportHandler == nil {
+		log.Fatal("❌ DiaryImportHandler 初始化失败")
+	}
+	log.Println("✅ DiaryImportHandler 初始化成功")
+
+	// 初始化 ExerciseRecordHandler
+	log.Println("🔄 初始化 ExerciseRecordHandler...")
+	exerciseHandler := handler.NewExerciseRecordHandler(exerciseService)
+	if exerciseHandler == nil {
+		log.Fatal("❌ ExerciseRecordHandler 初始化失败")
+	}
+	log.Println("✅ ExerciseRecordHandler 初始化成功")
+
+	// 初始化 WaterRecordHandler
+	log.Println("🔄 初始化 WaterRecordHandler...")
+	waterHandler := handler.NewWaterRecordHandler(waterService)
+	if waterHandler == nil {
+		log.Fatal("❌ WaterRecordHandler 初始化失败")
+	}
+	log.Println("✅ WaterRecordHandler 初始化成功")
+
+	// 初始化 SummaryHandler
+	log.Println("🔄 初始化 SummaryHandler...")
+	summaryHandler := handler.NewSummaryHandler(summaryService)
+	if summaryHandler == nil {
+		log.Fatal("❌ SummaryHandler 初始化失败")
+	}
+	log.Println("✅ SummaryHandler 初始化成功")
+
+	// 初始化 SuggestionHandler
+	log.Println("🔄 初始化 SuggestionHandler...")
+	suggestionHandler := handler.NewSuggestionHandler(suggestionService)
+	if suggestionHandler == nil {
+		log.Fatal("❌ SuggestionHandler 初始化失败")
+	}
+	log.Println("✅ SuggestionHandler 初始化成功")
+
+	// 初始化 SessionHandler
+	log.Println("🔄 初始化 SessionHandler...")
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	if sessionHandler == nil {
+		log.Fatal("❌ SessionHandler 初始化失败")
+	}
+	log.Println("✅ SessionHandler 初始化成功")
+
+	// 初始化 AccountHandler
+	log.Println("🔄 初始化 AccountHandler...")
+	accountHandler := handler.NewAccountHandler(accountService)
+	if accountHandler == nil {
+		log.Fatal("❌ AccountHandler 初始化失败")
+	}
+	log.Println("✅ AccountHandler 初始化成功")
+
+	// 初始化 MFAHandler
+	log.Println("🔄 初始化 MFAHandler...")
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	if mfaHandler == nil {
+		log.Fatal("❌ MFAHandler 初始化失败")
+	}
+	log.Println("✅ MFAHandler 初始化成功")
+
+	// 初始化 OIDCHandler
+	log.Println("🔄 初始化 OIDCHandler...")
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	if oidcHandler == nil {
+		log.Fatal("❌ OIDCHandler 初始化失败")
+	}
+	log.Println("✅ OIDCHandler 初始化成功")
+
+	// 初始化 APIKeyHandler
+	log.Println("🔄 初始化 APIKeyHandler...")
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	if apiKeyHandler == nil {
+		log.Fatal("❌ APIKeyHandler 初始化失败")
+	}
+	log.Println("✅ APIKeyHandler 初始化成功")
+
+	// 初始化 HouseholdHandler
+	log.Println("🔄 初始化 HouseholdHandler...")
+	householdHandler := handler.NewHouseholdHandler(householdService)
+	if householdHandler == nil {
+		log.Fatal("❌ HouseholdHandler 初始化失败")
+	}
+	log.Println("✅ HouseholdHandler 初始化成功")
+
+	// 初始化 CoachHandler
+	log.Println("🔄 初始化 CoachHandler...")
+	coachHandler := handler.NewCoachHandler(coachService)
+	if coachHandler == nil {
+		log.Fatal("❌ CoachHandler 初始化失败")
+	}
+	log.Println("✅ CoachHandler 初始化成功")
+
+	// 初始化 MealCommentHandler
+	log.Println("🔄 初始化 MealCommentHandler...")
+	mealCommentHandler := handler.NewMealCommentHandler(mealCommentService)
+	if mealCommentHandler == nil {
+		log.Fatal("❌ MealCommentHandler 初始化失败")
+	}
+	log.Println("✅ MealCommentHandler 初始化成功")
+
+	// 初始化 AdminHandler
+	log.Println("🔄 初始化 AdminHandler...")
+	adminHandler := handler.NewAdminHandler(adminService)
+	if adminHandler == nil {
+		log.Fatal("❌ AdminHandler 初始化失败")
+	}
+	log.Println("✅ AdminHandler 初始化成功")
+
 	// 9. 创建Gin引擎
 	log.Println("🔄 创建Gin引擎...")
 	r := gin.Default()
+	r.Use(handler.RequestID(), handler.ErrorHandler())
+
+	// 注册参数校验错误的中英文翻译
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := i18n.RegisterValidator(v); err != nil {
+			log.Fatal("❌ 注册校验错误翻译失败: ", err)
+		}
+	}
 
 	// 10. 添加日志中间件
 	r.Use(func(c *gin.Context) {
-		log.Printf("🌐 %s %s", c.Request.Method, c.Request.URL.Path)
+		log.Printf("🌐 %s %s [%s]", c.Request.Method, c.Request.URL.Path, c.GetString("request_id"))
 		c.Next()
 	})
 
 	// 11. 注册路由
 	log.Println("🔄 注册路由...")
 
+	// 签名公钥，供其他服务验证访问令牌
+	r.GET("/.well-known/jwks.json", handler.JWKS(keys))
+
 	// 公开路由（无需认证）
 	public := r.Group("/api/v1")
 	{
 		public.POST("/auth/register", authHandler.Register)
 		public.POST("/auth/login", authHandler.Login)
+		public.POST("/auth/refresh", authHandler.Refresh)
+		public.POST("/auth/logout", authHandler.Logout)
+		public.POST("/auth/forgot-password", accountHandler.ForgotPassword)
+		public.POST("/auth/reset-password", accountHandler.ResetPassword)
+		public.POST("/auth/verify-email", accountHandler.VerifyEmail)
+		public.POST("/auth/unlock", accountHandler.UnlockAccount)
+		public.POST("/auth/2fa/verify", mfaHandler.Verify)
+		public.GET("/auth/oidc/providers", oidcHandler.Providers)
+		public.GET("/auth/oidc/:provider/login", oidcHandler.Login)
+		public.GET("/auth/oidc/:provider/callback", oidcHandler.Callback)
+		public.POST("/auth/oidc/:provider/callback", oidcHandler.Callback)
 		public.GET("/health", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
 				"status":  "healthy",
@@ -189,9 +680,7 @@ func main() {
 		public.GET("/test/db", func(c *gin.Context) {
 			var count int64
 			if err := db.Model(&model.User{}).Count(&count).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "数据库查询失败: " + err.Error(),
-				})
+				c.Error(apperror.Internal("数据库查询失败", err))
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{
@@ -201,43 +690,165 @@ func main() {
 		})
 	}
 
-	// 受保护路由（需要认证）
+	// 受保护路由（需要登录，不接受 API 密钥）
 	protected := r.Group("/api/v1")
-	protected.Use(auth.AuthMiddleware())
+	protected.Use(auth.AuthMiddleware(revokedRepo, nil), handler.Locale(userService))
 	{
 		// 用户相关路由
 		protected.GET("/users/profile", userHandler.GetProfile)
 		protected.PUT("/users/profile", userHandler.UpdateProfile)
-	
+		protected.PUT("/users/password", accountHandler.ChangePassword)
+		protected.POST("/auth/resend-verification", accountHandler.ResendVerification)
+		protected.DELETE("/users/me", userHandler.DeleteAccount)
+		protected.GET("/users/me/export", userHandler.ExportData)
+
+		// 两步验证
+		protected.GET("/auth/2fa", mfaHandler.Status)
+		protected.POST("/auth/2fa/setup", mfaHandler.Setup)
+		protected.POST("/auth/2fa/enable", mfaHandler.Enable)
+		protected.POST("/auth/2fa/disable", mfaHandler.Disable)
+		protected.POST("/auth/2fa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+		// 登录会话相关路由
+		protected.GET("/sessions", sessionHandler.ListSessions)
+		protected.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+
+		// 个人 API 密钥
+		protected.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+		protected.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+		protected.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+		// 家庭共享
+		protected.POST("/households", householdHandler.CreateHousehold)
+		protected.GET("/households/me", householdHandler.GetHousehold)
+		protected.DELETE("/households/me", householdHandler.DeleteHousehold)
+		protected.POST("/households/me/invitations", householdHandler.InviteMember)
+		protected.DELETE("/households/me/invitations/:id", householdHandler.RevokeInvitation)
+		protected.DELETE("/households/me/members/:id", householdHandler.RemoveMember)
+		protected.POST("/households/me/leave", householdHandler.LeaveHousehold)
+		protected.POST("/households/me/dependents", householdHandler.CreateDependent)
+		protected.PUT("/households/me/dependents/:id", householdHandler.UpdateDependent)
+		protected.DELETE("/households/me/dependents/:id", householdHandler.DeleteDependent)
+		protected.GET("/households/invitations", householdHandler.ListInvitations)
+		protected.POST("/households/invitations/:id/accept", householdHandler.AcceptInvitation)
+		protected.POST("/households/invitations/:id/decline", householdHandler.DeclineInvitation)
+
+		// 我的教练相关路由
+		protected.GET("/coaches", coachHandler.ListCoaches)
+		protected.GET("/coaches/access-logs", coachHandler.ListAccessLogs)
+		protected.GET("/coaches/invitations", coachHandler.ListCoachInvitations)
+		protected.POST("/coaches/invitations/:id/accept", coachHandler.AcceptCoach)
+		protected.POST("/coaches/invitations/:id/decline", coachHandler.DeclineCoach)
+		protected.DELETE("/coaches/:id", coachHandler.EndCoach)
+	}
+
+	// 教练路由（需要 coach 角色），查看客户的记录通过下方记录路由加 X-Profile-ID 请求头
+	coach := r.Group("/api/v1/coach")
+	coach.Use(auth.AuthMiddleware(revokedRepo, nil), handler.Locale(userService), auth.RequireRole(model.RoleCoach))
+	{
+		coach.POST("/invitations", coachHandler.InviteClient)
+		coach.GET("/invitations", coachHandler.ListSentInvitations)
+		coach.DELETE("/invitations/:id", coachHandler.RevokeInvitation)
+		coach.GET("/clients", coachHandler.ListClients)
+		coach.DELETE("/clients/:id", coachHandler.EndClient)
+	}
+
+	// 记录和目标路由（登录用户或带相应授权范围的 API 密钥），X-Profile-ID 请求头可切换到家庭中受抚养成员的档案，
+	// 或教练按客户授予的范围查看客户的档案
+	records := r.Group("/api/v1")
+	records.Use(auth.AuthMiddleware(revokedRepo, apiKeyService), handler.Locale(userService), handler.ActingProfile(profileAuthorizer))
+	{
+		read := auth.RequireScope(auth.ScopeRecordsRead)
+		write := auth.RequireScope(auth.ScopeRecordsWrite)
+		// 评论：API 密钥需要写入范围，教练只需客户授予读取范围
+		comment := auth.RequireScopes(auth.ScopeRecordsWrite, auth.ScopeRecordsRead)
+
 		// 营养目标相关路由
-		protected.GET("/goals", goalHandler.GetNutritionGoal)
-		protected.POST("/goals", goalHandler.SetNutritionGoal)
-		protected.POST("/goals/calculate", goalHandler.CalculateNutritionGoal)
-	
+		records.GET("/goals", auth.RequireScope(auth.ScopeGoalsRead), goalHandler.GetNutritionGoal)
+		records.POST("/goals", auth.RequireScope(auth.ScopeGoalsWrite), goalHandler.SetNutritionGoal)
+		records.POST("/goals/calculate", auth.RequireScope(auth.ScopeGoalsWrite), goalHandler.CalculateNutritionGoal)
+
 		// 餐次记录相关路由
-		protected.POST("/meals", mealHandler.CreateMealRecord)
-		protected.GET("/meals", mealHandler.GetMealRecordsByDate)
-		protected.GET("/meals/:id", mealHandler.GetMealRecord)
-		protected.DELETE("/meals/:id", mealHandler.DeleteMealRecord)
-	
+		records.POST("/meals", write, mealHandler.CreateMealRecord)
+		records.POST("/meals/shared", write, mealHandler.CreateSharedMeal)
+		records.GET("/meals", read, mealHandler.ListMealRecords)
+		records.GET("/meals/:id", read, mealHandler.GetMealRecord)
+		records.DELETE("/meals/:id", write, mealHandler.DeleteMealRecord)
+
+		// 餐次评论相关路由（教练可以查看餐次即可评论）
+		records.GET("/meals/:id/comments", read, mealCommentHandler.ListComments)
+		records.POST("/meals/:id/comments", comment, mealCommentHandler.CreateComment)
+		records.DELETE("/meals/:id/comments/:comment_id", comment, mealCommentHandler.DeleteComment)
+
+		// 食物库
+		records.GET("/foods", read, foodCatalogHandler.ListFoods)
+
 		// 食物记录相关路由
-		protected.POST("/food-records", foodHandler.CreateFoodRecord)
-		protected.GET("/food-records", foodHandler.GetFoodRecordsByDate)
-		protected.GET("/food-records/meal", foodHandler.GetFoodRecordsByMeal)
-		protected.GET("/food-records/:id", foodHandler.GetFoodRecord)
-		protected.PUT("/food-records/:id", foodHandler.UpdateFoodRecord)
-		protected.DELETE("/food-records/:id", foodHandler.DeleteFoodRecord)
+		records.POST("/food-records", write, foodHandler.CreateFoodRecord)
+		records.GET("/food-records", read, foodHandler.ListFoodRecords)
+		records.GET("/food-records/meal", read, foodHandler.ListFoodRecordsByMeal)
+		records.GET("/food-records/:id", read, foodHandler.GetFoodRecord)
+		records.PUT("/food-records/:id", write, foodHandler.UpdateFoodRecord)
+		records.DELETE("/food-records/:id", write, foodHandler.DeleteFoodRecord)
+
+		// 饮食日记导出和导入
+		records.GET("/export", read, diaryExportHandler.ExportDiary)
+		records.POST("/imports", write, diaryImportHandler.ImportDiary)
+
+		// 营养报告和图表
+		records.GET("/reports/:file", read, reportHandler.DownloadReport)
+		records.GET("/charts/:file", read, chartHandler.GetChart)
+
+		// 运动记录相关路由
+		records.POST("/exercises", write, exerciseHandler.CreateExerciseRecord)
+		records.GET("/exercises", read, exerciseHandler.GetExerciseRecordsByDate)
+		records.DELETE("/exercises/:id", write, exerciseHandler.DeleteExerciseRecord)
+
+		// 饮水记录相关路由
+		records.POST("/water", write, waterHandler.CreateWaterRecord)
+		records.GET("/water", read, waterHandler.GetHydration)
+		records.GET("/water/presets", read, waterHandler.GetWaterPresets)
+		records.DELETE("/water/:id", write, waterHandler.DeleteWaterRecord)
+
+		// 营养统计相关路由
+		records.GET("/summary/daily", read, summaryHandler.GetDailySummary)
+		records.GET("/suggestions/foods", read, suggestionHandler.SuggestFoods)
 	}
 
+	// 管理员路由（需要 admin 角色）
+	admin := r.Group("/api/v1/admin")
+	admin.Use(auth.AuthMiddleware(revokedRepo, nil), handler.Locale(userService), auth.RequireRole(model.RoleAdmin))
+	{
+		admin.GET("/users", adminHandler.ListUsers)
+		admin.GET("/users/:id", adminHandler.GetUser)
+		admin.PUT("/users/:id/role", adminHandler.UpdateUserRole)
+		admin.POST("/users/:id/disable", adminHandler.DisableUser)
+		admin.POST("/users/:id/enable", adminHandler.EnableUser)
+		admin.POST("/users/:id/unlock", adminHandler.UnlockUser)
+		admin.POST("/users/:id/2fa/disable", adminHandler.DisableUserMFA)
+
+		admin.GET("/foods", adminHandler.ListFoods)
+		admin.POST("/foods", adminHandler.CreateFood)
+		admin.PUT("/foods/:id", adminHandler.UpdateFood)
+		admin.DELETE("/foods/:id", adminHandler.DeleteFood)
+
+		admin.GET("/audit-logs", adminHandler.ListAuditLogs)
+	}
+
+	// 注销账户保留期结束后永久删除数据
+	startPurgeJob(accountService)
+	log.Printf("🗑️ 注销账户清理任务已启动（保留 %d 天）", cfg.Account.DeletionGraceDays)
+
 	// 12. 启动服务器
-	log.Println("🚀 服务器启动完成，开始监听 :8080")
-	log.Println("📝 注册接口: POST http://localhost:8080/api/v1/auth/register")
-	log.Println("🔑 登录接口: POST http://localhost:8080/api/v1/auth/login")
-	log.Println("🧪 数据库测试: GET http://localhost:8080/api/v1/test/db")
-	log.Println("🎯 营养目标接口: GET/POST http://localhost:8080/api/v1/goals")
-	log.Println("⚡ 计算营养目标接口: POST http://localhost:8080/api/v1/goals/calculate")
-
-	if err := r.Run(":8080"); err != nil {
+	addr := cfg.Server.Addr()
+	log.Printf("🚀 服务器启动完成，开始监听 %s", addr)
+	log.Printf("📝 注册接口: POST http://localhost%s/api/v1/auth/register", addr)
+	log.Printf("🔑 登录接口: POST http://localhost%s/api/v1/auth/login", addr)
+	log.Printf("🧪 数据库测试: GET http://localhost%s/api/v1/test/db", addr)
+	log.Printf("🎯 营养目标接口: GET/POST http://localhost%s/api/v1/goals", addr)
+	log.Printf("⚡ 计算营养目标接口: POST http://localhost%s/api/v1/goals/calculate", addr)
+
+	if err := r.Run(addr); err != nil {
 		log.Fatalf("❌ 服务器启动失败: %v", err)
 	}
 }
\ No newline at end of file
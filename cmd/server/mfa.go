@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/config"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/pkg/database"
+)
+
+const mfaUsage = `用法: server mfa <command> [flags]
+
+命令:
+  disable <email>  强制关闭用户的两步验证（用户丢失验证器和恢复码时使用）
+
+flags 与启动服务时相同，例如 -config configs/config.yaml`
+
+// runMFA 执行 mfa 子命令
+func runMFA(args []string) {
+	if len(args) < 2 || args[0] != "disable" {
+		log.Fatal(mfaUsage)
+	}
+	email := args[1]
+
+	cfg, err := config.Load(args[2:])
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+
+	if err := database.Connect(cfg.Database.Driver, cfg.Database.ConnectionString()); err != nil {
+		log.Fatalf("❌ 数据库连接失败: %v", err)
+	}
+	db := database.GetDB()
+
+	ctx := context.Background()
+	user, err := repository.NewUserRepository(db).FindByEmail(ctx, email)
+	if err != nil {
+		log.Fatalf("❌ 查询用户 %s 失败: %v", email, err)
+	}
+	if user.TOTPEnabledAt == nil {
+		log.Printf("⚠️ 用户 %s 未开启两步验证", email)
+		return
+	}
+
+	if err := repository.NewMFARepository(db).Disable(ctx, user.ID); err != nil {
+		log.Fatalf("❌ 关闭两步验证失败: %v", err)
+	}
+	log.Printf("✅ 已关闭用户 %s 的两步验证", email)
+}
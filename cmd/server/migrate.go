@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/config"
+	"github.com/ljk20041215/nutrition-tracker/pkg/database"
+	"github.com/ljk20041215/nutrition-tracker/pkg/migrate"
+)
+
+// migrationsDir migrate create 生成迁移文件的目录（相对于项目根目录）
+const migrationsDir = "migrations"
+
+const migrateUsage = `用法: server migrate <command> [flags]
+
+命令:
+  up             执行所有未执行的迁移
+  down           回滚最近一次迁移
+  status         查看迁移执行状态
+  create <name>  在 migrations/ 下为每个驱动生成新的迁移文件
+
+flags 与启动服务时相同，例如 -config configs/config.yaml`
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	command := args[0]
+	if command == "create" {
+		if len(args) < 2 {
+			log.Fatal("❌ 请指定迁移名称，例如: server migrate create add_user_role")
+		}
+		files, err := migrate.Create(migrationsDir, []string{database.DriverPostgres, database.DriverSQLite}, args[1])
+		if err != nil {
+			log.Fatalf("❌ 创建迁移文件失败: %v", err)
+		}
+		for _, file := range files {
+			log.Printf("📝 已创建 %s", file)
+		}
+		return
+	}
+
+	cfg, err := config.Load(args[1:])
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+
+	if err := database.Connect(cfg.Database.Driver, cfg.Database.ConnectionString()); err != nil {
+		log.Fatalf("❌ 数据库连接失败: %v", err)
+	}
+
+	migrator, err := database.NewMigrator()
+	if err != nil {
+		log.Fatalf("❌ 加载迁移文件失败: %v", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		executed, err := migrator.Up(ctx)
+		for _, m := range executed {
+			log.Printf("✅ 已执行迁移 %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("❌ 迁移失败: %v", err)
+		}
+		if len(executed) == 0 {
+			log.Println("✅ 数据库结构已是最新")
+		}
+	case "down":
+		m, err := migrator.Down(ctx)
+		if err != nil {
+			log.Fatalf("❌ 回滚失败: %v", err)
+		}
+		if m == nil {
+			log.Println("⚠️ 没有可回滚的迁移")
+			return
+		}
+		log.Printf("✅ 已回滚迁移 %04d_%s", m.Version, m.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("❌ 查询迁移状态失败: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/ljk20041215/nutrition-tracker/internal/config"
+	"github.com/ljk20041215/nutrition-tracker/internal/oidc"
+)
+
+// buildOIDCRegistry 根据配置创建第三方登录的身份提供方列表
+func buildOIDCRegistry(cfg config.OIDCConfig) *oidc.Registry {
+	configs := make([]oidc.Config, 0, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		configs = append(configs, oidc.Config{
+			Name:         p.Name,
+			DisplayName:  p.DisplayName,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+	return oidc.NewRegistry(configs, nil)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/config"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+	"github.com/ljk20041215/nutrition-tracker/pkg/database"
+)
+
+const userUsage = `用法: server user <command> [flags]
+
+命令:
+  role <email> <role>  设置用户角色（user/coach/admin），用于创建第一个管理员；该用户的会话随即失效
+
+flags 与启动服务时相同，例如 -config configs/config.yaml`
+
+// runUser 执行 user 子命令
+func runUser(args []string) {
+	if len(args) < 3 || args[0] != "role" {
+		log.Fatal(userUsage)
+	}
+	email, role := args[1], args[2]
+	if role != model.RoleUser && role != model.RoleCoach && role != model.RoleAdmin {
+		log.Fatalf("❌ 无效的角色 %q，可选值: user/coach/admin", role)
+	}
+
+	cfg, err := config.Load(args[3:])
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+
+	if err := database.Connect(cfg.Database.Driver, cfg.Database.ConnectionString()); err != nil {
+		log.Fatalf("❌ 数据库连接失败: %v", err)
+	}
+	db := database.GetDB()
+	userRepo := repository.NewUserRepository(db)
+
+	ctx := context.Background()
+	user, err := userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		log.Fatalf("❌ 查询用户 %s 失败: %v", email, err)
+	}
+	if user.Role == role {
+		log.Printf("⚠️ 用户 %s 的角色已经是 %s", email, role)
+		return
+	}
+
+	user.Role = role
+	if err := userRepo.Update(ctx, user); err != nil {
+		log.Fatalf("❌ 更新用户角色失败: %v", err)
+	}
+
+	// 与管理员接口相同，撤销用户的全部会话，已签发的令牌携带旧角色，需要重新登录
+	sessionService := service.NewSessionService(repository.NewSessionRepository(db), repository.NewRevokedTokenRepository(db),
+		userRepo, cfg.JWT.RefreshExpiry())
+	if err := sessionService.RevokeAll(ctx, user.ID, ""); err != nil {
+		log.Fatalf("❌ 撤销用户会话失败: %v", err)
+	}
+	log.Printf("✅ 已把用户 %s 的角色设置为 %s，该用户需要重新登录", email, role)
+}
@@ -0,0 +1,133 @@
+// Package apperror 定义服务层使用的领域错误，错误渲染中间件根据错误类型映射 HTTP 状态码
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// 错误类型哨兵，使用 errors.Is(err, apperror.ErrNotFound) 判断
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrTooMany      = errors.New("too many requests")
+	ErrInternal     = errors.New("internal error")
+)
+
+// FieldError 字段校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error 领域错误
+type Error struct {
+	Kind    error        // 错误类型哨兵
+	Code    string       // 机器可读的错误码
+	Message string       // 面向用户的错误信息
+	Fields  []FieldError // 字段校验错误（仅 Validation）
+	Err     error        // 底层错误，仅用于日志
+	// RetryAfter 客户端需要等待的时间（仅 TooManyRequests），渲染为 Retry-After 响应头
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap 同时暴露错误类型和底层错误，便于 errors.Is 判断
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// BadRequest 请求格式错误（400）
+func BadRequest(code, message string) *Error {
+	return &Error{Kind: ErrBadRequest, Code: code, Message: message}
+}
+
+// Unauthorized 未认证或认证失败（401）
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+// Forbidden 无权限访问（403）
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+// NotFound 资源不存在（404）
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+// Conflict 资源冲突（409）
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+// Validation 业务校验失败（422）
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message, Fields: fields}
+}
+
+// TooManyRequests 请求过于频繁（429），retryAfter 为需要等待的时间
+func TooManyRequests(code, message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: ErrTooMany, Code: code, Message: message, RetryAfter: retryAfter}
+}
+
+// Internal 服务器内部错误（500），err 只记录日志不返回给客户端
+// err 本身已是领域错误时原样返回，避免 NotFound 等错误被包装成 500
+func Internal(message string, err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return &Error{Kind: ErrInternal, Code: CodeInternal, Message: message, Err: err}
+}
+
+// As 把任意错误转换为领域错误，未知错误视为内部错误
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal("服务器内部错误", err)
+}
+
+// Status 返回错误对应的 HTTP 状态码
+func (e *Error) Status() int {
+	switch e.Kind {
+	case ErrBadRequest:
+		return http.StatusBadRequest
+	case ErrUnauthorized:
+		return http.StatusUnauthorized
+	case ErrForbidden:
+		return http.StatusForbidden
+	case ErrNotFound:
+		return http.StatusNotFound
+	case ErrConflict:
+		return http.StatusConflict
+	case ErrValidation:
+		return http.StatusUnprocessableEntity
+	case ErrTooMany:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HTTPStatus 返回任意错误对应的 HTTP 状态码
+func HTTPStatus(err error) int {
+	return As(err).Status()
+}
@@ -0,0 +1,101 @@
+package apperror
+
+// 机器可读的错误码
+const (
+	CodeInternal       = "INTERNAL_ERROR"
+	CodeInvalidRequest = "INVALID_REQUEST"
+	CodeValidation     = "VALIDATION_FAILED"
+	CodeInvalidDate    = "INVALID_DATE"
+	CodeMissingID      = "MISSING_ID"
+	CodeInvalidFilter  = "INVALID_FILTER"
+	CodeInvalidSort    = "INVALID_SORT"
+	CodeInvalidCursor  = "INVALID_CURSOR"
+	CodeInvalidRange   = "INVALID_DATE_RANGE"
+
+	CodeUnauthenticated    = "UNAUTHENTICATED"
+	CodeInvalidToken       = "INVALID_TOKEN"
+	CodeTokenRevoked       = "TOKEN_REVOKED"
+	CodeInvalidCredentials = "INVALID_CREDENTIALS"
+	CodeEmailRegistered    = "EMAIL_ALREADY_REGISTERED"
+
+	CodeInvalidRefreshToken = "INVALID_REFRESH_TOKEN"
+	CodeRefreshTokenReused  = "REFRESH_TOKEN_REUSED"
+	CodeSessionNotFound     = "SESSION_NOT_FOUND"
+	CodeSessionForbidden    = "SESSION_FORBIDDEN"
+
+	CodeWrongPassword            = "WRONG_PASSWORD"
+	CodeSamePassword             = "SAME_PASSWORD"
+	CodeInvalidResetToken        = "INVALID_RESET_TOKEN"
+	CodeInvalidVerificationToken = "INVALID_VERIFICATION_TOKEN"
+	CodeEmailAlreadyVerified     = "EMAIL_ALREADY_VERIFIED"
+	CodeEmailNotVerified         = "EMAIL_NOT_VERIFIED"
+
+	CodeTooManyLoginAttempts = "TOO_MANY_LOGIN_ATTEMPTS"
+	CodeAccountLocked        = "ACCOUNT_LOCKED"
+	CodeInvalidUnlockToken   = "INVALID_UNLOCK_TOKEN"
+
+	CodeMFAAlreadyEnabled   = "MFA_ALREADY_ENABLED"
+	CodeMFANotEnabled       = "MFA_NOT_ENABLED"
+	CodeMFASetupRequired    = "MFA_SETUP_REQUIRED"
+	CodeInvalidMFACode      = "INVALID_MFA_CODE"
+	CodeInvalidMFAChallenge = "INVALID_MFA_CHALLENGE"
+
+	CodeInsufficientRole = "INSUFFICIENT_ROLE"
+	CodeAccountDisabled  = "ACCOUNT_DISABLED"
+	CodeCannotModifySelf = "CANNOT_MODIFY_SELF"
+	CodeFoodExists       = "FOOD_EXISTS"
+
+	CodeUnknownOIDCProvider  = "UNKNOWN_OIDC_PROVIDER"
+	CodeInvalidOIDCState     = "INVALID_OIDC_STATE"
+	CodeOIDCLoginFailed      = "OIDC_LOGIN_FAILED"
+	CodeOIDCEmailNotVerified = "OIDC_EMAIL_NOT_VERIFIED"
+
+	CodeInvalidAPIKey     = "INVALID_API_KEY"
+	CodeAPIKeyNotFound    = "API_KEY_NOT_FOUND"
+	CodeAPIKeyLimit       = "API_KEY_LIMIT"
+	CodeAPIKeyNotAllowed  = "API_KEY_NOT_ALLOWED"
+	CodeInsufficientScope = "INSUFFICIENT_SCOPE"
+
+	CodeHouseholdNotFound       = "HOUSEHOLD_NOT_FOUND"
+	CodeHouseholdMemberNotFound = "HOUSEHOLD_MEMBER_NOT_FOUND"
+	CodeAlreadyInHousehold      = "ALREADY_IN_HOUSEHOLD"
+	CodeNotHouseholdOwner       = "NOT_HOUSEHOLD_OWNER"
+	CodeHouseholdOwnerLeave     = "HOUSEHOLD_OWNER_CANNOT_LEAVE"
+	CodeHouseholdNotEmpty       = "HOUSEHOLD_NOT_EMPTY"
+	CodeProfileForbidden        = "PROFILE_FORBIDDEN"
+	CodeDuplicateMealMember     = "DUPLICATE_MEAL_MEMBER"
+	CodeMealShareTooSmall       = "MEAL_SHARE_TOO_SMALL"
+
+	CodeHouseholdInvitationNotFound   = "HOUSEHOLD_INVITATION_NOT_FOUND"
+	CodeHouseholdInvitationNotPending = "HOUSEHOLD_INVITATION_NOT_PENDING"
+
+	CodeNotCoach                  = "NOT_COACH"
+	CodeCannotCoachSelf           = "CANNOT_COACH_SELF"
+	CodeCoachClientNotFound       = "COACH_CLIENT_NOT_FOUND"
+	CodeCoachInvitationNotFound   = "COACH_INVITATION_NOT_FOUND"
+	CodeCoachInvitationNotPending = "COACH_INVITATION_NOT_PENDING"
+	CodeCoachScopeForbidden       = "COACH_SCOPE_FORBIDDEN"
+	CodeMealCommentNotFound       = "MEAL_COMMENT_NOT_FOUND"
+	CodeNotCommentAuthor          = "NOT_COMMENT_AUTHOR"
+	CodeImportInvalidFile         = "IMPORT_INVALID_FILE"
+	CodeImportFileTooLarge        = "IMPORT_FILE_TOO_LARGE"
+	CodeInvalidReportPeriod       = "INVALID_REPORT_PERIOD"
+	CodeInvalidChart              = "INVALID_CHART"
+
+	CodeUserNotFound           = "USER_NOT_FOUND"
+	CodeProfileIncomplete      = "PROFILE_INCOMPLETE"
+	CodeNutritionGoalNotFound  = "NUTRITION_GOAL_NOT_FOUND"
+	CodeInvalidGoalType        = "INVALID_GOAL_TYPE"
+	CodeFoodNotFound           = "FOOD_NOT_FOUND"
+	CodeMealRecordNotFound     = "MEAL_RECORD_NOT_FOUND"
+	CodeMealRecordForbidden    = "MEAL_RECORD_FORBIDDEN"
+	CodeMealRecordExists       = "MEAL_RECORD_EXISTS"
+	CodeFoodRecordNotFound     = "FOOD_RECORD_NOT_FOUND"
+	CodeFoodRecordForbidden    = "FOOD_RECORD_FORBIDDEN"
+	CodeExerciseRecordNotFound = "EXERCISE_RECORD_NOT_FOUND"
+	CodeExerciseForbidden      = "EXERCISE_RECORD_FORBIDDEN"
+	CodeWeightRequired         = "WEIGHT_REQUIRED"
+	CodeWaterRecordNotFound    = "WATER_RECORD_NOT_FOUND"
+	CodeWaterRecordForbidden   = "WATER_RECORD_FORBIDDEN"
+	CodeWaterAmountRequired    = "WATER_AMOUNT_REQUIRED"
+)
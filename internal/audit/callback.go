@@ -0,0 +1,329 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tables 记录审计日志的表
+var Tables = []string{"users", "nutrition_goals", "meal_records", "food_records", "foods"}
+
+// redactedColumns 敏感字段，只记录发生了变更，不记录值
+var redactedColumns = map[string]bool{
+	"password_hash": true,
+	"totp_secret":   true,
+}
+
+// ignoredColumns 不参与比较的字段：每次更新都会变化，或每次登录都会变化
+var ignoredColumns = map[string]bool{
+	"updated_at":     true,
+	"totp_last_step": true,
+}
+
+// redacted 敏感字段的占位值
+const redacted = "[REDACTED]"
+
+// beforeKey 更新和删除前的数据在语句实例中的键
+const beforeKey = "audit:before"
+
+// Register 注册创建、更新和删除的回调，在同一事务中写入审计日志
+func Register(db *gorm.DB) error {
+	audited := make(map[string]bool, len(Tables))
+	for _, table := range Tables {
+		audited[table] = true
+	}
+	r := &recorder{tables: audited}
+
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("audit:after_create", r.afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("audit:before_update", r.before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("audit:after_update", r.afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("audit:before_delete", r.before); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("audit:after_delete", r.afterDelete)
+}
+
+// recorder 审计回调
+type recorder struct {
+	tables map[string]bool
+}
+
+// afterCreate 创建后记录新数据的全部字段
+func (r *recorder) afterCreate(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || !r.tables[stmt.Table] || stmt.Schema == nil || Disabled(stmt.Context) {
+		return
+	}
+
+	owners := newOwnerResolver(db)
+	var entries []*model.AuditLog
+	eachStruct(stmt.ReflectValue, func(rv reflect.Value) {
+		row := make(map[string]interface{}, len(stmt.Schema.DBNames))
+		for _, name := range stmt.Schema.DBNames {
+			value, _ := stmt.Schema.FieldsByDBName[name].ValueOf(stmt.Context, rv)
+			row[name] = value
+		}
+		entries = append(entries, r.entry(stmt, owners, model.AuditCreate, nil, row))
+	})
+	r.write(db, entries)
+}
+
+// before 更新和删除前按语句的条件读取受影响的行
+func (r *recorder) before(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || !r.tables[stmt.Table] || Disabled(stmt.Context) {
+		return
+	}
+
+	rows, err := r.snapshot(db)
+	if err != nil {
+		log.Printf("⚠️ 审计日志读取 %s 变更前的数据失败: %v", stmt.Table, err)
+		return
+	}
+	db.InstanceSet(beforeKey, rows)
+}
+
+// afterUpdate 重新读取更新过的行，记录发生变化的字段
+func (r *recorder) afterUpdate(db *gorm.DB) {
+	before, ok := r.beforeRows(db)
+	if !ok {
+		return
+	}
+
+	ids := make([]interface{}, 0, len(before))
+	for _, row := range before {
+		ids = append(ids, row["id"])
+	}
+	var after []map[string]interface{}
+	if err := r.query(db).Where("id IN ?", ids).Find(&after).Error; err != nil {
+		log.Printf("⚠️ 审计日志读取 %s 变更后的数据失败: %v", db.Statement.Table, err)
+		return
+	}
+	afterByID := make(map[string]map[string]interface{}, len(after))
+	for _, row := range after {
+		afterByID[fmt.Sprint(row["id"])] = row
+	}
+
+	owners := newOwnerResolver(db)
+	var entries []*model.AuditLog
+	for _, old := range before {
+		if entry := r.entry(db.Statement, owners, model.AuditUpdate, old, afterByID[fmt.Sprint(old["id"])]); len(entry.Changes) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	r.write(db, entries)
+}
+
+// afterDelete 记录被删除的行（包括软删除）
+func (r *recorder) afterDelete(db *gorm.DB) {
+	before, ok := r.beforeRows(db)
+	if !ok {
+		return
+	}
+
+	owners := newOwnerResolver(db)
+	entries := make([]*model.AuditLog, 0, len(before))
+	for _, old := range before {
+		entries = append(entries, r.entry(db.Statement, owners, model.AuditDelete, old, nil))
+	}
+	r.write(db, entries)
+}
+
+// beforeRows 取出 before 回调读取的行，语句失败或没有影响任何行时返回 false
+func (r *recorder) beforeRows(db *gorm.DB) ([]map[string]interface{}, bool) {
+	if db.Error != nil || db.RowsAffected == 0 {
+		return nil, false
+	}
+	value, ok := db.InstanceGet(beforeKey)
+	if !ok {
+		return nil, false
+	}
+	rows := value.([]map[string]interface{})
+	return rows, len(rows) > 0
+}
+
+// snapshot 按语句的条件（和模型主键）查询将被更新或删除的行；没有条件时不读取
+func (r *recorder) snapshot(db *gorm.DB) ([]map[string]interface{}, error) {
+	stmt := db.Statement
+	query := r.query(db)
+
+	conditions := false
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(where)
+			conditions = true
+		}
+	}
+	if stmt.Schema != nil && stmt.Schema.PrioritizedPrimaryField != nil && stmt.ReflectValue.Kind() == reflect.Struct {
+		if id, isZero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
+			query = query.Where("id = ?", id)
+			conditions = true
+		}
+	}
+	if !conditions {
+		return nil, nil
+	}
+
+	var rows []map[string]interface{}
+	err := query.Find(&rows).Error
+	return rows, err
+}
+
+// query 在同一连接（事务）中查询语句所在的表，软删除和 Unscoped 与原语句一致
+func (r *recorder) query(db *gorm.DB) *gorm.DB {
+	stmt := db.Statement
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	if stmt.Schema != nil {
+		tx = tx.Model(reflect.New(stmt.Schema.ModelType).Interface())
+	} else {
+		tx = tx.Table(stmt.Table)
+	}
+	if stmt.Unscoped {
+		tx = tx.Unscoped()
+	}
+	return tx
+}
+
+// entry 比较变更前后的数据，生成一条审计日志
+func (r *recorder) entry(stmt *gorm.Statement, owners *ownerResolver, action string, old, new map[string]interface{}) *model.AuditLog {
+	changes := make(map[string]*model.AuditChange)
+	for _, name := range columns(old, new) {
+		if ignoredColumns[name] {
+			continue
+		}
+		oldValue, hadOld := old[name]
+		newValue, hasNew := new[name]
+		if hadOld && hasNew && fmt.Sprint(oldValue) == fmt.Sprint(newValue) {
+			continue
+		}
+		if !hadOld && newValue == nil || !hasNew && oldValue == nil {
+			continue
+		}
+
+		change := &model.AuditChange{Old: oldValue, New: newValue}
+		if redactedColumns[name] {
+			change = &model.AuditChange{}
+			if hadOld {
+				change.Old = redacted
+			}
+			if hasNew {
+				change.New = redacted
+			}
+		}
+		changes[name] = change
+	}
+
+	row := new
+	if row == nil {
+		row = old
+	}
+	return &model.AuditLog{
+		EntityType: stmt.Table,
+		EntityID:   fmt.Sprint(row["id"]),
+		OwnerID:    owners.of(stmt.Table, row),
+		Action:     action,
+		ActorID:    ActorFrom(stmt.Context),
+		RequestID:  RequestIDFrom(stmt.Context),
+		Changes:    changes,
+	}
+}
+
+// write 在原语句的连接（事务）中写入审计日志，事务回滚时一并回滚
+func (r *recorder) write(db *gorm.DB, entries []*model.AuditLog) {
+	if len(entries) == 0 {
+		return
+	}
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: context.WithoutCancel(db.Statement.Context)})
+	if err := tx.Create(&entries).Error; err != nil {
+		db.AddError(fmt.Errorf("写入审计日志失败: %w", err))
+	}
+}
+
+// ownerResolver 确定审计日志记录的数据属于哪个用户
+type ownerResolver struct {
+	db    *gorm.DB
+	meals map[string]string // 餐次ID到用户ID，同一语句中的食物记录通常属于同一餐次
+}
+
+// newOwnerResolver 在原语句的连接（事务）中查询所属用户
+func newOwnerResolver(db *gorm.DB) *ownerResolver {
+	return &ownerResolver{
+		db:    db.Session(&gorm.Session{NewDB: true, SkipHooks: true}),
+		meals: make(map[string]string),
+	}
+}
+
+// of 返回一行数据所属的用户：用户表为用户本人，食物记录为所在餐次的用户，其他表为 user_id 字段；公共数据返回空字符串
+func (o *ownerResolver) of(table string, row map[string]interface{}) string {
+	switch table {
+	case "users":
+		return fmt.Sprint(row["id"])
+	case "food_records":
+		if mealID, ok := row["meal_record_id"]; ok && mealID != nil {
+			return o.mealOwner(fmt.Sprint(mealID))
+		}
+		return ""
+	}
+	if userID, ok := row["user_id"]; ok && userID != nil {
+		return fmt.Sprint(userID)
+	}
+	return ""
+}
+
+// mealOwner 查询餐次所属的用户，包括已经软删除的餐次
+func (o *ownerResolver) mealOwner(mealID string) string {
+	if userID, ok := o.meals[mealID]; ok {
+		return userID
+	}
+
+	var userIDs []string
+	if err := o.db.Table("meal_records").Where("id = ?", mealID).Pluck("user_id", &userIDs).Error; err != nil {
+		log.Printf("⚠️ 审计日志查询餐次 %s 所属的用户失败: %v", mealID, err)
+	}
+	userID := ""
+	if len(userIDs) > 0 {
+		userID = userIDs[0]
+	}
+	o.meals[mealID] = userID
+	return userID
+}
+
+// columns 两行数据的全部列名
+func columns(rows ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, row := range rows {
+		for name := range row {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// eachStruct 对单个模型或模型切片中的每个模型调用 fn
+func eachStruct(rv reflect.Value, fn func(reflect.Value)) {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
@@ -0,0 +1,51 @@
+// Package audit 通过 GORM 回调记录数据变更审计日志
+//
+// 请求ID和操作用户由中间件放入请求的 context，仓库使用 WithContext(ctx) 写库时回调从中读取；
+// 没有这些信息的写入（后台任务等）照常记录，操作用户和请求ID为空。
+package audit
+
+import (
+	"context"
+)
+
+// contextKey context 中审计信息的键
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	actorKey
+	disabledKey
+)
+
+// WithRequestID 把请求ID放入 context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFrom 取出 context 中的请求ID，没有时返回空字符串
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithActor 把实际操作的用户ID放入 context；代为操作其他档案时仍为登录的用户
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey, userID)
+}
+
+// ActorFrom 取出 context 中的操作用户ID，没有时返回空字符串
+func ActorFrom(ctx context.Context) string {
+	id, _ := ctx.Value(actorKey).(string)
+	return id
+}
+
+// WithoutAudit 返回不记录审计日志的 context，用于永久清除用户数据，避免清除时写入被清除数据的快照
+func WithoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, disabledKey, true)
+}
+
+// Disabled 判断 context 是否关闭了审计日志
+func Disabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(disabledKey).(bool)
+	return disabled
+}
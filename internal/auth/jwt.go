@@ -4,60 +4,73 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// JWTSecret 密钥（生产环境应从配置读取）
-var JWTSecret = []byte("your-secret-key-change-this-in-production")
+// Keys 签名密钥集合，启动时由 Init 从配置设置
+var Keys *KeySet
+
+// TokenExpiry 访问令牌有效期，过期后客户端使用刷新令牌换取新令牌
+var TokenExpiry = 15 * time.Minute
+
+// Init 使用配置中的密钥集合和有效期初始化JWT
+func Init(keys *KeySet, expiry time.Duration) {
+	Keys = keys
+	TokenExpiry = expiry
+}
 
 // Claims 自定义的JWT声明
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Email    string `json:"email"`
 	Nickname string `json:"nickname,omitempty"`
+	// Role 用户角色（user/coach/admin），角色变更后用户的会话会被撤销，因此令牌中的角色始终有效
+	Role string `json:"role,omitempty"`
+	// SessionID 签发该令牌的会话，用于注销和会话列表标记当前设备
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT 生成JWT令牌
-func GenerateJWT(userID, email, nickname string) (string, error) {
-	// 设置令牌过期时间（24小时）
-	expirationTime := time.Now().Add(24 * time.Hour)
-
-	// 创建声明
-	claims := &Claims{
-		UserID:   userID,
-		Email:    email,
-		Nickname: nickname,
+// NewClaims 创建访问令牌声明，每个令牌都有唯一的 jti 以便单独撤销
+func NewClaims(userID, email, nickname, role, sessionID string) *Claims {
+	now := time.Now()
+	return &Claims{
+		UserID:    userID,
+		Email:     email,
+		Nickname:  nickname,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "nutrition-tracker",
 			Subject:   userID,
 		},
 	}
+}
 
-	// 创建令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// 签名令牌
-	tokenString, err := token.SignedString(JWTSecret)
-	if err != nil {
-		return "", err
+// SignClaims 使用当前密钥签名声明
+func SignClaims(claims *Claims) (string, error) {
+	if Keys == nil {
+		return "", errNoKeySet
 	}
+	return Keys.Sign(claims)
+}
 
-	return tokenString, nil
+// GenerateJWT 生成JWT令牌
+func GenerateJWT(userID, email, nickname, role, sessionID string) (string, error) {
+	return SignClaims(NewClaims(userID, email, nickname, role, sessionID))
 }
 
-// ParseJWT 解析和验证JWT令牌
+// ParseJWT 解析和验证JWT令牌，根据 kid 选择验证密钥
 func ParseJWT(tokenString string) (*Claims, error) {
-	// 解析令牌
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名方法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return JWTSecret, nil
-	})
+	if Keys == nil {
+		return nil, errNoKeySet
+	}
 
+	// 解析令牌，只接受密钥集合中使用的签名算法
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, Keys.Keyfunc, jwt.WithValidMethods(Keys.Methods()))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// SigningKey 带 kid 的签名密钥；没有私钥（或 HMAC 密钥）的密钥只能用于验证
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	sign   interface{} // 签名用密钥，为 nil 时只能验证
+	verify interface{} // 验证用密钥
+}
+
+// CanSign 密钥是否可以用于签名
+func (k *SigningKey) CanSign() bool {
+	return k.sign != nil
+}
+
+// NewHMACKey 创建 HS256 密钥，同一个密钥既用于签名也用于验证
+func NewHMACKey(id string, secret string) (*SigningKey, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("密钥 %s: HS256 密钥不能为空", id)
+	}
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, sign: []byte(secret), verify: []byte(secret)}, nil
+}
+
+// LoadKey 按算法从 PEM 文件加载密钥
+// 提供私钥文件时可用于签名（公钥从私钥推导），只提供公钥文件时只能用于验证
+func LoadKey(id, algorithm, privateKeyFile, publicKeyFile string) (*SigningKey, error) {
+	if privateKeyFile == "" && publicKeyFile == "" {
+		return nil, fmt.Errorf("密钥 %s: 需要设置 private_key_file 或 public_key_file", id)
+	}
+
+	key := &SigningKey{ID: id}
+	switch algorithm {
+	case AlgRS256:
+		key.Method = jwt.SigningMethodRS256
+		if privateKeyFile != "" {
+			data, err := os.ReadFile(privateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 读取私钥失败: %w", id, err)
+			}
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 解析 RSA 私钥失败: %w", id, err)
+			}
+			key.sign, key.verify = private, &private.PublicKey
+		} else {
+			data, err := os.ReadFile(publicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 读取公钥失败: %w", id, err)
+			}
+			public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 解析 RSA 公钥失败: %w", id, err)
+			}
+			key.verify = public
+		}
+	case AlgEdDSA:
+		key.Method = jwt.SigningMethodEdDSA
+		if privateKeyFile != "" {
+			data, err := os.ReadFile(privateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 读取私钥失败: %w", id, err)
+			}
+			private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 解析 Ed25519 私钥失败: %w", id, err)
+			}
+			key.sign, key.verify = private, private.(crypto.Signer).Public()
+		} else {
+			data, err := os.ReadFile(publicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 读取公钥失败: %w", id, err)
+			}
+			public, err := jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, fmt.Errorf("密钥 %s: 解析 Ed25519 公钥失败: %w", id, err)
+			}
+			key.verify = public
+		}
+	default:
+		return nil, fmt.Errorf("密钥 %s: 不支持的算法 %q", id, algorithm)
+	}
+	return key, nil
+}
+
+// KeySet 签名密钥集合：使用当前密钥签名，按令牌头中的 kid 选择验证密钥
+// 轮换密钥时把新密钥设为当前密钥，旧密钥保留到其签发的令牌全部过期
+type KeySet struct {
+	active  *SigningKey
+	keys    map[string]*SigningKey
+	ordered []*SigningKey // 保持配置顺序，JWKS 输出稳定
+}
+
+// NewKeySet 创建密钥集合，activeID 指定用于签名的密钥
+func NewKeySet(activeID string, keys ...*SigningKey) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("密钥 ID %s 重复", key.ID)
+		}
+		ks.keys[key.ID] = key
+		ks.ordered = append(ks.ordered, key)
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("当前签名密钥 %s 不存在", activeID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("当前签名密钥 %s 缺少私钥", activeID)
+	}
+	ks.active = active
+	return ks, nil
+}
+
+// Sign 使用当前密钥签名，并在令牌头中写入 kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.ID
+	return token.SignedString(ks.active.sign)
+}
+
+// Keyfunc 根据令牌头中的 kid 返回验证密钥，并确认签名算法与密钥一致
+// 没有 kid 的令牌（引入密钥轮换前签发）使用当前密钥验证
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = ks.active.ID
+	}
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("未知的密钥 ID %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.verify, nil
+}
+
+// Methods 返回密钥集合中使用的签名算法，用于限制可接受的算法
+func (ks *KeySet) Methods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, key := range ks.ordered {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWK JSON Web Key（RFC 7517），只包含公钥参数
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回非对称密钥的公钥集合，HMAC 密钥不会公开
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.ordered {
+		switch public := key.verify.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return set
+}
+
+// errNoKeySet 未调用 Init 时签发或验证令牌
+var errNoKeySet = errors.New("JWT 密钥未初始化")
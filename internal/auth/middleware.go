@@ -1,18 +1,33 @@
 package auth
 
 import (
-	"net/http"
+	"context"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/audit"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// Denylist 已撤销访问令牌的拒绝名单
+type Denylist interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// AuthMiddleware 认证中间件，验证访问令牌并检查其 jti 是否已被撤销
+// apiKeys 不为 nil 时也接受 X-API-Key 请求头中的个人 API 密钥，路由需要再用 RequireScope 限制范围；
+// 为 nil 时携带 API 密钥的请求返回 403，用于修改密码、管理会话等只允许登录用户的接口
+func AuthMiddleware(denylist Denylist, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			authenticateAPIKey(c, apiKeys, key)
+			return
+		}
+
 		// 从Header获取token
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "缺少认证令牌"})
+			c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "缺少认证令牌"))
 			c.Abort()
 			return
 		}
@@ -20,7 +35,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Bearer token格式
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "令牌格式无效"})
+			c.Error(apperror.Unauthorized(apperror.CodeInvalidToken, "令牌格式无效"))
 			c.Abort()
 			return
 		}
@@ -30,16 +45,61 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 使用我们实现的ParseJWT函数
 		claims, err := ParseJWT(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "令牌无效或已过期: " + err.Error()})
+			c.Error(apperror.Unauthorized(apperror.CodeInvalidToken, "令牌无效或已过期: "+err.Error()))
 			c.Abort()
 			return
 		}
 
+		// 检查令牌是否已被撤销
+		if claims.ID != "" {
+			revoked, err := denylist.IsRevoked(c.Request.Context(), claims.ID)
+			if err != nil {
+				c.Error(apperror.Internal("检查令牌状态失败", err))
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.Error(apperror.Unauthorized(apperror.CodeTokenRevoked, "令牌已被撤销"))
+				c.Abort()
+				return
+			}
+		}
+
 		// 将用户信息存入上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_nickname", claims.Nickname)
+		c.Set("user_role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		// 数据变更的审计日志记录实际操作的用户
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.UserID))
 
 		c.Next()
 	}
 }
+
+// authenticateAPIKey 验证 API 密钥并把密钥对应的用户和授权范围存入上下文
+func authenticateAPIKey(c *gin.Context, apiKeys APIKeyAuthenticator, key string) {
+	if apiKeys == nil {
+		c.Error(apperror.Forbidden(apperror.CodeAPIKeyNotAllowed, "该接口不支持使用 API 密钥访问"))
+		c.Abort()
+		return
+	}
+
+	principal, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.UserID)
+	c.Set("user_email", principal.Email)
+	c.Set("user_nickname", principal.Nickname)
+	c.Set("user_role", principal.Role)
+	c.Set("api_key_id", principal.KeyID)
+	c.Set("api_key_scopes", principal.Scopes)
+	c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), principal.UserID))
+
+	c.Next()
+}
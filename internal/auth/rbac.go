@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+)
+
+// RequireRole 授权中间件，只允许拥有指定角色之一的用户访问，需要放在 AuthMiddleware 之后
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(c, roles...) {
+			c.Error(apperror.Forbidden(apperror.CodeInsufficientRole, "没有权限执行该操作"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasRole 当前请求的用户是否拥有指定角色之一，用于在处理器内部按角色区分行为
+func HasRole(c *gin.Context, roles ...string) bool {
+	role := c.GetString("user_role")
+	for _, r := range roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+)
+
+// APIKeyHeader 携带个人 API 密钥的请求头
+const APIKeyHeader = "X-API-Key"
+
+// API 密钥的授权范围；登录会话的访问令牌不受范围限制
+const (
+	ScopeRecordsRead  = "records:read"  // 读取餐次、食物、运动、饮水记录和营养统计
+	ScopeRecordsWrite = "records:write" // 创建、修改和删除上述记录
+	ScopeGoalsRead    = "goals:read"    // 读取营养目标
+	ScopeGoalsWrite   = "goals:write"   // 设置和计算营养目标
+)
+
+// Scopes 全部可用的授权范围
+var Scopes = []string{ScopeRecordsRead, ScopeRecordsWrite, ScopeGoalsRead, ScopeGoalsWrite}
+
+// APIKeyPrincipal API 密钥验证通过后得到的用户和授权范围
+type APIKeyPrincipal struct {
+	KeyID    string
+	UserID   string
+	Email    string
+	Nickname string
+	Role     string
+	Scopes   []string
+}
+
+// APIKeyAuthenticator 验证个人 API 密钥
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*APIKeyPrincipal, error)
+}
+
+// RequireScope 授权中间件，需要放在 AuthMiddleware 之后：
+// 使用 API 密钥访问时要求密钥拥有指定范围；教练代为查看客户档案时（handler.ActingProfile），
+// 还要求客户授予了该范围
+func RequireScope(scope string) gin.HandlerFunc {
+	return RequireScopes(scope, scope)
+}
+
+// RequireScopes 与 RequireScope 相同，但 API 密钥和客户授权分别要求不同的范围。
+// 用于教练只凭读取授权就可以执行的写操作，如在客户的餐次下发表评论：API 密钥仍然需要写入范围
+func RequireScopes(apiKeyScope string, profileScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 登录会话和家庭成员代为操作不受范围限制
+		if scopes, ok := c.Get("api_key_scopes"); ok && !hasScope(scopes.([]string), apiKeyScope) {
+			c.Error(apperror.Forbidden(apperror.CodeInsufficientScope, "API 密钥缺少 "+apiKeyScope+" 权限"))
+			c.Abort()
+			return
+		}
+		if scopes, ok := c.Get("profile_scopes"); ok && !hasScope(scopes.([]string), profileScope) {
+			c.Error(apperror.Forbidden(apperror.CodeCoachScopeForbidden, "客户未授予 "+profileScope+" 权限"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// hasScope 授权范围列表中是否包含指定范围
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
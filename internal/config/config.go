@@ -0,0 +1,521 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// DefaultConfigPath 默认配置文件路径
+const DefaultConfigPath = "configs/config.yaml"
+
+// defaultJWTSecret 示例配置中的占位密钥，release 模式下禁止使用
+const defaultJWTSecret = "your-secret-key-change-this-in-production"
+
+// Config 应用配置
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	JWT      JWTConfig      `yaml:"jwt"`
+	Mail     MailConfig     `yaml:"mail"`
+	Account  AccountConfig  `yaml:"account"`
+	Login    LoginConfig    `yaml:"login"`
+	OIDC     OIDCConfig     `yaml:"oidc"`
+}
+
+// ServerConfig HTTP服务配置
+type ServerConfig struct {
+	Port int    `yaml:"port"`
+	Mode string `yaml:"mode"` // debug / release / test
+}
+
+// DatabaseConfig 数据库配置
+type DatabaseConfig struct {
+	Driver   string `yaml:"driver"` // postgres / sqlite
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"dbname"`
+	SSLMode  string `yaml:"sslmode"`
+	DSN      string `yaml:"dsn"` // 直接指定连接串时忽略上面的字段；sqlite 为数据库文件路径
+	// AutoMigrate 启动时自动执行未执行的迁移，生产环境应关闭并使用 migrate 子命令
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+// JWTConfig JWT配置
+type JWTConfig struct {
+	// SecretKey 未配置 keys 时使用的 HS256 密钥（kid 为 default）
+	SecretKey           string `yaml:"secret_key"`
+	AccessExpiryMinutes int    `yaml:"access_expiry_minutes"` // 访问令牌有效期，过期后使用刷新令牌换取
+	RefreshExpiryDays   int    `yaml:"refresh_expiry_days"`   // 刷新令牌（会话）有效期
+	// ActiveKeyID 用于签名的密钥，其余密钥只用于验证轮换前签发的令牌
+	ActiveKeyID string         `yaml:"active_key_id"`
+	Keys        []JWTKeyConfig `yaml:"keys"`
+}
+
+// JWTKeyConfig 签名密钥配置
+type JWTKeyConfig struct {
+	ID             string `yaml:"id"`
+	Algorithm      string `yaml:"algorithm"`        // HS256 / RS256 / EdDSA
+	Secret         string `yaml:"secret"`           // HS256 密钥
+	PrivateKeyFile string `yaml:"private_key_file"` // RS256/EdDSA 私钥 PEM 文件
+	PublicKeyFile  string `yaml:"public_key_file"`  // 只用于验证时可以只提供公钥 PEM 文件
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver   string `yaml:"driver"` // log / file / smtp
+	From     string `yaml:"from"`
+	FileDir  string `yaml:"file_dir"` // file 方式保存邮件的目录
+	SMTPHost string `yaml:"smtp_host"`
+	SMTPPort int    `yaml:"smtp_port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// BaseURL 邮件中验证和重置链接指向的前端地址
+	BaseURL string `yaml:"base_url"`
+}
+
+// AccountConfig 账户配置
+type AccountConfig struct {
+	// DeletionGraceDays 注销账户后保留数据的天数，到期后永久删除
+	DeletionGraceDays int `yaml:"deletion_grace_days"`
+}
+
+// LoginConfig 登录暴力破解保护配置
+type LoginConfig struct {
+	// ThrottleStore 失败计数存储：memory 仅适用于单实例，多实例部署使用 database
+	ThrottleStore    string `yaml:"throttle_store"`
+	FreeAttempts     int    `yaml:"free_attempts"`     // 同一账户连续失败多少次后开始指数退避
+	LockoutThreshold int    `yaml:"lockout_threshold"` // 同一账户连续失败多少次后临时锁定
+	LockoutMinutes   int    `yaml:"lockout_minutes"`   // 锁定时长，可通过解锁邮件或重置密码提前解锁
+	IPFreeAttempts   int    `yaml:"ip_free_attempts"`  // 同一 IP 连续失败多少次后开始指数退避
+}
+
+// OIDCConfig 第三方登录配置
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `yaml:"providers"`
+}
+
+// OIDCProviderConfig 身份提供方配置
+type OIDCProviderConfig struct {
+	Name         string `yaml:"name"`         // 提供方标识，出现在登录地址中，只能包含小写字母、数字和连字符
+	DisplayName  string `yaml:"display_name"` // 登录按钮上显示的名称
+	Issuer       string `yaml:"issuer"`       // 例如 https://accounts.google.com
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"` // 可通过 NUTRITION_OIDC_<NAME>_CLIENT_SECRET 设置
+	// RedirectURL 在提供方登记的回调地址：前端页面（收到 code 和 state 后 POST 给回调接口）
+	// 或直接使用 /api/v1/auth/oidc/<name>/callback
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"` // 为空时使用 openid email profile
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port: 8080,
+			Mode: "debug",
+		},
+		Database: DatabaseConfig{
+			Driver:  "postgres",
+			Host:    "localhost",
+			Port:    5432,
+			SSLMode: "disable",
+		},
+		JWT: JWTConfig{
+			AccessExpiryMinutes: 15,
+			RefreshExpiryDays:   30,
+		},
+		Mail: MailConfig{
+			Driver:   "log",
+			From:     "Nutrition Tracker <no-reply@localhost>",
+			SMTPPort: 587,
+			BaseURL:  "http://localhost:8080",
+		},
+		Account: AccountConfig{
+			DeletionGraceDays: 30,
+		},
+		Login: LoginConfig{
+			ThrottleStore:    "memory",
+			FreeAttempts:     3,
+			LockoutThreshold: 10,
+			LockoutMinutes:   30,
+			IPFreeAttempts:   20,
+		},
+	}
+}
+
+// Load 按 默认值 → YAML 配置文件 → 环境变量 → 命令行参数 的顺序加载配置并校验
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configPath := fs.String("config", DefaultConfigPath, "配置文件路径")
+	port := fs.Int("port", 0, "HTTP 监听端口")
+	mode := fs.String("mode", "", "运行模式：debug / release / test")
+	dbDriver := fs.String("db-driver", "", "数据库驱动：postgres / sqlite")
+	dbHost := fs.String("db-host", "", "数据库主机")
+	dbPort := fs.Int("db-port", 0, "数据库端口")
+	dbUser := fs.String("db-user", "", "数据库用户名")
+	dbName := fs.String("db-name", "", "数据库名称")
+	dbDSN := fs.String("db-dsn", "", "数据库连接串")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// 记录命令行中显式设置的参数
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	cfg := Default()
+
+	// 1. 加载配置文件（未显式指定且默认文件不存在时跳过）
+	if err := cfg.loadFile(*configPath); err != nil {
+		if !errors.Is(err, os.ErrNotExist) || setFlags["config"] {
+			return nil, err
+		}
+	}
+
+	// 2. 环境变量覆盖
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	// 3. 命令行参数覆盖
+	if setFlags["port"] {
+		cfg.Server.Port = *port
+	}
+	if setFlags["mode"] {
+		cfg.Server.Mode = *mode
+	}
+	if setFlags["db-driver"] {
+		cfg.Database.Driver = *dbDriver
+	}
+	if setFlags["db-host"] {
+		cfg.Database.Host = *dbHost
+	}
+	if setFlags["db-port"] {
+		cfg.Database.Port = *dbPort
+	}
+	if setFlags["db-user"] {
+		cfg.Database.Username = *dbUser
+	}
+	if setFlags["db-name"] {
+		cfg.Database.DBName = *dbName
+	}
+	if setFlags["db-dsn"] {
+		cfg.Database.DSN = *dbDSN
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile 从 YAML 文件加载配置
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	return nil
+}
+
+// loadEnv 使用 NUTRITION_ 前缀的环境变量覆盖配置
+func (c *Config) loadEnv() error {
+	strVars := map[string]*string{
+		"NUTRITION_SERVER_MODE":          &c.Server.Mode,
+		"NUTRITION_DB_DRIVER":            &c.Database.Driver,
+		"NUTRITION_DB_HOST":              &c.Database.Host,
+		"NUTRITION_DB_USER":              &c.Database.Username,
+		"NUTRITION_DB_PASSWORD":          &c.Database.Password,
+		"NUTRITION_DB_NAME":              &c.Database.DBName,
+		"NUTRITION_DB_SSLMODE":           &c.Database.SSLMode,
+		"NUTRITION_DB_DSN":               &c.Database.DSN,
+		"NUTRITION_JWT_SECRET":           &c.JWT.SecretKey,
+		"NUTRITION_JWT_ACTIVE_KEY_ID":    &c.JWT.ActiveKeyID,
+		"NUTRITION_MAIL_DRIVER":          &c.Mail.Driver,
+		"NUTRITION_MAIL_FROM":            &c.Mail.From,
+		"NUTRITION_MAIL_FILE_DIR":        &c.Mail.FileDir,
+		"NUTRITION_MAIL_SMTP_HOST":       &c.Mail.SMTPHost,
+		"NUTRITION_MAIL_USERNAME":        &c.Mail.Username,
+		"NUTRITION_MAIL_PASSWORD":        &c.Mail.Password,
+		"NUTRITION_MAIL_BASE_URL":        &c.Mail.BaseURL,
+		"NUTRITION_LOGIN_THROTTLE_STORE": &c.Login.ThrottleStore,
+	}
+	for key, target := range strVars {
+		if value, ok := os.LookupEnv(key); ok {
+			*target = value
+		}
+	}
+
+	intVars := map[string]*int{
+		"NUTRITION_SERVER_PORT":                 &c.Server.Port,
+		"NUTRITION_DB_PORT":                     &c.Database.Port,
+		"NUTRITION_JWT_ACCESS_EXPIRY_MINUTES":   &c.JWT.AccessExpiryMinutes,
+		"NUTRITION_JWT_REFRESH_EXPIRY_DAYS":     &c.JWT.RefreshExpiryDays,
+		"NUTRITION_MAIL_SMTP_PORT":              &c.Mail.SMTPPort,
+		"NUTRITION_ACCOUNT_DELETION_GRACE_DAYS": &c.Account.DeletionGraceDays,
+		"NUTRITION_LOGIN_LOCKOUT_THRESHOLD":     &c.Login.LockoutThreshold,
+		"NUTRITION_LOGIN_LOCKOUT_MINUTES":       &c.Login.LockoutMinutes,
+	}
+	boolVars := map[string]*bool{
+		"NUTRITION_DB_AUTO_MIGRATE": &c.Database.AutoMigrate,
+	}
+	for key, target := range boolVars {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("环境变量 %s 必须为布尔值: %w", key, err)
+		}
+		*target = b
+	}
+
+	// 身份提供方的密钥不写入配置文件，例如 NUTRITION_OIDC_GOOGLE_CLIENT_SECRET
+	for i := range c.OIDC.Providers {
+		p := &c.OIDC.Providers[i]
+		key := "NUTRITION_OIDC_" + strings.ToUpper(strings.ReplaceAll(p.Name, "-", "_")) + "_CLIENT_SECRET"
+		if value, ok := os.LookupEnv(key); ok {
+			p.ClientSecret = value
+		}
+	}
+
+	for key, target := range intVars {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("环境变量 %s 必须为整数: %w", key, err)
+		}
+		*target = n
+	}
+
+	return nil
+}
+
+// Validate 校验必填配置项
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		problems = append(problems, "server.port 必须在 1-65535 之间")
+	}
+	switch c.Server.Mode {
+	case "debug", "release", "test":
+	default:
+		problems = append(problems, "server.mode 只能是 debug、release 或 test")
+	}
+
+	switch c.Database.Driver {
+	case "sqlite":
+		if c.Database.DSN == "" {
+			problems = append(problems, "sqlite 驱动需要设置 database.dsn（数据库文件路径）")
+		}
+	case "postgres":
+		// 直接指定 DSN 时不再校验单独的连接字段
+		if c.Database.DSN == "" {
+			if c.Database.Host == "" {
+				problems = append(problems, "database.host 不能为空")
+			}
+			if c.Database.Port <= 0 {
+				problems = append(problems, "database.port 必须为正数")
+			}
+			if c.Database.Username == "" {
+				problems = append(problems, "database.username 不能为空")
+			}
+			if c.Database.DBName == "" {
+				problems = append(problems, "database.dbname 不能为空")
+			}
+		}
+	default:
+		problems = append(problems, "database.driver 只能是 postgres 或 sqlite")
+	}
+
+	if len(c.JWT.Keys) == 0 {
+		if c.JWT.SecretKey == "" {
+			problems = append(problems, "jwt.secret_key 不能为空（可通过 NUTRITION_JWT_SECRET 设置）")
+		} else if c.Server.Mode == "release" && c.JWT.SecretKey == defaultJWTSecret {
+			problems = append(problems, "release 模式下不能使用默认的 jwt.secret_key")
+		}
+	} else {
+		problems = append(problems, c.JWT.validateKeys()...)
+	}
+	if c.JWT.AccessExpiryMinutes <= 0 {
+		problems = append(problems, "jwt.access_expiry_minutes 必须为正数")
+	}
+	if c.JWT.RefreshExpiryDays <= 0 {
+		problems = append(problems, "jwt.refresh_expiry_days 必须为正数")
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "file":
+		if c.Mail.FileDir == "" {
+			problems = append(problems, "file 邮件发送方式需要设置 mail.file_dir")
+		}
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			problems = append(problems, "smtp 邮件发送方式需要设置 mail.smtp_host")
+		}
+		if c.Mail.SMTPPort <= 0 || c.Mail.SMTPPort > 65535 {
+			problems = append(problems, "mail.smtp_port 必须在 1-65535 之间")
+		}
+	default:
+		problems = append(problems, "mail.driver 只能是 log、file 或 smtp")
+	}
+	if c.Mail.From == "" {
+		problems = append(problems, "mail.from 不能为空")
+	}
+
+	if c.Account.DeletionGraceDays < 0 {
+		problems = append(problems, "account.deletion_grace_days 不能为负数")
+	}
+
+	switch c.Login.ThrottleStore {
+	case "memory", "database":
+	default:
+		problems = append(problems, "login.throttle_store 只能是 memory 或 database")
+	}
+	if c.Login.FreeAttempts <= 0 || c.Login.IPFreeAttempts <= 0 {
+		problems = append(problems, "login.free_attempts 和 login.ip_free_attempts 必须为正数")
+	}
+	if c.Login.LockoutThreshold < 0 {
+		problems = append(problems, "login.lockout_threshold 不能为负数（0 表示不锁定）")
+	}
+	if c.Login.LockoutThreshold > 0 && c.Login.LockoutMinutes <= 0 {
+		problems = append(problems, "login.lockout_minutes 必须为正数")
+	}
+
+	problems = append(problems, c.OIDC.validate()...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("配置校验失败: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validate 校验身份提供方配置
+func (o OIDCConfig) validate() []string {
+	var problems []string
+	names := make(map[string]bool)
+	for i, p := range o.Providers {
+		if !providerNamePattern.MatchString(p.Name) {
+			problems = append(problems, fmt.Sprintf("oidc.providers[%d].name 只能包含小写字母、数字和连字符", i))
+		} else if names[p.Name] {
+			problems = append(problems, fmt.Sprintf("oidc.providers[%d].name %s 重复", i, p.Name))
+		}
+		names[p.Name] = true
+
+		if p.Issuer == "" {
+			problems = append(problems, fmt.Sprintf("oidc.providers[%d].issuer 不能为空", i))
+		}
+		if p.ClientID == "" {
+			problems = append(problems, fmt.Sprintf("oidc.providers[%d].client_id 不能为空", i))
+		}
+		if p.RedirectURL == "" {
+			problems = append(problems, fmt.Sprintf("oidc.providers[%d].redirect_url 不能为空", i))
+		}
+	}
+	return problems
+}
+
+// providerNamePattern 身份提供方标识格式
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// validateKeys 校验签名密钥配置
+func (j JWTConfig) validateKeys() []string {
+	var problems []string
+	ids := make(map[string]bool)
+	for i, key := range j.Keys {
+		if key.ID == "" {
+			problems = append(problems, fmt.Sprintf("jwt.keys[%d].id 不能为空", i))
+		} else if ids[key.ID] {
+			problems = append(problems, fmt.Sprintf("jwt.keys[%d].id %s 重复", i, key.ID))
+		}
+		ids[key.ID] = true
+
+		switch key.Algorithm {
+		case "HS256":
+			if key.Secret == "" {
+				problems = append(problems, fmt.Sprintf("jwt.keys[%d] 使用 HS256 时 secret 不能为空", i))
+			}
+		case "RS256", "EdDSA":
+			if key.PrivateKeyFile == "" && key.PublicKeyFile == "" {
+				problems = append(problems, fmt.Sprintf("jwt.keys[%d] 需要设置 private_key_file 或 public_key_file", i))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("jwt.keys[%d].algorithm 只能是 HS256、RS256 或 EdDSA", i))
+		}
+	}
+	if j.ActiveKeyID == "" {
+		problems = append(problems, "配置 jwt.keys 时 jwt.active_key_id 不能为空")
+	} else if !ids[j.ActiveKeyID] {
+		problems = append(problems, "jwt.active_key_id 必须是 jwt.keys 中的一个")
+	}
+	return problems
+}
+
+// ConnectionString 返回数据库连接串，优先使用直接配置的 dsn
+func (d DatabaseConfig) ConnectionString() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+
+	// 空值会导致 key=value 格式解析错位，因此只拼接已设置的字段
+	parts := []string{
+		"host=" + d.Host,
+		"port=" + strconv.Itoa(d.Port),
+		"user=" + d.Username,
+		"dbname=" + d.DBName,
+	}
+	if d.Password != "" {
+		parts = append(parts, "password="+d.Password)
+	}
+	if d.SSLMode != "" {
+		parts = append(parts, "sslmode="+d.SSLMode)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Addr 返回 HTTP 监听地址
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
+}
+
+// AccessExpiry 返回访问令牌有效期
+func (j JWTConfig) AccessExpiry() time.Duration {
+	return time.Duration(j.AccessExpiryMinutes) * time.Minute
+}
+
+// RefreshExpiry 返回刷新令牌有效期
+func (j JWTConfig) RefreshExpiry() time.Duration {
+	return time.Duration(j.RefreshExpiryDays) * 24 * time.Hour
+}
+
+// DeletionGrace 返回注销账户后的数据保留期
+func (a AccountConfig) DeletionGrace() time.Duration {
+	return time.Duration(a.DeletionGraceDays) * 24 * time.Hour
+}
+
+// LockoutDuration 返回账户锁定时长
+func (l LoginConfig) LockoutDuration() time.Duration {
+	return time.Duration(l.LockoutMinutes) * time.Minute
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvWriter CSV 格式，第一行为列名
+type csvWriter struct {
+	cw      *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{cw: cw, columns: columns, record: make([]string, len(columns))}, nil
+}
+
+// WriteRow 写出一行
+func (w *csvWriter) WriteRow(values []interface{}) error {
+	for i, col := range w.columns {
+		text := formatText(col.Kind, values[i])
+		if col.Kind == Text {
+			text = escapeFormula(text)
+		}
+		w.record[i] = text
+	}
+	return w.cw.Write(w.record)
+}
+
+// Close 刷新缓冲区
+func (w *csvWriter) Close() error {
+	w.cw.Flush()
+	return w.cw.Error()
+}
+
+// escapeFormula 以 = + - @ 开头的文本在电子表格中会被当作公式执行，前面加单引号按文本显示
+func escapeFormula(text string) string {
+	if text == "" {
+		return text
+	}
+	switch text[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + text
+	}
+	return text
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
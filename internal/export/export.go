@@ -0,0 +1,82 @@
+// Package export 以流的方式把表格数据写成 CSV、JSON 或 XLSX，逐行写出，不在内存中保留整张表
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// 支持的导出格式
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+)
+
+// Kind 列的数据类型，决定各格式中值的写法
+type Kind int
+
+const (
+	Text   Kind = iota // 文本
+	Number             // 数值，值为 float64
+	Date               // 日期，值为 time.Time，写作 YYYY-MM-DD（XLSX 中为日期单元格）
+	Time               // 时间，值为 time.Time，写作 RFC3339
+)
+
+// Column 表格的一列
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Writer 逐行写出表格，nil 值写为空单元格（JSON 中为 null）
+type Writer interface {
+	WriteRow(values []interface{}) error
+	// Close 写出格式需要的结尾内容，不关闭底层的 io.Writer
+	Close() error
+}
+
+// NewWriter 按格式创建 Writer，并写出表头
+func NewWriter(format string, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatJSON:
+		return newJSONWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+	return nil, fmt.Errorf("不支持的导出格式: %s", format)
+}
+
+// ContentType 返回格式对应的 Content-Type
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// formatText 把值格式化为文本，用于 CSV 和 XLSX 的文本单元格
+func formatText(kind Kind, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return formatNumber(v)
+	case time.Time:
+		if kind == Date {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testColumns = []Column{
+	{Name: "date", Kind: Date},
+	{Name: "food_name", Kind: Text},
+	{Name: "calories", Kind: Number},
+	{Name: "recorded_at", Kind: Time},
+}
+
+var testRows = [][]interface{}{
+	{time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "米饭", 116.5, time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC)},
+	{time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), "=HYPERLINK(\"x\")", nil, time.Date(2024, 1, 16, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))},
+	{time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), "<Fish & Chips>", 1e-7, nil},
+}
+
+// write 按格式写出测试数据
+func write(t *testing.T, format string, rows [][]interface{}) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, testColumns)
+	if err != nil {
+		t.Fatalf("创建 %s Writer 失败: %v", format, err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("写出行失败: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("关闭 Writer 失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	got := string(write(t, FormatCSV, testRows))
+	want := "date,food_name,calories,recorded_at\n" +
+		"2024-01-15,米饭,116.5,2024-01-15T12:30:00Z\n" +
+		"2024-01-16,\"'=HYPERLINK(\"\"x\"\")\",,2024-01-16T08:00:00+08:00\n" +
+		"2024-01-16,<Fish & Chips>,0.0000001,\n"
+	if got != want {
+		t.Errorf("CSV =\n%s\n期望\n%s", got, want)
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	data := write(t, FormatJSON, testRows)
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("输出不是有效的 JSON: %v\n%s", err, data)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("行数 = %d，期望 3", len(rows))
+	}
+	if rows[0]["date"] != "2024-01-15" || rows[0]["food_name"] != "米饭" || rows[0]["calories"] != 116.5 ||
+		rows[0]["recorded_at"] != "2024-01-15T12:30:00Z" {
+		t.Errorf("第一行 = %v", rows[0])
+	}
+	// JSON 中不需要转义公式，空值为 null
+	if rows[1]["food_name"] != `=HYPERLINK("x")` || rows[1]["calories"] != nil {
+		t.Errorf("第二行 = %v", rows[1])
+	}
+	// 键的顺序与列的顺序相同
+	first := string(data[:bytes.IndexByte(data, '}')])
+	last := -1
+	for _, col := range testColumns {
+		i := strings.Index(first, `"`+col.Name+`":`)
+		if i <= last {
+			t.Fatalf("键 %s 的位置不对: %s", col.Name, first)
+		}
+		last = i
+	}
+
+	if empty := write(t, FormatJSON, nil); string(empty) != "[\n]\n" {
+		t.Errorf("没有数据时 = %q，期望空数组", empty)
+	}
+}
+
+// xlsxCell 工作表中的单元格
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Style  string `xml:"s,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := write(t, FormatXLSX, testRows)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("输出不是 zip 压缩包: %v", err)
+	}
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("打开 %s 失败: %v", f.Name, err)
+		}
+		parts[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("缺少 %s", name)
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Ref   string     `xml:"r,attr"`
+			Cells []xlsxCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("工作表不是有效的 XML: %v", err)
+	}
+	if len(sheet.Rows) != 4 {
+		t.Fatalf("行数 = %d，期望 4（含表头）", len(sheet.Rows))
+	}
+
+	cells := make(map[string]xlsxCell)
+	for _, row := range sheet.Rows {
+		for _, c := range row.Cells {
+			cells[c.Ref] = c
+		}
+	}
+	tests := []struct {
+		ref  string
+		want xlsxCell
+	}{
+		{"A1", xlsxCell{Ref: "A1", Style: "2", Type: "inlineStr", Inline: "date"}},
+		{"A2", xlsxCell{Ref: "A2", Style: "1", Value: "45306"}}, // 2024-01-15 的日期序列号
+		{"B2", xlsxCell{Ref: "B2", Type: "inlineStr", Inline: "米饭"}},
+		{"C2", xlsxCell{Ref: "C2", Value: "116.5"}},
+		{"D2", xlsxCell{Ref: "D2", Type: "inlineStr", Inline: "2024-01-15T12:30:00Z"}},
+		{"B3", xlsxCell{Ref: "B3", Type: "inlineStr", Inline: `=HYPERLINK("x")`}},
+		{"B4", xlsxCell{Ref: "B4", Type: "inlineStr", Inline: "<Fish & Chips>"}},
+		{"C4", xlsxCell{Ref: "C4", Value: "1e-07"}},
+	}
+	for _, tt := range tests {
+		if got := cells[tt.ref]; got != tt.want {
+			t.Errorf("%s = %+v，期望 %+v", tt.ref, got, tt.want)
+		}
+	}
+	// 空值不写单元格
+	for _, ref := range []string{"C3", "D4"} {
+		if _, ok := cells[ref]; ok {
+			t.Errorf("空值不应写出单元格 %s", ref)
+		}
+	}
+}
+
+func TestColumnRef(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range tests {
+		if got := columnRef(i); got != want {
+			t.Errorf("columnRef(%d) = %s，期望 %s", i, got, want)
+		}
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", io.Discard, testColumns); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+	if ContentType("pdf") != "application/octet-stream" {
+		t.Errorf("ContentType(pdf) = %s", ContentType("pdf"))
+	}
+}
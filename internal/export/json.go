@@ -0,0 +1,69 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonWriter JSON 格式：对象数组，每行一个对象，键与列名相同且顺序固定
+type jsonWriter struct {
+	bw      *bufio.Writer
+	columns []Column
+	keys    [][]byte
+	rows    int
+}
+
+func newJSONWriter(w io.Writer, columns []Column) (*jsonWriter, error) {
+	keys := make([][]byte, len(columns))
+	for i, col := range columns {
+		key, err := json.Marshal(col.Name)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = append(key, ':')
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("["); err != nil {
+		return nil, err
+	}
+	return &jsonWriter{bw: bw, columns: columns, keys: keys}, nil
+}
+
+// WriteRow 写出一行
+func (w *jsonWriter) WriteRow(values []interface{}) error {
+	if w.rows > 0 {
+		w.bw.WriteString(",")
+	}
+	w.rows++
+	w.bw.WriteString("\n{")
+	for i, col := range w.columns {
+		if i > 0 {
+			w.bw.WriteString(",")
+		}
+		w.bw.Write(w.keys[i])
+
+		value := values[i]
+		if t, ok := value.(time.Time); ok {
+			value = formatText(col.Kind, t)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if _, err := w.bw.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err := w.bw.WriteString("}")
+	return err
+}
+
+// Close 写出数组结尾并刷新缓冲区
+func (w *jsonWriter) Close() error {
+	if _, err := w.bw.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return w.bw.Flush()
+}
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// XLSX 是包含若干 XML 文件的 zip 压缩包。除工作表外的文件内容固定，先写出；
+// 工作表逐行写入压缩包，单元格统一使用内联字符串，不需要共享字符串表
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+	// 样式 0：默认；1：日期（内置格式 14）；2：粗体，用于表头
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`
+
+	// 冻结首行表头
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// excelEpoch Excel 日期序列号的起点
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter XLSX 格式，只有一个工作表，第一行为列名
+type xlsxWriter struct {
+	zw      *zip.Writer
+	bw      *bufio.Writer
+	columns []Column
+	refs    []string // 列字母：A, B, ..., AA
+	row     int
+}
+
+func newXLSXWriter(w io.Writer, columns []Column) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{zw: zw, bw: bufio.NewWriter(sheet), columns: columns, refs: make([]string, len(columns))}
+	for i := range columns {
+		x.refs[i] = columnRef(i)
+	}
+	x.bw.WriteString(xlsxSheetStart)
+
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	x.writeRow(header, true)
+	return x, nil
+}
+
+// WriteRow 写出一行
+func (x *xlsxWriter) WriteRow(values []interface{}) error {
+	return x.writeRow(values, false)
+}
+
+func (x *xlsxWriter) writeRow(values []interface{}, header bool) error {
+	x.row++
+	r := strconv.Itoa(x.row)
+	x.bw.WriteString(`<row r="` + r + `">`)
+	for i, col := range x.columns {
+		ref := x.refs[i] + r
+		switch v := values[i].(type) {
+		case nil:
+			continue
+		case float64:
+			if header || math.IsNaN(v) || math.IsInf(v, 0) {
+				break
+			}
+			x.bw.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatFloat(v, 'g', -1, 64) + `</v></c>`)
+			continue
+		case time.Time:
+			if header || col.Kind != Date {
+				break
+			}
+			day := time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC)
+			serial := int(day.Sub(excelEpoch).Hours() / 24)
+			x.bw.WriteString(`<c r="` + ref + `" s="1"><v>` + strconv.Itoa(serial) + `</v></c>`)
+			continue
+		}
+
+		style := ""
+		if header {
+			style = ` s="2"`
+		}
+		x.bw.WriteString(`<c r="` + ref + `"` + style + ` t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(x.bw, []byte(formatText(col.Kind, values[i]))); err != nil {
+			return err
+		}
+		x.bw.WriteString(`</t></is></c>`)
+	}
+	_, err := x.bw.WriteString(`</row>`)
+	return err
+}
+
+// Close 写出工作表结尾并完成压缩包
+func (x *xlsxWriter) Close() error {
+	if _, err := x.bw.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := x.bw.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// columnRef 返回从 0 开始的列序号对应的列字母
+func columnRef(i int) string {
+	ref := ""
+	for i >= 0 {
+		ref = string(rune('A'+i%26)) + ref
+		i = i/26 - 1
+	}
+	return ref
+}
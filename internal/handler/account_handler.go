@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// AccountHandler 账户处理器
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+// NewAccountHandler 创建账户处理器实例
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// ChangePassword 修改密码
+// @Summary 修改密码
+// @Description 校验当前密码后设置新密码，其他设备上的会话全部注销，当前会话保留
+// @Tags 账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.ChangePasswordRequest true "当前密码和新密码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/password [put]
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	if err := h.accountService.ChangePassword(c.Request.Context(), userID.(string), c.GetString("session_id"), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgPasswordChanged),
+	})
+}
+
+// ForgotPassword 找回密码
+// @Summary 找回密码
+// @Description 向邮箱发送重置密码链接（1 小时内有效）；邮箱未注册时同样返回成功
+// @Tags 账户
+// @Accept json
+// @Produce json
+// @Param request body service.ForgotPasswordRequest true "注册邮箱"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	if err := h.accountService.ForgotPassword(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgResetEmailSent),
+	})
+}
+
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 使用重置邮件中的令牌设置新密码，令牌只能使用一次，成功后所有设备需要重新登录
+// @Tags 账户
+// @Accept json
+// @Produce json
+// @Param request body service.ResetPasswordRequest true "重置令牌和新密码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/reset-password [post]
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	if err := h.accountService.ResetPassword(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgPasswordReset),
+	})
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的令牌完成邮箱验证
+// @Tags 账户
+// @Accept json
+// @Produce json
+// @Param request body service.VerifyEmailRequest true "验证令牌"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/verify-email [post]
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req service.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgEmailVerified),
+	})
+}
+
+// ResendVerification 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Description 向当前用户的邮箱重新发送验证链接（24 小时内有效），之前的链接随即失效
+// @Tags 账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/resend-verification [post]
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	if err := h.accountService.SendVerification(c.Request.Context(), userID.(string)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgVerificationSent),
+	})
+}
+
+// UnlockAccount 解锁账户
+// @Summary 解锁账户
+// @Description 使用解锁邮件中的令牌解除因多次登录失败导致的临时锁定
+// @Tags 账户
+// @Accept json
+// @Produce json
+// @Param request body service.UnlockAccountRequest true "解锁令牌"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/unlock [post]
+func (h *AccountHandler) UnlockAccount(c *gin.Context) {
+	var req service.UnlockAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	if err := h.accountService.Unlock(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgAccountUnlocked),
+	})
+}
@@ -0,0 +1,409 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// AdminHandler 管理员处理器，路由组需要 admin 角色
+type AdminHandler struct {
+	adminService service.AdminService
+}
+
+// NewAdminHandler 创建管理员处理器实例
+func NewAdminHandler(adminService service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// ListUsers 查询用户
+// @Summary 查询用户
+// @Description 按邮箱或昵称模糊搜索用户，可按角色和禁用状态过滤（role=coach, email~example, created_at>=2026-01-01），默认按注册时间倒序
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "邮箱或昵称关键字"
+// @Param role query string false "角色：user/coach/admin，等同于 filter=role=<角色>"
+// @Param disabled query bool false "是否已禁用"
+// @Param filter query []string false "过滤条件，可重复，如 role=coach" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 -created_at"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var req service.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	users, err := h.adminService.ListUsers(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    users,
+	})
+}
+
+// GetUser 获取用户详情
+// @Summary 获取用户详情
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    user,
+	})
+}
+
+// UpdateUserRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 修改用户角色（user/coach/admin），该用户的全部会话随即失效，重新登录后生效；不能修改自己的角色
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Param request body service.UpdateRoleRequest true "新角色"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	user, err := h.adminService.UpdateRole(c.Request.Context(), adminID.(string), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgRoleUpdated),
+		"data":    user,
+	})
+}
+
+// DisableUser 禁用账户
+// @Summary 禁用账户
+// @Description 禁用账户并撤销其全部会话，禁用期间无法登录；不能禁用自己
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/disable [post]
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.DisableUser(c.Request.Context(), adminID.(string), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgUserDisabled),
+	})
+}
+
+// EnableUser 启用账户
+// @Summary 启用账户
+// @Description 解除账户禁用，用户可以重新登录
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/enable [post]
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.EnableUser(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgUserEnabled),
+	})
+}
+
+// UnlockUser 解锁账户
+// @Summary 解锁账户
+// @Description 解除因多次登录失败导致的临时锁定
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.UnlockUser(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgAccountUnlocked),
+	})
+}
+
+// DisableUserMFA 强制关闭两步验证
+// @Summary 强制关闭两步验证
+// @Description 用户同时丢失验证器和恢复码时，由管理员关闭其两步验证
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/2fa/disable [post]
+func (h *AdminHandler) DisableUserMFA(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.DisableUserMFA(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgMFADisabled),
+	})
+}
+
+// ListFoods 查询公共食物库
+// @Summary 查询公共食物库
+// @Description 按名称模糊搜索食物，支持过滤（name~鸡, calories>=100）和排序（-protein,name）
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "名称关键字，等同于 filter=name~<关键字>"
+// @Param filter query []string false "过滤条件，可重复，如 calories>=100" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 name"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/foods [get]
+func (h *AdminHandler) ListFoods(c *gin.Context) {
+	var req service.ListFoodsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	foods, err := h.adminService.ListFoods(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    foods,
+	})
+}
+
+// CreateFood 添加食物
+// @Summary 添加食物
+// @Description 向公共食物库添加食物，营养成分按每 100 克计，名称不能重复
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.FoodRequest true "食物信息"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/admin/foods [post]
+func (h *AdminHandler) CreateFood(c *gin.Context) {
+	var req service.FoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	food, err := h.adminService.CreateFood(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCreated),
+		"data":    food,
+	})
+}
+
+// UpdateFood 修改食物
+// @Summary 修改食物
+// @Description 修改食物的名称和营养成分
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "食物ID"
+// @Param request body service.FoodRequest true "食物信息"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/foods/{id} [put]
+func (h *AdminHandler) UpdateFood(c *gin.Context) {
+	foodID := c.Param("id")
+	if foodID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "食物ID不能为空"))
+		return
+	}
+
+	var req service.FoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	food, err := h.adminService.UpdateFood(c.Request.Context(), foodID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgUpdated),
+		"data":    food,
+	})
+}
+
+// DeleteFood 删除食物
+// @Summary 删除食物
+// @Description 从公共食物库删除食物，已有的食物记录不受影响
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "食物ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/foods/{id} [delete]
+func (h *AdminHandler) DeleteFood(c *gin.Context) {
+	foodID := c.Param("id")
+	if foodID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "食物ID不能为空"))
+		return
+	}
+
+	if err := h.adminService.DeleteFood(c.Request.Context(), foodID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
+
+// userIDParam 读取路径中的用户ID，为空时写入错误并返回 false
+func userIDParam(c *gin.Context) (string, bool) {
+	userID := c.Param("id")
+	if userID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "用户ID不能为空"))
+		return "", false
+	}
+	return userID, true
+}
+
+// ListAuditLogs 查询审计日志
+// @Summary 查询审计日志
+// @Description 查询用户、营养目标、餐次、食物记录和食物的创建、修改和删除记录，包括操作用户、请求ID和变更前后的字段值，默认按时间倒序；各查询参数等同于对应字段的 filter 条件
+// @Tags 管理员
+// @Produce json
+// @Security BearerAuth
+// @Param entity_type query string false "数据类型：users/nutrition_goals/meal_records/food_records/foods"
+// @Param entity_id query string false "数据ID"
+// @Param owner_id query string false "数据所属的用户ID"
+// @Param actor_id query string false "操作用户ID"
+// @Param action query string false "操作类型：create/update/delete"
+// @Param request_id query string false "请求ID（响应头 X-Request-ID）"
+// @Param from query string false "开始日期 YYYY-MM-DD"
+// @Param to query string false "结束日期 YYYY-MM-DD（包含）"
+// @Param filter query []string false "过滤条件，可重复，如 action=update" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 -created_at"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/audit-logs [get]
+func (h *AdminHandler) ListAuditLogs(c *gin.Context) {
+	var req service.ListAuditLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	logs, err := h.adminService.ListAuditLogs(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    logs,
+	})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// APIKeyHandler 个人 API 密钥处理器
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+// NewAPIKeyHandler 创建个人 API 密钥处理器实例
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// ListAPIKeys 获取当前用户的 API 密钥
+// @Summary 获取 API 密钥列表
+// @Description 列出当前用户的全部 API 密钥（包括已过期的），只显示密钥开头几位
+// @Tags API 密钥
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	keys, err := h.apiKeyService.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    keys,
+	})
+}
+
+// CreateAPIKey 创建 API 密钥
+// @Summary 创建 API 密钥
+// @Description 创建带名称、授权范围和有效期的个人 API 密钥，密钥只在响应中返回一次。
+// @Description 调用接口时放在 X-API-Key 请求头中；可用范围：records:read、records:write、goals:read、goals:write
+// @Tags API 密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateAPIKeyRequest true "密钥名称、授权范围和有效天数"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	key, err := h.apiKeyService.Create(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgAPIKeyCreated),
+		"data":    key,
+	})
+}
+
+// RevokeAPIKey 删除 API 密钥
+// @Summary 删除 API 密钥
+// @Description 删除指定的 API 密钥，使用该密钥的请求立即失效
+// @Tags API 密钥
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "密钥ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	keyID := c.Param("id")
+	if keyID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "密钥ID不能为空"))
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request.Context(), userID.(string), keyID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
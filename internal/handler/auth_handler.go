@@ -1,23 +1,27 @@
 package handler
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
 	"github.com/ljk20041215/nutrition-tracker/internal/service"
 )
 
 type AuthHandler struct {
-	userService service.UserService
+	userService    service.UserService
+	sessionService service.SessionService
+	accountService service.AccountService
 }
 
-func NewAuthHandler(userService service.UserService) *AuthHandler {
-	return &AuthHandler{userService: userService}
+func NewAuthHandler(userService service.UserService, sessionService service.SessionService, accountService service.AccountService) *AuthHandler {
+	return &AuthHandler{userService: userService, sessionService: sessionService, accountService: accountService}
 }
 
 // Register 用户注册
 // @Summary 用户注册
-// @Description 创建新用户账户
+// @Description 创建新用户账户，并向注册邮箱发送验证邮件
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -27,29 +31,34 @@ func NewAuthHandler(userService service.UserService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req service.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		c.Error(bindError(c, err))
 		return
 	}
 
 	user, err := h.userService.Register(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	// 验证邮件发送失败不影响注册，用户可以稍后重新发送
+	if err := h.accountService.SendVerification(c.Request.Context(), user.ID); err != nil {
+		log.Printf("⚠️ 发送验证邮件失败（用户 %s）: %v", user.ID, err)
+	}
+
 	// 隐藏敏感信息
 	user.PasswordHash = ""
 
 	c.JSON(http.StatusCreated, gin.H{
 		"code":    200,
-		"message": "注册成功",
+		"message": message(c, i18n.MsgRegistered),
 		"data":    user,
 	})
 }
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录并获取访问令牌
+// @Description 用户登录并获取访问令牌和刷新令牌，每次登录创建一个新会话；开启两步验证的用户只返回挑战令牌（data.mfa），需要调用 /auth/2fa/verify 完成登录
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -59,13 +68,27 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req service.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效"})
+		c.Error(bindError(c, err))
 		return
 	}
 
-	resp, err := h.userService.Login(c.Request.Context(), &req)
+	client := service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+	resp, err := h.userService.Login(c.Request.Context(), &req, client)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	// 开启了两步验证，返回挑战令牌
+	if resp.MFA != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": message(c, i18n.MsgMFARequired),
+			"data":    resp,
+		})
 		return
 	}
 
@@ -74,7 +97,63 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "登录成功",
+		"message": message(c, i18n.MsgLoggedIn),
 		"data":    resp,
 	})
 }
+
+// Refresh 刷新访问令牌
+// @Summary 刷新访问令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌，旧刷新令牌立即失效；重复使用旧刷新令牌会注销整个会话
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req service.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgRefreshed),
+		"data":    tokens,
+	})
+}
+
+// Logout 退出登录
+// @Summary 退出登录
+// @Description 注销刷新令牌所属的会话
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req service.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	if err := h.sessionService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgLoggedOut),
+	})
+}
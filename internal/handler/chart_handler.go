@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// ChartHandler 统计图表处理器
+type ChartHandler struct {
+	chartService service.ChartService
+}
+
+// NewChartHandler 创建统计图表处理器实例
+func NewChartHandler(chartService service.ChartService) *ChartHandler {
+	return &ChartHandler{chartService: chartService}
+}
+
+// GetChart 获取 SVG 统计图表
+// @Summary 获取 SVG 统计图表
+// @Description 服务端绘制的 SVG 图表：calories 每日热量和目标线，macros 每日三大营养素热量堆叠柱状图，macro-ratio 三大营养素热量占比饼图，weight 体重变化。数据与营养报告相同，响应带 ETag，可用 If-None-Match 获取 304
+// @Tags 营养报告
+// @Produce image/svg+xml
+// @Security BearerAuth
+// @Param chart path string true "图表类型加扩展名：calories.svg、macros.svg、macro-ratio.svg、weight.svg"
+// @Param range query string false "范围：7d（默认）、30d、90d 为截至 date 的最近 N 天，week、month 为 date 所在的周或自然月"
+// @Param date query string false "日期，格式：YYYY-MM-DD，默认今天"
+// @Param width query int false "宽度（像素），240-2000，默认 600"
+// @Param height query int false "高度（像素），160-1200，默认 300"
+// @Param theme query string false "配色：light（默认）、dark"
+// @Success 200 {file} file
+// @Success 304 "图表未变化"
+// @Router /api/v1/charts/{chart}.svg [get]
+func (h *ChartHandler) GetChart(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// gin 的路径参数不能带固定后缀，整个文件名作为参数再去掉扩展名
+	file := c.Param("file")
+	chart, ok := strings.CutSuffix(file, ".svg")
+	if !ok {
+		c.Error(apperror.Validation(apperror.CodeInvalidChart, "图表类型无效，应为 calories、macros、macro-ratio 或 weight",
+			apperror.FieldError{Field: "chart", Message: file}))
+		return
+	}
+
+	var req service.ChartRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	image, err := h.chartService.Render(c.Request.Context(), userID.(string), chart, &req, localeOf(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 图表内容由数据和参数决定，直接用内容摘要作为 ETag；客户端每次都需要验证，数据变化后立即生效
+	sum := sha256.Sum256(image)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("Vary", "Authorization, Accept-Language, X-Profile-ID")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", image)
+}
+
+// etagMatches 判断 If-None-Match 请求头是否包含 etag，按弱比较处理 W/ 前缀
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// CoachHandler 教练与客户关系处理器
+type CoachHandler struct {
+	coachService service.CoachService
+}
+
+// NewCoachHandler 创建教练与客户关系处理器实例
+func NewCoachHandler(coachService service.CoachService) *CoachHandler {
+	return &CoachHandler{coachService: coachService}
+}
+
+// InviteClient 邀请客户
+// @Summary 邀请客户
+// @Description 教练按邮箱邀请客户并申请授权范围：records:read 查看饮食记录和营养统计，goals:read 查看营养目标，goals:write 设置营养目标；对方验证邮箱并接受后生效。不检查邮箱是否已注册，无论邮箱是否存在响应都相同；已是客户时重新邀请用于修改授权范围
+// @Tags 教练
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.InviteClientRequest true "客户邮箱和授权范围"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coach/invitations [post]
+func (h *CoachHandler) InviteClient(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.InviteClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	invitation, err := h.coachService.Invite(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCoachInvited),
+		"data":    invitation,
+	})
+}
+
+// ListSentInvitations 获取我发出的邀请
+// @Summary 获取我发出的邀请
+// @Description 教练发出的、等待对方接受的邀请
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coach/invitations [get]
+func (h *CoachHandler) ListSentInvitations(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	invitations, err := h.coachService.ListSentInvitations(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    invitations,
+	})
+}
+
+// RevokeInvitation 撤回邀请
+// @Summary 撤回邀请
+// @Description 教练撤回尚未接受的邀请
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "邀请ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coach/invitations/{id} [delete]
+func (h *CoachHandler) RevokeInvitation(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	id := c.Param("id")
+	if id == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "邀请ID不能为空"))
+		return
+	}
+
+	if err := h.coachService.RevokeInvitation(c.Request.Context(), userID.(string), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCoachInvitationRevoked),
+	})
+}
+
+// ListClients 获取我的客户
+// @Summary 获取我的客户
+// @Description 教练的客户，client.id 可作为 X-Profile-ID 请求头查看客户的记录；尚未接受的邀请见 GET /coach/invitations
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coach/clients [get]
+func (h *CoachHandler) ListClients(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	links, err := h.coachService.ListClients(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    links,
+	})
+}
+
+// EndClient 结束与客户的关系
+// @Summary 结束与客户的关系
+// @Description 教练结束与客户的关系，立即失去访问权限
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "教练关系ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coach/clients/{id} [delete]
+func (h *CoachHandler) EndClient(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	id := c.Param("id")
+	if id == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "教练关系ID不能为空"))
+		return
+	}
+
+	if err := h.coachService.End(c.Request.Context(), userID.(string), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCoachEnded),
+	})
+}
+
+// ListCoaches 获取我的教练
+// @Summary 获取我的教练
+// @Description 当前用户的教练及其授权范围；待处理的邀请见 GET /coaches/invitations
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coaches [get]
+func (h *CoachHandler) ListCoaches(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	links, err := h.coachService.ListCoaches(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    links,
+	})
+}
+
+// ListCoachInvitations 获取教练邀请
+// @Summary 获取教练邀请
+// @Description 发给当前用户邮箱、等待接受的教练邀请，包括教练申请的授权范围
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coaches/invitations [get]
+func (h *CoachHandler) ListCoachInvitations(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	invitations, err := h.coachService.ListInvitations(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    invitations,
+	})
+}
+
+// AcceptCoach 接受教练邀请
+// @Summary 接受教练邀请
+// @Description 接受后教练可以按邀请中的授权范围查看你的记录，每次访问都会被记录；已是该教练的客户时更新授权范围；邮箱验证之前不能接受或拒绝邀请
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "邀请ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coaches/invitations/{id}/accept [post]
+func (h *CoachHandler) AcceptCoach(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	id := c.Param("id")
+	if id == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "邀请ID不能为空"))
+		return
+	}
+
+	link, err := h.coachService.Accept(c.Request.Context(), userID.(string), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCoachAccepted),
+		"data":    link,
+	})
+}
+
+// DeclineCoach 拒绝教练邀请
+// @Summary 拒绝教练邀请
+// @Description 拒绝待处理的教练邀请，教练可以重新邀请
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "邀请ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coaches/invitations/{id}/decline [post]
+func (h *CoachHandler) DeclineCoach(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	id := c.Param("id")
+	if id == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "邀请ID不能为空"))
+		return
+	}
+
+	if err := h.coachService.Decline(c.Request.Context(), userID.(string), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCoachDeclined),
+	})
+}
+
+// EndCoach 结束与教练的关系
+// @Summary 结束与教练的关系
+// @Description 客户结束与教练的关系，教练立即失去访问权限
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "教练关系ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coaches/{id} [delete]
+func (h *CoachHandler) EndCoach(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	id := c.Param("id")
+	if id == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "教练关系ID不能为空"))
+		return
+	}
+
+	if err := h.coachService.End(c.Request.Context(), userID.(string), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCoachEnded),
+	})
+}
+
+// ListAccessLogs 获取教练访问记录
+// @Summary 获取教练访问记录
+// @Description 教练查看或修改你的记录的每次访问，支持过滤（coach_id=<ID>, method=PUT, status>=400）和排序，默认按时间倒序
+// @Tags 教练
+// @Produce json
+// @Security BearerAuth
+// @Param filter query []string false "过滤条件，可重复，如 method=PUT" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 -created_at"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/coaches/access-logs [get]
+func (h *CoachHandler) ListAccessLogs(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	req, err := bindListQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	logs, err := h.coachService.ListAccessLogs(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    logs,
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/export"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// DiaryExportHandler 饮食日记导出处理器
+type DiaryExportHandler struct {
+	exportService service.DiaryExportService
+}
+
+// NewDiaryExportHandler 创建饮食日记导出处理器实例
+func NewDiaryExportHandler(exportService service.DiaryExportService) *DiaryExportHandler {
+	return &DiaryExportHandler{exportService: exportService}
+}
+
+// ExportDiary 导出饮食日记
+// @Summary 导出饮食日记
+// @Description 以 CSV、JSON 或 XLSX 格式下载日期范围内的全部食物记录，每行带有餐次日期和类型、当天合计和营养目标，列定义见 service.DiaryColumns
+// @Tags 数据导出
+// @Produce text/csv
+// @Produce application/json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param from query string true "开始日期，格式：YYYY-MM-DD"
+// @Param to query string true "结束日期（包含），格式：YYYY-MM-DD"
+// @Param format query string false "导出格式：csv（默认）、json、xlsx"
+// @Success 200 {file} file
+// @Router /api/v1/export [get]
+func (h *DiaryExportHandler) ExportDiary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.DiaryExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	diary, err := h.exportService.Prepare(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(diary.Format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, diary.Filename()))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// 响应头已经发出，写入失败只能记录日志
+	if err := diary.WriteTo(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("❌ 导出饮食日记失败（用户 %s）: %v", userID, err)
+	}
+}
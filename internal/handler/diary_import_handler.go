@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// maxImportSize 导入文件的大小上限
+const maxImportSize = 20 << 20
+
+// DiaryImportHandler 饮食日记导入处理器
+type DiaryImportHandler struct {
+	importService service.DiaryImportService
+}
+
+// NewDiaryImportHandler 创建饮食日记导入处理器实例
+func NewDiaryImportHandler(importService service.DiaryImportService) *DiaryImportHandler {
+	return &DiaryImportHandler{importService: importService}
+}
+
+// ImportDiary 导入其他应用的饮食日记
+// @Summary 导入其他应用的饮食日记
+// @Description 上传 MyFitnessPal 或 Cronometer 导出的 CSV 文件，按名称匹配食物库，找不到的食物作为快速添加条目导入。
+// @Description 重复导入同一文件时跳过已导入的行；dry_run=true 时只返回预览，不写入任何记录
+// @Tags 数据导入
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param source formData string true "来源：myfitnesspal、cronometer"
+// @Param dry_run formData bool false "只预览，不导入"
+// @Param file formData file true "导出的 CSV 文件，最大 20MB"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/imports [post]
+func (h *DiaryImportHandler) ImportDiary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 预留 1MB 给表单的其他字段和分隔符
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize+1<<20)
+
+	var req service.DiaryImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(importBindError(c, err))
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(importBindError(c, err))
+		return
+	}
+	if header.Size > maxImportSize {
+		c.Error(apperror.BadRequest(apperror.CodeImportFileTooLarge, "导入文件不能超过 20MB"))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.Error(apperror.Internal("读取导入文件失败", err))
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.Import(c.Request.Context(), userID.(string), &req, file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	msg := i18n.MsgDiaryImported
+	if result.DryRun {
+		msg = i18n.MsgDiaryImportPreviewed
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, msg),
+		"data":    result,
+	})
+}
+
+// importBindError 请求体超过大小上限时返回对应的错误，其他情况与 bindError 相同
+func importBindError(c *gin.Context, err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apperror.BadRequest(apperror.CodeImportFileTooLarge, "导入文件不能超过 20MB")
+	}
+	if errors.Is(err, http.ErrMissingFile) {
+		return apperror.Validation(apperror.CodeImportInvalidFile, "请上传导入文件",
+			apperror.FieldError{Field: "file", Message: err.Error()})
+	}
+	return bindError(c, err)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+)
+
+// ErrorHandler 错误渲染中间件
+// 处理器通过 c.Error(err) 记录错误后直接返回，由这里按请求语言统一输出错误响应：
+//
+//	{"code": 404, "error_code": "MEAL_RECORD_NOT_FOUND", "message": "餐次记录不存在"}
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperror.As(c.Errors.Last().Err)
+		status := appErr.Status()
+		if appErr.Err != nil {
+			log.Printf("❌ %s %s: %v", c.Request.Method, c.Request.URL.Path, appErr)
+		}
+
+		body := gin.H{
+			"code":       status,
+			"error_code": appErr.Code,
+			"message":    i18n.T(localeOf(c), appErr.Code, appErr.Message),
+		}
+		if len(appErr.Fields) > 0 {
+			body["errors"] = appErr.Fields
+		}
+		if appErr.RetryAfter > 0 {
+			seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			body["retry_after"] = seconds
+		}
+		c.AbortWithStatusJSON(status, body)
+	}
+}
+
+// bindError 把请求绑定错误转换为领域错误，字段校验失败返回 422 并按请求语言列出字段
+func bindError(c *gin.Context, err error) *apperror.Error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "请求参数无效: "+err.Error())
+	}
+
+	locale := localeOf(c)
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, apperror.FieldError{
+			Field:   fe.Field(),
+			Message: i18n.TranslateFieldError(locale, fe),
+		})
+	}
+	return apperror.Validation(apperror.CodeValidation, "请求参数校验失败", fields...)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// ExerciseRecordHandler 运动记录处理器
+type ExerciseRecordHandler struct {
+	exerciseService service.ExerciseRecordService
+}
+
+// NewExerciseRecordHandler 创建运动记录处理器实例
+func NewExerciseRecordHandler(exerciseService service.ExerciseRecordService) *ExerciseRecordHandler {
+	return &ExerciseRecordHandler{exerciseService: exerciseService}
+}
+
+// CreateExerciseRecord 创建运动记录
+// @Summary 创建运动记录
+// @Description 记录一次运动，未填写消耗热量时根据 MET 和体重估算
+// @Tags 运动记录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateExerciseRecordRequest true "创建运动记录请求"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/exercises [post]
+func (h *ExerciseRecordHandler) CreateExerciseRecord(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.CreateExerciseRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	exerciseRecord, err := h.exerciseService.CreateExerciseRecord(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCreated),
+		"data":    exerciseRecord,
+	})
+}
+
+// GetExerciseRecordsByDate 获取指定日期的运动记录
+// @Summary 获取指定日期的运动记录
+// @Description 获取用户在指定日期的所有运动记录
+// @Tags 运动记录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string true "日期，格式：YYYY-MM-DD"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/exercises [get]
+func (h *ExerciseRecordHandler) GetExerciseRecordsByDate(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取查询参数
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		// 如果没有提供日期，默认使用今天
+		dateStr = time.Now().Format("2006-01-02")
+	}
+
+	// 解析日期
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD"))
+		return
+	}
+
+	exerciseRecords, err := h.exerciseService.GetExerciseRecordsByDate(c.Request.Context(), userID.(string), date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    exerciseRecords,
+	})
+}
+
+// DeleteExerciseRecord 删除运动记录
+// @Summary 删除运动记录
+// @Description 根据ID删除运动记录
+// @Tags 运动记录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "运动记录ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/exercises/{id} [delete]
+func (h *ExerciseRecordHandler) DeleteExerciseRecord(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	exerciseID := c.Param("id")
+	if exerciseID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "运动记录ID不能为空"))
+		return
+	}
+
+	if err := h.exerciseService.DeleteExerciseRecord(c.Request.Context(), userID.(string), exerciseID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// FoodCatalogHandler 食物库处理器
+type FoodCatalogHandler struct {
+	catalogService service.FoodCatalogService
+}
+
+// NewFoodCatalogHandler 创建食物库处理器实例
+func NewFoodCatalogHandler(catalogService service.FoodCatalogService) *FoodCatalogHandler {
+	return &FoodCatalogHandler{catalogService: catalogService}
+}
+
+// ListFoods 浏览食物库
+// @Summary 浏览食物库
+// @Description 分页查询食物库，支持过滤（name~鸡, calories>=100）和排序（-protein,name）
+// @Tags 食物库
+// @Produce json
+// @Security BearerAuth
+// @Param filter query []string false "过滤条件，可重复，如 calories>=100" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 name"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/foods [get]
+func (h *FoodCatalogHandler) ListFoods(c *gin.Context) {
+	req, err := bindListQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := h.catalogService.ListFoods(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    page,
+	})
+}
@@ -2,9 +2,10 @@ package handler
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
 	"github.com/ljk20041215/nutrition-tracker/internal/service"
 )
 
@@ -32,27 +33,27 @@ func (h *FoodRecordHandler) CreateFoodRecord(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	// 绑定请求参数
 	var req service.CreateFoodRecordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		c.Error(bindError(c, err))
 		return
 	}
 
 	// 创建食物记录
 	foodRecord, err := h.foodService.CreateFoodRecord(c.Request.Context(), userID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建食物记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "创建成功",
+		"message": message(c, i18n.MsgCreated),
 		"data":    foodRecord,
 	})
 }
@@ -71,110 +72,116 @@ func (h *FoodRecordHandler) GetFoodRecord(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	// 获取路径参数
 	foodID := c.Param("id")
 	if foodID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "食物记录ID不能为空"})
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "食物记录ID不能为空"))
 		return
 	}
 
 	foodRecord, err := h.foodService.GetFoodRecord(c.Request.Context(), userID.(string), foodID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "获取食物记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "获取成功",
+		"message": message(c, i18n.MsgFetched),
 		"data":    foodRecord,
 	})
 }
 
-// GetFoodRecordsByMeal 获取餐次下的食物记录
+// ListFoodRecordsByMeal 获取餐次下的食物记录
 // @Summary 获取餐次下的食物记录
-// @Description 根据餐次ID获取所有食物记录
+// @Description 分页查询餐次下的食物记录，支持过滤和排序
 // @Tags 食物记录
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param meal_id query string true "餐次记录ID"
+// @Param filter query []string false "过滤条件，可重复，如 calories>=100" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 created_at"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/food-records/meal [get]
-func (h *FoodRecordHandler) GetFoodRecordsByMeal(c *gin.Context) {
+func (h *FoodRecordHandler) ListFoodRecordsByMeal(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	// 获取查询参数
 	mealID := c.Query("meal_id")
 	if mealID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "餐次记录ID不能为空"})
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "餐次记录ID不能为空"))
 		return
 	}
 
-	foodRecords, err := h.foodService.GetFoodRecordsByMeal(c.Request.Context(), userID.(string), mealID)
+	req, err := bindListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取食物记录失败: " + err.Error()})
+		c.Error(err)
+		return
+	}
+
+	page, err := h.foodService.ListFoodRecordsByMeal(c.Request.Context(), userID.(string), mealID, req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "获取成功",
-		"data":    foodRecords,
+		"message": message(c, i18n.MsgFetched),
+		"data":    page,
 	})
 }
 
-// GetFoodRecordsByDate 获取指定日期的食物记录
-// @Summary 获取指定日期的食物记录
-// @Description 获取用户在指定日期的所有食物记录
+// ListFoodRecords 查询食物记录
+// @Summary 查询食物记录
+// @Description 分页查询用户的食物记录，支持过滤（date=2024-01-15, meal_type=lunch, calories>=100）和排序（-calories）
 // @Tags 食物记录
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param date query string true "日期，格式：YYYY-MM-DD"
+// @Param date query string false "日期，格式：YYYY-MM-DD，等同于 filter=date=YYYY-MM-DD"
+// @Param filter query []string false "过滤条件，可重复" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 created_at"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/food-records [get]
-func (h *FoodRecordHandler) GetFoodRecordsByDate(c *gin.Context) {
+func (h *FoodRecordHandler) ListFoodRecords(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
-	// 获取查询参数
-	dateStr := c.Query("date")
-	if dateStr == "" {
-		// 如果没有提供日期，默认使用今天
-		dateStr = time.Now().Format("2006-01-02")
-	}
-
-	// 解析日期
-	date, err := time.Parse("2006-01-02", dateStr)
+	req, err := bindListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "日期格式错误，应为 YYYY-MM-DD"})
+		c.Error(err)
 		return
 	}
 
-	foodRecords, err := h.foodService.GetFoodRecordsByDate(c.Request.Context(), userID.(string), date)
+	page, err := h.foodService.ListFoodRecords(c.Request.Context(), userID.(string), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取食物记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "获取成功",
-		"data":    foodRecords,
+		"message": message(c, i18n.MsgFetched),
+		"data":    page,
 	})
 }
 
@@ -193,32 +200,32 @@ func (h *FoodRecordHandler) UpdateFoodRecord(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	// 获取路径参数
 	foodID := c.Param("id")
 	if foodID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "食物记录ID不能为空"})
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "食物记录ID不能为空"))
 		return
 	}
 
 	var req service.UpdateFoodRecordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		c.Error(bindError(c, err))
 		return
 	}
 
 	foodRecord, err := h.foodService.UpdateFoodRecord(c.Request.Context(), userID.(string), foodID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新食物记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "更新成功",
+		"message": message(c, i18n.MsgUpdated),
 		"data":    foodRecord,
 	})
 }
@@ -237,25 +244,25 @@ func (h *FoodRecordHandler) DeleteFoodRecord(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	// 获取路径参数
 	foodID := c.Param("id")
 	if foodID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "食物记录ID不能为空"})
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "食物记录ID不能为空"))
 		return
 	}
 
 	if err := h.foodService.DeleteFoodRecord(c.Request.Context(), userID.(string), foodID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除食物记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "删除成功",
+		"message": message(c, i18n.MsgDeleted),
 	})
 }
 
@@ -0,0 +1,468 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// HouseholdHandler 家庭共享处理器
+type HouseholdHandler struct {
+	householdService service.HouseholdService
+}
+
+// NewHouseholdHandler 创建家庭共享处理器实例
+func NewHouseholdHandler(householdService service.HouseholdService) *HouseholdHandler {
+	return &HouseholdHandler{householdService: householdService}
+}
+
+// CreateHousehold 创建家庭
+// @Summary 创建家庭
+// @Description 创建家庭，创建者成为 owner；每个用户只能属于一个家庭
+// @Tags 家庭共享
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateHouseholdRequest true "家庭名称"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households [post]
+func (h *HouseholdHandler) CreateHousehold(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.CreateHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	household, err := h.householdService.Create(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCreated),
+		"data":    household,
+	})
+}
+
+// GetHousehold 获取我的家庭
+// @Summary 获取我的家庭
+// @Description 获取当前用户所在的家庭和成员列表，成员的 user_id 可作为 X-Profile-ID 请求头代为记录
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me [get]
+func (h *HouseholdHandler) GetHousehold(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	household, err := h.householdService.Get(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    household,
+	})
+}
+
+// DeleteHousehold 删除家庭
+// @Summary 删除家庭
+// @Description 只有创建者可以删除，需要先移除其他成年成员和受抚养成员
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me [delete]
+func (h *HouseholdHandler) DeleteHousehold(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	if err := h.householdService.Delete(c.Request.Context(), userID.(string)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
+
+// InviteMember 邀请家庭成员
+// @Summary 邀请家庭成员
+// @Description 创建者按邮箱邀请成年成员，对方登录后接受邀请才会加入家庭；无论该邮箱是否已注册，响应都相同
+// @Tags 家庭共享
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.InviteHouseholdMemberRequest true "成员邮箱"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me/invitations [post]
+func (h *HouseholdHandler) InviteMember(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.InviteHouseholdMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	invitation, err := h.householdService.Invite(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgHouseholdInvited),
+		"data":    invitation,
+	})
+}
+
+// RevokeInvitation 撤回家庭邀请
+// @Summary 撤回家庭邀请
+// @Description 创建者撤回尚未接受的邀请
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "邀请ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me/invitations/{id} [delete]
+func (h *HouseholdHandler) RevokeInvitation(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	invitationID := c.Param("id")
+	if invitationID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "邀请ID不能为空"))
+		return
+	}
+
+	if err := h.householdService.RevokeInvitation(c.Request.Context(), userID.(string), invitationID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgHouseholdInvitationRevoked),
+	})
+}
+
+// ListInvitations 获取家庭邀请
+// @Summary 获取家庭邀请
+// @Description 发给当前用户邮箱、等待接受的家庭邀请
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/invitations [get]
+func (h *HouseholdHandler) ListInvitations(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	invitations, err := h.householdService.ListInvitations(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    invitations,
+	})
+}
+
+// AcceptInvitation 接受家庭邀请
+// @Summary 接受家庭邀请
+// @Description 接受后成为家庭的成年成员，可以代为记录受抚养成员；已加入其他家庭时需要先退出；邮箱验证之前不能接受或拒绝邀请
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "邀请ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/invitations/{id}/accept [post]
+func (h *HouseholdHandler) AcceptInvitation(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	invitationID := c.Param("id")
+	if invitationID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "邀请ID不能为空"))
+		return
+	}
+
+	household, err := h.householdService.AcceptInvitation(c.Request.Context(), userID.(string), invitationID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgHouseholdJoined),
+		"data":    household,
+	})
+}
+
+// DeclineInvitation 拒绝家庭邀请
+// @Summary 拒绝家庭邀请
+// @Description 邮箱验证之前不能拒绝邀请
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "邀请ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/invitations/{id}/decline [post]
+func (h *HouseholdHandler) DeclineInvitation(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	invitationID := c.Param("id")
+	if invitationID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "邀请ID不能为空"))
+		return
+	}
+
+	if err := h.householdService.DeclineInvitation(c.Request.Context(), userID.(string), invitationID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgHouseholdInvitationDeclined),
+	})
+}
+
+// RemoveMember 移除家庭成员
+// @Summary 移除家庭成员
+// @Description 创建者移除成年成员
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "成员的用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me/members/{id} [delete]
+func (h *HouseholdHandler) RemoveMember(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	memberID := c.Param("id")
+	if memberID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "成员ID不能为空"))
+		return
+	}
+
+	if err := h.householdService.RemoveMember(c.Request.Context(), userID.(string), memberID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
+
+// LeaveHousehold 退出家庭
+// @Summary 退出家庭
+// @Description 成年成员退出家庭，创建者需要删除家庭
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me/leave [post]
+func (h *HouseholdHandler) LeaveHousehold(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	if err := h.householdService.Leave(c.Request.Context(), userID.(string)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgHouseholdLeft),
+	})
+}
+
+// CreateDependent 添加受抚养成员
+// @Summary 添加受抚养成员
+// @Description 为儿童或老人创建没有登录账户的档案，档案拥有独立的营养目标和饮食记录
+// @Tags 家庭共享
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateDependentRequest true "昵称和身体数据"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me/dependents [post]
+func (h *HouseholdHandler) CreateDependent(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.CreateDependentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	dependent, err := h.householdService.CreateDependent(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 隐藏密码哈希
+	dependent.PasswordHash = ""
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCreated),
+		"data":    dependent,
+	})
+}
+
+// UpdateDependent 修改受抚养成员档案
+// @Summary 修改受抚养成员档案
+// @Description 家庭中的成年成员都可以修改受抚养成员的昵称和身体数据
+// @Tags 家庭共享
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "受抚养成员的用户ID"
+// @Param request body service.UpdateProfileRequest true "档案信息"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me/dependents/{id} [put]
+func (h *HouseholdHandler) UpdateDependent(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	dependentID := c.Param("id")
+	if dependentID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "成员ID不能为空"))
+		return
+	}
+
+	var req service.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	dependent, err := h.householdService.UpdateDependent(c.Request.Context(), userID.(string), dependentID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgUpdated),
+		"data":    dependent,
+	})
+}
+
+// DeleteDependent 删除受抚养成员
+// @Summary 删除受抚养成员
+// @Description 删除受抚养成员的档案，饮食记录在保留期结束后永久删除
+// @Tags 家庭共享
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "受抚养成员的用户ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/households/me/dependents/{id} [delete]
+func (h *HouseholdHandler) DeleteDependent(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	dependentID := c.Param("id")
+	if dependentID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "成员ID不能为空"))
+		return
+	}
+
+	if err := h.householdService.DeleteDependent(c.Request.Context(), userID.(string), dependentID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
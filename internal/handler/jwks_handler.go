@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+)
+
+// JWKS 公开签名公钥
+// @Summary 获取签名公钥
+// @Description 以 JWKS 格式返回 RS256/EdDSA 签名公钥，其他服务可据此按 kid 验证本服务签发的访问令牌；HS256 密钥不会公开
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Router /.well-known/jwks.json [get]
+func JWKS(keys *auth.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keys.JWKS())
+	}
+}
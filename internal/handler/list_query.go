@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+)
+
+// bindListQuery 绑定列表接口的 filter、sort、cursor、limit 参数
+// 兼容旧的 date 参数：提供时转换为 date=YYYY-MM-DD 过滤条件
+func bindListQuery(c *gin.Context) (*query.Request, error) {
+	var req query.Request
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, bindError(c, err)
+	}
+
+	if dateStr := c.Query("date"); dateStr != "" {
+		if _, err := time.Parse("2006-01-02", dateStr); err != nil {
+			return nil, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+		}
+		req.Filter = append(req.Filter, "date="+dateStr)
+	}
+	return &req, nil
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// localeKey 上下文中保存协商后语言的键
+const localeKey = "locale"
+
+// Locale 语言协商中间件，需注册在认证中间件之后
+// 已登录用户优先使用个人资料中的语言偏好，其次是 Accept-Language 请求头
+func Locale(userService service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		if userID, exists := c.Get("user_id"); exists {
+			if user, err := userService.GetProfile(c.Request.Context(), userID.(string)); err == nil && i18n.Supported(user.Language) {
+				locale = user.Language
+			}
+		}
+		c.Set(localeKey, locale)
+		c.Next()
+	}
+}
+
+// localeOf 返回当前请求的语言，未经过 Locale 中间件的公开路由直接按请求头协商
+func localeOf(c *gin.Context) string {
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// message 返回成功消息码在当前请求语言下的文本
+func message(c *gin.Context, code string) string {
+	return i18n.T(localeOf(c), code, code)
+}
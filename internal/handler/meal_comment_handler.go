@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// MealCommentHandler 餐次评论处理器
+type MealCommentHandler struct {
+	commentService service.MealCommentService
+}
+
+// NewMealCommentHandler 创建餐次评论处理器实例
+func NewMealCommentHandler(commentService service.MealCommentService) *MealCommentHandler {
+	return &MealCommentHandler{commentService: commentService}
+}
+
+// ListComments 获取餐次评论
+// @Summary 获取餐次评论
+// @Description 获取餐次下的全部评论；教练通过 X-Profile-ID 请求头查看客户餐次的评论
+// @Tags 餐次评论
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "餐次记录ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/meals/{id}/comments [get]
+func (h *MealCommentHandler) ListComments(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	mealID := c.Param("id")
+	if mealID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "餐次记录ID不能为空"))
+		return
+	}
+
+	comments, err := h.commentService.List(c.Request.Context(), userID.(string), mealID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    comments,
+	})
+}
+
+// CreateComment 发表餐次评论
+// @Summary 发表餐次评论
+// @Description 在餐次下发表评论，作者为实际登录的用户；教练通过 X-Profile-ID 请求头评论客户的餐次
+// @Tags 餐次评论
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "餐次记录ID"
+// @Param request body service.CreateMealCommentRequest true "评论内容"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/meals/{id}/comments [post]
+func (h *MealCommentHandler) CreateComment(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	mealID := c.Param("id")
+	if mealID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "餐次记录ID不能为空"))
+		return
+	}
+
+	var req service.CreateMealCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	comment, err := h.commentService.Create(c.Request.Context(), userID.(string), actorOf(c), mealID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCreated),
+		"data":    comment,
+	})
+}
+
+// DeleteComment 删除餐次评论
+// @Summary 删除餐次评论
+// @Description 删除自己发表的评论
+// @Tags 餐次评论
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "餐次记录ID"
+// @Param comment_id path string true "评论ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/meals/{id}/comments/{comment_id} [delete]
+func (h *MealCommentHandler) DeleteComment(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	mealID := c.Param("id")
+	commentID := c.Param("comment_id")
+	if mealID == "" || commentID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "餐次记录ID和评论ID不能为空"))
+		return
+	}
+
+	if err := h.commentService.Delete(c.Request.Context(), userID.(string), actorOf(c), mealID, commentID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
@@ -2,9 +2,10 @@ package handler
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
 	"github.com/ljk20041215/nutrition-tracker/internal/service"
 )
 
@@ -32,29 +33,67 @@ func (h *MealRecordHandler) CreateMealRecord(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	var req service.CreateMealRecordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		c.Error(bindError(c, err))
 		return
 	}
 
 	mealRecord, err := h.mealService.CreateMealRecord(c.Request.Context(), userID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建餐次记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "创建成功",
+		"message": message(c, i18n.MsgCreated),
 		"data":    mealRecord,
 	})
 }
 
+// CreateSharedMeal 记录共享餐次
+// @Summary 记录共享餐次
+// @Description 一顿饭的食物按份数分给本人和家庭中的受抚养成员，为每个成员写入各自的餐次和食物记录；
+// @Description 成员当天已有该餐次时追加到原记录
+// @Tags 餐次记录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateSharedMealRequest true "日期、餐次、食物总份量和成员份数"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/meals/shared [post]
+func (h *MealRecordHandler) CreateSharedMeal(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.CreateSharedMealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	portions, err := h.mealService.CreateSharedMeal(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCreated),
+		"data":    portions,
+	})
+}
+
 // GetMealRecord 获取餐次记录
 // @Summary 获取餐次记录
 // @Description 根据ID获取餐次记录
@@ -69,72 +108,68 @@ func (h *MealRecordHandler) GetMealRecord(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	// 获取路径参数
 	mealID := c.Param("id")
 	if mealID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "餐次记录ID不能为空"})
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "餐次记录ID不能为空"))
 		return
 	}
 
 	mealRecord, err := h.mealService.GetMealRecord(c.Request.Context(), userID.(string), mealID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "获取餐次记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "获取成功",
+		"message": message(c, i18n.MsgFetched),
 		"data":    mealRecord,
 	})
 }
 
-// GetMealRecordsByDate 获取指定日期的餐次记录
-// @Summary 获取指定日期的餐次记录
-// @Description 获取用户在指定日期的所有餐次记录
+// ListMealRecords 查询餐次记录
+// @Summary 查询餐次记录
+// @Description 分页查询用户的餐次记录，支持过滤（date>=2024-01-01, meal_type=lunch）和排序，默认按日期倒序
 // @Tags 餐次记录
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param date query string true "日期，格式：YYYY-MM-DD"
+// @Param date query string false "日期，格式：YYYY-MM-DD，等同于 filter=date=YYYY-MM-DD"
+// @Param filter query []string false "过滤条件，可重复" collectionFormat(multi)
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序，默认 -date,meal_type"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/meals [get]
-func (h *MealRecordHandler) GetMealRecordsByDate(c *gin.Context) {
+func (h *MealRecordHandler) ListMealRecords(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
-	// 获取查询参数
-	dateStr := c.Query("date")
-	if dateStr == "" {
-		// 如果没有提供日期，默认使用今天
-		dateStr = time.Now().Format("2006-01-02")
-	}
-
-	// 解析日期
-	date, err := time.Parse("2006-01-02", dateStr)
+	req, err := bindListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "日期格式错误，应为 YYYY-MM-DD"})
+		c.Error(err)
 		return
 	}
 
-	mealRecords, err := h.mealService.GetMealRecordsByDate(c.Request.Context(), userID.(string), date)
+	page, err := h.mealService.ListMealRecords(c.Request.Context(), userID.(string), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取餐次记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "获取成功",
-		"data":    mealRecords,
+		"message": message(c, i18n.MsgFetched),
+		"data":    page,
 	})
 }
 
@@ -152,24 +187,24 @@ func (h *MealRecordHandler) DeleteMealRecord(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	// 获取路径参数
 	mealID := c.Param("id")
 	if mealID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "餐次记录ID不能为空"})
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "餐次记录ID不能为空"))
 		return
 	}
 
 	if err := h.mealService.DeleteMealRecord(c.Request.Context(), userID.(string), mealID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除餐次记录失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "删除成功",
+		"message": message(c, i18n.MsgDeleted),
 	})
 }
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// MFAHandler 两步验证处理器
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+// NewMFAHandler 创建两步验证处理器实例
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// Status 查询两步验证状态
+// @Summary 查询两步验证状态
+// @Description 返回是否开启两步验证以及剩余可用的恢复码数量
+// @Tags 两步验证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa [get]
+func (h *MFAHandler) Status(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	status, err := h.mfaService.Status(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    status,
+	})
+}
+
+// Setup 生成两步验证密钥
+// @Summary 生成两步验证密钥
+// @Description 生成新的 TOTP 密钥和 otpauth:// 链接（用于生成二维码），需要再调用开启接口提交验证码确认
+// @Tags 两步验证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/setup [post]
+func (h *MFAHandler) Setup(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	setup, err := h.mfaService.Setup(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgMFASetup),
+		"data":    setup,
+	})
+}
+
+// Enable 开启两步验证
+// @Summary 开启两步验证
+// @Description 提交验证器应用生成的验证码确认密钥，成功后返回 10 个一次性恢复码（只显示一次）
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.MFACodeRequest true "验证码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/enable [post]
+func (h *MFAHandler) Enable(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	codes, err := h.mfaService.Enable(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgMFAEnabled),
+		"data":    codes,
+	})
+}
+
+// Disable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 校验密码和验证码（或恢复码）后关闭两步验证，恢复码全部作废
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.DisableMFARequest true "密码和验证码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), userID.(string), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgMFADisabled),
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 校验验证码后生成 10 个新的恢复码，旧恢复码全部失效
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.MFACodeRequest true "验证码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgRecoveryCodesNew),
+		"data":    codes,
+	})
+}
+
+// Verify 提交两步验证码完成登录
+// @Summary 提交两步验证码完成登录
+// @Description 使用登录接口返回的挑战令牌（5 分钟内有效）和验证码或恢复码换取访问令牌和刷新令牌
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param request body service.VerifyMFARequest true "挑战令牌和验证码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/verify [post]
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var req service.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	client := service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+	resp, err := h.mfaService.Verify(c.Request.Context(), &req, client)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 隐藏密码哈希
+	resp.User.PasswordHash = ""
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgLoggedIn),
+		"data":    resp,
+	})
+}
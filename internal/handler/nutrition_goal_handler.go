@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
 	"github.com/ljk20041215/nutrition-tracker/internal/service"
 )
 
@@ -28,19 +30,19 @@ func (h *NutritionGoalHandler) GetNutritionGoal(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	goal, err := h.goalService.GetNutritionGoal(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "获取营养目标失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "获取成功",
+		"message": message(c, i18n.MsgFetched),
 		"data":    goal,
 	})
 }
@@ -59,25 +61,25 @@ func (h *NutritionGoalHandler) SetNutritionGoal(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	var req service.SetGoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		c.Error(bindError(c, err))
 		return
 	}
 
 	goal, err := h.goalService.SetNutritionGoal(c.Request.Context(), userID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "设置营养目标失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "设置成功",
+		"message": message(c, i18n.MsgSaved),
 		"data":    goal,
 	})
 }
@@ -96,25 +98,25 @@ func (h *NutritionGoalHandler) CalculateNutritionGoal(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	var req service.CalculateGoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		c.Error(bindError(c, err))
 		return
 	}
 
 	goal, err := h.goalService.CalculateNutritionGoal(c.Request.Context(), userID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "计算营养目标失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "计算成功",
+		"message": message(c, i18n.MsgCalculated),
 		"data":    goal,
 	})
 }
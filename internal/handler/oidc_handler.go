@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// OIDCHandler 第三方登录处理器
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+// NewOIDCHandler 创建第三方登录处理器实例
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// Providers 获取可用的第三方登录方式
+// @Summary 获取第三方登录方式
+// @Description 返回配置文件中启用的 OIDC 提供方及其登录地址
+// @Tags 第三方登录
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/oidc/providers [get]
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    h.oidcService.Providers(),
+	})
+}
+
+// Login 跳转到第三方登录页面
+// @Summary 发起第三方登录
+// @Description 生成 state、nonce 和 PKCE 参数后重定向到提供方的授权页面，授权后提供方回调 callback 接口
+// @Tags 第三方登录
+// @Param provider path string true "提供方名称"
+// @Param login_hint query string false "预填的登录邮箱"
+// @Success 302
+// @Router /api/v1/auth/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, err := h.oidcService.Begin(c.Request.Context(), c.Param("provider"), c.Query("login_hint"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 第三方登录回调
+// @Summary 第三方登录回调
+// @Description 校验 state 后用授权码换取 ID 令牌，按外部身份或已验证的邮箱找到用户（没有则创建）并登录；
+// @Description 开启了两步验证的用户返回挑战令牌。GET 接收提供方的重定向，POST 供前端转交 code 和 state
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供方名称"
+// @Param code query string false "授权码"
+// @Param state query string false "登录状态"
+// @Param request body service.OIDCCallbackRequest false "回调参数（POST）"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/oidc/{provider}/callback [get]
+// @Router /api/v1/auth/oidc/{provider}/callback [post]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req service.OIDCCallbackRequest
+	var err error
+	if c.Request.Method == http.MethodPost {
+		err = c.ShouldBindJSON(&req)
+	} else {
+		err = c.ShouldBindQuery(&req)
+	}
+	if err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	client := service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+	resp, err := h.oidcService.Complete(c.Request.Context(), c.Param("provider"), &req, client)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 开启了两步验证，返回挑战令牌
+	if resp.MFA != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": message(c, i18n.MsgMFARequired),
+			"data":    resp,
+		})
+		return
+	}
+
+	// 隐藏密码哈希
+	resp.User.PasswordHash = ""
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgLoggedIn),
+		"data":    resp,
+	})
+}
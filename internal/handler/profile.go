@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// ProfileHeader 切换代为操作的档案的请求头，值为家庭中受抚养成员或教练客户的用户ID
+const ProfileHeader = "X-Profile-ID"
+
+// ActingProfile 代为操作中间件，需注册在认证和语言中间件之后
+// 请求头指定了其他档案时，检查当前用户是否有权代为操作，通过后把上下文中的 user_id 换成该档案，
+// 原用户ID保存在 actor_id 中；后续处理器和服务按该档案读写记录。
+// 授权带有范围限制时（教练），范围保存在 profile_scopes 中由 auth.RequireScope 检查，
+// 请求结束后记录访问
+func ActingProfile(authorizer service.ProfileAuthorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		profileID := c.GetHeader(ProfileHeader)
+		userID := c.GetString("user_id")
+		if profileID == "" || profileID == userID {
+			c.Next()
+			return
+		}
+
+		grant, err := authorizer.AuthorizeProfile(c.Request.Context(), userID, profileID)
+		if err != nil {
+			c.Error(apperror.Internal("检查访问权限失败", err))
+			c.Abort()
+			return
+		}
+		if grant == nil {
+			c.Error(apperror.Forbidden(apperror.CodeProfileForbidden, "无权限代为操作该成员的档案"))
+			c.Abort()
+			return
+		}
+
+		c.Set("actor_id", userID)
+		c.Set("user_id", profileID)
+		c.Request = c.Request.WithContext(service.WithProfileGrant(c.Request.Context(), grant))
+		if grant.Scopes != nil {
+			c.Set("profile_scopes", grant.Scopes)
+		}
+		c.Next()
+
+		if grant.Audited {
+			// 错误响应由外层的 ErrorHandler 写入，此时按错误计算状态码
+			status := c.Writer.Status()
+			if len(c.Errors) > 0 && !c.Writer.Written() {
+				status = apperror.As(c.Errors.Last().Err).Status()
+			}
+			// 请求的 context 可能已取消，访问记录使用独立的 context 写入
+			authorizer.RecordAccess(context.WithoutCancel(c.Request.Context()), &model.CoachAccessLog{
+				CoachID:  userID,
+				ClientID: profileID,
+				Method:   c.Request.Method,
+				Path:     c.Request.URL.Path,
+				Status:   status,
+			})
+		}
+	}
+}
+
+// actorOf 实际操作的用户ID：代为操作时为 actor_id，否则与 user_id 相同
+func actorOf(c *gin.Context) string {
+	if actorID := c.GetString("actor_id"); actorID != "" {
+		return actorID
+	}
+	return c.GetString("user_id")
+}
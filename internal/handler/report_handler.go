@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// ReportHandler 营养报告处理器
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+// NewReportHandler 创建营养报告处理器实例
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// DownloadReport 下载 PDF 营养报告
+// @Summary 下载 PDF 营养报告
+// @Description 生成日期所在周（周一至周日）或自然月的可打印报告，包含个人资料、营养目标、执行情况、营养素占比、每日热量图和每日合计表，文字语言跟随请求语言
+// @Tags 营养报告
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param period path string true "报告周期加扩展名：weekly.pdf 或 monthly.pdf"
+// @Param date query string false "周期内的任意日期，格式：YYYY-MM-DD，默认今天"
+// @Success 200 {file} file
+// @Router /api/v1/reports/{period}.pdf [get]
+func (h *ReportHandler) DownloadReport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// gin 的路径参数不能带固定后缀，整个文件名作为参数再去掉扩展名
+	file := c.Param("file")
+	period, ok := strings.CutSuffix(file, ".pdf")
+	if !ok {
+		c.Error(apperror.Validation(apperror.CodeInvalidReportPeriod, "报告周期无效，应为 weekly 或 monthly",
+			apperror.FieldError{Field: "period", Message: file}))
+		return
+	}
+
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		dateStr = time.Now().Format("2006-01-02")
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD"))
+		return
+	}
+
+	report, err := h.reportService.GetReport(c.Request.Context(), userID.(string), period, date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := fmt.Sprintf("nutrition-report-%s-%s.pdf", period, report.From.Format("20060102"))
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// 响应头已经发出，写入失败只能记录日志
+	if err := report.WritePDF(c.Writer, localeOf(c)); err != nil {
+		log.Printf("❌ 生成营养报告失败（用户 %s）: %v", userID, err)
+	}
+}
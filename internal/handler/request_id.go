@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ljk20041215/nutrition-tracker/internal/audit"
+)
+
+// RequestIDHeader 请求ID的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID 接受调用方传入的请求ID的格式，避免把任意内容写入日志
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID 请求ID中间件，沿用调用方传入的 X-Request-ID，没有或格式不对时生成新的ID；
+// 请求ID写入响应头和 context，审计日志据此关联同一请求中的数据变更
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(audit.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// SessionHandler 登录会话处理器
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+// NewSessionHandler 创建登录会话处理器实例
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// ListSessions 获取当前用户的登录会话
+// @Summary 获取登录会话列表
+// @Description 获取当前用户所有设备上未注销且未过期的会话，发起请求的会话标记为 current
+// @Tags 会话
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), userID.(string), c.GetString("session_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    sessions,
+	})
+}
+
+// RevokeSession 撤销登录会话
+// @Summary 撤销登录会话
+// @Description 撤销指定设备上的会话，该会话的刷新令牌立即失效
+// @Tags 会话
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "会话ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "会话ID不能为空"))
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), userID.(string), sessionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgRevoked),
+	})
+}
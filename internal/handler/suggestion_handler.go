@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// SuggestionHandler 食物推荐处理器
+type SuggestionHandler struct {
+	suggestionService service.SuggestionService
+}
+
+// NewSuggestionHandler 创建食物推荐处理器实例
+func NewSuggestionHandler(suggestionService service.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{suggestionService: suggestionService}
+}
+
+// SuggestFoods 营养缺口分析和食物推荐
+// @Summary 营养缺口分析和食物推荐
+// @Description 根据当天剩余的热量预算和距离营养目标的缺口，从食物库中推荐能补齐缺口且不超出热量预算的食物和份量，最近常吃的食物优先
+// @Tags 营养统计
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "日期，格式：YYYY-MM-DD，默认今天"
+// @Param limit query int false "推荐数量，1-50，默认 10"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/suggestions/foods [get]
+func (h *SuggestionHandler) SuggestFoods(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.SuggestionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	analysis, err := h.suggestionService.SuggestFoods(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    analysis,
+	})
+}
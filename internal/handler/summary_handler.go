@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// SummaryHandler 每日营养统计处理器
+type SummaryHandler struct {
+	summaryService service.SummaryService
+}
+
+// NewSummaryHandler 创建每日营养统计处理器实例
+func NewSummaryHandler(summaryService service.SummaryService) *SummaryHandler {
+	return &SummaryHandler{summaryService: summaryService}
+}
+
+// GetDailySummary 获取每日营养统计
+// @Summary 获取每日营养统计
+// @Description 获取指定日期的摄入合计、营养目标、运动消耗及剩余热量预算
+// @Tags 营养统计
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "日期，格式：YYYY-MM-DD，默认今天"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/summary/daily [get]
+func (h *SummaryHandler) GetDailySummary(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取查询参数
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		// 如果没有提供日期，默认使用今天
+		dateStr = time.Now().Format("2006-01-02")
+	}
+
+	// 解析日期
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD"))
+		return
+	}
+
+	summary, err := h.summaryService.GetDailySummary(c.Request.Context(), userID.(string), date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    summary,
+	})
+}
@@ -1,18 +1,24 @@
 package handler
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
 	"github.com/ljk20041215/nutrition-tracker/internal/service"
 )
 
 type UserHandler struct {
-	userService service.UserService
+	userService     service.UserService
+	accountService  service.AccountService
+	userDataService service.UserDataService
 }
 
-func NewUserHandler(userService service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService service.UserService, accountService service.AccountService, userDataService service.UserDataService) *UserHandler {
+	return &UserHandler{userService: userService, accountService: accountService, userDataService: userDataService}
 }
 
 // GetProfile 获取用户资料
@@ -28,19 +34,19 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	user, err := h.userService.GetProfile(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "获取用户资料失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "获取成功",
+		"message": message(c, i18n.MsgFetched),
 		"data":    user,
 	})
 }
@@ -59,40 +65,93 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	// 从认证中间件设置的上下文中获取用户ID
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
 		return
 	}
 
 	var req service.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		c.Error(bindError(c, err))
 		return
 	}
 
 	if err := h.userService.UpdateProfile(c.Request.Context(), userID.(string), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新资料失败: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "更新成功",
+		"message": message(c, i18n.MsgUpdated),
 	})
 }
 
-// 可选：删除用户账户
-//func (h *UserHandler) DeleteAccount(c *gin.Context) {
-//	userID, exists := c.Get("user_id")
-//	if !exists {
-//		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
-//		return
-//	}
-//
-//	// 注意：实际应用中需要确认密码或二次验证
-//	c.JSON(http.StatusOK, gin.H{
-//		"code":    200,
-//		"message": "删除功能暂未实现",
-//		"note":    "实际应用中需要添加密码确认和二次验证",
-//	})
-//}
+// DeleteAccount 注销账户
+// @Summary 注销账户
+// @Description 确认密码后注销当前账户，所有会话立即失效；数据在保留期结束后永久删除，保留期内邮箱不能重新注册
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.DeleteAccountRequest true "当前密码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/me [delete]
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	deletion, err := h.accountService.DeleteAccount(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgAccountDeleted),
+		"data":    deletion,
+	})
+}
+
+// ExportData 导出个人数据
+// @Summary 导出个人数据
+// @Description 下载系统中保存的当前用户全部数据：zip 压缩包内 data.json 包含全部数据，每类记录另有一个 CSV 文件
+// @Tags 用户
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Router /api/v1/users/me/export [get]
+func (h *UserHandler) ExportData(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	data, err := h.userDataService.Export(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := fmt.Sprintf("nutrition-tracker-export-%s.zip", data.ExportedAt.Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// 响应头已经发出，写入失败只能记录日志
+	if err := service.WriteExportArchive(c.Writer, data); err != nil {
+		log.Printf("❌ 写入导出文件失败（用户 %s）: %v", userID, err)
+	}
+}
 
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// WaterRecordHandler 饮水记录处理器
+type WaterRecordHandler struct {
+	waterService service.WaterRecordService
+}
+
+// NewWaterRecordHandler 创建饮水记录处理器实例
+func NewWaterRecordHandler(waterService service.WaterRecordService) *WaterRecordHandler {
+	return &WaterRecordHandler{waterService: waterService}
+}
+
+// CreateWaterRecord 创建饮水记录
+// @Summary 创建饮水记录
+// @Description 记录一次饮水，可填写饮水量或使用快捷预设
+// @Tags 饮水记录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateWaterRecordRequest true "创建饮水记录请求"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/water [post]
+func (h *WaterRecordHandler) CreateWaterRecord(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	var req service.CreateWaterRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(c, err))
+		return
+	}
+
+	waterRecord, err := h.waterService.CreateWaterRecord(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgCreated),
+		"data":    waterRecord,
+	})
+}
+
+// GetHydration 获取指定日期的饮水统计
+// @Summary 获取饮水统计
+// @Description 获取指定日期的饮水记录、饮品摄入量及每日目标
+// @Tags 饮水记录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "日期，格式：YYYY-MM-DD，默认今天"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/water [get]
+func (h *WaterRecordHandler) GetHydration(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取查询参数
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		// 如果没有提供日期，默认使用今天
+		dateStr = time.Now().Format("2006-01-02")
+	}
+
+	// 解析日期
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		c.Error(apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD"))
+		return
+	}
+
+	hydration, err := h.waterService.GetHydration(c.Request.Context(), userID.(string), date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    hydration,
+	})
+}
+
+// GetWaterPresets 获取快捷饮水量预设
+// @Summary 获取快捷饮水量预设
+// @Description 获取 cup、bottle 等快捷预设对应的饮水量（ml）
+// @Tags 饮水记录
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/water/presets [get]
+func (h *WaterRecordHandler) GetWaterPresets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgFetched),
+		"data":    h.waterService.GetPresets(),
+	})
+}
+
+// DeleteWaterRecord 删除饮水记录
+// @Summary 删除饮水记录
+// @Description 根据ID删除饮水记录
+// @Tags 饮水记录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "饮水记录ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/water/{id} [delete]
+func (h *WaterRecordHandler) DeleteWaterRecord(c *gin.Context) {
+	// 从认证中间件设置的上下文中获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.Unauthorized(apperror.CodeUnauthenticated, "用户未认证"))
+		return
+	}
+
+	// 获取路径参数
+	waterID := c.Param("id")
+	if waterID == "" {
+		c.Error(apperror.BadRequest(apperror.CodeMissingID, "饮水记录ID不能为空"))
+		return
+	}
+
+	if err := h.waterService.DeleteWaterRecord(c.Request.Context(), userID.(string), waterID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message(c, i18n.MsgDeleted),
+	})
+}
@@ -0,0 +1,192 @@
+package i18n
+
+import "github.com/ljk20041215/nutrition-tracker/internal/apperror"
+
+var enUS = map[string]string{
+	MsgFetched:          "Fetched successfully",
+	MsgCreated:          "Created successfully",
+	MsgUpdated:          "Updated successfully",
+	MsgDeleted:          "Deleted successfully",
+	MsgSaved:            "Saved successfully",
+	MsgCalculated:       "Calculated successfully",
+	MsgRegistered:       "Registered successfully",
+	MsgLoggedIn:         "Logged in successfully",
+	MsgLoggedOut:        "Logged out successfully",
+	MsgRefreshed:        "Token refreshed successfully",
+	MsgRevoked:          "Session revoked",
+	MsgPasswordChanged:  "Password changed, other devices have been logged out",
+	MsgPasswordReset:    "Password reset, please log in with your new password",
+	MsgResetEmailSent:   "If the email is registered, a password reset email has been sent",
+	MsgEmailVerified:    "Email verified",
+	MsgVerificationSent: "Verification email sent",
+	MsgAccountDeleted:   "Account deleted, your data will be permanently erased after the retention period",
+	MsgAccountUnlocked:  "Account unlocked, please log in again",
+	MsgMFARequired:      "Enter your two-factor code to finish logging in",
+	MsgMFASetup:         "Scan the QR code with your authenticator app and enter a code to finish enabling",
+	MsgMFAEnabled:       "Two-factor authentication enabled, keep your recovery codes safe",
+	MsgMFADisabled:      "Two-factor authentication disabled",
+	MsgRecoveryCodesNew: "Recovery codes regenerated, the old codes no longer work",
+	MsgRoleUpdated:      "Role updated, the user needs to log in again",
+	MsgUserDisabled:     "Account disabled",
+	MsgUserEnabled:      "Account enabled",
+	MsgAPIKeyCreated:    "API key created. Copy it now, it will not be shown again",
+	MsgHouseholdLeft:    "You have left the household",
+	MsgCoachInvited:     "Invitation sent, it takes effect once the invitee verifies this email and accepts",
+	MsgCoachAccepted:    "Coach invitation accepted",
+	MsgCoachDeclined:    "Coach invitation declined",
+	MsgCoachEnded:       "Coaching relationship ended",
+
+	MsgCoachInvitationRevoked: "Coach invitation revoked",
+
+	MsgHouseholdInvited:            "Invitation sent, it can be accepted after signing in with this email",
+	MsgHouseholdJoined:             "You have joined the household",
+	MsgHouseholdInvitationDeclined: "Household invitation declined",
+	MsgHouseholdInvitationRevoked:  "Household invitation revoked",
+
+	MsgDiaryImported:        "Diary imported",
+	MsgDiaryImportPreviewed: "Preview complete, nothing has been imported",
+
+	apperror.CodeInternal:       "Internal server error",
+	apperror.CodeInvalidRequest: "Invalid request parameters",
+	apperror.CodeValidation:     "Request validation failed",
+	apperror.CodeInvalidDate:    "Invalid date format, expected YYYY-MM-DD",
+	apperror.CodeMissingID:      "Record ID is required",
+	apperror.CodeInvalidFilter:  "Invalid filter expression",
+	apperror.CodeInvalidSort:    "Invalid sort field",
+	apperror.CodeInvalidCursor:  "Invalid or stale cursor, start again from the first page",
+	apperror.CodeInvalidRange:   "The end date must not be before the start date",
+
+	apperror.CodeUnauthenticated:    "Authentication required",
+	apperror.CodeInvalidToken:       "Token is invalid or expired",
+	apperror.CodeTokenRevoked:       "Token has been revoked, please log in again",
+	apperror.CodeInvalidCredentials: "Invalid email or password",
+	apperror.CodeEmailRegistered:    "Email is already registered",
+
+	apperror.CodeInvalidRefreshToken: "Refresh token is invalid or expired",
+	apperror.CodeRefreshTokenReused:  "Refresh token was already used; the session has been revoked, please log in again",
+	apperror.CodeSessionNotFound:     "Session not found",
+	apperror.CodeSessionForbidden:    "You do not have access to this session",
+
+	apperror.CodeWrongPassword:            "Current password is incorrect",
+	apperror.CodeSamePassword:             "New password must differ from the current password",
+	apperror.CodeInvalidResetToken:        "Reset link is invalid or expired, please request a new one",
+	apperror.CodeInvalidVerificationToken: "Verification link is invalid or expired, please request a new one",
+	apperror.CodeEmailAlreadyVerified:     "Email is already verified",
+	apperror.CodeEmailNotVerified:         "Please verify your email before accepting the invitation",
+
+	apperror.CodeTooManyLoginAttempts: "Too many failed login attempts, please try again later",
+	apperror.CodeAccountLocked:        "Account temporarily locked after too many failed logins, try again later or use the unlock link sent by email",
+	apperror.CodeInvalidUnlockToken:   "Unlock link is invalid or expired",
+
+	apperror.CodeMFAAlreadyEnabled:   "Two-factor authentication is already enabled",
+	apperror.CodeMFANotEnabled:       "Two-factor authentication is not enabled",
+	apperror.CodeMFASetupRequired:    "Generate a two-factor secret first",
+	apperror.CodeInvalidMFACode:      "Invalid verification or recovery code",
+	apperror.CodeInvalidMFAChallenge: "Two-factor verification expired, please log in again",
+
+	apperror.CodeInsufficientRole: "You do not have permission to perform this action",
+	apperror.CodeAccountDisabled:  "Account has been disabled, please contact an administrator",
+	apperror.CodeCannotModifySelf: "You cannot change your own role or disable your own account",
+	apperror.CodeFoodExists:       "A food with this name already exists",
+
+	apperror.CodeUnknownOIDCProvider:  "Unsupported sign-in provider",
+	apperror.CodeInvalidOIDCState:     "Sign-in request is invalid or expired, please try again",
+	apperror.CodeOIDCLoginFailed:      "Sign-in with the external provider failed, please try again",
+	apperror.CodeOIDCEmailNotVerified: "The email of the external account is not verified",
+
+	apperror.CodeInvalidAPIKey:     "API key is invalid or expired",
+	apperror.CodeAPIKeyNotFound:    "API key not found",
+	apperror.CodeAPIKeyLimit:       "Too many API keys, please delete unused keys first",
+	apperror.CodeAPIKeyNotAllowed:  "This endpoint does not accept API keys, please sign in",
+	apperror.CodeInsufficientScope: "The API key does not have permission for this operation",
+
+	apperror.CodeHouseholdNotFound:       "You have not joined a household",
+	apperror.CodeHouseholdMemberNotFound: "Household member not found",
+	apperror.CodeAlreadyInHousehold:      "This user already belongs to a household",
+	apperror.CodeNotHouseholdOwner:       "Only the household owner can do this",
+	apperror.CodeHouseholdOwnerLeave:     "The household owner cannot leave, delete the household instead",
+	apperror.CodeHouseholdNotEmpty:       "Please remove the other household members first",
+	apperror.CodeProfileForbidden:        "You are not allowed to act for this profile",
+	apperror.CodeDuplicateMealMember:     "Each member can only appear once",
+	apperror.CodeMealShareTooSmall:       "A member's share is less than 0.1, increase the quantity or adjust the portions",
+
+	apperror.CodeHouseholdInvitationNotFound:   "Household invitation not found",
+	apperror.CodeHouseholdInvitationNotPending: "This invitation has already been handled",
+
+	apperror.CodeNotCoach:                  "Only coaches can invite clients",
+	apperror.CodeCannotCoachSelf:           "You cannot invite yourself",
+	apperror.CodeCoachClientNotFound:       "Coaching relationship not found",
+	apperror.CodeCoachInvitationNotFound:   "Coach invitation not found",
+	apperror.CodeCoachInvitationNotPending: "This invitation has already been handled",
+	apperror.CodeCoachScopeForbidden:       "The client has not granted this permission to the coach",
+	apperror.CodeMealCommentNotFound:       "Comment not found",
+	apperror.CodeNotCommentAuthor:          "You can only delete your own comments",
+	apperror.CodeImportInvalidFile:         "Invalid import file, check that the source matches the exported file",
+	apperror.CodeImportFileTooLarge:        "Import file is too large",
+	apperror.CodeInvalidReportPeriod:       "Invalid report period, expected weekly or monthly",
+	apperror.CodeInvalidChart:              "Invalid chart, expected calories, macros, macro-ratio or weight",
+
+	apperror.CodeUserNotFound:           "User not found",
+	apperror.CodeProfileIncomplete:      "Profile is incomplete, please fill in your personal information first",
+	apperror.CodeNutritionGoalNotFound:  "Nutrition goal not found",
+	apperror.CodeInvalidGoalType:        "Invalid goal type",
+	apperror.CodeFoodNotFound:           "Food not found",
+	apperror.CodeMealRecordNotFound:     "Meal record not found",
+	apperror.CodeMealRecordForbidden:    "You do not have access to this meal record",
+	apperror.CodeMealRecordExists:       "A record for this meal already exists",
+	apperror.CodeFoodRecordNotFound:     "Food record not found",
+	apperror.CodeFoodRecordForbidden:    "You do not have access to this food record",
+	apperror.CodeExerciseRecordNotFound: "Exercise record not found",
+	apperror.CodeExerciseForbidden:      "You do not have access to this exercise record",
+	apperror.CodeWeightRequired:         "Weight is missing, please update your profile or enter calories burned manually",
+	apperror.CodeWaterRecordNotFound:    "Water record not found",
+	apperror.CodeWaterRecordForbidden:   "You do not have access to this water record",
+	apperror.CodeWaterAmountRequired:    "Please enter an amount or choose a preset",
+
+	MailVerifySubject: "Verify your email",
+	MailVerifyBody:    "Hi %s,\n\nThanks for signing up for Nutrition Tracker. Open the link below to verify your email (valid for %d hours):\n\n%s\n\nIf you did not sign up, you can ignore this email.\n",
+	MailResetSubject:  "Reset your password",
+	MailResetBody:     "Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one (valid for %d minutes, single use):\n\n%s\n\nIf you did not request this, you can ignore this email and your password will not change.\n",
+	MailUnlockSubject: "Your account has been temporarily locked",
+	MailUnlockBody:    "Hi %s,\n\nYour account has been locked for %d minutes after too many failed login attempts. If this was you, open the link below to unlock it now (valid for 24 hours):\n\n%s\n\nIf this was not you, someone may be trying to access your account. We recommend changing your password.\n",
+
+	ReportTitle:         "Nutrition Report",
+	ReportWeekly:        "Weekly report",
+	ReportMonthly:       "Monthly report",
+	ReportRange:         "%s to %s",
+	ReportGenerated:     "Generated %s",
+	ReportPage:          "Page %d of %d",
+	ReportProfile:       "Profile",
+	ReportNickname:      "Name",
+	ReportGender:        "Gender",
+	ReportMale:          "Male",
+	ReportFemale:        "Female",
+	ReportAge:           "Age",
+	ReportHeight:        "Height",
+	ReportWeight:        "Weight",
+	ReportActivity:      "Activity level",
+	ReportGoal:          "Nutrition goal",
+	ReportNoGoal:        "No nutrition goal set",
+	ReportCalories:      "Calories",
+	ReportProtein:       "Protein",
+	ReportCarbohydrates: "Carbohydrates",
+	ReportFat:           "Fat",
+	ReportAdherence:     "Adherence",
+	ReportDaysLogged:    "Days logged",
+	ReportAverage:       "Daily average",
+	ReportOnTarget:      "Within 10% of goal",
+	ReportOver:          "Over goal",
+	ReportUnder:         "Under goal",
+	ReportMacros:        "Macro distribution",
+	ReportIntake:        "Intake",
+	ReportDailyCalories: "Daily calories",
+	ReportDailyTotals:   "Daily totals",
+	ReportDate:          "Date",
+	ReportRecords:       "Entries",
+	ReportOfGoal:        "% of goal",
+	ReportNoData:        "No food records in this period",
+
+	ChartDailyMacros: "Daily macros",
+	ChartWeightTrend: "Weight trend",
+	ChartNoData:      "No data",
+}
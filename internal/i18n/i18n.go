@@ -0,0 +1,170 @@
+// Package i18n 提供接口消息的多语言目录和语言协商
+package i18n
+
+import (
+	"golang.org/x/text/language"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+
+	// Default 无法协商时使用的默认语言
+	Default = ZhCN
+)
+
+// 成功响应的消息码
+const (
+	MsgFetched    = "FETCHED"
+	MsgCreated    = "CREATED"
+	MsgUpdated    = "UPDATED"
+	MsgDeleted    = "DELETED"
+	MsgSaved      = "SAVED"
+	MsgCalculated = "CALCULATED"
+	MsgRegistered = "REGISTERED"
+	MsgLoggedIn   = "LOGGED_IN"
+	MsgLoggedOut  = "LOGGED_OUT"
+	MsgRefreshed  = "REFRESHED"
+	MsgRevoked    = "REVOKED"
+
+	MsgPasswordChanged  = "PASSWORD_CHANGED"
+	MsgPasswordReset    = "PASSWORD_RESET"
+	MsgResetEmailSent   = "RESET_EMAIL_SENT"
+	MsgEmailVerified    = "EMAIL_VERIFIED"
+	MsgVerificationSent = "VERIFICATION_SENT"
+	MsgAccountDeleted   = "ACCOUNT_DELETED"
+	MsgAccountUnlocked  = "ACCOUNT_UNLOCKED"
+
+	MsgMFARequired      = "MFA_REQUIRED"
+	MsgMFASetup         = "MFA_SETUP"
+	MsgMFAEnabled       = "MFA_ENABLED"
+	MsgMFADisabled      = "MFA_DISABLED"
+	MsgRecoveryCodesNew = "RECOVERY_CODES_REGENERATED"
+
+	MsgRoleUpdated  = "ROLE_UPDATED"
+	MsgUserDisabled = "USER_DISABLED"
+	MsgUserEnabled  = "USER_ENABLED"
+
+	MsgAPIKeyCreated = "API_KEY_CREATED"
+	MsgHouseholdLeft = "HOUSEHOLD_LEFT"
+
+	MsgCoachInvited  = "COACH_INVITED"
+	MsgCoachAccepted = "COACH_ACCEPTED"
+	MsgCoachDeclined = "COACH_DECLINED"
+	MsgCoachEnded    = "COACH_ENDED"
+
+	MsgCoachInvitationRevoked = "COACH_INVITATION_REVOKED"
+
+	MsgHouseholdInvited            = "HOUSEHOLD_INVITED"
+	MsgHouseholdJoined             = "HOUSEHOLD_JOINED"
+	MsgHouseholdInvitationDeclined = "HOUSEHOLD_INVITATION_DECLINED"
+	MsgHouseholdInvitationRevoked  = "HOUSEHOLD_INVITATION_REVOKED"
+
+	MsgDiaryImported        = "DIARY_IMPORTED"
+	MsgDiaryImportPreviewed = "DIARY_IMPORT_PREVIEWED"
+)
+
+// 邮件模板的消息码，正文使用 fmt 占位符
+const (
+	MailVerifySubject = "MAIL_VERIFY_SUBJECT"
+	MailVerifyBody    = "MAIL_VERIFY_BODY" // 昵称、链接、有效小时数
+	MailResetSubject  = "MAIL_RESET_SUBJECT"
+	MailResetBody     = "MAIL_RESET_BODY" // 昵称、链接、有效分钟数
+	MailUnlockSubject = "MAIL_UNLOCK_SUBJECT"
+	MailUnlockBody    = "MAIL_UNLOCK_BODY" // 昵称、锁定分钟数、链接
+)
+
+// 营养报告中的文字，带占位符的使用 fmt 格式化
+const (
+	ReportTitle         = "REPORT_TITLE"
+	ReportWeekly        = "REPORT_WEEKLY"
+	ReportMonthly       = "REPORT_MONTHLY"
+	ReportRange         = "REPORT_RANGE"     // 开始日期、结束日期
+	ReportGenerated     = "REPORT_GENERATED" // 生成时间
+	ReportPage          = "REPORT_PAGE"      // 页码、总页数
+	ReportProfile       = "REPORT_PROFILE"
+	ReportNickname      = "REPORT_NICKNAME"
+	ReportGender        = "REPORT_GENDER"
+	ReportMale          = "REPORT_MALE"
+	ReportFemale        = "REPORT_FEMALE"
+	ReportAge           = "REPORT_AGE"
+	ReportHeight        = "REPORT_HEIGHT"
+	ReportWeight        = "REPORT_WEIGHT"
+	ReportActivity      = "REPORT_ACTIVITY"
+	ReportGoal          = "REPORT_GOAL"
+	ReportNoGoal        = "REPORT_NO_GOAL"
+	ReportCalories      = "REPORT_CALORIES"
+	ReportProtein       = "REPORT_PROTEIN"
+	ReportCarbohydrates = "REPORT_CARBOHYDRATES"
+	ReportFat           = "REPORT_FAT"
+	ReportAdherence     = "REPORT_ADHERENCE"
+	ReportDaysLogged    = "REPORT_DAYS_LOGGED"
+	ReportAverage       = "REPORT_AVERAGE"
+	ReportOnTarget      = "REPORT_ON_TARGET"
+	ReportOver          = "REPORT_OVER"
+	ReportUnder         = "REPORT_UNDER"
+	ReportMacros        = "REPORT_MACROS"
+	ReportIntake        = "REPORT_INTAKE"
+	ReportDailyCalories = "REPORT_DAILY_CALORIES"
+	ReportDailyTotals   = "REPORT_DAILY_TOTALS"
+	ReportDate          = "REPORT_DATE"
+	ReportRecords       = "REPORT_RECORDS"
+	ReportOfGoal        = "REPORT_OF_GOAL"
+	ReportNoData        = "REPORT_NO_DATA"
+)
+
+// 图表中的文字，其余沿用营养报告的文字
+const (
+	ChartDailyMacros = "CHART_DAILY_MACROS"
+	ChartWeightTrend = "CHART_WEIGHT_TREND"
+	ChartNoData      = "CHART_NO_DATA"
+)
+
+// catalogs 按语言和消息码索引的消息目录
+var catalogs = map[string]map[string]string{
+	ZhCN: zhCN,
+	EnUS: enUS,
+}
+
+// matcher 的候选顺序与 supported 一致，Default 必须排在第一位
+var (
+	supported = []string{ZhCN, EnUS}
+	matcher   = language.NewMatcher([]language.Tag{
+		language.MustParse(ZhCN),
+		language.MustParse(EnUS),
+	})
+)
+
+// Supported 判断是否为支持的语言
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate 根据 Accept-Language 请求头选择语言，无法匹配时返回默认语言
+func Negotiate(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return supported[index]
+}
+
+// T 返回消息码在指定语言下的文本，目录中没有时依次回退到默认语言和 fallback
+func T(locale, code, fallback string) string {
+	if msg, ok := catalogs[locale][code]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[Default][code]; ok {
+		return msg
+	}
+	return fallback
+}
@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// validatorTranslators 各语言对应的校验错误翻译器
+var validatorTranslators = map[string]ut.Translator{}
+
+// RegisterValidator 为 gin 使用的校验器注册中英文翻译，并让字段名使用 json 标签（查询参数使用 form 标签）
+func RegisterValidator(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" {
+			// 查询参数只有 form 标签
+			name = strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		}
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	uni := ut.New(zh.New(), zh.New(), en.New())
+
+	zhTrans, _ := uni.GetTranslator("zh")
+	if err := zhTranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+		return err
+	}
+	enTrans, _ := uni.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		return err
+	}
+
+	validatorTranslators[ZhCN] = zhTrans
+	validatorTranslators[EnUS] = enTrans
+	return nil
+}
+
+// TranslateFieldError 返回字段校验错误在指定语言下的描述
+func TranslateFieldError(locale string, fe validator.FieldError) string {
+	trans, ok := validatorTranslators[locale]
+	if !ok {
+		trans, ok = validatorTranslators[Default]
+	}
+	if !ok {
+		return fe.Error()
+	}
+	return fe.Translate(trans)
+}
@@ -0,0 +1,192 @@
+package i18n
+
+import "github.com/ljk20041215/nutrition-tracker/internal/apperror"
+
+var zhCN = map[string]string{
+	MsgFetched:          "获取成功",
+	MsgCreated:          "创建成功",
+	MsgUpdated:          "更新成功",
+	MsgDeleted:          "删除成功",
+	MsgSaved:            "设置成功",
+	MsgCalculated:       "计算成功",
+	MsgRegistered:       "注册成功",
+	MsgLoggedIn:         "登录成功",
+	MsgLoggedOut:        "已退出登录",
+	MsgRefreshed:        "刷新成功",
+	MsgRevoked:          "会话已撤销",
+	MsgPasswordChanged:  "密码修改成功，其他设备已退出登录",
+	MsgPasswordReset:    "密码重置成功，请使用新密码登录",
+	MsgResetEmailSent:   "如果该邮箱已注册，重置密码邮件已发送",
+	MsgEmailVerified:    "邮箱验证成功",
+	MsgVerificationSent: "验证邮件已发送",
+	MsgAccountDeleted:   "账户已注销，数据将在保留期结束后永久删除",
+	MsgAccountUnlocked:  "账户已解锁，请重新登录",
+	MsgMFARequired:      "请输入两步验证码完成登录",
+	MsgMFASetup:         "请使用验证器应用扫描二维码，并输入验证码完成开启",
+	MsgMFAEnabled:       "两步验证已开启，请妥善保存恢复码",
+	MsgMFADisabled:      "两步验证已关闭",
+	MsgRecoveryCodesNew: "恢复码已重新生成，旧恢复码已失效",
+	MsgRoleUpdated:      "角色已修改，该用户需要重新登录",
+	MsgUserDisabled:     "账户已禁用",
+	MsgUserEnabled:      "账户已启用",
+	MsgAPIKeyCreated:    "API 密钥已创建，请立即保存，之后无法再次查看",
+	MsgHouseholdLeft:    "已退出家庭",
+	MsgCoachInvited:     "邀请已发送，对方验证邮箱并接受后生效",
+	MsgCoachAccepted:    "已接受教练邀请",
+	MsgCoachDeclined:    "已拒绝教练邀请",
+	MsgCoachEnded:       "已结束教练关系",
+
+	MsgCoachInvitationRevoked: "已撤回教练邀请",
+
+	MsgHouseholdInvited:            "邀请已发送，对方登录后接受邀请即可加入家庭",
+	MsgHouseholdJoined:             "已加入家庭",
+	MsgHouseholdInvitationDeclined: "已拒绝家庭邀请",
+	MsgHouseholdInvitationRevoked:  "已撤回家庭邀请",
+
+	MsgDiaryImported:        "饮食日记已导入",
+	MsgDiaryImportPreviewed: "预览完成，尚未导入任何记录",
+
+	apperror.CodeInternal:       "服务器内部错误",
+	apperror.CodeInvalidRequest: "请求参数无效",
+	apperror.CodeValidation:     "请求参数校验失败",
+	apperror.CodeInvalidDate:    "日期格式错误，应为 YYYY-MM-DD",
+	apperror.CodeMissingID:      "记录ID不能为空",
+	apperror.CodeInvalidFilter:  "过滤条件无效",
+	apperror.CodeInvalidSort:    "排序字段无效",
+	apperror.CodeInvalidCursor:  "分页游标无效或已过期，请从第一页重新查询",
+	apperror.CodeInvalidRange:   "结束日期不能早于开始日期",
+
+	apperror.CodeUnauthenticated:    "用户未认证",
+	apperror.CodeInvalidToken:       "令牌无效或已过期",
+	apperror.CodeTokenRevoked:       "令牌已被撤销，请重新登录",
+	apperror.CodeInvalidCredentials: "用户不存在或密码错误",
+	apperror.CodeEmailRegistered:    "邮箱已被注册",
+
+	apperror.CodeInvalidRefreshToken: "刷新令牌无效或已过期",
+	apperror.CodeRefreshTokenReused:  "刷新令牌已被使用，该会话已被注销，请重新登录",
+	apperror.CodeSessionNotFound:     "会话不存在",
+	apperror.CodeSessionForbidden:    "无权限操作该会话",
+
+	apperror.CodeWrongPassword:            "当前密码错误",
+	apperror.CodeSamePassword:             "新密码不能与当前密码相同",
+	apperror.CodeInvalidResetToken:        "重置链接无效或已过期，请重新申请",
+	apperror.CodeInvalidVerificationToken: "验证链接无效或已过期，请重新发送验证邮件",
+	apperror.CodeEmailAlreadyVerified:     "邮箱已验证",
+	apperror.CodeEmailNotVerified:         "请先验证邮箱后再接受邀请",
+
+	apperror.CodeTooManyLoginAttempts: "登录失败次数过多，请稍后再试",
+	apperror.CodeAccountLocked:        "账户因多次登录失败已被临时锁定，请稍后再试或通过邮件中的链接解锁",
+	apperror.CodeInvalidUnlockToken:   "解锁链接无效或已过期",
+
+	apperror.CodeMFAAlreadyEnabled:   "两步验证已开启",
+	apperror.CodeMFANotEnabled:       "两步验证未开启",
+	apperror.CodeMFASetupRequired:    "请先生成两步验证密钥",
+	apperror.CodeInvalidMFACode:      "验证码或恢复码错误",
+	apperror.CodeInvalidMFAChallenge: "两步验证已过期，请重新登录",
+
+	apperror.CodeInsufficientRole: "没有权限执行该操作",
+	apperror.CodeAccountDisabled:  "账户已被禁用，请联系管理员",
+	apperror.CodeCannotModifySelf: "不能修改自己的角色或禁用自己的账户",
+	apperror.CodeFoodExists:       "食物名称已存在",
+
+	apperror.CodeUnknownOIDCProvider:  "不支持该登录方式",
+	apperror.CodeInvalidOIDCState:     "登录请求无效或已过期，请重新登录",
+	apperror.CodeOIDCLoginFailed:      "第三方登录失败，请重试",
+	apperror.CodeOIDCEmailNotVerified: "第三方账户的邮箱未验证，无法登录",
+
+	apperror.CodeInvalidAPIKey:     "API 密钥无效或已过期",
+	apperror.CodeAPIKeyNotFound:    "API 密钥不存在",
+	apperror.CodeAPIKeyLimit:       "API 密钥数量已达上限，请先删除不用的密钥",
+	apperror.CodeAPIKeyNotAllowed:  "该接口不支持使用 API 密钥访问，请登录后操作",
+	apperror.CodeInsufficientScope: "API 密钥没有该操作的权限",
+
+	apperror.CodeHouseholdNotFound:       "尚未加入家庭",
+	apperror.CodeHouseholdMemberNotFound: "家庭成员不存在",
+	apperror.CodeAlreadyInHousehold:      "该用户已加入家庭",
+	apperror.CodeNotHouseholdOwner:       "只有家庭创建者可以执行该操作",
+	apperror.CodeHouseholdOwnerLeave:     "家庭创建者不能退出家庭，请删除家庭",
+	apperror.CodeHouseholdNotEmpty:       "请先移除家庭中的其他成员",
+	apperror.CodeProfileForbidden:        "无权限代为操作该成员的档案",
+	apperror.CodeDuplicateMealMember:     "同一成员不能重复出现",
+	apperror.CodeMealShareTooSmall:       "有成员分到的份量不足 0.1，请增加食物份量或调整份数",
+
+	apperror.CodeHouseholdInvitationNotFound:   "家庭邀请不存在",
+	apperror.CodeHouseholdInvitationNotPending: "该邀请已处理",
+
+	apperror.CodeNotCoach:                  "只有教练可以邀请客户",
+	apperror.CodeCannotCoachSelf:           "不能邀请自己",
+	apperror.CodeCoachClientNotFound:       "教练关系不存在",
+	apperror.CodeCoachInvitationNotFound:   "教练邀请不存在",
+	apperror.CodeCoachInvitationNotPending: "该邀请已处理",
+	apperror.CodeCoachScopeForbidden:       "客户未授权教练执行该操作",
+	apperror.CodeMealCommentNotFound:       "评论不存在",
+	apperror.CodeNotCommentAuthor:          "只能删除自己的评论",
+	apperror.CodeImportInvalidFile:         "导入文件无效，请确认选择了正确的来源和导出文件",
+	apperror.CodeImportFileTooLarge:        "导入文件过大",
+	apperror.CodeInvalidReportPeriod:       "报告周期无效，应为 weekly 或 monthly",
+	apperror.CodeInvalidChart:              "图表类型无效，应为 calories、macros、macro-ratio 或 weight",
+
+	apperror.CodeUserNotFound:           "用户不存在",
+	apperror.CodeProfileIncomplete:      "缺少必要的用户信息，请先完善个人资料",
+	apperror.CodeNutritionGoalNotFound:  "营养目标不存在",
+	apperror.CodeInvalidGoalType:        "无效的目标类型",
+	apperror.CodeFoodNotFound:           "食物不存在",
+	apperror.CodeMealRecordNotFound:     "餐次记录不存在",
+	apperror.CodeMealRecordForbidden:    "无权限访问该餐次记录",
+	apperror.CodeMealRecordExists:       "该餐次记录已存在",
+	apperror.CodeFoodRecordNotFound:     "食物记录不存在",
+	apperror.CodeFoodRecordForbidden:    "无权限访问该食物记录",
+	apperror.CodeExerciseRecordNotFound: "运动记录不存在",
+	apperror.CodeExerciseForbidden:      "无权限访问该运动记录",
+	apperror.CodeWeightRequired:         "缺少体重信息，请先完善个人资料或手动填写消耗热量",
+	apperror.CodeWaterRecordNotFound:    "饮水记录不存在",
+	apperror.CodeWaterRecordForbidden:   "无权限访问该饮水记录",
+	apperror.CodeWaterAmountRequired:    "请填写饮水量或选择快捷预设",
+
+	MailVerifySubject: "请验证你的邮箱",
+	MailVerifyBody:    "%s，你好：\n\n感谢注册营养追踪。请打开以下链接验证邮箱（%d 小时内有效）：\n\n%s\n\n如果这不是你的操作，请忽略本邮件。\n",
+	MailResetSubject:  "重置密码",
+	MailResetBody:     "%s，你好：\n\n我们收到了重置密码的请求。请打开以下链接设置新密码（%d 分钟内有效，只能使用一次）：\n\n%s\n\n如果这不是你的操作，请忽略本邮件，你的密码不会改变。\n",
+	MailUnlockSubject: "账户已被临时锁定",
+	MailUnlockBody:    "%s，你好：\n\n你的账户因多次登录失败已被锁定 %d 分钟。如果是你本人操作，可以打开以下链接立即解锁（24 小时内有效）：\n\n%s\n\n如果不是你本人操作，说明有人在尝试登录你的账户，建议尽快修改密码。\n",
+
+	ReportTitle:         "营养报告",
+	ReportWeekly:        "周报",
+	ReportMonthly:       "月报",
+	ReportRange:         "%s 至 %s",
+	ReportGenerated:     "生成于 %s",
+	ReportPage:          "第 %d 页，共 %d 页",
+	ReportProfile:       "个人资料",
+	ReportNickname:      "昵称",
+	ReportGender:        "性别",
+	ReportMale:          "男",
+	ReportFemale:        "女",
+	ReportAge:           "年龄",
+	ReportHeight:        "身高",
+	ReportWeight:        "体重",
+	ReportActivity:      "活动水平",
+	ReportGoal:          "营养目标",
+	ReportNoGoal:        "未设置营养目标",
+	ReportCalories:      "热量",
+	ReportProtein:       "蛋白质",
+	ReportCarbohydrates: "碳水化合物",
+	ReportFat:           "脂肪",
+	ReportAdherence:     "执行情况",
+	ReportDaysLogged:    "记录天数",
+	ReportAverage:       "日均",
+	ReportOnTarget:      "热量达标（目标 ±10%）",
+	ReportOver:          "超出目标",
+	ReportUnder:         "低于目标",
+	ReportMacros:        "三大营养素热量占比",
+	ReportIntake:        "实际摄入",
+	ReportDailyCalories: "每日热量",
+	ReportDailyTotals:   "每日合计",
+	ReportDate:          "日期",
+	ReportRecords:       "记录数",
+	ReportOfGoal:        "目标完成",
+	ReportNoData:        "本周期没有饮食记录",
+
+	ChartDailyMacros: "每日三大营养素热量",
+	ChartWeightTrend: "体重变化",
+	ChartNoData:      "暂无数据",
+}
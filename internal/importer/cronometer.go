@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// cronometer Cronometer 导出的食物记录文件（servings.csv）
+//
+// 每行是一种食物：Day, Time, Group, Food Name, Amount, Energy (kcal), ..., Carbs (g), ..., Fat (g), ..., Protein (g), ...。
+// Amount 为“数量 单位”，如“150.00 g”、“1.00 cup”；Group 为餐次名称。
+type cronometer struct{}
+
+// Parse 解析文件
+func (cronometer) Parse(r io.Reader) ([]*Entry, []RowError, error) {
+	t, err := openTable(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	day, err := t.require("day", "date")
+	if err != nil {
+		return nil, nil, err
+	}
+	food, err := t.require("food name")
+	if err != nil {
+		return nil, nil, err
+	}
+	amount, err := t.require("amount")
+	if err != nil {
+		return nil, nil, err
+	}
+	energy, err := t.require("energy (kcal)")
+	if err != nil {
+		return nil, nil, err
+	}
+	group := t.column("group", "meal")
+	protein := t.column("protein (g)")
+	carbohydrates := t.column("carbs (g)", "net carbs (g)")
+	fat := t.column("fat (g)")
+
+	var (
+		entries []*Entry
+		rowErrs []RowError
+	)
+	for {
+		line, err := t.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entry, err := cronometerEntry(t, line, day, group, food, amount, energy, protein, carbohydrates, fat)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rowErrs, nil
+}
+
+func cronometerEntry(t *table, line int, day, group, food, amount, energy, protein, carbohydrates, fat int) (*Entry, error) {
+	date, err := parseDate(t.get(day))
+	if err != nil {
+		return nil, err
+	}
+	name := t.get(food)
+	if name == "" {
+		return nil, errors.New("食物名称为空")
+	}
+	quantity, unit, err := parseAmount(t.get(amount))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		Line:     line,
+		Date:     date,
+		MealType: MealTypeOf(t.get(group)),
+		FoodName: truncate(name, maxFoodNameLength),
+		Quantity: quantity,
+		Unit:     truncate(unit, maxUnitLength),
+	}
+	for _, f := range []struct {
+		column int
+		value  *float64
+	}{
+		{energy, &entry.Calories},
+		{protein, &entry.Protein},
+		{carbohydrates, &entry.Carbohydrates},
+		{fat, &entry.Fat},
+	} {
+		if *f.value, err = t.number(f.column); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// parseAmount 解析“150.00 g”格式的份量，没有单位时记为 serving
+func parseAmount(value string) (float64, string, error) {
+	number, unit, _ := strings.Cut(strings.TrimSpace(value), " ")
+	quantity, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+	if err != nil || quantity <= 0 {
+		return 0, "", fmt.Errorf("无效的份量 %q", value)
+	}
+	unit = strings.TrimSpace(unit)
+	if unit == "" {
+		unit = "serving"
+	}
+	return quantity, unit, nil
+}
@@ -0,0 +1,190 @@
+// Package importer 解析其他营养记录应用导出的饮食日记 CSV 文件
+//
+// 每种来源一个 Parser，把文件中的每一行转换为 Entry；无法解析的行记录为 RowError 并跳过，
+// 缺少必需的列时整个文件无效。
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+)
+
+// 支持的导入来源
+const (
+	SourceMyFitnessPal = "myfitnesspal"
+	SourceCronometer   = "cronometer"
+)
+
+// 与 food_records 表的列长度一致
+const (
+	maxFoodNameLength = 100
+	maxUnitLength     = 20
+)
+
+// ErrInvalidFile 文件不是 CSV 或缺少必需的列
+var ErrInvalidFile = errors.New("导入文件无效")
+
+// Entry 导入文件中的一条食物记录
+type Entry struct {
+	Line          int
+	Date          time.Time
+	MealType      model.MealType
+	FoodName      string
+	Quantity      float64
+	Unit          string
+	Calories      float64
+	Protein       float64
+	Carbohydrates float64
+	Fat           float64
+}
+
+// RowError 无法导入的行
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Parser 解析一种来源的导出文件
+type Parser interface {
+	Parse(r io.Reader) ([]*Entry, []RowError, error)
+}
+
+// parsers 按来源注册的解析器
+var parsers = map[string]Parser{
+	SourceMyFitnessPal: myFitnessPal{},
+	SourceCronometer:   cronometer{},
+}
+
+// ParserFor 返回来源对应的解析器
+func ParserFor(source string) (Parser, bool) {
+	p, ok := parsers[source]
+	return p, ok
+}
+
+// MealTypeOf 把来源中的餐次名称映射为餐次类型，无法识别的餐次（如自定义的“Meal 5”、未分组）记为加餐
+func MealTypeOf(name string) model.MealType {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "breakfast"):
+		return model.Breakfast
+	case strings.Contains(name, "lunch"):
+		return model.Lunch
+	case strings.Contains(name, "dinner"), strings.Contains(name, "supper"):
+		return model.Dinner
+	}
+	return model.Snack
+}
+
+// table 带表头的 CSV 文件，按列名读取单元格
+type table struct {
+	r       *csv.Reader
+	columns map[string]int
+	record  []string
+}
+
+// openTable 读取表头；列名不区分大小写，忽略首尾空白和 UTF-8 BOM
+func openTable(r io.Reader) (*table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无法读取表头", ErrInvalidFile)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return &table{r: cr, columns: columns}, nil
+}
+
+// column 返回第一个存在的列名对应的序号，都不存在时返回 -1
+func (t *table) column(names ...string) int {
+	for _, name := range names {
+		if i, ok := t.columns[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// require 与 column 相同，列不存在时返回错误
+func (t *table) require(names ...string) (int, error) {
+	i := t.column(names...)
+	if i < 0 {
+		return -1, fmt.Errorf("%w: 缺少 %s 列", ErrInvalidFile, names[0])
+	}
+	return i, nil
+}
+
+// next 读取下一行，返回行号；文件结束时返回 io.EOF
+func (t *table) next() (int, error) {
+	record, err := t.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return 0, fmt.Errorf("%w: 第 %d 行格式错误", ErrInvalidFile, parseErr.Line)
+		}
+		return 0, err
+	}
+	t.record = record
+	line, _ := t.r.FieldPos(0)
+	return line, nil
+}
+
+// get 返回当前行指定列的值，列不存在时返回空字符串
+func (t *table) get(i int) string {
+	if i < 0 || i >= len(t.record) {
+		return ""
+	}
+	return strings.TrimSpace(t.record[i])
+}
+
+// number 返回当前行指定列的数值，空值为 0，允许千位分隔符
+func (t *table) number(i int) (float64, error) {
+	value := strings.ReplaceAll(t.get(i), ",", "")
+	if value == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("无效的数值 %q", t.get(i))
+	}
+	return v, nil
+}
+
+// dateLayouts 导出文件中常见的日期格式
+var dateLayouts = []string{"2006-01-02", "1/2/2006", "01/02/2006"}
+
+// parseDate 解析日期
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的日期 %q", value)
+}
+
+// truncate 按字符截断到数据库列的长度
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,157 @@
+package importer_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/importer"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+)
+
+// parse 用来源对应的解析器解析 CSV 内容
+func parse(t *testing.T, source string, csv string) ([]*importer.Entry, []importer.RowError, error) {
+	t.Helper()
+
+	p, ok := importer.ParserFor(source)
+	if !ok {
+		t.Fatalf("没有 %s 的解析器", source)
+	}
+	return p.Parse(strings.NewReader(csv))
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMyFitnessPal(t *testing.T) {
+	csv := "\ufeffDate,Meal,Calories,Fat (g),Carbohydrates (g),Protein (g),Note\n" +
+		"2024-01-15,Breakfast,\"1,250\",10.5,40,20,\n" +
+		"1/15/2024,Meal 5,300,,,,\n" +
+		"2024-01-16,Dinner,abc,1,2,3,\n" +
+		"2024-13-01,Lunch,100,1,2,3,\n" +
+		"01/16/2024,Lunch,-5,1,2,3,\n"
+
+	entries, rowErrs, err := parse(t, importer.SourceMyFitnessPal, csv)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	want := []*importer.Entry{
+		{Line: 2, Date: day(2024, 1, 15), MealType: model.Breakfast, FoodName: "MyFitnessPal Breakfast", Quantity: 1, Unit: "serving",
+			Calories: 1250, Protein: 20, Carbohydrates: 40, Fat: 10.5},
+		{Line: 3, Date: day(2024, 1, 15), MealType: model.Snack, FoodName: "MyFitnessPal Meal 5", Quantity: 1, Unit: "serving", Calories: 300},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v\n期望 %+v", deref(entries), deref(want))
+	}
+	if lines := errorLines(rowErrs); !reflect.DeepEqual(lines, []int{4, 5, 6}) {
+		t.Errorf("出错的行 = %v，期望 [4 5 6]", lines)
+	}
+}
+
+func TestMyFitnessPalWithFoods(t *testing.T) {
+	csv := "Date,Meal,Food Name,Quantity,Unit,Calories,Protein,Carbohydrates,Fat\n" +
+		"2024-01-15,Lunch,Chicken breast,150,g,248,46.5,0,5.4\n" +
+		"2024-01-15,Supper," + strings.Repeat("长", 120) + ",0,,100,1,2,3\n"
+
+	entries, rowErrs, err := parse(t, importer.SourceMyFitnessPal, csv)
+	if err != nil || len(rowErrs) != 0 {
+		t.Fatalf("解析失败: %v %v", err, rowErrs)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d 条，期望 2 条", len(entries))
+	}
+	if e := entries[0]; e.FoodName != "Chicken breast" || e.Quantity != 150 || e.Unit != "g" || e.Protein != 46.5 {
+		t.Errorf("第一条 = %+v", *e)
+	}
+	// 数量为 0 时按 1 份，食物名称截断到列长度
+	if e := entries[1]; e.MealType != model.Dinner || e.Quantity != 1 || e.Unit != "serving" || len([]rune(e.FoodName)) != 100 {
+		t.Errorf("第二条 = %+v", *e)
+	}
+}
+
+func TestCronometer(t *testing.T) {
+	csv := "Day,Time,Group,Food Name,Amount,Energy (kcal),Carbs (g),Fat (g),Protein (g)\n" +
+		"2024-01-15,08:00,Breakfast,Oatmeal,\"1,000.00 g\",3890,663,69,169\n" +
+		"2024-01-15,,Uncategorized,Apple,2,104,28,0.3,0.5\n" +
+		"2024-01-15,,Lunch,,100 g,100,1,1,1\n" +
+		"2024-01-15,,Lunch,Rice,0 g,0,0,0,0\n" +
+		"2024-01-15,,Lunch,Rice,abc g,0,0,0,0\n"
+
+	entries, rowErrs, err := parse(t, importer.SourceCronometer, csv)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	want := []*importer.Entry{
+		{Line: 2, Date: day(2024, 1, 15), MealType: model.Breakfast, FoodName: "Oatmeal", Quantity: 1000, Unit: "g",
+			Calories: 3890, Protein: 169, Carbohydrates: 663, Fat: 69},
+		{Line: 3, Date: day(2024, 1, 15), MealType: model.Snack, FoodName: "Apple", Quantity: 2, Unit: "serving",
+			Calories: 104, Protein: 0.5, Carbohydrates: 28, Fat: 0.3},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v\n期望 %+v", deref(entries), deref(want))
+	}
+	if lines := errorLines(rowErrs); !reflect.DeepEqual(lines, []int{4, 5, 6}) {
+		t.Errorf("出错的行 = %v，期望 [4 5 6]", lines)
+	}
+}
+
+func TestInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		csv    string
+	}{
+		{"空文件", importer.SourceMyFitnessPal, ""},
+		{"缺少 Calories 列", importer.SourceMyFitnessPal, "Date,Meal\n2024-01-15,Lunch\n"},
+		{"缺少 Energy 列", importer.SourceCronometer, "Day,Food Name,Amount\n2024-01-15,Rice,100 g\n"},
+		{"引号不匹配", importer.SourceCronometer, "Day,Food Name,Amount,Energy (kcal)\n2024-01-15,\"Rice,100 g,100\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parse(t, tt.source, tt.csv); !errors.Is(err, importer.ErrInvalidFile) {
+				t.Errorf("err = %v，期望 ErrInvalidFile", err)
+			}
+		})
+	}
+
+	if _, ok := importer.ParserFor("loseit"); ok {
+		t.Error("不支持的来源不应有解析器")
+	}
+}
+
+func TestMealTypeOf(t *testing.T) {
+	tests := map[string]model.MealType{
+		"Breakfast":      model.Breakfast,
+		"LUNCH":          model.Lunch,
+		"Dinner":         model.Dinner,
+		"Supper":         model.Dinner,
+		"Snacks":         model.Snack,
+		"Meal 5":         model.Snack,
+		"":               model.Snack,
+		"Late Breakfast": model.Breakfast,
+	}
+	for name, want := range tests {
+		if got := importer.MealTypeOf(name); got != want {
+			t.Errorf("MealTypeOf(%q) = %v，期望 %v", name, got, want)
+		}
+	}
+}
+
+func errorLines(rowErrs []importer.RowError) []int {
+	lines := make([]int, len(rowErrs))
+	for i, e := range rowErrs {
+		lines[i] = e.Line
+	}
+	return lines
+}
+
+func deref(entries []*importer.Entry) []importer.Entry {
+	values := make([]importer.Entry, len(entries))
+	for i, e := range entries {
+		values[i] = *e
+	}
+	return values
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"errors"
+	"io"
+)
+
+// myFitnessPal MyFitnessPal 导出的营养汇总文件（Nutrition Summary）
+//
+// 每行是一个餐次的合计：Date, Meal, Calories, Fat (g), ..., Carbohydrates (g), ..., Protein (g), Note。
+// 文件中没有具体的食物时，每个餐次导入为一条名为“MyFitnessPal <餐次>”的快速添加条目；
+// 带有 Food Name 列的文件（部分第三方导出工具）按食物导入。
+type myFitnessPal struct{}
+
+// Parse 解析文件
+func (myFitnessPal) Parse(r io.Reader) ([]*Entry, []RowError, error) {
+	t, err := openTable(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	date, err := t.require("date")
+	if err != nil {
+		return nil, nil, err
+	}
+	meal, err := t.require("meal")
+	if err != nil {
+		return nil, nil, err
+	}
+	calories, err := t.require("calories")
+	if err != nil {
+		return nil, nil, err
+	}
+	protein := t.column("protein (g)", "protein")
+	carbohydrates := t.column("carbohydrates (g)", "carbohydrates", "carbs (g)")
+	fat := t.column("fat (g)", "fat")
+	food := t.column("food name", "food")
+	quantity := t.column("quantity", "servings")
+	unit := t.column("unit", "serving size")
+
+	var (
+		entries []*Entry
+		rowErrs []RowError
+	)
+	for {
+		line, err := t.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entry, err := myFitnessPalEntry(t, line, date, meal, calories, protein, carbohydrates, fat, food, quantity, unit)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rowErrs, nil
+}
+
+func myFitnessPalEntry(t *table, line int, date, meal, calories, protein, carbohydrates, fat, food, quantity, unit int) (*Entry, error) {
+	day, err := parseDate(t.get(date))
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{
+		Line:     line,
+		Date:     day,
+		MealType: MealTypeOf(t.get(meal)),
+		FoodName: t.get(food),
+		Quantity: 1,
+		Unit:     "serving",
+	}
+	if entry.FoodName == "" {
+		entry.FoodName = "MyFitnessPal " + t.get(meal)
+	}
+	entry.FoodName = truncate(entry.FoodName, maxFoodNameLength)
+
+	if quantity >= 0 {
+		if q, err := t.number(quantity); err == nil && q > 0 {
+			entry.Quantity = q
+		}
+	}
+	if u := t.get(unit); u != "" {
+		entry.Unit = truncate(u, maxUnitLength)
+	}
+
+	for _, f := range []struct {
+		column int
+		value  *float64
+	}{
+		{calories, &entry.Calories},
+		{protein, &entry.Protein},
+		{carbohydrates, &entry.Carbohydrates},
+		{fat, &entry.Fat},
+	} {
+		if *f.value, err = t.number(f.column); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,203 @@
+// Package loginguard 防止暴力破解登录：按账户和 IP 记录连续失败次数，
+// 超过免费次数后按指数退避拒绝登录，账户连续失败过多时临时锁定
+package loginguard
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// State 某个键（账户或 IP）的失败记录
+type State struct {
+	Failures      int       // 统计窗口内的连续失败次数
+	LastFailureAt time.Time // 最近一次失败时间
+	LockedUntil   time.Time // 锁定截止时间，零值表示未锁定
+}
+
+// Store 失败记录存储接口
+// 单实例部署使用 MemoryStore，多实例部署使用数据库存储（repository.NewLoginThrottleRepository）共享状态
+type Store interface {
+	// Get 返回键的失败记录，没有记录时返回零值
+	Get(ctx context.Context, key string) (State, error)
+	// Reserve 记录仍为 seen 的失败次数且未锁定时，原子地增加失败次数（预占一次尝试）并返回新的记录；
+	// 上次失败早于 windowStart 时从 1 重新计数。记录已被其他请求修改时返回 ok=false
+	Reserve(ctx context.Context, key string, seen State, now time.Time, windowStart time.Time) (state State, ok bool, err error)
+	// Release 撤销一次预占，失败次数减 1
+	Release(ctx context.Context, key string) error
+	// Lock 锁定键直到 until 并清零失败次数，锁定结束后重新计数
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset 清除键的失败记录和锁定
+	Reset(ctx context.Context, key string) error
+}
+
+// maxReserveRetries 记录被并发请求修改时重新检查的次数，超过后拒绝本次尝试，1 秒后再试
+const maxReserveRetries = 5
+
+// Policy 退避和锁定策略
+type Policy struct {
+	FreeAttempts     int           // 连续失败多少次之后开始退避
+	BaseDelay        time.Duration // 第一次退避的等待时间，之后每次失败翻倍
+	MaxDelay         time.Duration // 退避等待时间上限
+	LockoutThreshold int           // 连续失败多少次后锁定，0 表示不锁定
+	LockoutDuration  time.Duration // 锁定时长
+	Window           time.Duration // 超过该时间没有失败则重新计数
+}
+
+// delay 返回失败 failures 次后需要等待的时间
+func (p Policy) delay(failures int) time.Duration {
+	if failures < p.FreeAttempts {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := p.FreeAttempts; i < failures && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// wait 返回当前还需要等待的时间，locked 表示处于锁定状态
+func (p Policy) wait(state State, now time.Time) (time.Duration, bool) {
+	if now.Before(state.LockedUntil) {
+		return state.LockedUntil.Sub(now), true
+	}
+	if state.Failures == 0 || now.Sub(state.LastFailureAt) >= p.Window {
+		return 0, false
+	}
+	// 并发的请求可能已经写入了比 now 稍晚的失败时间，无需退避时直接允许
+	delay := p.delay(state.Failures)
+	if delay == 0 {
+		return 0, false
+	}
+	if until := state.LastFailureAt.Add(delay); now.Before(until) {
+		return until.Sub(now), false
+	}
+	return 0, false
+}
+
+// Decision 登录尝试的检查结果
+type Decision struct {
+	RetryAfter time.Duration // 大于 0 时拒绝本次尝试
+	Locked     bool          // 账户处于锁定状态（需要等待锁定结束或解锁）
+}
+
+// Allowed 是否允许本次尝试
+func (d Decision) Allowed() bool {
+	return d.RetryAfter <= 0
+}
+
+// Reservation 预占的登录尝试，验证之后调用 Guard.Fail、Guard.Succeed 或 Guard.Release
+type Reservation struct {
+	Decision
+	email    string
+	ip       string
+	failures int // 预占后账户的失败次数
+}
+
+// Guard 按账户和 IP 两个维度检查登录尝试
+type Guard struct {
+	store   Store
+	account Policy
+	ip      Policy
+	now     func() time.Time
+}
+
+// New 创建登录保护，account 和 ip 分别为按账户和按 IP 的策略
+func New(store Store, account Policy, ip Policy) *Guard {
+	return &Guard{store: store, account: account, ip: ip, now: time.Now}
+}
+
+// Reserve 在验证密码或验证码之前检查账户和 IP 是否允许尝试，允许时先按失败计数，
+// 检查和计数是同一个原子操作，并发的请求会看到彼此，不能同时绕过退避和锁定
+func (g *Guard) Reserve(ctx context.Context, email string, ip string) (*Reservation, error) {
+	now := g.now()
+	r := &Reservation{email: email, ip: ip}
+
+	state, decision, err := g.reserve(ctx, accountKey(email), g.account, now)
+	if err != nil || !decision.Allowed() {
+		r.Decision = decision
+		return r, err
+	}
+	r.failures = state.Failures
+
+	if ip != "" {
+		_, decision, err := g.reserve(ctx, ipKey(ip), g.ip, now)
+		if err == nil && decision.Allowed() {
+			return r, nil
+		}
+		if releaseErr := g.store.Release(ctx, accountKey(email)); err == nil {
+			err = releaseErr
+		}
+		r.Decision = decision
+		return r, err
+	}
+	return r, nil
+}
+
+// reserve 检查并预占一个键，记录被并发请求修改时重新检查
+func (g *Guard) reserve(ctx context.Context, key string, policy Policy, now time.Time) (State, Decision, error) {
+	for i := 0; i < maxReserveRetries; i++ {
+		seen, err := g.store.Get(ctx, key)
+		if err != nil {
+			return State{}, Decision{}, err
+		}
+		if wait, locked := policy.wait(seen, now); wait > 0 {
+			return seen, Decision{RetryAfter: wait, Locked: locked}, nil
+		}
+
+		state, ok, err := g.store.Reserve(ctx, key, seen, now, now.Add(-policy.Window))
+		if err != nil {
+			return State{}, Decision{}, err
+		}
+		if ok {
+			return state, Decision{}, nil
+		}
+	}
+	return State{}, Decision{RetryAfter: time.Second}, nil
+}
+
+// Fail 验证失败，预占的尝试计为失败；账户连续失败达到锁定阈值时锁定账户并返回 locked=true
+func (g *Guard) Fail(ctx context.Context, r *Reservation) (locked bool, err error) {
+	if g.account.LockoutThreshold > 0 && r.failures >= g.account.LockoutThreshold {
+		return true, g.store.Lock(ctx, accountKey(r.email), g.now().Add(g.account.LockoutDuration))
+	}
+	return false, nil
+}
+
+// Succeed 登录成功后清除账户的失败记录；IP 的记录保留，只撤销本次预占，避免用自己的账户重置计数
+func (g *Guard) Succeed(ctx context.Context, r *Reservation) error {
+	if err := g.store.Reset(ctx, accountKey(r.email)); err != nil {
+		return err
+	}
+	if r.ip != "" {
+		return g.store.Release(ctx, ipKey(r.ip))
+	}
+	return nil
+}
+
+// Release 撤销预占，用于验证通过但没有完成登录的情况（需要两步验证、账户已停用等）
+func (g *Guard) Release(ctx context.Context, r *Reservation) error {
+	if err := g.store.Release(ctx, accountKey(r.email)); err != nil {
+		return err
+	}
+	if r.ip != "" {
+		return g.store.Release(ctx, ipKey(r.ip))
+	}
+	return nil
+}
+
+// Unlock 解除账户锁定并清除失败记录（解锁邮件、重置密码或管理员操作）
+func (g *Guard) Unlock(ctx context.Context, email string) error {
+	return g.store.Reset(ctx, accountKey(email))
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
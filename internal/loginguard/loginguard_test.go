@@ -0,0 +1,125 @@
+package loginguard_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+)
+
+// policy 失败 3 次后退避，第 3 次失败时锁定
+var policy = loginguard.Policy{
+	FreeAttempts:     3,
+	BaseDelay:        time.Minute,
+	MaxDelay:         time.Hour,
+	LockoutThreshold: 3,
+	LockoutDuration:  time.Hour,
+	Window:           time.Hour,
+}
+
+// slowStore 读取记录后等待一会儿，让并发的请求都在检查和计数之间
+type slowStore struct {
+	loginguard.Store
+}
+
+func (s slowStore) Get(ctx context.Context, key string) (loginguard.State, error) {
+	state, err := s.Store.Get(ctx, key)
+	time.Sleep(10 * time.Millisecond)
+	return state, err
+}
+
+// reserveConcurrently 从不同 IP 并发预占同一账户 n 次，返回允许的预占
+func reserveConcurrently(t *testing.T, guard *loginguard.Guard, n int) []*loginguard.Reservation {
+	t.Helper()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed []*loginguard.Reservation
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := guard.Reserve(context.Background(), "user@example.com", "")
+			if err != nil {
+				t.Errorf("预占失败: %v", err)
+				return
+			}
+			if r.Allowed() {
+				mu.Lock()
+				allowed = append(allowed, r)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return allowed
+}
+
+func TestConcurrentAttemptsCannotBypassLockout(t *testing.T) {
+	ctx := context.Background()
+	guard := loginguard.New(slowStore{loginguard.NewMemoryStore()}, policy, loginguard.Policy{FreeAttempts: 100, Window: time.Hour})
+
+	allowed := reserveConcurrently(t, guard, 50)
+	if len(allowed) != policy.FreeAttempts {
+		t.Fatalf("并发尝试中允许了 %d 次，期望 %d 次", len(allowed), policy.FreeAttempts)
+	}
+
+	locked := 0
+	for _, r := range allowed {
+		l, err := guard.Fail(ctx, r)
+		if err != nil {
+			t.Fatalf("记录失败: %v", err)
+		}
+		if l {
+			locked++
+		}
+	}
+	if locked != 1 {
+		t.Errorf("锁定了 %d 次，期望 1 次", locked)
+	}
+
+	r, err := guard.Reserve(ctx, "USER@example.com", "203.0.113.1")
+	if err != nil {
+		t.Fatalf("预占失败: %v", err)
+	}
+	if r.Allowed() || !r.Locked {
+		t.Errorf("锁定后的尝试: %+v，期望锁定", r.Decision)
+	}
+}
+
+func TestReleaseAndSucceed(t *testing.T) {
+	ctx := context.Background()
+	store := loginguard.NewMemoryStore()
+	guard := loginguard.New(store, policy, loginguard.Policy{FreeAttempts: 100, Window: time.Hour})
+
+	// 撤销的预占不计入失败次数
+	for i := 0; i < 2*policy.FreeAttempts; i++ {
+		r, err := guard.Reserve(ctx, "user@example.com", "203.0.113.1")
+		if err != nil || !r.Allowed() {
+			t.Fatalf("第 %d 次尝试被拒绝: %+v, %v", i+1, r, err)
+		}
+		if err := guard.Release(ctx, r); err != nil {
+			t.Fatalf("撤销预占失败: %v", err)
+		}
+	}
+
+	// 登录成功清除账户的失败记录，IP 只撤销本次预占
+	r, _ := guard.Reserve(ctx, "user@example.com", "203.0.113.1")
+	if _, err := guard.Fail(ctx, r); err != nil {
+		t.Fatalf("记录失败: %v", err)
+	}
+	r, _ = guard.Reserve(ctx, "user@example.com", "203.0.113.1")
+	if err := guard.Succeed(ctx, r); err != nil {
+		t.Fatalf("记录成功失败: %v", err)
+	}
+	if state, _ := store.Get(ctx, "account:user@example.com"); state.Failures != 0 {
+		t.Errorf("登录成功后账户失败次数 = %d，期望 0", state.Failures)
+	}
+	if state, _ := store.Get(ctx, "ip:203.0.113.1"); state.Failures != 1 {
+		t.Errorf("登录成功后 IP 失败次数 = %d，期望 1", state.Failures)
+	}
+}
@@ -0,0 +1,91 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxMemoryKeys 内存中的记录超过该数量时清理过期记录
+const maxMemoryKeys = 10000
+
+// MemoryStore 进程内存储，适用于单实例部署，重启后记录丢失
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]State)}
+}
+
+// Get 返回键的失败记录
+func (s *MemoryStore) Get(_ context.Context, key string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[key], nil
+}
+
+// Reserve 失败次数未被修改且未锁定时增加失败次数
+func (s *MemoryStore) Reserve(_ context.Context, key string, seen State, now time.Time, windowStart time.Time) (State, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.states) >= maxMemoryKeys {
+		s.prune(now, windowStart)
+	}
+
+	state := s.states[key]
+	if state.Failures != seen.Failures || now.Before(state.LockedUntil) {
+		return state, false, nil
+	}
+	if state.LastFailureAt.Before(windowStart) {
+		state.Failures = 0
+	}
+	state.Failures++
+	state.LastFailureAt = now
+	s.states[key] = state
+	return state, true, nil
+}
+
+// Release 失败次数减 1
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, ok := s.states[key]; ok && state.Failures > 0 {
+		state.Failures--
+		s.states[key] = state
+	}
+	return nil
+}
+
+// Lock 锁定键
+func (s *MemoryStore) Lock(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[key]
+	state.Failures = 0
+	state.LockedUntil = until
+	s.states[key] = state
+	return nil
+}
+
+// Reset 清除键的记录
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// prune 删除窗口外且未锁定的记录，调用方需持有锁
+func (s *MemoryStore) prune(now time.Time, windowStart time.Time) {
+	for key, state := range s.states {
+		if state.LastFailureAt.Before(windowStart) && !now.Before(state.LockedUntil) {
+			delete(s.states, key)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileSender 把邮件保存为 .eml 文件，便于本地测试时查看邮件中的链接
+type FileSender struct {
+	from string
+	dir  string
+}
+
+// NewFileSender 创建文件邮件发送器，目录不存在时自动创建
+func NewFileSender(from, dir string) (*FileSender, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("file 邮件发送方式需要设置保存目录")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建邮件目录失败: %w", err)
+	}
+	return &FileSender{from: from, dir: dir}, nil
+}
+
+// Send 将邮件写入 <dir>/<时间>-<uuid>.eml
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), uuid.NewString())
+	return os.WriteFile(filepath.Join(s.dir, name), buildMessage(s.from, msg, false), 0o644)
+}
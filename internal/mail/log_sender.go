@@ -0,0 +1,22 @@
+package mail
+
+import (
+	"context"
+	"log"
+)
+
+// LogSender 把邮件内容写入日志，不真正发送
+type LogSender struct {
+	from string
+}
+
+// NewLogSender 创建日志邮件发送器
+func NewLogSender(from string) *LogSender {
+	return &LogSender{from: from}
+}
+
+// Send 输出邮件到日志
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	log.Printf("📧 邮件 From: %s To: %s Subject: %s\n%s", s.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,82 @@
+// Package mail 提供可替换的邮件发送实现：SMTP 用于生产，日志和文件用于本地开发测试
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"time"
+)
+
+// Message 邮件内容，正文为纯文本
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 邮件发送接口
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// 发送方式
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+// Config 邮件发送配置
+type Config struct {
+	Driver   string
+	From     string
+	FileDir  string // file 方式保存邮件的目录
+	SMTPHost string
+	SMTPPort int
+	Username string
+	Password string
+}
+
+// New 根据配置创建邮件发送器
+func New(cfg Config) (Sender, error) {
+	switch cfg.Driver {
+	case DriverLog, "":
+		return NewLogSender(cfg.From), nil
+	case DriverFile:
+		return NewFileSender(cfg.From, cfg.FileDir)
+	case DriverSMTP:
+		return NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.Username, cfg.Password, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件发送方式: %s", cfg.Driver)
+	}
+}
+
+// buildMessage 生成 RFC 5322 格式的纯文本邮件，主题使用 UTF-8 编码
+// base64Body 为 false 时正文按 8bit 原样写入，便于直接阅读
+func buildMessage(from string, msg *Message, base64Body bool) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+
+	if !base64Body {
+		buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+		buf.WriteString(msg.Body)
+		return buf.Bytes()
+	}
+
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
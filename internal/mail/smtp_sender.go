@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPSender 通过 SMTP 服务器发送邮件，服务器支持时自动使用 STARTTLS
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPSender 创建 SMTP 邮件发送器，username 为空时不进行认证
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPSender{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send 发送邮件
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("发件人地址无效: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, from.Address, []string{msg.To}, buildMessage(s.from, msg, true))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
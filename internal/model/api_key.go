@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// APIKey 个人 API 密钥，供脚本和第三方集成调用接口；密钥只在创建时返回一次，数据库中只保存摘要
+type APIKey struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string     `gorm:"type:uuid;index;not null" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"` // 密钥开头几位，用于在列表中辨认
+	KeyHash    string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Scopes     []string   `gorm:"type:text;serializer:json;not null" json:"scopes"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active 密钥未过期
+func (k *APIKey) Active(now time.Time) bool {
+	return now.Before(k.ExpiresAt)
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// 审计日志的操作类型
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditLog 数据变更审计日志，只追加不修改
+type AuditLog struct {
+	ID         string                  `gorm:"type:uuid;primaryKey" json:"id"`
+	EntityType string                  `gorm:"type:varchar(50);not null" json:"entity_type"` // 表名，如 nutrition_goals
+	EntityID   string                  `gorm:"type:varchar(64);not null" json:"entity_id"`
+	Action     string                  `gorm:"type:varchar(10);not null" json:"action"`            // create/update/delete
+	OwnerID    string                  `gorm:"type:varchar(64);index" json:"owner_id,omitempty"`   // 数据所属的用户，公共食物为空；永久清除用户时按该字段删除
+	ActorID    string                  `gorm:"type:varchar(64);index" json:"actor_id,omitempty"`   // 操作的用户，注册等匿名请求和后台任务为空
+	RequestID  string                  `gorm:"type:varchar(64);index" json:"request_id,omitempty"` // 请求ID，对应响应头 X-Request-ID
+	Changes    map[string]*AuditChange `gorm:"type:text;serializer:json;not null" json:"changes"`
+	CreatedAt  time.Time               `gorm:"index" json:"created_at"`
+}
+
+// AuditChange 一个字段变更前后的值；创建时只有 new，删除时只有 old
+type AuditChange struct {
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
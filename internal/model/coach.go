@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+)
+
+// 教练与客户关系的状态
+const (
+	CoachClientActive = "active" // 客户已接受，教练可以按授权范围查看客户数据
+	CoachClientEnded  = "ended"  // 任一方结束了关系
+)
+
+// 教练邀请的状态
+const (
+	CoachInvitationPending  = "pending"  // 教练已邀请，等待对方接受
+	CoachInvitationAccepted = "accepted" // 对方已接受，教练关系生效
+	CoachInvitationDeclined = "declined" // 对方拒绝了邀请
+)
+
+// CoachClient 教练与客户的关系，客户接受教练邀请后建立
+type CoachClient struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	CoachID    string     `gorm:"type:uuid;uniqueIndex:idx_coach_clients_pair;not null" json:"coach_id"`
+	ClientID   string     `gorm:"type:uuid;uniqueIndex:idx_coach_clients_pair;index;not null" json:"client_id"`
+	Status     string     `gorm:"type:varchar(20);not null" json:"status"`
+	Scopes     []string   `gorm:"type:text;serializer:json;not null" json:"scopes"` // records:read/goals:read/goals:write
+	Message    string     `gorm:"type:varchar(500)" json:"message,omitempty"`       // 最近一次接受的邀请附言
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// 关联关系
+	Coach  *User `gorm:"foreignKey:CoachID" json:"-"`
+	Client *User `gorm:"foreignKey:ClientID" json:"-"`
+}
+
+// CoachInvitation 教练按邮箱邀请客户，对方验证邮箱并接受后建立（或更新）教练关系
+// 不要求该邮箱已注册，邀请时不会透露该邮箱是否已注册
+type CoachInvitation struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	CoachID   string    `gorm:"type:uuid;uniqueIndex:idx_coach_invitations_pair;not null" json:"coach_id"`
+	Email     string    `gorm:"type:varchar(255);uniqueIndex:idx_coach_invitations_pair;index;not null" json:"email"` // 统一为小写
+	Scopes    []string  `gorm:"type:text;serializer:json;not null" json:"scopes"`                                     // 申请的授权范围，接受后写入教练关系
+	Message   string    `gorm:"type:varchar(500)" json:"message,omitempty"`                                           // 邀请附言
+	Status    string    `gorm:"type:varchar(20);not null" json:"status"`                                              // pending/accepted/declined
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联关系
+	Coach *User `gorm:"foreignKey:CoachID" json:"-"`
+}
+
+// CoachAccessLog 教练查看或修改客户数据的访问记录，客户可以查看
+type CoachAccessLog struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	CoachID   string    `gorm:"type:uuid;not null" json:"coach_id"`
+	ClientID  string    `gorm:"type:uuid;index;not null" json:"client_id"`
+	Method    string    `gorm:"type:varchar(10);not null" json:"method"`
+	Path      string    `gorm:"type:varchar(255);not null" json:"path"`
+	Status    int       `gorm:"not null" json:"status"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// MealComment 餐次记录下的评论，教练和记录的主人都可以评论
+type MealComment struct {
+	ID           string    `gorm:"type:uuid;primaryKey" json:"id"`
+	MealRecordID string    `gorm:"type:uuid;index;not null" json:"meal_record_id"`
+	AuthorID     string    `gorm:"type:uuid;index;not null" json:"author_id"`
+	Body         string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// 关联关系
+	Author *User `gorm:"foreignKey:AuthorID" json:"-"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// ExerciseIntensity 运动强度
+type ExerciseIntensity string
+
+const (
+	IntensityLow      ExerciseIntensity = "low"      // 低强度
+	IntensityModerate ExerciseIntensity = "moderate" // 中等强度
+	IntensityHigh     ExerciseIntensity = "high"     // 高强度
+)
+
+// ExerciseRecord 运动记录模型
+type ExerciseRecord struct {
+	ID             string            `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID         string            `gorm:"type:uuid;index;not null" json:"user_id"`
+	Date           time.Time         `gorm:"type:date;index;not null" json:"date"`
+	ExerciseType   string            `gorm:"type:varchar(50);not null" json:"exercise_type"` // 运动类型（running, cycling 等）
+	Duration       int               `gorm:"type:int;not null" json:"duration"`              // 运动时长（分钟）
+	Intensity      ExerciseIntensity `gorm:"type:varchar(20);not null" json:"intensity"`     // 运动强度
+	CaloriesBurned float64           `json:"calories_burned"`                                // 消耗热量（kcal）
+	IsManual       bool              `gorm:"default:false" json:"is_manual"`                 // 热量是否为用户手动填写
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
@@ -5,7 +5,7 @@ import (
 )
 
 type Food struct {
-	ID            string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ID            string    `gorm:"type:uuid;primaryKey" json:"id"`
 	Name          string    `gorm:"index;not null" json:"name"`
 	Calories      float64   `json:"calories"`
 	Protein       float64   `json:"protein"`
@@ -6,9 +6,9 @@ import (
 
 // FoodRecord 食物记录模型
 type FoodRecord struct {
-	ID            string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ID            string    `gorm:"type:uuid;primaryKey" json:"id"`
 	MealRecordID  string    `gorm:"type:uuid;index;not null" json:"meal_record_id"` // 关联的餐次ID
-	FoodID        string    `gorm:"type:uuid;index;not null" json:"food_id"`        // 关联的食物ID
+	FoodID        *string   `gorm:"type:uuid;index" json:"food_id"`                 // 关联的食物ID，导入的快速添加条目为空
 	FoodName      string    `gorm:"type:varchar(100);not null" json:"food_name"`    // 冗余存储食物名称，提高查询效率
 	Quantity      float64   `gorm:"type:float;not null" json:"quantity"`            // 份量
 	Unit          string    `gorm:"type:varchar(20);not null" json:"unit"`          // 单位（g, kg, ml, 个等）
@@ -18,6 +18,7 @@ type FoodRecord struct {
 	Fat           float64   `json:"fat"`                                             // 实际摄入的脂肪
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	ImportKey     *string   `gorm:"type:varchar(64);uniqueIndex" json:"-"` // 导入来源中对应行的标识，用于重复导入时去重
 
 	// 关联关系
 	MealRecord MealRecord `gorm:"foreignKey:MealRecordID" json:"-"`
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+)
+
+// 家庭成员角色
+const (
+	HouseholdRoleOwner     = "owner"     // 创建者，可以邀请和移除成年成员
+	HouseholdRoleMember    = "member"    // 成年成员，使用自己的账户登录，可以代为记录受抚养成员
+	HouseholdRoleDependent = "dependent" // 受抚养成员（儿童、老人），没有登录账户，由成年成员代为记录
+)
+
+// 家庭邀请的状态
+const (
+	HouseholdInvitationPending  = "pending"  // 创建者已邀请，等待对方接受
+	HouseholdInvitationAccepted = "accepted" // 对方已接受并加入家庭
+	HouseholdInvitationDeclined = "declined" // 对方拒绝了邀请
+)
+
+// Household 家庭，成年成员可以管理受抚养成员的档案和饮食记录
+type Household struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	CreatedBy string    `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HouseholdMember 家庭成员，每个用户（档案）最多属于一个家庭
+type HouseholdMember struct {
+	ID          string    `gorm:"type:uuid;primaryKey" json:"id"`
+	HouseholdID string    `gorm:"type:uuid;index;not null" json:"household_id"`
+	UserID      string    `gorm:"type:uuid;uniqueIndex;not null" json:"user_id"`
+	Role        string    `gorm:"type:varchar(20);not null" json:"role"` // owner/member/dependent
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 关联关系
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// HouseholdInvitation 邀请成年成员加入家庭，对方登录后接受才会加入
+// 按邮箱邀请，不要求该邮箱已注册，邀请时不会透露该邮箱是否已注册
+type HouseholdInvitation struct {
+	ID          string    `gorm:"type:uuid;primaryKey" json:"id"`
+	HouseholdID string    `gorm:"type:uuid;uniqueIndex:idx_household_invitations_pair;not null" json:"household_id"`
+	Email       string    `gorm:"type:varchar(255);uniqueIndex:idx_household_invitations_pair;index;not null" json:"email"` // 统一为小写
+	InvitedBy   string    `gorm:"type:uuid;not null" json:"invited_by"`
+	Status      string    `gorm:"type:varchar(20);not null" json:"status"` // pending/accepted/declined
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联关系
+	Household *Household `gorm:"foreignKey:HouseholdID" json:"-"`
+}
+
+// Adult 是否为成年成员（可以代为记录受抚养成员）
+func (m *HouseholdMember) Adult() bool {
+	return m.Role == HouseholdRoleOwner || m.Role == HouseholdRoleMember
+}
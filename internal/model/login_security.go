@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginThrottle 登录失败计数（按账户或 IP），多实例部署时共享
+type LoginThrottle struct {
+	Key           string     `gorm:"column:throttle_key;type:varchar(320);primaryKey" json:"key"` // account:<邮箱> 或 ip:<地址>
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// LoginAttempt 登录审计记录
+type LoginAttempt struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    *string   `gorm:"type:uuid;index" json:"user_id,omitempty"` // 邮箱未注册时为空
+	Email     string    `gorm:"type:varchar(255);index;not null" json:"email"`
+	IP        string    `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent string    `gorm:"type:varchar(255)" json:"user_agent"`
+	Success   bool      `gorm:"not null" json:"success"`
+	Reason    string    `gorm:"type:varchar(30)" json:"reason,omitempty"` // 失败原因
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// 登录失败原因
+const (
+	LoginFailedInvalidCredentials = "invalid_credentials"
+	LoginFailedThrottled          = "throttled"
+	LoginFailedLocked             = "locked"
+	LoginFailedInvalidMFACode     = "invalid_mfa_code"
+)
@@ -69,7 +69,7 @@ func (m *MealType) UnmarshalJSON(data []byte) error {
 
 // MealRecord 餐次记录模型
 type MealRecord struct {
-	ID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
 	UserID    string    `gorm:"type:uuid;index;not null" json:"user_id"`
 	Date      time.Time `gorm:"type:date;index;not null" json:"date"`
 	MealType  MealType  `gorm:"type:int;not null" json:"meal_type"` // 1:早餐, 2:午餐, 3:晚餐, 4:加餐
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+)
+
+// MFARecoveryCode 两步验证恢复码，丢失验证器时代替验证码登录，每个只能使用一次，只保存摘要
+type MFARecoveryCode struct {
+	ID        string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    string     `gorm:"type:uuid;index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
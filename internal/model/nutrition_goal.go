@@ -5,7 +5,7 @@ import (
 )
 
 type NutritionGoal struct {
-	ID            string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ID            string    `gorm:"type:uuid;primaryKey" json:"id"`
 	UserID        string    `gorm:"type:uuid;index;not null" json:"user_id"`
 	Calories      float64   `json:"calories"`
 	Protein       float64   `json:"protein"`
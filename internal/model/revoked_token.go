@@ -0,0 +1,12 @@
+package model
+
+import (
+	"time"
+)
+
+// RevokedToken 已撤销的访问令牌（jti 拒绝名单），过期后可以清理
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey" json:"jti"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// Session 登录会话，同一次登录轮换出的所有刷新令牌属于同一个会话（令牌族）
+type Session struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string     `gorm:"type:uuid;index;not null" json:"user_id"`
+	DeviceName string     `gorm:"type:varchar(100)" json:"device_name"`
+	UserAgent  string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IP         string     `gorm:"type:varchar(45)" json:"ip"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt time.Time  `gorm:"not null" json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// 最近签发的访问令牌，撤销会话时加入拒绝名单
+	AccessTokenID   string     `gorm:"type:varchar(64)" json:"-"`
+	AccessExpiresAt *time.Time `json:"-"`
+}
+
+// Active 会话未撤销且未过期
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken 刷新令牌，只保存摘要；每次刷新后旧令牌被标记为已使用
+type RefreshToken struct {
+	ID        string     `gorm:"type:uuid;primaryKey" json:"id"`
+	SessionID string     `gorm:"type:uuid;index;not null" json:"session_id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
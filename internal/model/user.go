@@ -6,19 +6,35 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户
+	RoleCoach = "coach" // 教练，可以查看授权客户的数据
+	RoleAdmin = "admin" // 管理员，可以管理用户和公共食物库
+)
+
 type User struct {
-	ID            string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	Email         string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
-	PasswordHash  string         `gorm:"not null" json:"-"`
-	Nickname      string         `gorm:"type:varchar(50)" json:"nickname"`
-	Gender        int            `gorm:"type:int;default:0" json:"gender"` // 0:未知,1:男,2:女
-	Age           int            `gorm:"type:int" json:"age"`
-	Height        float64        `gorm:"type:float" json:"height"`                 // cm
-	Weight        float64        `gorm:"type:float" json:"weight"`                 // kg
-	ActivityLevel int            `gorm:"type:int;default:3" json:"activity_level"` // 1-5
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"` // 软删除字段
+	ID              string         `gorm:"type:uuid;primaryKey" json:"id"`
+	Email           string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	PasswordHash    string         `gorm:"not null" json:"-"`
+	Nickname        string         `gorm:"type:varchar(50)" json:"nickname"`
+	Gender          int            `gorm:"type:int;default:0" json:"gender"` // 0:未知,1:男,2:女
+	Age             int            `gorm:"type:int" json:"age"`
+	Height          float64        `gorm:"type:float" json:"height"`                                 // cm
+	Weight          float64        `gorm:"type:float" json:"weight"`                                 // kg
+	ActivityLevel   int            `gorm:"type:int;default:3" json:"activity_level"`                 // 1-5
+	ExerciseMode    string         `gorm:"type:varchar(10);default:auto" json:"exercise_mode"`       // 运动消耗计入预算方式：auto/add/ignore
+	Language        string         `gorm:"type:varchar(10)" json:"language"`                         // 接口消息语言偏好：zh-CN/en-US，为空时按请求头协商
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`                                        // 邮箱验证时间，为空表示未验证
+	TOTPSecret      string         `gorm:"column:totp_secret;type:varchar(64)" json:"-"`             // 两步验证密钥（base32），开启前为待确认的密钥
+	TOTPEnabledAt   *time.Time     `gorm:"column:totp_enabled_at" json:"totp_enabled_at"`            // 两步验证开启时间，为空表示未开启
+	TOTPLastStep    int64          `gorm:"column:totp_last_step;default:0" json:"-"`                 // 最近一次使用的验证码步数，防止重放
+	Role            string         `gorm:"type:varchar(20);index;not null;default:user" json:"role"` // 角色：user/coach/admin
+	DisabledAt      *time.Time     `json:"disabled_at,omitempty"`                                    // 管理员禁用账户的时间，为空表示正常
+	Dependent       bool           `gorm:"not null;default:false" json:"dependent"`                  // 家庭中的受抚养成员档案，没有密码，不能登录
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"` // 软删除字段
 }
 
 // 注意：如果使用软删除，查询时会自动过滤已删除的记录
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// UserData 用户的全部数据，用于个人数据导出
+type UserData struct {
+	ExportedAt           time.Time              `json:"exported_at"`
+	User                 *User                  `json:"user"`
+	NutritionGoals       []*NutritionGoal       `json:"nutrition_goals"`
+	MealRecords          []*MealRecord          `json:"meal_records"`
+	FoodRecords          []*FoodRecord          `json:"food_records"`
+	ExerciseRecords      []*ExerciseRecord      `json:"exercise_records"`
+	WaterRecords         []*WaterRecord         `json:"water_records"`
+	Sessions             []*Session             `json:"sessions"`
+	Identities           []*UserIdentity        `json:"identities"`            // 关联的第三方登录身份
+	APIKeys              []*APIKey              `json:"api_keys"`              // 个人 API 密钥（不含密钥本身）
+	CoachClients         []*CoachClient         `json:"coach_clients"`         // 作为教练或客户的关系
+	CoachInvitations     []*CoachInvitation     `json:"coach_invitations"`     // 发给自己邮箱的和自己发出的教练邀请
+	MealComments         []*MealComment         `json:"meal_comments"`         // 自己餐次下的评论和自己发表的评论
+	CoachAccessLogs      []*CoachAccessLog      `json:"coach_access_logs"`     // 教练对自己数据的访问记录
+	Households           []*Household           `json:"households"`            // 所在的家庭
+	HouseholdMembers     []*HouseholdMember     `json:"household_members"`     // 在家庭中的成员身份
+	HouseholdInvitations []*HouseholdInvitation `json:"household_invitations"` // 发给自己邮箱的和自己发出的家庭邀请
+	LoginAttempts        []*LoginAttempt        `json:"login_attempts"`        // 登录记录，包括 IP 地址和设备
+	MFARecoveryCodes     []*MFARecoveryCode     `json:"mfa_recovery_codes"`    // 两步验证恢复码的生成和使用时间（不含恢复码）
+	UserTokens           []*UserToken           `json:"user_tokens"`           // 一次性令牌的用途和使用时间（不含令牌）
+	RefreshTokens        []*RefreshToken        `json:"refresh_tokens"`        // 登录会话的刷新令牌轮换记录（不含令牌）
+	AuditLogs            []*AuditLog            `json:"audit_logs"`            // 自己数据的变更记录，以及自己执行的变更
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// UserIdentity 与用户关联的外部身份（OIDC 提供方 + 该提供方中的用户标识 sub）
+type UserIdentity struct {
+	ID          string    `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      string    `gorm:"type:uuid;index;not null" json:"user_id"`
+	Provider    string    `gorm:"type:varchar(50);uniqueIndex:idx_user_identities_provider_subject;not null" json:"provider"`
+	Subject     string    `gorm:"type:varchar(255);uniqueIndex:idx_user_identities_provider_subject;not null" json:"subject"`
+	Email       string    `gorm:"type:varchar(255)" json:"email"` // 关联时提供方返回的邮箱
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OIDCState 进行中的第三方登录，保存 state 摘要、nonce 和 PKCE verifier，回调时一次性使用
+type OIDCState struct {
+	StateHash    string    `gorm:"type:varchar(64);primaryKey" json:"-"`
+	Provider     string    `gorm:"type:varchar(50);not null" json:"provider"`
+	Nonce        string    `gorm:"type:varchar(64);not null" json:"-"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"index;not null" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (OIDCState) TableName() string {
+	return "oidc_states"
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// UserTokenPurpose 一次性令牌用途
+type UserTokenPurpose string
+
+const (
+	TokenPurposePasswordReset     UserTokenPurpose = "password_reset"
+	TokenPurposeEmailVerification UserTokenPurpose = "email_verification"
+	TokenPurposeAccountUnlock     UserTokenPurpose = "account_unlock"
+	TokenPurposeMFAChallenge      UserTokenPurpose = "mfa_challenge"
+)
+
+// UserToken 一次性令牌（密码重置、邮箱验证、账户解锁、两步验证登录挑战），只保存摘要
+type UserToken struct {
+	ID        string           `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    string           `gorm:"type:uuid;index;not null" json:"user_id"`
+	Purpose   UserTokenPurpose `gorm:"type:varchar(30);not null" json:"purpose"`
+	TokenHash string           `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time        `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time       `json:"used_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// WaterRecord 饮水记录模型
+type WaterRecord struct {
+	ID         string    `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string    `gorm:"type:uuid;index;not null" json:"user_id"`
+	Date       time.Time `gorm:"type:date;index;not null" json:"date"`
+	Amount     float64   `gorm:"type:float;not null" json:"amount"` // 饮水量（ml）
+	RecordedAt time.Time `gorm:"not null" json:"recorded_at"`       // 饮水时间
+	CreatedAt  time.Time `json:"created_at"`
+}
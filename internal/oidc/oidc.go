@@ -0,0 +1,319 @@
+// Package oidc 实现 OpenID Connect 授权码登录（RFC 6749 + PKCE RFC 7636）：
+// 通过发现文档获取端点，用授权码换取 ID 令牌，并按 JWKS 校验签名和声明
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Config 身份提供方配置
+type Config struct {
+	Name         string // 提供方标识，用于路由，例如 google
+	DisplayName  string // 登录按钮上显示的名称
+	Issuer       string // 发现文档地址为 <Issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // 在提供方登记的回调地址
+	Scopes       []string // 为空时使用 openid email profile
+}
+
+// Identity 从 ID 令牌中得到的外部身份
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// ErrInvalidIDToken ID 令牌签名或声明校验失败
+var ErrInvalidIDToken = errors.New("ID 令牌无效")
+
+// leeway 校验 exp/iat 时容忍的时钟误差
+const leeway = time.Minute
+
+// metadata 发现文档中用到的字段
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider 身份提供方客户端；发现文档和签名公钥在首次使用时加载并缓存，
+// 遇到未知的 kid 时重新加载公钥以支持提供方轮换密钥
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu   sync.Mutex
+	meta *metadata
+	keys map[string]interface{}
+}
+
+// NewProvider 创建身份提供方客户端，client 为 nil 时使用 10 秒超时的默认客户端
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.DisplayName == "" {
+		cfg.DisplayName = cfg.Name
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+// Name 提供方标识
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// DisplayName 提供方显示名称
+func (p *Provider) DisplayName() string {
+	return p.cfg.DisplayName
+}
+
+// AuthCodeURL 返回跳转到提供方登录页的地址，codeChallenge 由 Challenge(verifier) 计算
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge, loginHint string) (string, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	if loginHint != "" {
+		params.Set("login_hint", loginHint)
+	}
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange 用授权码和 PKCE verifier 换取 ID 令牌，校验后返回外部身份
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic，凭据需要先做表单编码（RFC 6749 2.3.1）
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求令牌端点失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取令牌响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("令牌端点返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("解析令牌响应失败: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: 令牌响应中没有 id_token", ErrInvalidIDToken)
+	}
+
+	return p.verify(ctx, meta, token.IDToken, nonce)
+}
+
+// idTokenClaims ID 令牌中用到的声明
+type idTokenClaims struct {
+	Email string `json:"email"`
+	// EmailVerified 部分提供方返回字符串 "true"
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+	Nonce         string      `json:"nonce"`
+	AuthorizedBy  string      `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// verify 校验 ID 令牌的签名、签发方、受众、有效期和 nonce（OIDC Core 3.1.3.7）
+func (p *Provider) verify(ctx context.Context, meta *metadata, raw, nonce string) (*Identity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedBy != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp 与 client_id 不一致", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce 不匹配", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: 缺少 sub", ErrInvalidIDToken)
+	}
+
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	return &Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: verified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// metadata 加载并缓存发现文档
+func (p *Provider) metadata(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	var meta metadata
+	wellKnown := strings.TrimRight(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, &meta); err != nil {
+		return nil, fmt.Errorf("加载 %s 的发现文档失败: %w", p.cfg.Name, err)
+	}
+	// 发现文档中的 issuer 必须与配置一致（OIDC Discovery 4.3）
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("发现文档的 issuer %q 与配置 %q 不一致", meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%s 的发现文档缺少必要的端点", p.cfg.Name)
+	}
+
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// key 返回 kid 对应的签名公钥，缓存中没有时重新加载 JWKS
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+
+	var set jwks
+	if err := p.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("加载签名公钥失败: %w", err)
+	}
+	keys, err := set.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未知的签名密钥 %q", kid)
+}
+
+// lookup 在缓存中查找公钥；令牌没有 kid 且只有一个公钥时使用该公钥
+func (p *Provider) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// getJSON 请求地址并解析 JSON 响应
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回 %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// RandomString 生成 URL 安全的随机字符串，用于 state、nonce 和 PKCE verifier
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge 计算 PKCE S256 code_challenge
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
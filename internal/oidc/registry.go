@@ -0,0 +1,33 @@
+package oidc
+
+import (
+	"net/http"
+)
+
+// Registry 已配置的身份提供方，保持配置顺序
+type Registry struct {
+	providers map[string]*Provider
+	ordered   []*Provider
+}
+
+// NewRegistry 按配置创建身份提供方，没有配置时返回空集合
+func NewRegistry(configs []Config, client *http.Client) *Registry {
+	r := &Registry{providers: make(map[string]*Provider, len(configs))}
+	for _, cfg := range configs {
+		p := NewProvider(cfg, client)
+		r.providers[cfg.Name] = p
+		r.ordered = append(r.ordered, p)
+	}
+	return r
+}
+
+// Get 按标识查找身份提供方
+func (r *Registry) Get(name string) (*Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// List 返回全部身份提供方
+func (r *Registry) List() []*Provider {
+	return r.ordered
+}
@@ -0,0 +1,38 @@
+package pdf
+
+// cjkHalfWidth STSong-Light 中 ASCII 字符（CID 1-95）的宽度
+const cjkHalfWidth = 500
+
+// fontWidths 标准字体 ASCII 可打印字符（32-126）的宽度，单位为字号的千分之一，取自 Adobe 字体度量文件
+type fontWidths [95]int
+
+func (w *fontWidths) of(c byte) int {
+	if c < 32 || c > 126 {
+		return w[0]
+	}
+	return w[c-32]
+}
+
+var helvetica = fontWidths{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // 空格 - /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0-9
+	278, 278, 584, 584, 584, 556, 1015, // : - @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A-M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N-Z
+	278, 278, 278, 469, 556, 333, // [ - `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a-m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n-z
+	334, 260, 334, 584, // { - ~
+}
+
+var helveticaBold = fontWidths{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278, // 空格 - /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0-9
+	333, 333, 584, 584, 584, 611, 975, // : - @
+	722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, // A-M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N-Z
+	333, 278, 333, 584, 556, 333, // [ - `
+	556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, // a-m
+	611, 611, 611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, // n-z
+	389, 280, 389, 584, // { - ~
+}
@@ -0,0 +1,309 @@
+// Package pdf 生成简单的 PDF 文档：文字、线条和填充矩形，足以排版表格和条形图
+//
+// 坐标以点（1/72 英寸）为单位，原点在页面左上角，y 轴向下。
+// 只包含 ASCII 字符的文字使用 PDF 标准字体 Helvetica；包含中文等其他字符的文字使用
+// 阅读器内置的 STSong-Light 字体，不嵌入字体文件。
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// A4 纸的尺寸
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Color RGB 颜色，各分量取值 0-1
+type Color struct {
+	R, G, B float64
+}
+
+// 常用颜色
+var (
+	Black = Color{0, 0, 0}
+	Gray  = Color{0.45, 0.45, 0.45}
+	White = Color{1, 1, 1}
+)
+
+// RGB 由 0-255 的分量创建颜色
+func RGB(r, g, b uint8) Color {
+	return Color{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
+
+// Font 文字样式
+type Font struct {
+	Size  float64
+	Bold  bool // 只对 ASCII 文字有效
+	Color Color
+}
+
+// Document PDF 文档
+type Document struct {
+	title   string
+	created time.Time
+	pages   []*Page
+}
+
+// New 创建文档，title 写入文档属性
+func New(title string) *Document {
+	return &Document{title: title, created: time.Now()}
+}
+
+// AddPage 添加一个 A4 页面
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages 返回已添加的页面，用于在排版完成后补充页眉页脚
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Page 一个页面，绘制操作按调用顺序写入页面内容
+type Page struct {
+	content bytes.Buffer
+}
+
+// Text 在 (x, y) 处绘制文字，y 为基线位置
+func (p *Page) Text(x, y float64, s string, font Font) {
+	if s == "" {
+		return
+	}
+	name, encoded := encodeText(s, font.Bold)
+	fmt.Fprintf(&p.content, "%s rg BT /%s %s Tf %s %s Td %s Tj ET\n",
+		colorOp(font.Color), name, num(font.Size), num(x), num(PageHeight-y), encoded)
+}
+
+// TextRight 绘制右端对齐到 x 的文字
+func (p *Page) TextRight(x, y float64, s string, font Font) {
+	p.Text(x-Width(s, font), y, s, font)
+}
+
+// TextCenter 绘制以 x 为中心的文字
+func (p *Page) TextCenter(x, y float64, s string, font Font) {
+	p.Text(x-Width(s, font)/2, y, s, font)
+}
+
+// Rect 绘制左上角在 (x, y) 的填充矩形
+func (p *Page) Rect(x, y, w, h float64, fill Color) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		colorOp(fill), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line 绘制线段
+func (p *Page) Line(x1, y1, x2, y2, width float64, stroke Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		colorOp(stroke), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// DashedLine 绘制虚线
+func (p *Page) DashedLine(x1, y1, x2, y2, width float64, stroke Color) {
+	fmt.Fprintf(&p.content, "q [4 3] 0 d %s RG %s w %s %s m %s %s l S Q\n",
+		colorOp(stroke), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Width 返回文字的宽度
+func Width(s string, font Font) float64 {
+	units := 0
+	if isASCII(s) {
+		widths := &helvetica
+		if font.Bold {
+			widths = &helveticaBold
+		}
+		for i := 0; i < len(s); i++ {
+			units += widths.of(s[i])
+		}
+	} else {
+		for _, r := range s {
+			if r < 0x80 {
+				units += cjkHalfWidth
+			} else {
+				units += 1000
+			}
+		}
+	}
+	return float64(units) * font.Size / 1000
+}
+
+// WriteTo 写出完整的 PDF 文件
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	pw := &writer{w: w}
+
+	// 对象编号：1 目录，2 页面树，3-7 字体，8 文档属性，之后每页两个对象（页面和内容）
+	const firstPage = 9
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	pw.header()
+	pw.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	pw.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	pw.object(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	pw.object(4, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	pw.object(5, "<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [6 0 R] >>")
+	pw.object(6, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 7 0 R /DW 1000 /W [1 95 %d] >>", cjkHalfWidth))
+	pw.object(7, "<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] "+
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	pw.object(8, fmt.Sprintf("<< /Title %s /Producer (nutrition-tracker) /CreationDate (D:%s) >>",
+		utf16Hex(d.title, true), d.created.UTC().Format("20060102150405Z")))
+
+	for i, page := range d.pages {
+		id := firstPage + 2*i
+		pw.object(id, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), id+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(page.content.Bytes())
+		zw.Close()
+		pw.stream(id+1, compressed.Bytes())
+	}
+
+	pw.trailer(firstPage + 2*len(d.pages))
+	return pw.n, pw.err
+}
+
+// writer 记录每个对象的偏移量，用于生成交叉引用表
+type writer struct {
+	w       io.Writer
+	n       int64
+	err     error
+	offsets []int64
+}
+
+func (pw *writer) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+func (pw *writer) write(data []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(data)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+func (pw *writer) header() {
+	// 第二行的非 ASCII 字节提示传输工具按二进制文件处理
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+}
+
+func (pw *writer) begin(id int) {
+	for len(pw.offsets) < id {
+		pw.offsets = append(pw.offsets, 0)
+	}
+	pw.offsets[id-1] = pw.n
+	pw.printf("%d 0 obj\n", id)
+}
+
+func (pw *writer) object(id int, body string) {
+	pw.begin(id)
+	pw.printf("%s\nendobj\n", body)
+}
+
+func (pw *writer) stream(id int, data []byte) {
+	pw.begin(id)
+	pw.printf("<< /Length %d /Filter /FlateDecode >>\nstream\n", len(data))
+	pw.write(data)
+	pw.printf("\nendstream\nendobj\n")
+}
+
+func (pw *writer) trailer(size int) {
+	xref := pw.n
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", size)
+	for _, offset := range pw.offsets {
+		pw.printf("%010d 00000 n \n", offset)
+	}
+	pw.printf("trailer\n<< /Size %d /Root 1 0 R /Info 8 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, xref)
+}
+
+// encodeText 选择字体并编码文字：ASCII 使用 Helvetica 的字面字符串，其他文字使用 STSong-Light 的 UCS-2 编码
+func encodeText(s string, bold bool) (string, string) {
+	if isASCII(s) {
+		name := "F1"
+		if bold {
+			name = "F2"
+		}
+		var b strings.Builder
+		b.WriteByte('(')
+		for i := 0; i < len(s); i++ {
+			c := s[i]
+			switch {
+			case c == '(' || c == ')' || c == '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case c < 0x20:
+				b.WriteByte(' ')
+			default:
+				b.WriteByte(c)
+			}
+		}
+		b.WriteByte(')')
+		return name, b.String()
+	}
+	return "F3", utf16Hex(s, false)
+}
+
+// utf16Hex 把文字编码为 UTF-16BE 十六进制字符串；UCS-2 不支持的字符替换为问号
+func utf16Hex(s string, bom bool) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	if bom {
+		b.WriteString("FEFF")
+	}
+	for _, r := range s {
+		if r > 0xFFFF || r < 0x20 {
+			r = '?'
+		}
+		for _, u := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&b, "%04X", u)
+		}
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func colorOp(c Color) string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+// num 格式化数值，保留两位小数并去掉末尾的零
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+)
+
+// filterPattern 过滤表达式：字段名、运算符、值；两个字符的运算符需要先匹配
+var filterPattern = regexp.MustCompile(`^([a-z_]+)(>=|<=|!=|=|>|<|~)(.*)$`)
+
+// likeEscaper 转义 LIKE 通配符，用户输入的 % 和 _ 按普通字符匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern 返回不区分大小写的包含匹配模式，配合 LOWER(column) LIKE ? ESCAPE '\' 使用
+// PostgreSQL 的 LIKE 区分大小写而 SQLite 不区分，统一转成小写后两者行为一致
+func ContainsPattern(value string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(value)) + "%"
+}
+
+// condition 一个 SQL 条件及其参数
+type condition struct {
+	sql  string
+	args []interface{}
+}
+
+// parseFilter 解析一个过滤表达式，如 calories>=100
+func (s *Schema) parseFilter(expr string) (condition, error) {
+	invalid := func(message string) error {
+		return apperror.Validation(apperror.CodeInvalidFilter, message, apperror.FieldError{Field: "filter", Message: expr})
+	}
+
+	m := filterPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		return condition{}, invalid("过滤条件格式应为 <字段><运算符><值>")
+	}
+	field, ok := s.Fields[m[1]]
+	if !ok {
+		return condition{}, invalid("不支持按 " + m[1] + " 过滤")
+	}
+	if field.Values != nil && m[2] != "=" && m[2] != "!=" {
+		return condition{}, invalid("过滤条件 " + expr + " 的运算符或值无效")
+	}
+	c, ok := field.compare(m[2], strings.TrimSpace(m[3]))
+	if !ok {
+		return condition{}, invalid("过滤条件 " + expr + " 的运算符或值无效")
+	}
+	return c, nil
+}
+
+// compare 生成字段与值比较的条件，过滤和游标条件共用，运算符不适用于该字段或值无法解析时返回 false
+func (f Field) compare(op string, raw string) (condition, bool) {
+	if f.Values != nil {
+		// 枚举值作为过滤条件时只支持 = 和 !=（见 parseFilter），游标比较按数据库值的顺序
+		value, ok := f.Values[raw]
+		if !ok || op == "~" {
+			return condition{}, false
+		}
+		return condition{f.Column + " " + op + " ?", []interface{}{value}}, true
+	}
+
+	switch f.Type {
+	case String:
+		if op == "~" {
+			return condition{"LOWER(" + f.Column + `) LIKE ? ESCAPE '\'`, []interface{}{ContainsPattern(raw)}}, true
+		}
+		return condition{f.Column + " " + op + " ?", []interface{}{raw}}, true
+	case Number:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || op == "~" {
+			return condition{}, false
+		}
+		return condition{f.Column + " " + op + " ?", []interface{}{value}}, true
+	case Date:
+		day, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return condition{}, false
+		}
+		// 与 repository.dayRange 相同，date 列按日期字符串比较
+		return dayCompare(f.Column, op, day.Format("2006-01-02"), day.AddDate(0, 0, 1).Format("2006-01-02"))
+	case Time:
+		if day, err := time.Parse("2006-01-02", raw); err == nil {
+			return dayCompare(f.Column, op, day, day.AddDate(0, 0, 1))
+		}
+		value, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil || op == "~" {
+			return condition{}, false
+		}
+		return condition{f.Column + " " + op + " ?", []interface{}{value}}, true
+	}
+	return condition{}, false
+}
+
+// dayCompare 把与某一天的比较转换为 [start, end) 范围条件
+func dayCompare(column string, op string, start interface{}, end interface{}) (condition, bool) {
+	switch op {
+	case "=":
+		return condition{column + " >= ? AND " + column + " < ?", []interface{}{start, end}}, true
+	case "!=":
+		return condition{"(" + column + " < ? OR " + column + " >= ?)", []interface{}{start, end}}, true
+	case ">":
+		return condition{column + " >= ?", []interface{}{end}}, true
+	case ">=":
+		return condition{column + " >= ?", []interface{}{start}}, true
+	case "<":
+		return condition{column + " < ?", []interface{}{start}}, true
+	case "<=":
+		return condition{column + " < ?", []interface{}{end}}, true
+	}
+	return condition{}, false
+}
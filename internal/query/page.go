@@ -0,0 +1,162 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"gorm.io/gorm"
+)
+
+// Page 一页查询结果
+type Page[T any] struct {
+	Items      []*T    `json:"items"`
+	Total      int64   `json:"total"`       // 符合过滤条件的总数
+	NextCursor *string `json:"next_cursor"` // 下一页的游标，没有更多数据时为 null
+}
+
+// cursor 游标内容：排序方式和上一页最后一条记录的排序字段值
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// Find 在 db（已限定好数据范围，如 user_id）上执行过滤、排序和游标分页
+func Find[T any](db *gorm.DB, q *Query) (*Page[T], error) {
+	for _, c := range q.conditions {
+		db = db.Where(c.sql, c.args...)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	if q.after != nil {
+		c, err := q.afterCondition()
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(c.sql, c.args...)
+	}
+	for _, key := range q.sorts {
+		if key.desc {
+			db = db.Order(key.field.Column + " DESC")
+		} else {
+			db = db.Order(key.field.Column)
+		}
+	}
+
+	items := make([]*T, 0, q.limit+1)
+	if err := db.Limit(q.limit + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: items, Total: total}
+	if len(items) > q.limit {
+		page.Items = items[:q.limit]
+		next, err := q.encodeCursor(db, page.Items[q.limit-1])
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// afterCondition 游标位置之后的记录：按排序字段逐个比较（keyset 分页）
+// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND id > z)
+func (q *Query) afterCondition() (condition, error) {
+	var (
+		clauses []string
+		args    []interface{}
+		equal   []condition
+	)
+	for i, key := range q.sorts {
+		op := ">"
+		if key.desc {
+			op = "<"
+		}
+		after, ok1 := key.field.compare(op, q.after[i])
+		same, ok2 := key.field.compare("=", q.after[i])
+		if !ok1 || !ok2 {
+			return condition{}, apperror.Validation(apperror.CodeInvalidCursor, "分页游标无效")
+		}
+
+		parts := make([]string, 0, len(equal)+1)
+		for _, e := range equal {
+			parts = append(parts, e.sql)
+			args = append(args, e.args...)
+		}
+		parts = append(parts, after.sql)
+		args = append(args, after.args...)
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+		equal = append(equal, same)
+	}
+	return condition{"(" + strings.Join(clauses, " OR ") + ")", args}, nil
+}
+
+// encodeCursor 用最后一条记录的排序字段值生成下一页的游标
+func (q *Query) encodeCursor(db *gorm.DB, item interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(item); err != nil {
+		return "", err
+	}
+	rv := reflect.Indirect(reflect.ValueOf(item))
+
+	values := make([]string, 0, len(q.sorts))
+	for _, key := range q.sorts {
+		name := key.field.Column[strings.LastIndex(key.field.Column, ".")+1:]
+		field := stmt.Schema.LookUpField(name)
+		if field == nil {
+			return "", fmt.Errorf("分页字段 %s 不在模型 %s 中", name, stmt.Schema.Name)
+		}
+		value, _ := field.ValueOf(stmt.Context, rv)
+		values = append(values, key.field.format(value))
+	}
+
+	data, err := json.Marshal(cursor{Sort: q.sortSpec(), Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 解析游标，排序方式与本次请求不同时视为无效
+func decodeCursor(raw string, sort string, n int) ([]string, error) {
+	invalid := apperror.Validation(apperror.CodeInvalidCursor, "分页游标无效")
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort || len(c.Values) != n {
+		return nil, invalid
+	}
+	return c.Values, nil
+}
+
+// format 把字段值格式化为游标中的字符串，解析方式与过滤值相同
+func (f Field) format(value interface{}) string {
+	for name, v := range f.Values {
+		if v == value {
+			return name
+		}
+	}
+	switch v := value.(type) {
+	case time.Time:
+		if f.Type == Date {
+			return v.Format("2006-01-02")
+		}
+		return v.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,162 @@
+// Package query 把列表接口的过滤、排序和游标分页参数安全地转换为 GORM 查询条件
+//
+// 每个列表接口用 Schema 声明允许过滤和排序的字段，字段名映射到固定的列名，
+// 过滤值一律作为参数绑定，请求中的任何内容都不会拼接进 SQL。
+//
+// 参数格式：
+//
+//	filter=calories>=100&filter=meal_type=lunch   多个条件之间为 AND
+//	sort=-calories,name                           - 表示倒序
+//	cursor=<上一页返回的 next_cursor>&limit=20
+package query
+
+import (
+	"strings"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+)
+
+// 每页数量
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Type 字段类型，决定过滤值的解析和比较方式
+type Type int
+
+const (
+	String Type = iota // 文本：支持比较运算符和 ~（包含，不区分大小写）
+	Number             // 数值：支持 = != > >= < <=
+	Date               // date 列：值为 YYYY-MM-DD
+	Time               // 时间列：值为 RFC3339 时间，或 YYYY-MM-DD 表示当天（UTC）
+)
+
+// Field 允许过滤或排序的字段
+type Field struct {
+	Column   string                 // 带表名的列名，如 food_records.calories
+	Type     Type                   // 字段类型
+	Sortable bool                   // 是否允许排序
+	Values   map[string]interface{} // 枚举字段：接口中的取值到数据库值的映射，过滤时只支持 = 和 !=
+}
+
+// Schema 一个列表接口允许的字段和默认排序
+type Schema struct {
+	Fields map[string]Field
+	Sort   string // 默认排序，格式同 sort 参数
+	ID     string // 主键列名，作为最后一个排序字段保证翻页顺序稳定
+}
+
+// Request 列表接口的查询参数
+type Request struct {
+	Filter []string `form:"filter"`
+	Sort   string   `form:"sort"`
+	Cursor string   `form:"cursor"`
+	Limit  int      `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// Query 解析后的查询
+type Query struct {
+	schema     *Schema
+	conditions []condition
+	sorts      []sortKey
+	after      []string // 游标中上一页最后一条记录的排序字段值
+	limit      int
+}
+
+// sortKey 一个排序字段
+type sortKey struct {
+	name  string
+	field Field
+	desc  bool
+}
+
+// Parse 校验并解析查询参数，字段、运算符或值不合法时返回 422 错误
+func (s *Schema) Parse(req *Request) (*Query, error) {
+	q := &Query{schema: s, limit: req.Limit}
+	if q.limit <= 0 {
+		q.limit = DefaultLimit
+	}
+	if q.limit > MaxLimit {
+		q.limit = MaxLimit
+	}
+
+	for _, expr := range req.Filter {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		c, err := s.parseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		q.conditions = append(q.conditions, c)
+	}
+
+	sort := strings.TrimSpace(req.Sort)
+	if sort == "" {
+		sort = s.Sort
+	}
+	sorts, err := s.parseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	q.sorts = sorts
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, q.sortSpec(), len(q.sorts))
+		if err != nil {
+			return nil, err
+		}
+		q.after = after
+	}
+	return q, nil
+}
+
+// Where 追加一个过滤条件，用于把兼容旧接口的参数（如 date）转换为过滤条件
+func (q *Query) Where(expr string) error {
+	c, err := q.schema.parseFilter(expr)
+	if err != nil {
+		return err
+	}
+	q.conditions = append(q.conditions, c)
+	return nil
+}
+
+// parseSort 解析排序参数，最后追加主键保证顺序唯一
+func (s *Schema) parseSort(sort string) ([]sortKey, error) {
+	var keys []sortKey
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := s.Fields[name]
+		if !ok || !field.Sortable {
+			return nil, apperror.Validation(apperror.CodeInvalidSort, "不支持按 "+name+" 排序",
+				apperror.FieldError{Field: "sort", Message: name})
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		keys = append(keys, sortKey{name: name, field: field, desc: desc})
+	}
+	return append(keys, sortKey{name: "id", field: Field{Column: s.ID, Type: String}}), nil
+}
+
+// sortSpec 排序的规范形式，写入游标，排序改变后旧游标失效
+func (q *Query) sortSpec() string {
+	names := make([]string, 0, len(q.sorts))
+	for _, key := range q.sorts {
+		if key.desc {
+			names = append(names, "-"+key.name)
+		} else {
+			names = append(names, key.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,224 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+)
+
+// testSchema 覆盖全部字段类型的测试用 Schema
+var testSchema = &Schema{
+	Fields: map[string]Field{
+		"name":       {Column: "foods.name", Type: String, Sortable: true},
+		"calories":   {Column: "foods.calories", Type: Number, Sortable: true},
+		"date":       {Column: "meals.date", Type: Date, Sortable: true},
+		"created_at": {Column: "foods.created_at", Type: Time, Sortable: true},
+		"meal_type":  {Column: "meals.meal_type", Type: Number, Sortable: true, Values: map[string]interface{}{"breakfast": 1, "lunch": 2}},
+		"notes":      {Column: "meals.notes", Type: String},
+	},
+	Sort: "-calories,name",
+	ID:   "foods.id",
+}
+
+// errorCode 返回 apperror 错误码，不是 apperror 时返回空字符串
+func errorCode(err error) string {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
+func TestParseFilter(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		filter   string
+		wantSQL  string
+		wantArgs []interface{}
+		wantCode string
+	}{
+		{"calories>=100", "foods.calories >= ?", []interface{}{100.0}, ""},
+		{" calories<99.5 ", "foods.calories < ?", []interface{}{99.5}, ""},
+		{"name=燕麦", "foods.name = ?", []interface{}{"燕麦"}, ""},
+		{"name!=a=b", "foods.name != ?", []interface{}{"a=b"}, ""},
+		{"name~Oat", `LOWER(foods.name) LIKE ? ESCAPE '\'`, []interface{}{"%oat%"}, ""},
+		{`name~50%_\`, `LOWER(foods.name) LIKE ? ESCAPE '\'`, []interface{}{`%50\%\_\\%`}, ""},
+		{"meal_type=lunch", "meals.meal_type = ?", []interface{}{2}, ""},
+		{"meal_type!=breakfast", "meals.meal_type != ?", []interface{}{1}, ""},
+		{"date=2024-01-15", "meals.date >= ? AND meals.date < ?", []interface{}{"2024-01-15", "2024-01-16"}, ""},
+		{"date!=2024-01-15", "(meals.date < ? OR meals.date >= ?)", []interface{}{"2024-01-15", "2024-01-16"}, ""},
+		{"date>2024-01-15", "meals.date >= ?", []interface{}{"2024-01-16"}, ""},
+		{"date<=2024-01-15", "meals.date < ?", []interface{}{"2024-01-16"}, ""},
+		{"created_at>=2024-01-15", "foods.created_at >= ?", []interface{}{day}, ""},
+		{"created_at<2024-01-15T08:00:00Z", "foods.created_at < ?", []interface{}{day.Add(8 * time.Hour)}, ""},
+		{"notes~加餐", `LOWER(meals.notes) LIKE ? ESCAPE '\'`, []interface{}{"%加餐%"}, ""},
+
+		{"calories", "", nil, apperror.CodeInvalidFilter},
+		{"calories >1", "", nil, apperror.CodeInvalidFilter},
+		{"Calories>1", "", nil, apperror.CodeInvalidFilter},
+		{"fiber>1", "", nil, apperror.CodeInvalidFilter},
+		{"calories>abc", "", nil, apperror.CodeInvalidFilter},
+		{"calories~1", "", nil, apperror.CodeInvalidFilter},
+		{"meal_type>lunch", "", nil, apperror.CodeInvalidFilter},
+		{"meal_type=brunch", "", nil, apperror.CodeInvalidFilter},
+		{"date=2024-13-01", "", nil, apperror.CodeInvalidFilter},
+		{"date~2024-01-15", "", nil, apperror.CodeInvalidFilter},
+		{"created_at>yesterday", "", nil, apperror.CodeInvalidFilter},
+		{"created_at~2024-01-15T08:00:00Z", "", nil, apperror.CodeInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			q, err := testSchema.Parse(&Request{Filter: []string{tt.filter, " "}})
+			if tt.wantCode != "" {
+				if code := errorCode(err); code != tt.wantCode {
+					t.Fatalf("err = %v，期望 %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if len(q.conditions) != 1 {
+				t.Fatalf("条件数 = %d，期望 1", len(q.conditions))
+			}
+			c := q.conditions[0]
+			if c.sql != tt.wantSQL || !reflect.DeepEqual(c.args, tt.wantArgs) {
+				t.Errorf("条件 = %q %v，期望 %q %v", c.sql, c.args, tt.wantSQL, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestParseSortAndLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       Request
+		wantSort  string
+		wantLimit int
+		wantCode  string
+	}{
+		{"默认排序", Request{}, "-calories,name,id", DefaultLimit, ""},
+		{"指定排序", Request{Sort: "date, -created_at", Limit: 5}, "date,-created_at,id", 5, ""},
+		{"重复字段只取第一次", Request{Sort: "name,-name,,calories"}, "name,calories,id", DefaultLimit, ""},
+		{"超过上限", Request{Limit: 1000}, "-calories,name,id", MaxLimit, ""},
+		{"不可排序的字段", Request{Sort: "notes"}, "", 0, apperror.CodeInvalidSort},
+		{"未知字段", Request{Sort: "-fiber"}, "", 0, apperror.CodeInvalidSort},
+		{"不能直接按主键排序", Request{Sort: "id"}, "", 0, apperror.CodeInvalidSort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := testSchema.Parse(&tt.req)
+			if tt.wantCode != "" {
+				if code := errorCode(err); code != tt.wantCode {
+					t.Fatalf("err = %v，期望 %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if spec := q.sortSpec(); spec != tt.wantSort {
+				t.Errorf("排序 = %q，期望 %q", spec, tt.wantSort)
+			}
+			if q.limit != tt.wantLimit {
+				t.Errorf("limit = %d，期望 %d", q.limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// encode 按游标格式编码
+func encode(t *testing.T, c cursor) string {
+	t.Helper()
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("编码游标失败: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestCursor(t *testing.T) {
+	valid := encode(t, cursor{Sort: "-calories,name,id", Values: []string{"116", "米饭", "id-1"}})
+
+	q, err := testSchema.Parse(&Request{Cursor: valid})
+	if err != nil {
+		t.Fatalf("解析游标失败: %v", err)
+	}
+	if want := []string{"116", "米饭", "id-1"}; !reflect.DeepEqual(q.after, want) {
+		t.Errorf("游标值 = %v，期望 %v", q.after, want)
+	}
+
+	c, err := q.afterCondition()
+	if err != nil {
+		t.Fatalf("生成游标条件失败: %v", err)
+	}
+	wantSQL := "((foods.calories < ?) OR (foods.calories = ? AND foods.name > ?) OR (foods.calories = ? AND foods.name = ? AND foods.id > ?))"
+	wantArgs := []interface{}{116.0, 116.0, "米饭", 116.0, "米饭", "id-1"}
+	if c.sql != wantSQL || !reflect.DeepEqual(c.args, wantArgs) {
+		t.Errorf("游标条件 = %q %v\n期望 %q %v", c.sql, c.args, wantSQL, wantArgs)
+	}
+
+	invalid := []struct {
+		name   string
+		req    Request
+		parsed bool // 游标能解码，但值与字段类型不符，生成条件时才报错
+	}{
+		{"不是 base64", Request{Cursor: "!!!"}, false},
+		{"不是 JSON", Request{Cursor: base64.RawURLEncoding.EncodeToString([]byte("nope"))}, false},
+		{"排序方式不同", Request{Cursor: valid, Sort: "name"}, false},
+		{"值的个数不同", Request{Cursor: encode(t, cursor{Sort: "-calories,name,id", Values: []string{"116", "id-1"}})}, false},
+		{"值无法解析", Request{Cursor: encode(t, cursor{Sort: "-calories,name,id", Values: []string{"abc", "米饭", "id-1"}})}, true},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := testSchema.Parse(&tt.req)
+			if tt.parsed {
+				if err != nil {
+					t.Fatalf("解析游标失败: %v", err)
+				}
+				_, err = q.afterCondition()
+			}
+			if code := errorCode(err); code != apperror.CodeInvalidCursor {
+				t.Errorf("err = %v，期望 %s", err, apperror.CodeInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestFieldFormatRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 15, 8, 30, 0, 123456789, time.FixedZone("CST", 8*3600))
+	tests := []struct {
+		field string
+		value interface{}
+		want  string
+	}{
+		{"calories", 116.5, "116.5"},
+		{"calories", 1e21, "1e+21"},
+		{"name", "米饭", "米饭"},
+		{"meal_type", 2, "lunch"},
+		{"date", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "2024-01-15"},
+		{"created_at", created, "2024-01-15T00:30:00.123456789Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field+"="+tt.want, func(t *testing.T) {
+			field := testSchema.Fields[tt.field]
+			got := field.format(tt.value)
+			if got != tt.want {
+				t.Fatalf("format = %q，期望 %q", got, tt.want)
+			}
+			// 游标中的值必须能按相同字段重新解析
+			if _, ok := field.compare("=", got); !ok {
+				t.Errorf("游标值 %q 无法重新解析", got)
+			}
+		})
+	}
+}
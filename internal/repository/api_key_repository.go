@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository 个人 API 密钥仓库接口
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	FindByUserID(ctx context.Context, userID string) ([]*model.APIKey, error)
+	CountByUserID(ctx context.Context, userID string) (int64, error)
+	Delete(ctx context.Context, userID string, id string) error
+	Touch(ctx context.Context, id string, now time.Time, interval time.Duration) error
+}
+
+// apiKeyRepository 个人 API 密钥仓库实现
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 创建个人 API 密钥仓库实例
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	if db == nil {
+		log.Fatal("❌ NewAPIKeyRepository: db 参数为 nil")
+	}
+	return &apiKeyRepository{db: db}
+}
+
+// Create 保存新密钥
+func (r *apiKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// FindByHash 根据摘要查找密钥
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var key model.APIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.Unauthorized(apperror.CodeInvalidAPIKey, "API 密钥无效或已过期")
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// FindByUserID 查找用户的全部密钥（包括已过期的），最新创建的排在前面
+func (r *apiKeyRepository) FindByUserID(ctx context.Context, userID string) ([]*model.APIKey, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var keys []*model.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// CountByUserID 统计用户的密钥数量
+func (r *apiKeyRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("repository 未初始化")
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.APIKey{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Delete 删除用户的密钥，密钥立即失效
+func (r *apiKeyRepository) Delete(ctx context.Context, userID string, id string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的删除了密钥
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeAPIKeyNotFound, "没有找到要删除的 API 密钥")
+	}
+
+	return nil
+}
+
+// Touch 更新密钥的最近使用时间；距上次更新不足 interval 时跳过，避免每个请求都写数据库
+func (r *apiKeyRepository) Touch(ctx context.Context, id string, now time.Time, interval time.Duration) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Update("last_used_at", now).Error
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/audit"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository 审计日志仓库接口，日志由 audit 包的 GORM 回调写入，这里只提供查询
+type AuditLogRepository interface {
+	List(ctx context.Context, q *query.Query) (*query.Page[model.AuditLog], error)
+	FindColumnChanges(ctx context.Context, entityType, entityID, column string, before time.Time) ([]*model.AuditLog, error)
+}
+
+// AuditLogQuery 管理员审计日志列表允许的过滤和排序字段
+var AuditLogQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"entity_type": {Column: "audit_logs.entity_type", Type: query.String, Values: stringValues(audit.Tables...)},
+		"entity_id":   {Column: "audit_logs.entity_id", Type: query.String},
+		"owner_id":    {Column: "audit_logs.owner_id", Type: query.String},
+		"actor_id":    {Column: "audit_logs.actor_id", Type: query.String},
+		"action":      {Column: "audit_logs.action", Type: query.String, Values: stringValues(model.AuditCreate, model.AuditUpdate, model.AuditDelete)},
+		"request_id":  {Column: "audit_logs.request_id", Type: query.String},
+		"created_at":  {Column: "audit_logs.created_at", Type: query.Time, Sortable: true},
+	},
+	Sort: "-created_at",
+	ID:   "audit_logs.id",
+}
+
+// auditLogRepository 审计日志仓库实现
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓库实例
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	if db == nil {
+		log.Fatal("❌ NewAuditLogRepository: db 参数为 nil")
+	}
+	return &auditLogRepository{db: db}
+}
+
+// List 按过滤条件分页查询审计日志
+func (r *auditLogRepository) List(ctx context.Context, q *query.Query) (*query.Page[model.AuditLog], error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+	return query.Find[model.AuditLog](r.db.WithContext(ctx).Model(&model.AuditLog{}), q)
+}
+
+// FindColumnChanges 查询一条数据中某个字段的变更记录（包括创建时的初始值），按时间正序，before 不包含
+func (r *auditLogRepository) FindColumnChanges(ctx context.Context, entityType, entityID, column string, before time.Time) ([]*model.AuditLog, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	// changes 以 JSON 文本保存，先按字段名粗略过滤，调用方再检查 Changes 中是否有该字段
+	var entries []*model.AuditLog
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ? AND created_at < ?", entityType, entityID, before).
+		Where(`changes LIKE ? ESCAPE '\'`, containsPattern(`"`+column+`"`)).
+		Order("created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,4 @@
+package repository
+
+// batchSize IN 查询每批的参数个数，避免超过数据库的参数数量限制
+const batchSize = 500
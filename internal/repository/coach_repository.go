@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+	"gorm.io/gorm"
+)
+
+// CoachRepository 教练与客户关系仓库接口
+type CoachRepository interface {
+	Create(ctx context.Context, link *model.CoachClient) error
+	Update(ctx context.Context, link *model.CoachClient) error
+	FindByID(ctx context.Context, id string) (*model.CoachClient, error)
+	FindByPair(ctx context.Context, coachID string, clientID string) (*model.CoachClient, error)
+	FindByCoach(ctx context.Context, coachID string) ([]*model.CoachClient, error)
+	FindByClient(ctx context.Context, clientID string) ([]*model.CoachClient, error)
+	FindInvitation(ctx context.Context, id string) (*model.CoachInvitation, error)
+	FindInvitationByEmail(ctx context.Context, coachID string, email string) (*model.CoachInvitation, error)
+	FindPendingInvitations(ctx context.Context, coachID string) ([]*model.CoachInvitation, error)
+	FindInvitationsForEmail(ctx context.Context, email string) ([]*model.CoachInvitation, error)
+	SaveInvitation(ctx context.Context, invitation *model.CoachInvitation) error
+	DeleteInvitation(ctx context.Context, coachID string, id string) error
+	AcceptInvitation(ctx context.Context, invitation *model.CoachInvitation, link *model.CoachClient) error
+	CreateAccessLog(ctx context.Context, entry *model.CoachAccessLog) error
+	FindAccessLogs(ctx context.Context, clientID string, q *query.Query) (*query.Page[model.CoachAccessLog], error)
+}
+
+// coachRepository 教练与客户关系仓库实现
+type coachRepository struct {
+	db *gorm.DB
+}
+
+// NewCoachRepository 创建教练与客户关系仓库实例
+func NewCoachRepository(db *gorm.DB) CoachRepository {
+	if db == nil {
+		log.Fatal("❌ NewCoachRepository: db 参数为 nil")
+	}
+	return &coachRepository{db: db}
+}
+
+// Create 创建关系
+func (r *coachRepository) Create(ctx context.Context, link *model.CoachClient) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Create(link).Error
+}
+
+// Update 更新关系的状态和授权范围
+func (r *coachRepository) Update(ctx context.Context, link *model.CoachClient) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Omit("Coach", "Client").Save(link).Error
+}
+
+// FindByID 根据ID查找关系
+func (r *coachRepository) FindByID(ctx context.Context, id string) (*model.CoachClient, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var link model.CoachClient
+	err := r.db.WithContext(ctx).Preload("Coach").Preload("Client").Where("id = ?", id).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeCoachClientNotFound, "教练关系不存在")
+		}
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// FindByPair 查找教练与客户之间的关系（任意状态）
+func (r *coachRepository) FindByPair(ctx context.Context, coachID string, clientID string) (*model.CoachClient, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var link model.CoachClient
+	err := r.db.WithContext(ctx).Where("coach_id = ? AND client_id = ?", coachID, clientID).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeCoachClientNotFound, "教练关系不存在")
+		}
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// FindByCoach 查找教练未结束的客户关系，已注销的客户不包括在内
+func (r *coachRepository) FindByCoach(ctx context.Context, coachID string) ([]*model.CoachClient, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var links []*model.CoachClient
+	err := r.db.WithContext(ctx).Preload("Client").
+		Where("coach_id = ? AND status <> ?", coachID, model.CoachClientEnded).
+		Order("created_at DESC").
+		Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := links[:0]
+	for _, l := range links {
+		if l.Client != nil {
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+// FindByClient 查找客户未结束的教练关系，已注销的教练不包括在内
+func (r *coachRepository) FindByClient(ctx context.Context, clientID string) ([]*model.CoachClient, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var links []*model.CoachClient
+	err := r.db.WithContext(ctx).Preload("Coach").
+		Where("client_id = ? AND status <> ?", clientID, model.CoachClientEnded).
+		Order("created_at DESC").
+		Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := links[:0]
+	for _, l := range links {
+		if l.Coach != nil {
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+// FindInvitation 根据ID查找教练邀请
+func (r *coachRepository) FindInvitation(ctx context.Context, id string) (*model.CoachInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitation model.CoachInvitation
+	err := r.db.WithContext(ctx).Preload("Coach").Where("id = ?", id).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeCoachInvitationNotFound, "教练邀请不存在")
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// FindInvitationByEmail 查找教练发给某个邮箱的邀请（任意状态）
+func (r *coachRepository) FindInvitationByEmail(ctx context.Context, coachID string, email string) (*model.CoachInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitation model.CoachInvitation
+	err := r.db.WithContext(ctx).Where("coach_id = ? AND email = ?", coachID, email).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeCoachInvitationNotFound, "教练邀请不存在")
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// FindPendingInvitations 查找教练等待接受的邀请，按邀请时间倒序
+func (r *coachRepository) FindPendingInvitations(ctx context.Context, coachID string) ([]*model.CoachInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitations []*model.CoachInvitation
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ? AND status = ?", coachID, model.CoachInvitationPending).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// FindInvitationsForEmail 查找发给某个邮箱、等待接受的邀请及其教练，按邀请时间倒序，已注销的教练不包括在内
+func (r *coachRepository) FindInvitationsForEmail(ctx context.Context, email string) ([]*model.CoachInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitations []*model.CoachInvitation
+	err := r.db.WithContext(ctx).Preload("Coach").
+		Where("email = ? AND status = ?", email, model.CoachInvitationPending).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := invitations[:0]
+	for _, i := range invitations {
+		if i.Coach != nil {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
+
+// SaveInvitation 创建或更新教练邀请
+func (r *coachRepository) SaveInvitation(ctx context.Context, invitation *model.CoachInvitation) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Omit("Coach").Save(invitation).Error
+}
+
+// DeleteInvitation 删除（撤回）教练尚未被接受的邀请
+func (r *coachRepository) DeleteInvitation(ctx context.Context, coachID string, id string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("coach_id = ? AND id = ? AND status = ?", coachID, id, model.CoachInvitationPending).
+		Delete(&model.CoachInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的删除了邀请
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeCoachInvitationNotFound, "没有找到要撤回的邀请")
+	}
+
+	return nil
+}
+
+// AcceptInvitation 在一个事务中把邀请标记为已接受，并创建或更新教练关系
+func (r *coachRepository) AcceptInvitation(ctx context.Context, invitation *model.CoachInvitation, link *model.CoachClient) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 只有仍在等待接受的邀请可以接受，避免与撤回同时发生
+		result := tx.Model(&model.CoachInvitation{}).
+			Where("id = ? AND status = ?", invitation.ID, model.CoachInvitationPending).
+			Update("status", model.CoachInvitationAccepted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.NotFound(apperror.CodeCoachInvitationNotFound, "教练邀请不存在")
+		}
+		invitation.Status = model.CoachInvitationAccepted
+		return tx.Omit("Coach", "Client").Save(link).Error
+	})
+}
+
+// CreateAccessLog 记录一次教练访问
+func (r *coachRepository) CreateAccessLog(ctx context.Context, entry *model.CoachAccessLog) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// CoachAccessLogQuery 客户查看教练访问记录时允许的过滤和排序字段
+var CoachAccessLogQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"coach_id":   {Column: "coach_access_logs.coach_id", Type: query.String},
+		"method":     {Column: "coach_access_logs.method", Type: query.String},
+		"path":       {Column: "coach_access_logs.path", Type: query.String},
+		"status":     {Column: "coach_access_logs.status", Type: query.Number, Sortable: true},
+		"created_at": {Column: "coach_access_logs.created_at", Type: query.Time, Sortable: true},
+	},
+	Sort: "-created_at",
+	ID:   "coach_access_logs.id",
+}
+
+// FindAccessLogs 按过滤条件分页查询教练对客户数据的访问记录
+func (r *coachRepository) FindAccessLogs(ctx context.Context, clientID string, q *query.Query) (*query.Page[model.CoachAccessLog], error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	db := r.db.WithContext(ctx).Model(&model.CoachAccessLog{}).Where("client_id = ?", clientID)
+	return query.Find[model.CoachAccessLog](db, q)
+}
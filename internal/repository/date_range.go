@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"time"
+)
+
+// dayRange 返回指定日期及其次日的 YYYY-MM-DD 字符串，用于 [start, end) 范围查询
+// 使用范围比较代替 DATE(...) 函数，使查询在 PostgreSQL 和 SQLite 上行为一致，并且可以使用索引；
+// 参数使用日期字符串而不是 time.Time，避免 PostgreSQL 按会话时区把 date 列转换为 timestamptz 后比较出错
+func dayRange(date time.Time) (string, string) {
+	return date.Format("2006-01-02"), date.AddDate(0, 0, 1).Format("2006-01-02")
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// ExerciseRecordRepository 运动记录仓库接口
+type ExerciseRecordRepository interface {
+	Create(ctx context.Context, exerciseRecord *model.ExerciseRecord) error
+	FindByID(ctx context.Context, id string) (*model.ExerciseRecord, error)
+	FindByUserIDAndDate(ctx context.Context, userID string, date time.Time) ([]*model.ExerciseRecord, error)
+	SumCaloriesByUserIDAndDate(ctx context.Context, userID string, date time.Time) (float64, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// exerciseRecordRepository 运动记录仓库实现
+type exerciseRecordRepository struct {
+	db *gorm.DB
+}
+
+// NewExerciseRecordRepository 创建运动记录仓库实例
+func NewExerciseRecordRepository(db *gorm.DB) ExerciseRecordRepository {
+	if db == nil {
+		log.Fatal("❌ NewExerciseRecordRepository: db 参数为 nil")
+	}
+	return &exerciseRecordRepository{db: db}
+}
+
+// Create 创建运动记录
+func (r *exerciseRecordRepository) Create(ctx context.Context, exerciseRecord *model.ExerciseRecord) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Create(exerciseRecord).Error
+}
+
+// FindByID 根据ID查找运动记录
+func (r *exerciseRecordRepository) FindByID(ctx context.Context, id string) (*model.ExerciseRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var exerciseRecord model.ExerciseRecord
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&exerciseRecord).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeExerciseRecordNotFound, "运动记录不存在")
+		}
+		return nil, err
+	}
+
+	return &exerciseRecord, nil
+}
+
+// FindByUserIDAndDate 根据用户ID和日期查找运动记录
+func (r *exerciseRecordRepository) FindByUserIDAndDate(ctx context.Context, userID string, date time.Time) ([]*model.ExerciseRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	// 计算当天的时间范围
+	start, end := dayRange(date)
+
+	var exerciseRecords []*model.ExerciseRecord
+	err := r.db.WithContext(ctx).Where("user_id = ? AND date >= ? AND date < ?", userID, start, end).Order("created_at").Find(&exerciseRecords).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return exerciseRecords, nil
+}
+
+// SumCaloriesByUserIDAndDate 统计用户在指定日期的运动消耗热量
+func (r *exerciseRecordRepository) SumCaloriesByUserIDAndDate(ctx context.Context, userID string, date time.Time) (float64, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("repository 未初始化")
+	}
+
+	// 计算当天的时间范围
+	start, end := dayRange(date)
+
+	var total float64
+	err := r.db.WithContext(ctx).Model(&model.ExerciseRecord{}).
+		Where("user_id = ? AND date >= ? AND date < ?", userID, start, end).
+		Select("COALESCE(SUM(calories_burned), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// Delete 删除运动记录
+func (r *exerciseRecordRepository) Delete(ctx context.Context, id string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.ExerciseRecord{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的删除了记录
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeExerciseRecordNotFound, "没有找到要删除的运动记录")
+	}
+
+	return nil
+}
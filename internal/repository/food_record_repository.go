@@ -6,7 +6,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
 	"gorm.io/gorm"
 )
 
@@ -16,11 +18,52 @@ type FoodRecordRepository interface {
 	FindByID(ctx context.Context, id string) (*model.FoodRecord, error)
 	FindByMealRecordID(ctx context.Context, mealRecordID string) ([]*model.FoodRecord, error)
 	FindByUserIDAndDate(ctx context.Context, userID string, date time.Time) ([]*model.FoodRecord, error)
+	ListByMealRecordID(ctx context.Context, mealRecordID string, q *query.Query) (*query.Page[model.FoodRecord], error)
+	ListByUser(ctx context.Context, userID string, q *query.Query) (*query.Page[model.FoodRecord], error)
+	FindImportKeys(ctx context.Context, keys []string) ([]string, error)
+	EachInRange(ctx context.Context, userID string, from time.Time, to time.Time, fn func(*DiaryEntry) error) error
+	CountFoodUsage(ctx context.Context, userID string, from time.Time, to time.Time, limit int) ([]FoodUsage, error)
 	Update(ctx context.Context, foodRecord *model.FoodRecord) error
 	Delete(ctx context.Context, id string) error
 	DeleteByMealRecordID(ctx context.Context, mealRecordID string) error
 }
 
+// FoodRecordQuery 食物记录列表允许的过滤和排序字段
+// 查询时总是关联 meal_records，date 和 meal_type 取自所属餐次，只能用于过滤
+var FoodRecordQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"food_name":     {Column: "food_records.food_name", Type: query.String, Sortable: true},
+		"food_id":       {Column: "food_records.food_id", Type: query.String},
+		"unit":          {Column: "food_records.unit", Type: query.String},
+		"quantity":      {Column: "food_records.quantity", Type: query.Number, Sortable: true},
+		"calories":      {Column: "food_records.calories", Type: query.Number, Sortable: true},
+		"protein":       {Column: "food_records.protein", Type: query.Number, Sortable: true},
+		"carbohydrates": {Column: "food_records.carbohydrates", Type: query.Number, Sortable: true},
+		"fat":           {Column: "food_records.fat", Type: query.Number, Sortable: true},
+		"created_at":    {Column: "food_records.created_at", Type: query.Time, Sortable: true},
+		"date":          {Column: "meal_records.date", Type: query.Date},
+		"meal_type":     {Column: "meal_records.meal_type", Type: query.Number, Values: mealTypeValues()},
+	},
+	Sort: "created_at",
+	ID:   "food_records.id",
+}
+
+// DiaryEntry 一条食物记录及所属餐次的日期和类型
+type DiaryEntry struct {
+	ID            string
+	FoodID        *string // 导入的快速添加条目为空
+	FoodName      string
+	Quantity      float64
+	Unit          string
+	Calories      float64
+	Protein       float64
+	Carbohydrates float64
+	Fat           float64
+	CreatedAt     time.Time
+	Date          time.Time
+	MealType      model.MealType
+}
+
 // foodRecordRepository 食物记录仓库实现
 type foodRecordRepository struct {
 	db *gorm.DB
@@ -52,7 +95,7 @@ func (r *foodRecordRepository) FindByID(ctx context.Context, id string) (*model.
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&foodRecord).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("食物记录不存在")
+			return nil, apperror.NotFound(apperror.CodeFoodRecordNotFound, "食物记录不存在")
 		}
 		return nil, err
 	}
@@ -81,11 +124,11 @@ func (r *foodRecordRepository) FindByUserIDAndDate(ctx context.Context, userID s
 		return nil, errors.New("repository 未初始化")
 	}
 
-	// 格式化日期为 YYYY-MM-DD 格式
-	dateStr := date.Format("2006-01-02")
+	// 计算当天的时间范围
+	start, end := dayRange(date)
 
 	var foodRecords []*model.FoodRecord
-	err := r.db.WithContext(ctx).Joins("JOIN meal_records ON meal_records.id = food_records.meal_record_id").Where("meal_records.user_id = ? AND DATE(meal_records.date) = ?", userID, dateStr).Find(&foodRecords).Error
+	err := r.db.WithContext(ctx).Joins("JOIN meal_records ON meal_records.id = food_records.meal_record_id").Where("meal_records.user_id = ? AND meal_records.date >= ? AND meal_records.date < ?", userID, start, end).Find(&foodRecords).Error
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +136,113 @@ func (r *foodRecordRepository) FindByUserIDAndDate(ctx context.Context, userID s
 	return foodRecords, nil
 }
 
+// ListByMealRecordID 按过滤条件分页查询餐次下的食物记录
+func (r *foodRecordRepository) ListByMealRecordID(ctx context.Context, mealRecordID string, q *query.Query) (*query.Page[model.FoodRecord], error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+	db := r.db.WithContext(ctx).Model(&model.FoodRecord{}).
+		Joins("JOIN meal_records ON meal_records.id = food_records.meal_record_id").
+		Where("food_records.meal_record_id = ?", mealRecordID)
+	return query.Find[model.FoodRecord](db, q)
+}
+
+// ListByUser 按过滤条件分页查询用户的食物记录
+func (r *foodRecordRepository) ListByUser(ctx context.Context, userID string, q *query.Query) (*query.Page[model.FoodRecord], error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+	db := r.db.WithContext(ctx).Model(&model.FoodRecord{}).
+		Joins("JOIN meal_records ON meal_records.id = food_records.meal_record_id").
+		Where("meal_records.user_id = ?", userID)
+	return query.Find[model.FoodRecord](db, q)
+}
+
+// FindImportKeys 返回已经导入过的导入标识
+func (r *foodRecordRepository) FindImportKeys(ctx context.Context, keys []string) ([]string, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var existing []string
+	for start := 0; start < len(keys); start += batchSize {
+		end := min(start+batchSize, len(keys))
+		var batch []string
+		if err := r.db.WithContext(ctx).Model(&model.FoodRecord{}).Where("import_key IN ?", keys[start:end]).Pluck("import_key", &batch).Error; err != nil {
+			return nil, err
+		}
+		existing = append(existing, batch...)
+	}
+	return existing, nil
+}
+
+// EachInRange 按日期、餐次和记录时间的顺序逐条读取用户在 [from, to] 日期范围内的食物记录
+// 使用游标逐行读取，不会一次把整个范围的记录加载到内存中；fn 返回错误时停止读取
+func (r *foodRecordRepository) EachInRange(ctx context.Context, userID string, from time.Time, to time.Time, fn func(*DiaryEntry) error) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	start, _ := dayRange(from)
+	_, end := dayRange(to)
+
+	rows, err := r.db.WithContext(ctx).Model(&model.FoodRecord{}).
+		Select("food_records.id, food_records.food_id, food_records.food_name, food_records.quantity, food_records.unit, "+
+			"food_records.calories, food_records.protein, food_records.carbohydrates, food_records.fat, food_records.created_at, "+
+			"meal_records.date, meal_records.meal_type").
+		Joins("JOIN meal_records ON meal_records.id = food_records.meal_record_id").
+		Where("meal_records.user_id = ? AND meal_records.date >= ? AND meal_records.date < ?", userID, start, end).
+		Order("meal_records.date, meal_records.meal_type, food_records.created_at, food_records.id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry DiaryEntry
+		if err := r.db.ScanRows(rows, &entry); err != nil {
+			return err
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// FoodUsage 用户记录某种食物的次数
+type FoodUsage struct {
+	FoodID string
+	Count  int64
+}
+
+// CountFoodUsage 统计用户在 [from, to] 日期范围内最常记录的食物，按次数从多到少返回前 limit 种
+// 导入的快速添加条目没有关联食物，不参与统计
+func (r *foodRecordRepository) CountFoodUsage(ctx context.Context, userID string, from time.Time, to time.Time, limit int) ([]FoodUsage, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	start, _ := dayRange(from)
+	_, end := dayRange(to)
+
+	var usage []FoodUsage
+	err := r.db.WithContext(ctx).Model(&model.FoodRecord{}).
+		Select("food_records.food_id, COUNT(*) AS count").
+		Joins("JOIN meal_records ON meal_records.id = food_records.meal_record_id").
+		Where("meal_records.user_id = ? AND meal_records.date >= ? AND meal_records.date < ?", userID, start, end).
+		Where("food_records.food_id IS NOT NULL").
+		Group("food_records.food_id").
+		Order("count DESC, food_records.food_id").
+		Limit(limit).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
 // Update 更新食物记录
 func (r *foodRecordRepository) Update(ctx context.Context, foodRecord *model.FoodRecord) error {
 	if r == nil || r.db == nil {
@@ -106,7 +256,7 @@ func (r *foodRecordRepository) Update(ctx context.Context, foodRecord *model.Foo
 
 	// 检查是否真的更新了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要更新的食物记录")
+		return apperror.NotFound(apperror.CodeFoodRecordNotFound, "没有找到要更新的食物记录")
 	}
 
 	return nil
@@ -126,7 +276,7 @@ func (r *foodRecordRepository) Delete(ctx context.Context, id string) error {
 
 	// 检查是否真的删除了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要删除的食物记录")
+		return apperror.NotFound(apperror.CodeFoodRecordNotFound, "没有找到要删除的食物记录")
 	}
 
 	return nil
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"log"
+	"strings"
 
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FoodRepository 食物仓库接口
@@ -14,7 +18,9 @@ type FoodRepository interface {
 	Create(ctx context.Context, food *model.Food) error
 	FindByID(ctx context.Context, id string) (*model.Food, error)
 	FindByName(ctx context.Context, name string) (*model.Food, error)
-	FindAll(ctx context.Context) ([]*model.Food, error)
+	FindByNames(ctx context.Context, names []string) ([]*model.Food, error)
+	List(ctx context.Context, q *query.Query) (*query.Page[model.Food], error)
+	FindForGap(ctx context.Context, gap NutrientGap, minPortion float64, limit int, include []string) ([]*model.Food, error)
 	Update(ctx context.Context, food *model.Food) error
 	Delete(ctx context.Context, id string) error
 }
@@ -50,7 +56,7 @@ func (r *foodRepository) FindByID(ctx context.Context, id string) (*model.Food,
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&food).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("食物不存在")
+			return nil, apperror.NotFound(apperror.CodeFoodNotFound, "食物不存在")
 		}
 		return nil, err
 	}
@@ -68,7 +74,7 @@ func (r *foodRepository) FindByName(ctx context.Context, name string) (*model.Fo
 	err := r.db.WithContext(ctx).Where("name = ?", name).First(&food).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("食物不存在")
+			return nil, apperror.NotFound(apperror.CodeFoodNotFound, "食物不存在")
 		}
 		return nil, err
 	}
@@ -76,18 +82,113 @@ func (r *foodRepository) FindByName(ctx context.Context, name string) (*model.Fo
 	return &food, nil
 }
 
-// FindAll 查找所有食物
-func (r *foodRepository) FindAll(ctx context.Context) ([]*model.Food, error) {
+// FindByNames 按名称批量查找食物，不区分大小写
+func (r *foodRepository) FindByNames(ctx context.Context, names []string) ([]*model.Food, error) {
 	if r == nil || r.db == nil {
 		return nil, errors.New("repository 未初始化")
 	}
 
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+	}
+
 	var foods []*model.Food
-	err := r.db.WithContext(ctx).Find(&foods).Error
+	for start := 0; start < len(lower); start += batchSize {
+		end := min(start+batchSize, len(lower))
+		var batch []*model.Food
+		if err := r.db.WithContext(ctx).Where("LOWER(name) IN ?", lower[start:end]).Order("created_at").Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		foods = append(foods, batch...)
+	}
+	return foods, nil
+}
+
+// FoodQuery 食物列表允许的过滤和排序字段
+var FoodQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"name":          {Column: "foods.name", Type: query.String, Sortable: true},
+		"calories":      {Column: "foods.calories", Type: query.Number, Sortable: true},
+		"protein":       {Column: "foods.protein", Type: query.Number, Sortable: true},
+		"carbohydrates": {Column: "foods.carbohydrates", Type: query.Number, Sortable: true},
+		"fat":           {Column: "foods.fat", Type: query.Number, Sortable: true},
+		"created_at":    {Column: "foods.created_at", Type: query.Time, Sortable: true},
+	},
+	Sort: "name",
+	ID:   "foods.id",
+}
+
+// List 按过滤条件分页查询食物
+func (r *foodRepository) List(ctx context.Context, q *query.Query) (*query.Page[model.Food], error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+	return query.Find[model.Food](r.db.WithContext(ctx).Model(&model.Food{}), q)
+}
+
+// NutrientGap 剩余热量预算和各营养素距离目标的缺口
+type NutrientGap struct {
+	Calories      float64
+	Protein       float64
+	Carbohydrates float64
+	Fat           float64
+}
+
+// FindForGap 从食物库中预筛选能补齐营养素缺口的食物，最多返回 limit 种，include 中的食物（如常吃的食物）总是返回
+//
+// 只选出最小份量 minPortion 克不超出剩余热量、且含有至少一种有缺口的营养素的食物，
+// 按每千卡热量中能补齐缺口的营养素热量（按缺口大小加权）从高到低排序，相同时按 ID 排序
+func (r *foodRepository) FindForGap(ctx context.Context, gap NutrientGap, minPortion float64, limit int, include []string) ([]*model.Food, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	needed := [3]float64{gap.Protein * 4, gap.Carbohydrates * 4, gap.Fat * 9}
+	total := needed[0] + needed[1] + needed[2]
+	if gap.Calories <= 0 || total <= 0 || limit <= 0 {
+		return []*model.Food{}, nil
+	}
+
+	var provides []string
+	for i, column := range []string{"protein", "carbohydrates", "fat"} {
+		if needed[i] > 0 {
+			provides = append(provides, column+" > 0")
+		}
+	}
+
+	var foods []*model.Food
+	err := r.db.WithContext(ctx).
+		Where("calories > 0 AND calories * ? <= ?", minPortion/100, gap.Calories).
+		Where("(" + strings.Join(provides, " OR ") + ")").
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "(? * protein * 4 + ? * carbohydrates * 4 + ? * fat * 9) / calories DESC, id",
+			Vars: []interface{}{needed[0] / total, needed[1] / total, needed[2] / total},
+		}}).
+		Limit(limit).
+		Find(&foods).Error
 	if err != nil {
 		return nil, err
 	}
 
+	seen := make(map[string]bool, len(foods))
+	for _, food := range foods {
+		seen[food.ID] = true
+	}
+	var missing []string
+	for _, id := range include {
+		if !seen[id] {
+			missing = append(missing, id)
+			seen[id] = true
+		}
+	}
+	if len(missing) > 0 {
+		var extra []*model.Food
+		if err := r.db.WithContext(ctx).Where("id IN ?", missing).Order("id").Find(&extra).Error; err != nil {
+			return nil, err
+		}
+		foods = append(foods, extra...)
+	}
 	return foods, nil
 }
 
@@ -104,7 +205,7 @@ func (r *foodRepository) Update(ctx context.Context, food *model.Food) error {
 
 	// 检查是否真的更新了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要更新的食物")
+		return apperror.NotFound(apperror.CodeFoodNotFound, "没有找到要更新的食物")
 	}
 
 	return nil
@@ -116,7 +217,7 @@ func (r *foodRepository) Delete(ctx context.Context, id string) error {
 		return errors.New("repository 未初始化")
 	}
 
-	// 使用软删除（如果模型有 DeletedAt 字段）
+	// 引用该食物的食物记录由外键 ON DELETE SET NULL 将 food_id 置空
 	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Food{})
 	if result.Error != nil {
 		return result.Error
@@ -124,7 +225,7 @@ func (r *foodRepository) Delete(ctx context.Context, id string) error {
 
 	// 检查是否真的删除了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要删除的食物")
+		return apperror.NotFound(apperror.CodeFoodNotFound, "没有找到要删除的食物")
 	}
 
 	return nil
@@ -0,0 +1,166 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/pkg/database"
+	"gorm.io/gorm"
+)
+
+// openForeignKeyDB 创建开启外键检查的 SQLite 数据库并执行全部迁移
+func openForeignKeyDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)"
+	if err := database.Connect(database.DriverSQLite, dsn); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewMigrator()
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	return database.DB
+}
+
+// seedFoodRecord 创建一个食物以及引用它的食物记录
+func seedFoodRecord(t *testing.T, db *gorm.DB) (*model.Food, *model.FoodRecord) {
+	t.Helper()
+
+	food := &model.Food{Name: "燕麦", Calories: 389, Protein: 16.9, Carbohydrates: 66.3, Fat: 6.9}
+	if err := db.Create(food).Error; err != nil {
+		t.Fatalf("创建食物失败: %v", err)
+	}
+	meal := &model.MealRecord{UserID: "00000000-0000-0000-0000-000000000001", Date: time.Now(), MealType: model.Breakfast}
+	if err := db.Create(meal).Error; err != nil {
+		t.Fatalf("创建餐次失败: %v", err)
+	}
+	record := &model.FoodRecord{
+		MealRecordID: meal.ID,
+		FoodID:       &food.ID,
+		FoodName:     food.Name,
+		Quantity:     50,
+		Unit:         "g",
+		Calories:     194.5,
+	}
+	if err := db.Omit("MealRecord", "Food").Create(record).Error; err != nil {
+		t.Fatalf("创建食物记录失败: %v", err)
+	}
+	return food, record
+}
+
+func TestFoodDeleteKeepsFoodRecords(t *testing.T) {
+	db := openForeignKeyDB(t)
+	food, record := seedFoodRecord(t, db)
+
+	if err := repository.NewFoodRepository(db).Delete(context.Background(), food.ID); err != nil {
+		t.Fatalf("删除已被记录引用的食物失败: %v", err)
+	}
+
+	var got model.FoodRecord
+	if err := db.First(&got, "id = ?", record.ID).Error; err != nil {
+		t.Fatalf("查询食物记录失败: %v", err)
+	}
+	if got.FoodID != nil {
+		t.Errorf("food_id = %q，期望置空", *got.FoodID)
+	}
+	if got.FoodName != record.FoodName || got.Calories != record.Calories {
+		t.Errorf("食物记录的名称和营养数据被修改: %+v", got)
+	}
+}
+
+func TestFoodDeleteRejectedWithoutSetNull(t *testing.T) {
+	db := openForeignKeyDB(t)
+
+	// 回滚 0016 后外键没有 ON DELETE 规则，删除被引用的食物应当违反外键约束
+	migrator, err := database.NewMigrator()
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+	for {
+		m, err := migrator.Down(context.Background())
+		if err != nil {
+			t.Fatalf("回滚迁移失败: %v", err)
+		}
+		if m == nil || m.Version == 16 {
+			break
+		}
+	}
+
+	food, _ := seedFoodRecord(t, db)
+	if err := repository.NewFoodRepository(db).Delete(context.Background(), food.ID); err == nil {
+		t.Fatal("期望违反外键约束，实际删除成功")
+	}
+}
+
+func TestFoodFindForGap(t *testing.T) {
+	db := openForeignKeyDB(t)
+	ctx := context.Background()
+
+	foods := map[string]*model.Food{}
+	for _, food := range []*model.Food{
+		{Name: "米饭", Calories: 116, Protein: 2.6, Carbohydrates: 25.9, Fat: 0.3},
+		{Name: "鸡蛋", Calories: 144, Protein: 13.3, Carbohydrates: 2.8, Fat: 8.8},
+		{Name: "豆腐", Calories: 84, Protein: 6.6, Carbohydrates: 3.4, Fat: 5.3},
+		{Name: "鸡胸肉", Calories: 133, Protein: 31, Fat: 1.2},
+		{Name: "白糖", Calories: 400, Carbohydrates: 99.9},    // 不含有缺口的营养素
+		{Name: "牛排", Calories: 1200, Protein: 25, Fat: 110}, // 25 克就超出剩余热量
+		{Name: "零热量饮料", Calories: 0, Protein: 0.1},
+	} {
+		if err := db.Create(food).Error; err != nil {
+			t.Fatalf("创建食物失败: %v", err)
+		}
+		foods[food.Name] = food
+	}
+
+	repo := repository.NewFoodRepository(db)
+	gap := repository.NutrientGap{Calories: 250, Protein: 40}
+	names := func(got []*model.Food) []string {
+		var out []string
+		for _, food := range got {
+			out = append(out, food.Name)
+		}
+		return out
+	}
+
+	got, err := repo.FindForGap(ctx, gap, 25, 10, nil)
+	if err != nil {
+		t.Fatalf("预筛选失败: %v", err)
+	}
+	// 只有蛋白质有缺口，按每千卡的蛋白质热量排序
+	want := []string{"鸡胸肉", "鸡蛋", "豆腐", "米饭"}
+	if !reflect.DeepEqual(names(got), want) {
+		t.Errorf("候选食物 = %v，期望 %v", names(got), want)
+	}
+
+	// 超出数量上限的食物被截断，但 include 中的食物总是返回且不重复
+	got, err = repo.FindForGap(ctx, gap, 25, 2, []string{foods["米饭"].ID, foods["鸡胸肉"].ID, foods["白糖"].ID})
+	if err != nil {
+		t.Fatalf("预筛选失败: %v", err)
+	}
+	if got := names(got); len(got) != 4 || got[0] != "鸡胸肉" || got[1] != "鸡蛋" {
+		t.Errorf("候选食物 = %v，期望鸡胸肉、鸡蛋以及 include 中的米饭和白糖", got)
+	}
+
+	// 没有剩余热量或没有缺口时不查询
+	for _, gap := range []repository.NutrientGap{{Calories: 0, Protein: 40}, {Calories: 250}} {
+		got, err := repo.FindForGap(ctx, gap, 25, 10, nil)
+		if err != nil || len(got) != 0 {
+			t.Errorf("gap = %+v: 候选食物 = %v, err = %v，期望为空", gap, names(got), err)
+		}
+	}
+}
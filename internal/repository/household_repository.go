@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// HouseholdRepository 家庭仓库接口
+type HouseholdRepository interface {
+	Create(ctx context.Context, household *model.Household, owner *model.HouseholdMember) error
+	FindByID(ctx context.Context, id string) (*model.Household, error)
+	FindMembership(ctx context.Context, userID string) (*model.HouseholdMember, error)
+	FindMembers(ctx context.Context, householdID string) ([]*model.HouseholdMember, error)
+	FindInvitation(ctx context.Context, id string) (*model.HouseholdInvitation, error)
+	FindInvitationByEmail(ctx context.Context, householdID string, email string) (*model.HouseholdInvitation, error)
+	FindPendingInvitations(ctx context.Context, householdID string) ([]*model.HouseholdInvitation, error)
+	FindInvitationsForEmail(ctx context.Context, email string) ([]*model.HouseholdInvitation, error)
+	SaveInvitation(ctx context.Context, invitation *model.HouseholdInvitation) error
+	DeleteInvitation(ctx context.Context, householdID string, id string) error
+	AcceptInvitation(ctx context.Context, invitation *model.HouseholdInvitation, member *model.HouseholdMember) error
+	RemoveMember(ctx context.Context, householdID string, userID string) error
+	CreateDependent(ctx context.Context, user *model.User, member *model.HouseholdMember) error
+	DeleteDependent(ctx context.Context, householdID string, userID string) error
+	Delete(ctx context.Context, id string) error
+	CanAccess(ctx context.Context, subjectID string, ownerID string) (bool, error)
+	DeleteOrphanedDependents(ctx context.Context, userID string) (int64, error)
+}
+
+// householdRepository 家庭仓库实现
+type householdRepository struct {
+	db *gorm.DB
+}
+
+// NewHouseholdRepository 创建家庭仓库实例
+func NewHouseholdRepository(db *gorm.DB) HouseholdRepository {
+	if db == nil {
+		log.Fatal("❌ NewHouseholdRepository: db 参数为 nil")
+	}
+	return &householdRepository{db: db}
+}
+
+// Create 创建家庭，创建者成为 owner
+func (r *householdRepository) Create(ctx context.Context, household *model.Household, owner *model.HouseholdMember) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(household).Error; err != nil {
+			return err
+		}
+		owner.HouseholdID = household.ID
+		return tx.Create(owner).Error
+	})
+}
+
+// FindByID 根据ID查找家庭
+func (r *householdRepository) FindByID(ctx context.Context, id string) (*model.Household, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var household model.Household
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&household).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeHouseholdNotFound, "家庭不存在")
+		}
+		return nil, err
+	}
+
+	return &household, nil
+}
+
+// FindMembership 查找用户所在家庭的成员记录
+func (r *householdRepository) FindMembership(ctx context.Context, userID string) (*model.HouseholdMember, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var member model.HouseholdMember
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeHouseholdNotFound, "尚未加入家庭")
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// FindMembers 查找家庭的全部成员及其用户信息，已注销的用户不包括在内
+func (r *householdRepository) FindMembers(ctx context.Context, householdID string) ([]*model.HouseholdMember, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var members []*model.HouseholdMember
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("household_id = ?", householdID).
+		Order("created_at").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := members[:0]
+	for _, m := range members {
+		if m.User != nil {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+// FindInvitation 根据ID查找家庭邀请
+func (r *householdRepository) FindInvitation(ctx context.Context, id string) (*model.HouseholdInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitation model.HouseholdInvitation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeHouseholdInvitationNotFound, "家庭邀请不存在")
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// FindInvitationByEmail 查找家庭发给某个邮箱的邀请（任意状态）
+func (r *householdRepository) FindInvitationByEmail(ctx context.Context, householdID string, email string) (*model.HouseholdInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitation model.HouseholdInvitation
+	err := r.db.WithContext(ctx).Where("household_id = ? AND email = ?", householdID, email).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeHouseholdInvitationNotFound, "家庭邀请不存在")
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// FindPendingInvitations 查找家庭等待接受的邀请，按邀请时间排序
+func (r *householdRepository) FindPendingInvitations(ctx context.Context, householdID string) ([]*model.HouseholdInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitations []*model.HouseholdInvitation
+	err := r.db.WithContext(ctx).
+		Where("household_id = ? AND status = ?", householdID, model.HouseholdInvitationPending).
+		Order("created_at").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// FindInvitationsForEmail 查找发给某个邮箱、等待接受的邀请及其家庭，按邀请时间倒序
+func (r *householdRepository) FindInvitationsForEmail(ctx context.Context, email string) ([]*model.HouseholdInvitation, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var invitations []*model.HouseholdInvitation
+	err := r.db.WithContext(ctx).Preload("Household").
+		Where("email = ? AND status = ?", email, model.HouseholdInvitationPending).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// SaveInvitation 创建或更新家庭邀请
+func (r *householdRepository) SaveInvitation(ctx context.Context, invitation *model.HouseholdInvitation) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Omit("Household").Save(invitation).Error
+}
+
+// DeleteInvitation 删除（撤回）家庭邀请
+func (r *householdRepository) DeleteInvitation(ctx context.Context, householdID string, id string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("household_id = ? AND id = ? AND status = ?", householdID, id, model.HouseholdInvitationPending).
+		Delete(&model.HouseholdInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的删除了邀请
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeHouseholdInvitationNotFound, "没有找到要撤回的邀请")
+	}
+
+	return nil
+}
+
+// AcceptInvitation 在一个事务中把邀请标记为已接受并添加成员
+func (r *householdRepository) AcceptInvitation(ctx context.Context, invitation *model.HouseholdInvitation, member *model.HouseholdMember) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 只有仍在等待接受的邀请可以接受，避免与撤回同时发生
+		result := tx.Model(&model.HouseholdInvitation{}).
+			Where("id = ? AND status = ?", invitation.ID, model.HouseholdInvitationPending).
+			Update("status", model.HouseholdInvitationAccepted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.NotFound(apperror.CodeHouseholdInvitationNotFound, "家庭邀请不存在")
+		}
+		invitation.Status = model.HouseholdInvitationAccepted
+		return tx.Create(member).Error
+	})
+}
+
+// RemoveMember 移除成员
+func (r *householdRepository) RemoveMember(ctx context.Context, householdID string, userID string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("household_id = ? AND user_id = ?", householdID, userID).
+		Delete(&model.HouseholdMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的移除了成员
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeHouseholdMemberNotFound, "没有找到要移除的成员")
+	}
+
+	return nil
+}
+
+// CreateDependent 在一个事务中创建受抚养成员的档案和成员记录
+func (r *householdRepository) CreateDependent(ctx context.Context, user *model.User, member *model.HouseholdMember) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		member.UserID = user.ID
+		return tx.Create(member).Error
+	})
+}
+
+// DeleteDependent 移除受抚养成员并注销其档案，数据在保留期结束后由清理任务永久删除
+func (r *householdRepository) DeleteDependent(ctx context.Context, householdID string, userID string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("household_id = ? AND user_id = ? AND role = ?", householdID, userID, model.HouseholdRoleDependent).
+			Delete(&model.HouseholdMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.NotFound(apperror.CodeHouseholdMemberNotFound, "没有找到要删除的成员")
+		}
+		return tx.Where("id = ?", userID).Delete(&model.User{}).Error
+	})
+}
+
+// Delete 删除家庭及其成员记录和邀请
+func (r *householdRepository) Delete(ctx context.Context, id string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("household_id = ?", id).Delete(&model.HouseholdMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("household_id = ?", id).Delete(&model.HouseholdInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.Household{}).Error
+	})
+}
+
+// CanAccess owner 是否为 subject 所在家庭的受抚养成员（subject 需为成年成员）
+func (r *householdRepository) CanAccess(ctx context.Context, subjectID string, ownerID string) (bool, error) {
+	if r == nil || r.db == nil {
+		return false, errors.New("repository 未初始化")
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).Table("household_members AS o").
+		Joins("JOIN household_members AS s ON s.household_id = o.household_id").
+		Where("o.user_id = ? AND o.role = ?", ownerID, model.HouseholdRoleDependent).
+		Where("s.user_id = ? AND s.role IN ?", subjectID, []string{model.HouseholdRoleOwner, model.HouseholdRoleMember}).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteOrphanedDependents 用户注销后，如果家庭中已没有其他成年成员，则一并注销受抚养成员的档案，
+// 返回注销的档案数
+func (r *householdRepository) DeleteOrphanedDependents(ctx context.Context, userID string) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("repository 未初始化")
+	}
+
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var member model.HouseholdMember
+		err := tx.Where("user_id = ?", userID).First(&member).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if !member.Adult() {
+			return nil
+		}
+
+		// 其他未注销的成年成员
+		var adults int64
+		err = tx.Model(&model.HouseholdMember{}).
+			Joins("JOIN users ON users.id = household_members.user_id AND users.deleted_at IS NULL").
+			Where("household_members.household_id = ? AND household_members.user_id <> ? AND household_members.role IN ?",
+				member.HouseholdID, userID, []string{model.HouseholdRoleOwner, model.HouseholdRoleMember}).
+			Count(&adults).Error
+		if err != nil || adults > 0 {
+			return err
+		}
+
+		dependents := tx.Model(&model.HouseholdMember{}).Select("user_id").
+			Where("household_id = ? AND role = ?", member.HouseholdID, model.HouseholdRoleDependent)
+		result := tx.Where("id IN (?)", dependents).Delete(&model.User{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository 登录审计记录仓库接口
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *model.LoginAttempt) error
+}
+
+// loginAttemptRepository 登录审计记录仓库实现
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository 创建登录审计记录仓库实例
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	if db == nil {
+		log.Fatal("❌ NewLoginAttemptRepository: db 参数为 nil")
+	}
+	return &loginAttemptRepository{db: db}
+}
+
+// Create 记录一次登录尝试
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *model.LoginAttempt) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
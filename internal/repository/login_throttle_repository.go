@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loginThrottleRepository 登录失败计数的数据库存储，实现 loginguard.Store
+// 预占通过带条件的 UPDATE 或 INSERT ... ON CONFLICT DO NOTHING 原子完成，多个实例可以共享同一张表
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginThrottleRepository 创建登录失败计数仓库实例
+func NewLoginThrottleRepository(db *gorm.DB) loginguard.Store {
+	if db == nil {
+		log.Fatal("❌ NewLoginThrottleRepository: db 参数为 nil")
+	}
+	return &loginThrottleRepository{db: db}
+}
+
+// Get 返回键的失败记录
+func (r *loginThrottleRepository) Get(ctx context.Context, key string) (loginguard.State, error) {
+	if r == nil || r.db == nil {
+		return loginguard.State{}, errors.New("repository 未初始化")
+	}
+	return r.find(r.db.WithContext(ctx), key)
+}
+
+// Reserve 失败次数仍为 seen.Failures 且未锁定时原子地增加失败次数，条件和计数在同一条语句中
+func (r *loginThrottleRepository) Reserve(ctx context.Context, key string, seen loginguard.State, now time.Time, windowStart time.Time) (loginguard.State, bool, error) {
+	if r == nil || r.db == nil {
+		return loginguard.State{}, false, errors.New("repository 未初始化")
+	}
+
+	var state loginguard.State
+	var ok bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.LoginThrottle{}).
+			Where("throttle_key = ? AND failures = ? AND (locked_until IS NULL OR locked_until <= ?)", key, seen.Failures, now).
+			Updates(map[string]interface{}{
+				"failures":        gorm.Expr("CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END", windowStart),
+				"last_failure_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		ok = result.RowsAffected == 1
+
+		// 还没有记录时插入，同时插入的请求只有一个成功
+		if !ok && seen.Failures == 0 {
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&model.LoginThrottle{Key: key, Failures: 1, LastFailureAt: now})
+			if result.Error != nil {
+				return result.Error
+			}
+			ok = result.RowsAffected == 1
+		}
+		if !ok {
+			return nil
+		}
+
+		var err error
+		state, err = r.find(tx, key)
+		return err
+	})
+	return state, ok, err
+}
+
+// Release 失败次数减 1
+func (r *loginThrottleRepository) Release(ctx context.Context, key string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Model(&model.LoginThrottle{}).Where("throttle_key = ? AND failures > 0", key).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+// Lock 锁定键并清零失败次数
+func (r *loginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Model(&model.LoginThrottle{}).Where("throttle_key = ?", key).
+		Updates(map[string]interface{}{"failures": 0, "locked_until": until}).Error
+}
+
+// Reset 删除键的记录
+func (r *loginThrottleRepository) Reset(ctx context.Context, key string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Where("throttle_key = ?", key).Delete(&model.LoginThrottle{}).Error
+}
+
+// find 读取记录并转换为 loginguard.State，没有记录时返回零值
+func (r *loginThrottleRepository) find(db *gorm.DB, key string) (loginguard.State, error) {
+	var row model.LoginThrottle
+	err := db.Where("throttle_key = ?", key).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return loginguard.State{}, nil
+		}
+		return loginguard.State{}, err
+	}
+
+	state := loginguard.State{Failures: row.Failures, LastFailureAt: row.LastFailureAt}
+	if row.LockedUntil != nil {
+		state.LockedUntil = *row.LockedUntil
+	}
+	return state, nil
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+func TestLoginThrottleConcurrentReserve(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewLoginThrottleRepository(openForeignKeyDB(t))
+	account := loginguard.Policy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Minute,
+		MaxDelay:         time.Hour,
+		LockoutThreshold: 3,
+		LockoutDuration:  time.Hour,
+		Window:           time.Hour,
+	}
+	guard := loginguard.New(store, account, loginguard.Policy{FreeAttempts: 100, Window: time.Hour})
+
+	// 并发猜测验证码（同一账户、不同 IP），检查和计数不是原子操作时全部请求都会通过
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed []*loginguard.Reservation
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := guard.Reserve(ctx, "user@example.com", "")
+			if err != nil {
+				t.Errorf("预占失败: %v", err)
+				return
+			}
+			if r.Allowed() {
+				mu.Lock()
+				allowed = append(allowed, r)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(allowed) != account.FreeAttempts {
+		t.Fatalf("并发尝试中允许了 %d 次，期望 %d 次", len(allowed), account.FreeAttempts)
+	}
+
+	for _, r := range allowed {
+		if _, err := guard.Fail(ctx, r); err != nil {
+			t.Fatalf("记录失败: %v", err)
+		}
+	}
+	r, err := guard.Reserve(ctx, "user@example.com", "203.0.113.1")
+	if err != nil {
+		t.Fatalf("预占失败: %v", err)
+	}
+	if !r.Locked {
+		t.Errorf("连续失败 %d 次后账户没有锁定: %+v", account.LockoutThreshold, r.Decision)
+	}
+
+	// 解锁后重新计数，撤销的预占不计入失败次数
+	if err := guard.Unlock(ctx, "user@example.com"); err != nil {
+		t.Fatalf("解锁失败: %v", err)
+	}
+	for i := 0; i < 2*account.FreeAttempts; i++ {
+		r, err := guard.Reserve(ctx, "user@example.com", "203.0.113.1")
+		if err != nil || !r.Allowed() {
+			t.Fatalf("解锁后第 %d 次尝试被拒绝: %+v, %v", i+1, r, err)
+		}
+		if err := guard.Release(ctx, r); err != nil {
+			t.Fatalf("撤销预占失败: %v", err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// MealCommentRepository 餐次评论仓库接口
+type MealCommentRepository interface {
+	Create(ctx context.Context, comment *model.MealComment) error
+	FindByID(ctx context.Context, id string) (*model.MealComment, error)
+	FindByMealRecordID(ctx context.Context, mealRecordID string) ([]*model.MealComment, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// mealCommentRepository 餐次评论仓库实现
+type mealCommentRepository struct {
+	db *gorm.DB
+}
+
+// NewMealCommentRepository 创建餐次评论仓库实例
+func NewMealCommentRepository(db *gorm.DB) MealCommentRepository {
+	if db == nil {
+		log.Fatal("❌ NewMealCommentRepository: db 参数为 nil")
+	}
+	return &mealCommentRepository{db: db}
+}
+
+// Create 创建评论
+func (r *mealCommentRepository) Create(ctx context.Context, comment *model.MealComment) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+// FindByID 根据ID查找评论
+func (r *mealCommentRepository) FindByID(ctx context.Context, id string) (*model.MealComment, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var comment model.MealComment
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&comment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeMealCommentNotFound, "评论不存在")
+		}
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// FindByMealRecordID 查找餐次的全部评论及作者，按时间顺序
+func (r *mealCommentRepository) FindByMealRecordID(ctx context.Context, mealRecordID string) ([]*model.MealComment, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var comments []*model.MealComment
+	err := r.db.WithContext(ctx).Preload("Author", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("meal_record_id = ?", mealRecordID).
+		Order("created_at").
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// Delete 删除评论
+func (r *mealCommentRepository) Delete(ctx context.Context, id string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.MealComment{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的删除了评论
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeMealCommentNotFound, "评论不存在")
+	}
+
+	return nil
+}
@@ -6,7 +6,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
 	"gorm.io/gorm"
 )
 
@@ -15,9 +17,38 @@ type MealRecordRepository interface {
 	Create(ctx context.Context, mealRecord *model.MealRecord) error
 	FindByID(ctx context.Context, id string) (*model.MealRecord, error)
 	FindByUserIDAndDate(ctx context.Context, userID string, date time.Time) ([]*model.MealRecord, error)
+	FindByUserIDInRange(ctx context.Context, userID string, from time.Time, to time.Time) ([]*model.MealRecord, error)
+	List(ctx context.Context, userID string, q *query.Query) (*query.Page[model.MealRecord], error)
 	FindByUserIDDateAndType(ctx context.Context, userID string, date time.Time, mealType model.MealType) (*model.MealRecord, error)
 	Update(ctx context.Context, mealRecord *model.MealRecord) error
 	Delete(ctx context.Context, id string) error
+	CreateWithFoods(ctx context.Context, portions []*MealPortion) error
+}
+
+// MealPortion 共享餐次中一个成员的餐次记录和分到的食物记录
+type MealPortion struct {
+	Meal  *model.MealRecord   `json:"meal"` // ID 为空时创建新的餐次记录
+	Foods []*model.FoodRecord `json:"foods"`
+}
+
+// MealRecordQuery 餐次记录列表允许的过滤和排序字段
+var MealRecordQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"date":       {Column: "meal_records.date", Type: query.Date, Sortable: true},
+		"meal_type":  {Column: "meal_records.meal_type", Type: query.Number, Sortable: true, Values: mealTypeValues()},
+		"created_at": {Column: "meal_records.created_at", Type: query.Time, Sortable: true},
+	},
+	Sort: "-date,meal_type",
+	ID:   "meal_records.id",
+}
+
+// mealTypeValues 过滤条件中的餐次名称到数据库值的映射
+func mealTypeValues() map[string]interface{} {
+	values := make(map[string]interface{}, len(model.MealTypeValues))
+	for name, value := range model.MealTypeValues {
+		values[name] = value
+	}
+	return values
 }
 
 // mealRecordRepository 餐次记录仓库实现
@@ -51,7 +82,7 @@ func (r *mealRecordRepository) FindByID(ctx context.Context, id string) (*model.
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&mealRecord).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("餐次记录不存在")
+			return nil, apperror.NotFound(apperror.CodeMealRecordNotFound, "餐次记录不存在")
 		}
 		return nil, err
 	}
@@ -65,11 +96,29 @@ func (r *mealRecordRepository) FindByUserIDAndDate(ctx context.Context, userID s
 		return nil, errors.New("repository 未初始化")
 	}
 
-	// 格式化日期为 YYYY-MM-DD 格式
-	dateStr := date.Format("2006-01-02")
+	// 计算当天的时间范围
+	start, end := dayRange(date)
+
+	var mealRecords []*model.MealRecord
+	err := r.db.WithContext(ctx).Where("user_id = ? AND date >= ? AND date < ?", userID, start, end).Order("meal_type").Find(&mealRecords).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return mealRecords, nil
+}
+
+// FindByUserIDInRange 查找用户在 [from, to] 日期范围内的餐次记录
+func (r *mealRecordRepository) FindByUserIDInRange(ctx context.Context, userID string, from time.Time, to time.Time) ([]*model.MealRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	start, _ := dayRange(from)
+	_, end := dayRange(to)
 
 	var mealRecords []*model.MealRecord
-	err := r.db.WithContext(ctx).Where("user_id = ? AND DATE(date) = ?", userID, dateStr).Order("meal_type").Find(&mealRecords).Error
+	err := r.db.WithContext(ctx).Where("user_id = ? AND date >= ? AND date < ?", userID, start, end).Order("date, meal_type, created_at").Find(&mealRecords).Error
 	if err != nil {
 		return nil, err
 	}
@@ -77,20 +126,29 @@ func (r *mealRecordRepository) FindByUserIDAndDate(ctx context.Context, userID s
 	return mealRecords, nil
 }
 
+// List 按过滤条件分页查询用户的餐次记录
+func (r *mealRecordRepository) List(ctx context.Context, userID string, q *query.Query) (*query.Page[model.MealRecord], error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+	db := r.db.WithContext(ctx).Model(&model.MealRecord{}).Where("meal_records.user_id = ?", userID)
+	return query.Find[model.MealRecord](db, q)
+}
+
 // FindByUserIDDateAndType 根据用户ID、日期和餐次类型查找餐次记录
 func (r *mealRecordRepository) FindByUserIDDateAndType(ctx context.Context, userID string, date time.Time, mealType model.MealType) (*model.MealRecord, error) {
 	if r == nil || r.db == nil {
 		return nil, errors.New("repository 未初始化")
 	}
 
-	// 格式化日期为 YYYY-MM-DD 格式
-	dateStr := date.Format("2006-01-02")
+	// 计算当天的时间范围
+	start, end := dayRange(date)
 
 	var mealRecord model.MealRecord
-	err := r.db.WithContext(ctx).Where("user_id = ? AND DATE(date) = ? AND meal_type = ?", userID, dateStr, mealType).First(&mealRecord).Error
+	err := r.db.WithContext(ctx).Where("user_id = ? AND date >= ? AND date < ? AND meal_type = ?", userID, start, end, mealType).First(&mealRecord).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("餐次记录不存在")
+			return nil, apperror.NotFound(apperror.CodeMealRecordNotFound, "餐次记录不存在")
 		}
 		return nil, err
 	}
@@ -111,7 +169,7 @@ func (r *mealRecordRepository) Update(ctx context.Context, mealRecord *model.Mea
 
 	// 检查是否真的更新了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要更新的餐次记录")
+		return apperror.NotFound(apperror.CodeMealRecordNotFound, "没有找到要更新的餐次记录")
 	}
 
 	return nil
@@ -131,8 +189,32 @@ func (r *mealRecordRepository) Delete(ctx context.Context, id string) error {
 
 	// 检查是否真的删除了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要删除的餐次记录")
+		return apperror.NotFound(apperror.CodeMealRecordNotFound, "没有找到要删除的餐次记录")
 	}
 
 	return nil
 }
+
+// CreateWithFoods 在一个事务中为每个成员创建（或沿用）餐次记录并写入食物记录
+func (r *mealRecordRepository) CreateWithFoods(ctx context.Context, portions []*MealPortion) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, p := range portions {
+			if p.Meal.ID == "" {
+				if err := tx.Create(p.Meal).Error; err != nil {
+					return err
+				}
+			}
+			for _, f := range p.Foods {
+				f.MealRecordID = p.Meal.ID
+			}
+			if err := tx.Create(p.Foods).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
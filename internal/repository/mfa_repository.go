@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrTOTPStepUsed 验证码所在的时间步已经使用过（重放）
+var ErrTOTPStepUsed = errors.New("验证码已使用")
+
+// ErrRecoveryCodeInvalid 恢复码不存在或已使用
+var ErrRecoveryCodeInvalid = errors.New("恢复码无效")
+
+// MFARepository 两步验证仓库接口：用户的 TOTP 密钥和恢复码
+type MFARepository interface {
+	SetSecret(ctx context.Context, userID string, secret string) error
+	Enable(ctx context.Context, userID string, step int64, now time.Time, codes []*model.MFARecoveryCode) error
+	Disable(ctx context.Context, userID string) error
+	UseStep(ctx context.Context, userID string, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*model.MFARecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string, now time.Time) error
+	CountRecoveryCodes(ctx context.Context, userID string) (int64, error)
+}
+
+// mfaRepository 两步验证仓库实现
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository 创建两步验证仓库实例
+func NewMFARepository(db *gorm.DB) MFARepository {
+	if db == nil {
+		log.Fatal("❌ NewMFARepository: db 参数为 nil")
+	}
+	return &mfaRepository{db: db}
+}
+
+// SetSecret 保存待确认的密钥，开启前可以重复生成
+func (r *mfaRepository) SetSecret(ctx context.Context, userID string, secret string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.updateUser(r.db.WithContext(ctx), userID, map[string]interface{}{"totp_secret": secret})
+}
+
+// Enable 开启两步验证并保存恢复码
+func (r *mfaRepository) Enable(ctx context.Context, userID string, step int64, now time.Time, codes []*model.MFARecoveryCode) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := r.updateUser(tx, userID, map[string]interface{}{
+			"totp_enabled_at": now,
+			"totp_last_step":  step,
+		})
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// Disable 关闭两步验证，清除密钥和恢复码
+func (r *mfaRepository) Disable(ctx context.Context, userID string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := r.updateUser(tx, userID, map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error
+	})
+}
+
+// UseStep 记录已使用的时间步，只有比上次更新的步数才能使用
+func (r *mfaRepository) UseStep(ctx context.Context, userID string, step int64) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	// 条件更新保证同一个验证码只能使用一次
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTOTPStepUsed
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes 用新的恢复码替换全部旧恢复码
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*model.MFARecoveryCode) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseRecoveryCode 使用一个恢复码
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string, now time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecoveryCodeInvalid
+	}
+	return nil
+}
+
+// CountRecoveryCodes 统计未使用的恢复码
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("repository 未初始化")
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// updateUser 更新用户的两步验证字段
+func (r *mfaRepository) updateUser(db *gorm.DB, userID string, fields map[string]interface{}) error {
+	result := db.Model(&model.User{}).Where("id = ?", userID).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeUserNotFound, "没有找到要更新的用户")
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 在事务中删除旧恢复码并写入新恢复码
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codes []*model.MFARecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
 	"gorm.io/gorm"
 )
@@ -43,7 +44,7 @@ func (r *nutritionGoalRepository) FindByUserID(ctx context.Context, userID strin
 	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&goal).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("营养目标不存在")
+			return nil, apperror.NotFound(apperror.CodeNutritionGoalNotFound, "营养目标不存在")
 		}
 		return nil, err
 	}
@@ -60,7 +61,7 @@ func (r *nutritionGoalRepository) Update(ctx context.Context, goal *model.Nutrit
 
 	// 检查是否真的更新了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要更新的营养目标")
+		return apperror.NotFound(apperror.CodeNutritionGoalNotFound, "没有找到要更新的营养目标")
 	}
 
 	return nil
@@ -75,7 +76,7 @@ func (r *nutritionGoalRepository) Delete(ctx context.Context, id string) error {
 
 	// 检查是否真的删除了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要删除的营养目标")
+		return apperror.NotFound(apperror.CodeNutritionGoalNotFound, "没有找到要删除的营养目标")
 	}
 
 	return nil
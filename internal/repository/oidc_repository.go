@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrOIDCStateNotFound 登录状态不存在、已使用或已过期
+var ErrOIDCStateNotFound = errors.New("登录状态不存在")
+
+// ErrIdentityNotFound 外部身份没有关联用户
+var ErrIdentityNotFound = errors.New("外部身份不存在")
+
+// OIDCRepository 第三方登录仓库接口：登录状态和外部身份
+type OIDCRepository interface {
+	CreateState(ctx context.Context, state *model.OIDCState) error
+	ConsumeState(ctx context.Context, provider string, stateHash string, now time.Time) (*model.OIDCState, error)
+	DeleteExpiredStates(ctx context.Context, now time.Time) (int64, error)
+	FindIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *model.UserIdentity) error
+	CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.UserIdentity) error
+	TouchIdentity(ctx context.Context, id string, now time.Time) error
+}
+
+// oidcRepository 第三方登录仓库实现
+type oidcRepository struct {
+	db *gorm.DB
+}
+
+// NewOIDCRepository 创建第三方登录仓库实例
+func NewOIDCRepository(db *gorm.DB) OIDCRepository {
+	if db == nil {
+		log.Fatal("❌ NewOIDCRepository: db 参数为 nil")
+	}
+	return &oidcRepository{db: db}
+}
+
+// CreateState 保存进行中的登录状态
+func (r *oidcRepository) CreateState(ctx context.Context, state *model.OIDCState) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// ConsumeState 取出并删除登录状态，保证每个 state 只能回调一次
+func (r *oidcRepository) ConsumeState(ctx context.Context, provider string, stateHash string, now time.Time) (*model.OIDCState, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var state model.OIDCState
+	err := r.db.WithContext(ctx).Where("state_hash = ? AND provider = ?", stateHash, provider).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOIDCStateNotFound
+		}
+		return nil, err
+	}
+
+	// 删除成功的请求才能使用该状态，并发回调中只有一个会成功
+	result := r.db.WithContext(ctx).Where("state_hash = ?", stateHash).Delete(&model.OIDCState{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || !now.Before(state.ExpiresAt) {
+		return nil, ErrOIDCStateNotFound
+	}
+	return &state, nil
+}
+
+// DeleteExpiredStates 删除已过期的登录状态
+func (r *oidcRepository) DeleteExpiredStates(ctx context.Context, now time.Time) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("repository 未初始化")
+	}
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&model.OIDCState{})
+	return result.RowsAffected, result.Error
+}
+
+// FindIdentity 按提供方和外部用户标识查找身份
+func (r *oidcRepository) FindIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity 为已有用户关联外部身份
+func (r *oidcRepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// CreateUserWithIdentity 在同一个事务中创建用户和外部身份
+func (r *oidcRepository) CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// TouchIdentity 更新外部身份的最近登录时间
+func (r *oidcRepository) TouchIdentity(ctx context.Context, id string, now time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Model(&model.UserIdentity{}).Where("id = ?", id).Update("last_login_at", now).Error
+}
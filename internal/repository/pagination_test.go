@@ -0,0 +1,237 @@
+package repository_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// walkPages 按 next_cursor 逐页读取，返回全部记录的ID和每页的 total
+func walkPages[T any](t *testing.T, req query.Request, schema *query.Schema, list func(*query.Query) (*query.Page[T], error), id func(*T) string) ([]string, []int64) {
+	t.Helper()
+
+	var (
+		ids    []string
+		totals []int64
+	)
+	for page := 0; ; page++ {
+		if page > 100 {
+			t.Fatal("翻页没有结束")
+		}
+		q, err := schema.Parse(&req)
+		if err != nil {
+			t.Fatalf("解析查询失败: %v", err)
+		}
+		result, err := list(q)
+		if err != nil {
+			t.Fatalf("查询第 %d 页失败: %v", page+1, err)
+		}
+		if len(result.Items) > req.Limit {
+			t.Fatalf("第 %d 页有 %d 条记录，超过 limit %d", page+1, len(result.Items), req.Limit)
+		}
+		for _, item := range result.Items {
+			ids = append(ids, id(item))
+		}
+		totals = append(totals, result.Total)
+		if result.NextCursor == nil {
+			return ids, totals
+		}
+		req.Cursor = *result.NextCursor
+	}
+}
+
+// assertOrder 比较翻页得到的ID顺序
+func assertOrder(t *testing.T, got []string, want []string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("翻页得到 %d 条记录，期望 %d 条", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("第 %d 条记录 = %s，期望 %s\n得到 %v\n期望 %v", i+1, got[i], want[i], got, want)
+		}
+	}
+}
+
+func TestFoodListKeysetPagination(t *testing.T) {
+	db := openForeignKeyDB(t)
+	ctx := context.Background()
+	repo := repository.NewFoodRepository(db)
+
+	// 热量和名称有大量重复，翻页必须靠主键保证不重不漏
+	base := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	var foods []*model.Food
+	for i, item := range []struct {
+		name     string
+		calories float64
+	}{
+		{"米饭", 116}, {"米饭", 116}, {"燕麦", 389}, {"鸡蛋", 144}, {"牛奶", 54}, {"苹果", 52},
+		{"香蕉", 89}, {"米饭", 130}, {"Oat milk", 46}, {"oat bran", 246}, {"豆腐", 81}, {"鸡蛋", 144},
+	} {
+		food := &model.Food{Name: item.name, Calories: item.calories, CreatedAt: base.Add(time.Duration(i%5) * 1500 * time.Microsecond)}
+		if err := db.Create(food).Error; err != nil {
+			t.Fatalf("创建食物失败: %v", err)
+		}
+		foods = append(foods, food)
+	}
+
+	list := func(q *query.Query) (*query.Page[model.Food], error) { return repo.List(ctx, q) }
+	id := func(f *model.Food) string { return f.ID }
+	expect := func(keep func(*model.Food) bool, less func(a, b *model.Food) bool) []string {
+		var want []*model.Food
+		for _, f := range foods {
+			if keep == nil || keep(f) {
+				want = append(want, f)
+			}
+		}
+		sort.Slice(want, func(i, j int) bool { return less(want[i], want[j]) })
+		ids := make([]string, len(want))
+		for i, f := range want {
+			ids[i] = f.ID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		req  query.Request
+		keep func(*model.Food) bool
+		less func(a, b *model.Food) bool
+	}{
+		{
+			name: "默认按名称",
+			req:  query.Request{Limit: 2},
+			less: func(a, b *model.Food) bool {
+				if a.Name != b.Name {
+					return a.Name < b.Name
+				}
+				return a.ID < b.ID
+			},
+		},
+		{
+			name: "热量倒序、名称正序",
+			req:  query.Request{Sort: "-calories,name", Limit: 3},
+			less: func(a, b *model.Food) bool {
+				if a.Calories != b.Calories {
+					return a.Calories > b.Calories
+				}
+				if a.Name != b.Name {
+					return a.Name < b.Name
+				}
+				return a.ID < b.ID
+			},
+		},
+		{
+			name: "创建时间倒序",
+			req:  query.Request{Sort: "-created_at", Limit: 4},
+			less: func(a, b *model.Food) bool {
+				if !a.CreatedAt.Equal(b.CreatedAt) {
+					return a.CreatedAt.After(b.CreatedAt)
+				}
+				return a.ID < b.ID
+			},
+		},
+		{
+			name: "过滤后翻页",
+			req:  query.Request{Filter: []string{"calories>=100", "calories<300"}, Sort: "calories", Limit: 1},
+			keep: func(f *model.Food) bool { return f.Calories >= 100 && f.Calories < 300 },
+			less: func(a, b *model.Food) bool {
+				if a.Calories != b.Calories {
+					return a.Calories < b.Calories
+				}
+				return a.ID < b.ID
+			},
+		},
+		{
+			name: "名称包含（不区分大小写）",
+			req:  query.Request{Filter: []string{"name~OAT"}, Sort: "-name", Limit: 1},
+			keep: func(f *model.Food) bool { return f.Name == "Oat milk" || f.Name == "oat bran" },
+			less: func(a, b *model.Food) bool { return a.Name > b.Name },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := expect(tt.keep, tt.less)
+			got, totals := walkPages(t, tt.req, repository.FoodQuery, list, id)
+			assertOrder(t, got, want)
+			for _, total := range totals {
+				if total != int64(len(want)) {
+					t.Errorf("total = %d，期望 %d", total, len(want))
+				}
+			}
+		})
+	}
+
+	// 游标与排序方式绑定，换了排序的游标无效
+	q, _ := repository.FoodQuery.Parse(&query.Request{Sort: "-calories", Limit: 2})
+	page, err := repo.List(ctx, q)
+	if err != nil || page.NextCursor == nil {
+		t.Fatalf("查询第一页失败: %v", err)
+	}
+	_, err = repository.FoodQuery.Parse(&query.Request{Sort: "calories", Cursor: *page.NextCursor, Limit: 2})
+	if e := apperror.As(err); e == nil || e.Code != apperror.CodeInvalidCursor {
+		t.Errorf("换了排序的游标: err = %v，期望 %s", err, apperror.CodeInvalidCursor)
+	}
+}
+
+func TestMealRecordListKeysetPagination(t *testing.T) {
+	db := openForeignKeyDB(t)
+	ctx := context.Background()
+	repo := repository.NewMealRecordRepository(db)
+
+	const userID, otherID = "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"
+	var meals []*model.MealRecord
+	for day := 1; day <= 4; day++ {
+		for _, mealType := range []model.MealType{model.Dinner, model.Breakfast, model.Lunch} {
+			for _, owner := range []string{userID, otherID} {
+				meal := &model.MealRecord{UserID: owner, Date: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC), MealType: mealType}
+				if err := db.Create(meal).Error; err != nil {
+					t.Fatalf("创建餐次失败: %v", err)
+				}
+				if owner == userID {
+					meals = append(meals, meal)
+				}
+			}
+		}
+	}
+
+	// 默认排序：日期倒序、餐次类型正序
+	sort.Slice(meals, func(i, j int) bool {
+		a, b := meals[i], meals[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.After(b.Date)
+		}
+		return a.MealType < b.MealType
+	})
+	want := make([]string, len(meals))
+	for i, m := range meals {
+		want[i] = m.ID
+	}
+
+	list := func(q *query.Query) (*query.Page[model.MealRecord], error) { return repo.List(ctx, userID, q) }
+	id := func(m *model.MealRecord) string { return m.ID }
+	got, _ := walkPages(t, query.Request{Limit: 5}, repository.MealRecordQuery, list, id)
+	assertOrder(t, got, want)
+
+	// 日期和餐次名称作为过滤条件，游标中的餐次类型按名称保存
+	var lunches []string
+	for _, m := range meals {
+		if m.MealType == model.Lunch && m.Date.Day() >= 2 {
+			lunches = append(lunches, m.ID)
+		}
+	}
+	req := query.Request{Filter: []string{"meal_type=lunch", "date>=2024-01-02"}, Sort: "-date,-meal_type", Limit: 1}
+	got, totals := walkPages(t, req, repository.MealRecordQuery, list, id)
+	assertOrder(t, got, lunches)
+	if totals[0] != int64(len(lunches)) {
+		t.Errorf("total = %d，期望 %d", totals[0], len(lunches))
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedTokenRepository 已撤销访问令牌仓库接口
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// revokedTokenRepository 已撤销访问令牌仓库实现
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository 创建已撤销访问令牌仓库实例
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepository {
+	if db == nil {
+		log.Fatal("❌ NewRevokedTokenRepository: db 参数为 nil")
+	}
+	return &revokedTokenRepository{db: db}
+}
+
+// Revoke 将 jti 加入拒绝名单，重复撤销时忽略
+func (r *revokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	token := &model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// IsRevoked 判断 jti 是否在拒绝名单中
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if r == nil || r.db == nil {
+		return false, errors.New("repository 未初始化")
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteExpired 清理已过期的记录，过期令牌本身已无法通过验证
+func (r *revokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&model.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
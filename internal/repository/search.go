@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+)
+
+// containsPattern 返回不区分大小写的包含匹配模式，配合 LOWER(column) LIKE ? ESCAPE '\' 使用
+func containsPattern(q string) string {
+	return query.ContainsPattern(q)
+}
+
+// stringValues 取值与数据库中相同的枚举字段，作为 query.Field 的 Values
+func stringValues(values ...string) map[string]interface{} {
+	m := make(map[string]interface{}, len(values))
+	for _, v := range values {
+		m[v] = v
+	}
+	return m
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenUsed 刷新令牌已被使用过（并发刷新或令牌被盗用）
+var ErrRefreshTokenUsed = errors.New("刷新令牌已被使用")
+
+// SessionRepository 登录会话仓库接口
+type SessionRepository interface {
+	Create(ctx context.Context, session *model.Session, token *model.RefreshToken) error
+	FindByID(ctx context.Context, id string) (*model.Session, error)
+	FindActiveByUserID(ctx context.Context, userID string, now time.Time) ([]*model.Session, error)
+	FindTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	Rotate(ctx context.Context, oldToken *model.RefreshToken, newToken *model.RefreshToken, now time.Time) error
+	SetAccessToken(ctx context.Context, id string, jti string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id string, now time.Time) error
+}
+
+// sessionRepository 登录会话仓库实现
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository 创建登录会话仓库实例
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	if db == nil {
+		log.Fatal("❌ NewSessionRepository: db 参数为 nil")
+	}
+	return &sessionRepository{db: db}
+}
+
+// Create 创建会话及其第一个刷新令牌
+func (r *sessionRepository) Create(ctx context.Context, session *model.Session, token *model.RefreshToken) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+// FindByID 根据ID查找会话
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (*model.Session, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeSessionNotFound, "会话不存在")
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindActiveByUserID 查找用户未撤销且未过期的会话，最近使用的排在前面
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID string, now time.Time) ([]*model.Session, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var sessions []*model.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// FindTokenByHash 根据摘要查找刷新令牌
+func (r *sessionRepository) FindTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var token model.RefreshToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "刷新令牌无效或已过期")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Rotate 将旧刷新令牌标记为已使用、保存新令牌并顺延会话有效期
+// 旧令牌已被使用时返回 ErrRefreshTokenUsed，两个并发刷新只有一个能成功
+func (r *sessionRepository) Rotate(ctx context.Context, oldToken *model.RefreshToken, newToken *model.RefreshToken, now time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", oldToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenUsed
+		}
+
+		newToken.SessionID = oldToken.SessionID
+		if err := tx.Create(newToken).Error; err != nil {
+			return err
+		}
+
+		// 会话有效期随刷新顺延
+		return tx.Model(&model.Session{}).Where("id = ?", oldToken.SessionID).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"expires_at":   newToken.ExpiresAt,
+		}).Error
+	})
+}
+
+// SetAccessToken 记录会话最近签发的访问令牌
+func (r *sessionRepository) SetAccessToken(ctx context.Context, id string, jti string, expiresAt time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Model(&model.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"access_token_id":   jti,
+		"access_expires_at": expiresAt,
+	}).Error
+}
+
+// Revoke 撤销会话，会话下的所有刷新令牌随之失效
+func (r *sessionRepository) Revoke(ctx context.Context, id string, now time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的撤销了会话
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeSessionNotFound, "没有找到要撤销的会话")
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/audit"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// UserDataRepository 用户全部数据的导出和清除
+type UserDataRepository interface {
+	Load(ctx context.Context, userID string) (*model.UserData, error)
+	FindDeletedBefore(ctx context.Context, before time.Time) ([]string, error)
+	Purge(ctx context.Context, userID string) error
+}
+
+// userDataRepository 用户数据仓库实现
+type userDataRepository struct {
+	db *gorm.DB
+}
+
+// NewUserDataRepository 创建用户数据仓库实例
+func NewUserDataRepository(db *gorm.DB) UserDataRepository {
+	if db == nil {
+		log.Fatal("❌ NewUserDataRepository: db 参数为 nil")
+	}
+	return &userDataRepository{db: db}
+}
+
+// Load 读取用户的全部数据，各类记录按时间排序
+func (r *userDataRepository) Load(ctx context.Context, userID string) (*model.UserData, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	db := r.db.WithContext(ctx)
+	data := &model.UserData{ExportedAt: time.Now()}
+
+	var user model.User
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeUserNotFound, "用户不存在")
+		}
+		return nil, err
+	}
+	data.User = &user
+
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.NutritionGoals).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("date, meal_type").Find(&data.MealRecords).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("meal_record_id IN (?)", mealIDsOf(db, userID)).Order("created_at").Find(&data.FoodRecords).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("date, created_at").Find(&data.ExerciseRecords).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("recorded_at").Find(&data.WaterRecords).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Sessions).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Identities).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.APIKeys).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("coach_id = ? OR client_id = ?", userID, userID).Order("created_at").Find(&data.CoachClients).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("coach_id = ? OR email = ?", userID, strings.ToLower(user.Email)).Order("created_at").
+		Find(&data.CoachInvitations).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("meal_record_id IN (?) OR author_id = ?", mealIDsOf(db, userID), userID).Order("created_at").
+		Find(&data.MealComments).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("client_id = ?", userID).Order("created_at").Find(&data.CoachAccessLogs).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.HouseholdMembers).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("id IN (?)", db.Model(&model.HouseholdMember{}).Select("household_id").Where("user_id = ?", userID)).
+		Order("created_at").Find(&data.Households).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("email = ? OR invited_by = ?", strings.ToLower(user.Email), userID).Order("created_at").
+		Find(&data.HouseholdInvitations).Error; err != nil {
+		return nil, err
+	}
+
+	// 登录和两步验证的记录，摘要和密钥不导出（模型中不序列化）
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.LoginAttempts).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.MFARecoveryCodes).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.UserTokens).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("session_id IN (?)", db.Model(&model.Session{}).Select("id").Where("user_id = ?", userID)).
+		Order("created_at").Find(&data.RefreshTokens).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("owner_id = ? OR actor_id = ?", userID, userID).Order("created_at").Find(&data.AuditLogs).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FindDeletedBefore 查找在指定时间之前注销（软删除）的用户ID
+func (r *userDataRepository) FindDeletedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var ids []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Purge 在一个事务中永久删除用户及其全部数据
+// 审计日志保存了完整的行数据，与用户数据有关的审计日志一并删除，清除本身也不记录审计日志
+func (r *userDataRepository) Purge(ctx context.Context, userID string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(audit.WithoutAudit(ctx)).Transaction(func(tx *gorm.DB) error {
+		if err := purgeAuditLogs(tx, userID); err != nil {
+			return err
+		}
+
+		// 先删除引用其他表的记录
+		if err := tx.Where("meal_record_id IN (?)", mealIDsOf(tx, userID)).Delete(&model.FoodRecord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("meal_record_id IN (?) OR author_id = ?", mealIDsOf(tx, userID), userID).
+			Delete(&model.MealComment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("session_id IN (?)", tx.Model(&model.Session{}).Select("id").Where("user_id = ?", userID)).
+			Delete(&model.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		// 发给用户邮箱的和用户发出的家庭邀请、教练邀请
+		var emails []string
+		if err := tx.Unscoped().Model(&model.User{}).Where("id = ?", userID).Pluck("email", &emails).Error; err != nil {
+			return err
+		}
+		for _, email := range emails {
+			if err := tx.Where("email = ?", strings.ToLower(email)).Delete(&model.HouseholdInvitation{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("email = ?", strings.ToLower(email)).Delete(&model.CoachInvitation{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("invited_by = ?", userID).Delete(&model.HouseholdInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("coach_id = ?", userID).Delete(&model.CoachInvitation{}).Error; err != nil {
+			return err
+		}
+
+		// 退出家庭，最后一个成员离开后删除家庭及其邀请
+		var householdIDs []string
+		if err := tx.Model(&model.HouseholdMember{}).Where("user_id = ?", userID).Pluck("household_id", &householdIDs).Error; err != nil {
+			return err
+		}
+
+		for _, table := range []interface{}{
+			&model.MealRecord{},
+			&model.NutritionGoal{},
+			&model.ExerciseRecord{},
+			&model.WaterRecord{},
+			&model.Session{},
+			&model.UserToken{},
+			&model.MFARecoveryCode{},
+			&model.LoginAttempt{},
+			&model.UserIdentity{},
+			&model.APIKey{},
+			&model.HouseholdMember{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(table).Error; err != nil {
+				return err
+			}
+		}
+
+		// 教练关系和访问记录，无论用户是教练还是客户
+		for _, table := range []interface{}{&model.CoachClient{}, &model.CoachAccessLog{}} {
+			if err := tx.Where("coach_id = ? OR client_id = ?", userID, userID).Delete(table).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(householdIDs) > 0 {
+			err := tx.Where("id IN ? AND NOT EXISTS (?)", householdIDs,
+				tx.Model(&model.HouseholdMember{}).Select("1").Where("household_members.household_id = households.id")).
+				Delete(&model.Household{}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Where("household_id IN ? AND household_id NOT IN (?)", householdIDs, tx.Model(&model.Household{}).Select("id")).
+				Delete(&model.HouseholdInvitation{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Where("id = ?", userID).Delete(&model.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.NotFound(apperror.CodeUserNotFound, "没有找到要删除的用户")
+		}
+		return nil
+	})
+}
+
+// purgeAuditLogs 删除属于用户数据（用户本人、目标、餐次和食物记录，包括已经删除的记录）的审计日志；
+// 用户操作其他数据（公共食物、客户的记录等）留下的审计日志只清除操作者
+func purgeAuditLogs(tx *gorm.DB, userID string) error {
+	if err := tx.Where("owner_id = ?", userID).Delete(&model.AuditLog{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.AuditLog{}).Where("actor_id = ?", userID).Update("actor_id", "").Error
+}
+
+// mealIDsOf 返回用户餐次ID的子查询
+func mealIDsOf(db *gorm.DB, userID string) *gorm.DB {
+	return db.Model(&model.MealRecord{}).Select("id").Where("user_id = ?", userID)
+}
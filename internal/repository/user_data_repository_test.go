@@ -0,0 +1,207 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/audit"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/pkg/database"
+	"gorm.io/gorm"
+)
+
+// openAuditedDB 创建开启外键检查和审计回调的 SQLite 数据库
+func openAuditedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := openForeignKeyDB(t)
+	if err := audit.Register(db); err != nil {
+		t.Fatalf("注册审计回调失败: %v", err)
+	}
+	return db
+}
+
+// userActivity 用户创建、修改和删除目标、餐次和食物记录，返回用到的全部记录ID
+func userActivity(t *testing.T, db *gorm.DB, user *model.User) []string {
+	t.Helper()
+
+	ctx := audit.WithActor(context.Background(), user.ID)
+	goalRepo := repository.NewNutritionGoalRepository(db)
+	mealRepo := repository.NewMealRecordRepository(db)
+	foodRecordRepo := repository.NewFoodRecordRepository(db)
+	var ids []string
+
+	for i := 0; i < 2; i++ {
+		goal := &model.NutritionGoal{UserID: user.ID, Calories: 2000}
+		if err := goalRepo.Create(ctx, goal); err != nil {
+			t.Fatalf("创建营养目标失败: %v", err)
+		}
+		goal.Calories = 1800
+		if err := goalRepo.Update(ctx, goal); err != nil {
+			t.Fatalf("修改营养目标失败: %v", err)
+		}
+		ids = append(ids, goal.ID)
+		if i == 0 {
+			if err := goalRepo.Delete(ctx, goal.ID); err != nil {
+				t.Fatalf("删除营养目标失败: %v", err)
+			}
+		}
+	}
+
+	for i, mealType := range []model.MealType{model.Breakfast, model.Lunch} {
+		meal := &model.MealRecord{UserID: user.ID, Date: time.Now(), MealType: mealType}
+		if err := mealRepo.Create(ctx, meal); err != nil {
+			t.Fatalf("创建餐次失败: %v", err)
+		}
+		ids = append(ids, meal.ID)
+
+		for j := 0; j < 2; j++ {
+			record := &model.FoodRecord{MealRecordID: meal.ID, FoodName: "米饭", Quantity: 100, Unit: "g", Calories: 116}
+			if err := foodRecordRepo.Create(ctx, record); err != nil {
+				t.Fatalf("创建食物记录失败: %v", err)
+			}
+			ids = append(ids, record.ID)
+			if j == 0 {
+				if err := foodRecordRepo.Delete(ctx, record.ID); err != nil {
+					t.Fatalf("删除食物记录失败: %v", err)
+				}
+			}
+		}
+
+		// 第一个餐次连同剩下的食物记录一起删除，只剩审计日志
+		if i == 0 {
+			if err := foodRecordRepo.DeleteByMealRecordID(ctx, meal.ID); err != nil {
+				t.Fatalf("删除餐次的食物记录失败: %v", err)
+			}
+			if err := mealRepo.Delete(ctx, meal.ID); err != nil {
+				t.Fatalf("删除餐次失败: %v", err)
+			}
+		}
+	}
+	return ids
+}
+
+// countAuditLogs 统计提到 userID 或 entityIDs 的审计日志
+func countAuditLogs(t *testing.T, db *gorm.DB, userID string, entityIDs []string) int64 {
+	t.Helper()
+
+	var count int64
+	err := db.Model(&model.AuditLog{}).
+		Where("entity_id = ? OR entity_id IN ? OR owner_id = ? OR actor_id = ? OR changes LIKE ?",
+			userID, entityIDs, userID, userID, "%"+userID+"%").
+		Count(&count).Error
+	if err != nil {
+		t.Fatalf("查询审计日志失败: %v", err)
+	}
+	return count
+}
+
+func TestPurgeRemovesAuditHistory(t *testing.T) {
+	db := openAuditedDB(t)
+	ctx := context.Background()
+	userRepo := repository.NewUserRepository(db)
+
+	purged := &model.User{Email: "purged@example.com", PasswordHash: "x", Role: model.RoleUser}
+	kept := &model.User{Email: "kept@example.com", PasswordHash: "x", Role: model.RoleUser}
+	for _, user := range []*model.User{purged, kept} {
+		if err := userRepo.Create(audit.WithActor(ctx, user.ID), user); err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+	}
+	purgedIDs := userActivity(t, db, purged)
+	keptIDs := userActivity(t, db, kept)
+
+	// 被清除的用户修改公共食物，日志保留但清空操作用户
+	food := &model.Food{Name: "燕麦", Calories: 389}
+	if err := db.Create(food).Error; err != nil {
+		t.Fatalf("创建食物失败: %v", err)
+	}
+	if err := db.WithContext(audit.WithActor(ctx, purged.ID)).Model(food).Update("calories", 380).Error; err != nil {
+		t.Fatalf("修改食物失败: %v", err)
+	}
+
+	if n := countAuditLogs(t, db, purged.ID, purgedIDs); n == 0 {
+		t.Fatal("清除之前没有审计日志，测试数据无效")
+	}
+	keptBefore := countAuditLogs(t, db, kept.ID, keptIDs)
+
+	if err := repository.NewUserDataRepository(db).Purge(ctx, purged.ID); err != nil {
+		t.Fatalf("清除用户失败: %v", err)
+	}
+
+	if n := countAuditLogs(t, db, purged.ID, purgedIDs); n != 0 {
+		t.Errorf("清除后仍有 %d 条审计日志提到该用户或其记录", n)
+	}
+	if n := countAuditLogs(t, db, kept.ID, keptIDs); n != keptBefore {
+		t.Errorf("其他用户的审计日志 = %d 条，期望 %d 条", n, keptBefore)
+	}
+	var foodLogs int64
+	if err := db.Model(&model.AuditLog{}).Where("entity_type = ? AND entity_id = ?", "foods", food.ID).Count(&foodLogs).Error; err != nil {
+		t.Fatalf("查询审计日志失败: %v", err)
+	}
+	if foodLogs != 2 {
+		t.Errorf("公共食物的审计日志 = %d 条，期望保留 2 条", foodLogs)
+	}
+}
+
+func TestAuditLogOwnerBackfill(t *testing.T) {
+	db := openForeignKeyDB(t)
+	_, record := seedFoodRecord(t, db)
+	var meal model.MealRecord
+	if err := db.First(&meal, "id = ?", record.MealRecordID).Error; err != nil {
+		t.Fatalf("查询餐次失败: %v", err)
+	}
+	ownerID := meal.UserID
+
+	// 回滚 0018，按之前的格式写入没有 owner_id 的审计日志
+	migrator, err := database.NewMigrator()
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+	for {
+		m, err := migrator.Down(context.Background())
+		if err != nil {
+			t.Fatalf("回滚迁移失败: %v", err)
+		}
+		if m == nil || m.Version == 18 {
+			break
+		}
+	}
+
+	const (
+		deletedGoal = "goal-deleted"
+		deletedMeal = "meal-deleted"
+	)
+	logs := []struct{ id, entityType, entityID, changes, want string }{
+		{"log-user", "users", ownerID, `{"email":{"new":"a@example.com"}}`, ownerID},
+		{"log-goal-create", "nutrition_goals", deletedGoal, `{"calories":{"new":2000},"user_id":{"new":"` + ownerID + `"}}`, ownerID},
+		{"log-goal-update", "nutrition_goals", deletedGoal, `{"calories":{"old":2000,"new":1800}}`, ownerID},
+		{"log-meal-delete", "meal_records", deletedMeal, `{"meal_type":{"old":2},"user_id":{"old":"` + ownerID + `"}}`, ownerID},
+		{"log-food-record-deleted-meal", "food_records", "record-deleted", `{"meal_record_id":{"new":"` + deletedMeal + `"}}`, ownerID},
+		{"log-food-record-update", "food_records", record.ID, `{"quantity":{"old":50,"new":80}}`, ownerID},
+		{"log-meal-update", "meal_records", meal.ID, `{"notes":{"old":"","new":"加餐"}}`, ownerID},
+		{"log-food", "foods", "food-id", `{"calories":{"old":389,"new":380}}`, ""},
+	}
+	for _, l := range logs {
+		err := db.Exec("INSERT INTO audit_logs (id, entity_type, entity_id, action, changes, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			l.id, l.entityType, l.entityID, model.AuditUpdate, l.changes, time.Now()).Error
+		if err != nil {
+			t.Fatalf("写入审计日志失败: %v", err)
+		}
+	}
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	for _, l := range logs {
+		var entry model.AuditLog
+		if err := db.First(&entry, "id = ?", l.id).Error; err != nil {
+			t.Fatalf("查询审计日志失败: %v", err)
+		}
+		if entry.OwnerID != l.want {
+			t.Errorf("%s: owner_id = %q，期望 %q", l.id, entry.OwnerID, l.want)
+		}
+	}
+}
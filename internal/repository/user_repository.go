@@ -5,7 +5,9 @@ import (
 	"errors"
 	"log"
 
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +17,26 @@ type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id string) error
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	List(ctx context.Context, filter UserFilter, q *query.Query) (*query.Page[model.User], error)
+}
+
+// UserFilter 管理员查询用户时 filter 参数无法表达的条件
+type UserFilter struct {
+	Query    string // 按邮箱或昵称模糊匹配
+	Disabled *bool  // 为空表示不限
+}
+
+// UserQuery 管理员用户列表允许的过滤和排序字段
+var UserQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"email":      {Column: "users.email", Type: query.String, Sortable: true},
+		"nickname":   {Column: "users.nickname", Type: query.String, Sortable: true},
+		"role":       {Column: "users.role", Type: query.String, Values: stringValues(model.RoleUser, model.RoleCoach, model.RoleAdmin)},
+		"created_at": {Column: "users.created_at", Type: query.Time, Sortable: true},
+	},
+	Sort: "-created_at",
+	ID:   "users.id",
 }
 
 type userRepository struct {
@@ -42,7 +64,7 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (*model.User,
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, apperror.NotFound(apperror.CodeUserNotFound, "用户不存在")
 		}
 		return nil, err
 	}
@@ -59,7 +81,7 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.
 	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, apperror.NotFound(apperror.CodeUserNotFound, "用户不存在")
 		}
 		return nil, err
 	}
@@ -76,7 +98,7 @@ func (r *userRepository) Update(ctx context.Context, user *model.User) error {
 
 	// 检查是否真的更新了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要更新的用户")
+		return apperror.NotFound(apperror.CodeUserNotFound, "没有找到要更新的用户")
 	}
 
 	return nil
@@ -91,18 +113,39 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 
 	// 检查是否真的删除了记录
 	if result.RowsAffected == 0 {
-		return errors.New("没有找到要删除的用户")
+		return apperror.NotFound(apperror.CodeUserNotFound, "没有找到要删除的用户")
 	}
 
 	return nil
 }
 
-// 可选：添加其他有用的方法
+// ExistsByEmail 检查邮箱是否已被使用，包括已注销但尚未永久删除的账户
 func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count).Error
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("email = ?", email).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
+
+// List 按条件分页查询用户
+func (r *userRepository) List(ctx context.Context, filter UserFilter, q *query.Query) (*query.Page[model.User], error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	db := r.db.WithContext(ctx).Model(&model.User{})
+	if filter.Query != "" {
+		pattern := containsPattern(filter.Query)
+		db = db.Where(`(LOWER(email) LIKE ? ESCAPE '\' OR LOWER(nickname) LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			db = db.Where("disabled_at IS NOT NULL")
+		} else {
+			db = db.Where("disabled_at IS NULL")
+		}
+	}
+	return query.Find[model.User](db, q)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrUserTokenNotFound 一次性令牌不存在
+var ErrUserTokenNotFound = errors.New("令牌不存在")
+
+// UserTokenRepository 一次性令牌仓库接口
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *model.UserToken) error
+	FindByHash(ctx context.Context, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error)
+	MarkUsed(ctx context.Context, id string, now time.Time) error
+	InvalidateByUserID(ctx context.Context, userID string, purpose model.UserTokenPurpose, now time.Time) error
+}
+
+// userTokenRepository 一次性令牌仓库实现
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository 创建一次性令牌仓库实例
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	if db == nil {
+		log.Fatal("❌ NewUserTokenRepository: db 参数为 nil")
+	}
+	return &userTokenRepository{db: db}
+}
+
+// Create 创建一次性令牌
+func (r *userTokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// FindByHash 根据用途和摘要查找令牌
+func (r *userTokenRepository) FindByHash(ctx context.Context, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var token model.UserToken
+	err := r.db.WithContext(ctx).Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserTokenNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed 将令牌标记为已使用，令牌已被使用时返回 ErrUserTokenNotFound
+func (r *userTokenRepository) MarkUsed(ctx context.Context, id string, now time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserTokenNotFound
+	}
+
+	return nil
+}
+
+// InvalidateByUserID 使用户某种用途的未使用令牌全部失效，重新发送邮件时调用
+func (r *userTokenRepository) InvalidateByUserID(ctx context.Context, userID string, purpose model.UserTokenPurpose, now time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	return r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now).Error
+}
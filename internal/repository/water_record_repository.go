@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"gorm.io/gorm"
+)
+
+// WaterRecordRepository 饮水记录仓库接口
+type WaterRecordRepository interface {
+	Create(ctx context.Context, waterRecord *model.WaterRecord) error
+	FindByID(ctx context.Context, id string) (*model.WaterRecord, error)
+	FindByUserIDAndDate(ctx context.Context, userID string, date time.Time) ([]*model.WaterRecord, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// waterRecordRepository 饮水记录仓库实现
+type waterRecordRepository struct {
+	db *gorm.DB
+}
+
+// NewWaterRecordRepository 创建饮水记录仓库实例
+func NewWaterRecordRepository(db *gorm.DB) WaterRecordRepository {
+	if db == nil {
+		log.Fatal("❌ NewWaterRecordRepository: db 参数为 nil")
+	}
+	return &waterRecordRepository{db: db}
+}
+
+// Create 创建饮水记录
+func (r *waterRecordRepository) Create(ctx context.Context, waterRecord *model.WaterRecord) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+	return r.db.WithContext(ctx).Create(waterRecord).Error
+}
+
+// FindByID 根据ID查找饮水记录
+func (r *waterRecordRepository) FindByID(ctx context.Context, id string) (*model.WaterRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	var waterRecord model.WaterRecord
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&waterRecord).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeWaterRecordNotFound, "饮水记录不存在")
+		}
+		return nil, err
+	}
+
+	return &waterRecord, nil
+}
+
+// FindByUserIDAndDate 根据用户ID和日期查找饮水记录
+func (r *waterRecordRepository) FindByUserIDAndDate(ctx context.Context, userID string, date time.Time) ([]*model.WaterRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("repository 未初始化")
+	}
+
+	// 计算当天的时间范围
+	start, end := dayRange(date)
+
+	var waterRecords []*model.WaterRecord
+	err := r.db.WithContext(ctx).Where("user_id = ? AND date >= ? AND date < ?", userID, start, end).Order("recorded_at").Find(&waterRecords).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return waterRecords, nil
+}
+
+// Delete 删除饮水记录
+func (r *waterRecordRepository) Delete(ctx context.Context, id string) error {
+	if r == nil || r.db == nil {
+		return errors.New("repository 未初始化")
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.WaterRecord{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 检查是否真的删除了记录
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(apperror.CodeWaterRecordNotFound, "没有找到要删除的饮水记录")
+	}
+
+	return nil
+}
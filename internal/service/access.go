@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+)
+
+// AccessChecker 判断一个档案能否读写另一个档案的记录，例如家庭中的成年成员可以代为记录受抚养成员
+type AccessChecker interface {
+	CanAccess(ctx context.Context, subjectID string, ownerID string) (bool, error)
+}
+
+// checkAccess 检查 subjectID 能否访问属于 ownerID 的记录，本人的记录总是可以访问
+func checkAccess(ctx context.Context, access AccessChecker, subjectID string, ownerID string, code string, message string) error {
+	if subjectID == ownerID {
+		return nil
+	}
+	// 教练的授权只覆盖客户本人的记录，不能再经由客户的家庭访问受抚养成员
+	if grant := profileGrantFrom(ctx); grant != nil && grant.Audited {
+		return apperror.Forbidden(code, message)
+	}
+
+	allowed, err := access.CanAccess(ctx, subjectID, ownerID)
+	if err != nil {
+		return apperror.Internal("检查访问权限失败", err)
+	}
+	if !allowed {
+		return apperror.Forbidden(code, message)
+	}
+	return nil
+}
+
+// ProfileGrant 代为操作另一个档案的授权
+type ProfileGrant struct {
+	Scopes  []string // 允许的授权范围，为 nil 时不受限制
+	Audited bool     // 是否需要记录每次访问
+}
+
+// profileGrantKey context 中代为操作授权的键
+type profileGrantKey struct{}
+
+// WithProfileGrant 把代为操作的授权放入 context，供服务层检查访问权限
+func WithProfileGrant(ctx context.Context, grant *ProfileGrant) context.Context {
+	return context.WithValue(ctx, profileGrantKey{}, grant)
+}
+
+// profileGrantFrom 取出 context 中代为操作的授权，没有代为操作时返回 nil
+func profileGrantFrom(ctx context.Context) *ProfileGrant {
+	grant, _ := ctx.Value(profileGrantKey{}).(*ProfileGrant)
+	return grant
+}
+
+// ProfileAuthorizer 判断当前用户能否代为操作另一个档案，并记录需要审计的访问
+type ProfileAuthorizer interface {
+	AuthorizeProfile(ctx context.Context, actorID string, profileID string) (*ProfileGrant, error)
+	RecordAccess(ctx context.Context, entry *model.CoachAccessLog)
+}
+
+// profileAuthorizer 依次检查家庭成员关系和教练授权
+type profileAuthorizer struct {
+	households AccessChecker
+	coaches    CoachService
+}
+
+// NewProfileAuthorizer 创建代为操作档案的授权检查
+func NewProfileAuthorizer(households AccessChecker, coaches CoachService) ProfileAuthorizer {
+	return &profileAuthorizer{
+		households: households,
+		coaches:    coaches,
+	}
+}
+
+// AuthorizeProfile 成年家庭成员可以不受限制地操作受抚养成员的档案；
+// 教练按客户授予的范围操作客户的档案，每次访问都会被记录。没有授权时返回 nil
+func (a *profileAuthorizer) AuthorizeProfile(ctx context.Context, actorID string, profileID string) (*ProfileGrant, error) {
+	allowed, err := a.households.CanAccess(ctx, actorID, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if allowed {
+		return &ProfileGrant{}, nil
+	}
+
+	link, err := a.coaches.ActiveGrant(ctx, actorID, profileID)
+	if err != nil || link == nil {
+		return nil, err
+	}
+	return &ProfileGrant{Scopes: link.Scopes, Audited: true}, nil
+}
+
+// RecordAccess 记录教练的访问
+func (a *profileAuthorizer) RecordAccess(ctx context.Context, entry *model.CoachAccessLog) {
+	a.coaches.RecordAccess(ctx, entry)
+}
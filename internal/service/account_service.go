@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+	"github.com/ljk20041215/nutrition-tracker/internal/mail"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// 一次性令牌有效期
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 24 * time.Hour
+	accountUnlockTTL     = 24 * time.Hour
+)
+
+// AccountService 账户服务接口：修改密码、找回密码、邮箱验证、账户解锁、注销账户
+type AccountService interface {
+	ChangePassword(ctx context.Context, userID string, currentSessionID string, req *ChangePasswordRequest) error
+	ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+	SendVerification(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, req *VerifyEmailRequest) error
+	SendUnlock(ctx context.Context, user *model.User) error
+	Unlock(ctx context.Context, req *UnlockAccountRequest) error
+	DeleteAccount(ctx context.Context, userID string, req *DeleteAccountRequest) (*AccountDeletion, error)
+	PurgeDeleted(ctx context.Context) (int, error)
+}
+
+// accountService 账户服务实现
+type accountService struct {
+	userRepo       repository.UserRepository
+	tokenRepo      repository.UserTokenRepository
+	userDataRepo   repository.UserDataRepository
+	householdRepo  repository.HouseholdRepository
+	sessionService SessionService
+	sender         mail.Sender
+	guard          *loginguard.Guard
+	baseURL        string
+	lockout        time.Duration
+	deletionGrace  time.Duration
+}
+
+// NewAccountService 创建账户服务实例
+// baseURL 用于拼接邮件中的链接，lockout 为登录失败锁定时长（用于解锁邮件），deletionGrace 为注销账户后保留数据的时长
+func NewAccountService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.UserTokenRepository,
+	userDataRepo repository.UserDataRepository,
+	householdRepo repository.HouseholdRepository,
+	sessionService SessionService,
+	sender mail.Sender,
+	guard *loginguard.Guard,
+	baseURL string,
+	lockout time.Duration,
+	deletionGrace time.Duration,
+) AccountService {
+	return &accountService{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		userDataRepo:   userDataRepo,
+		householdRepo:  householdRepo,
+		sessionService: sessionService,
+		sender:         sender,
+		guard:          guard,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		lockout:        lockout,
+		deletionGrace:  deletionGrace,
+	}
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// VerifyEmailRequest 邮箱验证请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// UnlockAccountRequest 账户解锁请求
+type UnlockAccountRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// DeleteAccountRequest 注销账户请求，需要再次输入密码确认
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// AccountDeletion 注销结果
+type AccountDeletion struct {
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"` // 在此之后数据被永久删除
+}
+
+// ChangePassword 修改密码，成功后注销除当前会话外的全部会话
+func (s *accountService) ChangePassword(ctx context.Context, userID string, currentSessionID string, req *ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return apperror.Internal("获取用户失败", err)
+	}
+
+	if !checkPasswordHash(req.CurrentPassword, user.PasswordHash) {
+		return apperror.Validation(apperror.CodeWrongPassword, "当前密码错误")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return apperror.Validation(apperror.CodeSamePassword, "新密码不能与当前密码相同")
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+	return s.sessionService.RevokeAll(ctx, user.ID, currentSessionID)
+}
+
+// ForgotPassword 发送重置密码邮件
+// 无论邮箱是否注册都返回成功，避免通过该接口探测已注册的邮箱
+func (s *accountService) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return apperror.Internal("查询用户失败", err)
+	}
+
+	// 新的重置链接生效后，之前发送的链接全部作废
+	token, err := newUserToken(ctx, s.tokenRepo, user.ID, model.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	locale := userLocale(user)
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, i18n.MailResetSubject, "重置密码"),
+		Body: fmt.Sprintf(i18n.T(locale, i18n.MailResetBody, ""),
+			user.Nickname, int(passwordResetTTL.Minutes()), s.link("/reset-password", token)),
+	}
+	if err := s.sender.Send(ctx, msg); err != nil {
+		// 发送失败同样不能体现在响应里，只记录日志
+		log.Printf("❌ 发送重置密码邮件失败（用户 %s）: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword 使用邮件中的令牌重置密码，成功后注销全部会话
+func (s *accountService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	invalid := apperror.BadRequest(apperror.CodeInvalidResetToken, "重置链接无效或已过期，请重新申请")
+
+	token, err := s.consumeToken(ctx, model.TokenPurposePasswordReset, req.Token, invalid)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return apperror.Internal("获取用户失败", err)
+	}
+
+	// 能收到重置邮件说明邮箱属于该用户
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+
+	// 重置密码同时解除登录失败锁定
+	if err := s.guard.Unlock(ctx, user.Email); err != nil {
+		return apperror.Internal("解除账户锁定失败", err)
+	}
+	return s.sessionService.RevokeAll(ctx, user.ID, "")
+}
+
+// SendVerification 发送邮箱验证邮件，之前发送的验证链接随即失效
+func (s *accountService) SendVerification(ctx context.Context, userID string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return apperror.Internal("获取用户失败", err)
+	}
+	if user.EmailVerifiedAt != nil {
+		return apperror.Conflict(apperror.CodeEmailAlreadyVerified, "邮箱已验证")
+	}
+
+	token, err := newUserToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	locale := userLocale(user)
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, i18n.MailVerifySubject, "请验证你的邮箱"),
+		Body: fmt.Sprintf(i18n.T(locale, i18n.MailVerifyBody, ""),
+			user.Nickname, int(emailVerificationTTL.Hours()), s.link("/verify-email", token)),
+	}
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return apperror.Internal("发送验证邮件失败", err)
+	}
+	return nil
+}
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+func (s *accountService) VerifyEmail(ctx context.Context, req *VerifyEmailRequest) error {
+	invalid := apperror.BadRequest(apperror.CodeInvalidVerificationToken, "验证链接无效或已过期，请重新发送验证邮件")
+
+	token, err := s.consumeToken(ctx, model.TokenPurposeEmailVerification, req.Token, invalid)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return apperror.Internal("获取用户失败", err)
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return apperror.Internal("更新用户失败", err)
+	}
+	return nil
+}
+
+// SendUnlock 账户因登录失败被锁定后发送解锁邮件
+func (s *accountService) SendUnlock(ctx context.Context, user *model.User) error {
+	token, err := newUserToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeAccountUnlock, accountUnlockTTL)
+	if err != nil {
+		return err
+	}
+
+	locale := userLocale(user)
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, i18n.MailUnlockSubject, "账户已被临时锁定"),
+		Body: fmt.Sprintf(i18n.T(locale, i18n.MailUnlockBody, ""),
+			user.Nickname, int(s.lockout.Minutes()), s.link("/unlock-account", token)),
+	}
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return apperror.Internal("发送解锁邮件失败", err)
+	}
+	return nil
+}
+
+// Unlock 使用解锁邮件中的令牌解除登录失败锁定
+func (s *accountService) Unlock(ctx context.Context, req *UnlockAccountRequest) error {
+	invalid := apperror.BadRequest(apperror.CodeInvalidUnlockToken, "解锁链接无效或已过期")
+
+	token, err := s.consumeToken(ctx, model.TokenPurposeAccountUnlock, req.Token, invalid)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return apperror.Internal("获取用户失败", err)
+	}
+	if err := s.guard.Unlock(ctx, user.Email); err != nil {
+		return apperror.Internal("解除账户锁定失败", err)
+	}
+	return nil
+}
+
+// DeleteAccount 注销账户：账户立即停用并注销全部会话，数据在保留期结束后永久删除
+func (s *accountService) DeleteAccount(ctx context.Context, userID string, req *DeleteAccountRequest) (*AccountDeletion, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+	if !checkPasswordHash(req.Password, user.PasswordHash) {
+		return nil, apperror.Validation(apperror.CodeWrongPassword, "当前密码错误")
+	}
+
+	// 软删除，保留期内数据仍在数据库中
+	now := time.Now()
+	if err := s.userRepo.Delete(ctx, user.ID); err != nil {
+		return nil, apperror.Internal("注销账户失败", err)
+	}
+	if err := s.sessionService.RevokeAll(ctx, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	// 家庭中已没有其他成年成员时，受抚养成员的档案一并注销
+	deleted, err := s.householdRepo.DeleteOrphanedDependents(ctx, user.ID)
+	if err != nil {
+		return nil, apperror.Internal("注销受抚养成员档案失败", err)
+	}
+	if deleted > 0 {
+		log.Printf("🏠 用户 %s 注销后，一并注销了 %d 个受抚养成员档案", user.ID, deleted)
+	}
+
+	return &AccountDeletion{
+		DeletedAt:  now,
+		PurgeAfter: now.Add(s.deletionGrace),
+	}, nil
+}
+
+// PurgeDeleted 永久删除保留期已结束的注销账户，返回删除的账户数
+func (s *accountService) PurgeDeleted(ctx context.Context) (int, error) {
+	ids, err := s.userDataRepo.FindDeletedBefore(ctx, time.Now().Add(-s.deletionGrace))
+	if err != nil {
+		return 0, apperror.Internal("查询已注销账户失败", err)
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if err := s.userDataRepo.Purge(ctx, id); err != nil {
+			return purged, apperror.Internal("永久删除账户失败", err)
+		}
+		log.Printf("🗑️ 已永久删除注销账户 %s", id)
+		purged++
+	}
+	return purged, nil
+}
+
+// setPassword 加密并保存新密码
+func (s *accountService) setPassword(ctx context.Context, user *model.User, password string) error {
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return apperror.Internal("加密密码失败", err)
+	}
+	user.PasswordHash = passwordHash
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return apperror.Internal("更新密码失败", err)
+	}
+	return nil
+}
+
+// newUserToken 作废用户同用途的旧令牌并生成新令牌，返回明文
+func newUserToken(ctx context.Context, tokenRepo repository.UserTokenRepository, userID string, purpose model.UserTokenPurpose, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if err := tokenRepo.InvalidateByUserID(ctx, userID, purpose, now); err != nil {
+		return "", apperror.Internal("作废旧令牌失败", err)
+	}
+
+	raw, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return "", apperror.Internal("生成令牌失败", err)
+	}
+	token := &model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: auth.HashToken(raw),
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := tokenRepo.Create(ctx, token); err != nil {
+		return "", apperror.Internal("保存令牌失败", err)
+	}
+	return raw, nil
+}
+
+// consumeToken 校验并标记一次性令牌为已使用，令牌不存在、已过期或已使用时返回 invalid
+func (s *accountService) consumeToken(ctx context.Context, purpose model.UserTokenPurpose, raw string, invalid error) (*model.UserToken, error) {
+	now := time.Now()
+	token, err := s.tokenRepo.FindByHash(ctx, purpose, auth.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, invalid
+		}
+		return nil, apperror.Internal("查询令牌失败", err)
+	}
+	if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		return nil, invalid
+	}
+
+	// 条件更新保证并发请求中只有一个能使用该令牌
+	if err := s.tokenRepo.MarkUsed(ctx, token.ID, now); err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, invalid
+		}
+		return nil, apperror.Internal("更新令牌失败", err)
+	}
+	return token, nil
+}
+
+// link 拼接前端页面链接
+func (s *accountService) link(path string, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// userLocale 邮件使用用户设置的语言，未设置时使用默认语言
+func userLocale(user *model.User) string {
+	if user.Language != "" {
+		return user.Language
+	}
+	return i18n.Default
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// AdminService 管理员服务接口：管理用户账户和公共食物库
+type AdminService interface {
+	ListUsers(ctx context.Context, req *ListUsersRequest) (*query.Page[model.User], error)
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+	UpdateRole(ctx context.Context, adminID string, userID string, req *UpdateRoleRequest) (*model.User, error)
+	DisableUser(ctx context.Context, adminID string, userID string) error
+	EnableUser(ctx context.Context, userID string) error
+	UnlockUser(ctx context.Context, userID string) error
+	DisableUserMFA(ctx context.Context, userID string) error
+	ListFoods(ctx context.Context, req *ListFoodsRequest) (*query.Page[model.Food], error)
+	CreateFood(ctx context.Context, req *FoodRequest) (*model.Food, error)
+	UpdateFood(ctx context.Context, foodID string, req *FoodRequest) (*model.Food, error)
+	DeleteFood(ctx context.Context, foodID string) error
+	ListAuditLogs(ctx context.Context, req *ListAuditLogsRequest) (*query.Page[model.AuditLog], error)
+}
+
+// adminService 管理员服务实现
+type adminService struct {
+	userRepo       repository.UserRepository
+	foodRepo       repository.FoodRepository
+	auditLogRepo   repository.AuditLogRepository
+	sessionService SessionService
+	mfaService     MFAService
+	guard          *loginguard.Guard
+}
+
+// NewAdminService 创建管理员服务实例
+func NewAdminService(
+	userRepo repository.UserRepository,
+	foodRepo repository.FoodRepository,
+	auditLogRepo repository.AuditLogRepository,
+	sessionService SessionService,
+	mfaService MFAService,
+	guard *loginguard.Guard,
+) AdminService {
+	return &adminService{
+		userRepo:       userRepo,
+		foodRepo:       foodRepo,
+		auditLogRepo:   auditLogRepo,
+		sessionService: sessionService,
+		mfaService:     mfaService,
+		guard:          guard,
+	}
+}
+
+// ListUsersRequest 查询用户请求：通用的 filter、sort、cursor、limit 参数，
+// 以及 filter 无法表达的关键字和禁用状态；role 兼容旧接口，转换为 role=<角色> 过滤条件
+type ListUsersRequest struct {
+	query.Request
+	Query    string `form:"q"`
+	Role     string `form:"role" binding:"omitempty,oneof=user coach admin"`
+	Disabled *bool  `form:"disabled"`
+}
+
+// UpdateRoleRequest 修改角色请求
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user coach admin"`
+}
+
+// ListFoodsRequest 查询食物请求，q 兼容旧接口，转换为 name~<关键字> 过滤条件
+type ListFoodsRequest struct {
+	query.Request
+	Query string `form:"q"`
+}
+
+// ListAuditLogsRequest 查询审计日志请求：通用的 filter、sort、cursor、limit 参数；
+// 其余参数兼容旧接口，转换为对应的过滤条件，日期按 UTC 计算，to 当天包含在内
+type ListAuditLogsRequest struct {
+	query.Request
+	EntityType string `form:"entity_type" binding:"omitempty,oneof=users nutrition_goals meal_records food_records foods"`
+	EntityID   string `form:"entity_id"`
+	OwnerID    string `form:"owner_id"`
+	ActorID    string `form:"actor_id"`
+	Action     string `form:"action" binding:"omitempty,oneof=create update delete"`
+	RequestID  string `form:"request_id"`
+	From       string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To         string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// FoodRequest 创建或修改食物请求，营养成分按每 100 克计
+type FoodRequest struct {
+	Name          string  `json:"name" binding:"required,max=100"`
+	Calories      float64 `json:"calories" binding:"min=0"`
+	Protein       float64 `json:"protein" binding:"min=0"`
+	Carbohydrates float64 `json:"carbohydrates" binding:"min=0"`
+	Fat           float64 `json:"fat" binding:"min=0"`
+}
+
+// ListUsers 按邮箱、昵称、角色和状态查询用户，默认按注册时间倒序
+func (s *adminService) ListUsers(ctx context.Context, req *ListUsersRequest) (*query.Page[model.User], error) {
+	q, err := repository.UserQuery.Parse(&req.Request)
+	if err != nil {
+		return nil, err
+	}
+	if req.Role != "" {
+		if err := q.Where("role=" + req.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := s.userRepo.List(ctx, repository.UserFilter{
+		Query:    strings.TrimSpace(req.Query),
+		Disabled: req.Disabled,
+	}, q)
+	if err != nil {
+		return nil, apperror.Internal("查询用户失败", err)
+	}
+	return page, nil
+}
+
+// GetUser 获取用户详情
+func (s *adminService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+	return user, nil
+}
+
+// UpdateRole 修改用户角色，用户的全部会话随即撤销，重新登录后令牌携带新角色
+func (s *adminService) UpdateRole(ctx context.Context, adminID string, userID string, req *UpdateRoleRequest) (*model.User, error) {
+	// 不能修改自己的角色，避免最后一个管理员误操作后无人能管理系统
+	if adminID == userID {
+		return nil, apperror.Forbidden(apperror.CodeCannotModifySelf, "不能修改自己的角色")
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == req.Role {
+		return user, nil
+	}
+
+	user.Role = req.Role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, apperror.Internal("更新用户角色失败", err)
+	}
+	if err := s.sessionService.RevokeAll(ctx, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	log.Printf("👮 管理员 %s 把用户 %s 的角色修改为 %s", adminID, user.ID, user.Role)
+	return user, nil
+}
+
+// DisableUser 禁用账户并撤销全部会话，禁用期间无法登录
+func (s *adminService) DisableUser(ctx context.Context, adminID string, userID string) error {
+	if adminID == userID {
+		return apperror.Forbidden(apperror.CodeCannotModifySelf, "不能禁用自己的账户")
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt == nil {
+		now := time.Now()
+		user.DisabledAt = &now
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return apperror.Internal("禁用账户失败", err)
+		}
+	}
+	if err := s.sessionService.RevokeAll(ctx, user.ID, ""); err != nil {
+		return err
+	}
+
+	log.Printf("👮 管理员 %s 禁用了用户 %s", adminID, user.ID)
+	return nil
+}
+
+// EnableUser 解除账户禁用
+func (s *adminService) EnableUser(ctx context.Context, userID string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt == nil {
+		return nil
+	}
+
+	user.DisabledAt = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return apperror.Internal("启用账户失败", err)
+	}
+	return nil
+}
+
+// UnlockUser 解除因登录失败导致的临时锁定
+func (s *adminService) UnlockUser(ctx context.Context, userID string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.guard.Unlock(ctx, user.Email); err != nil {
+		return apperror.Internal("解锁账户失败", err)
+	}
+	return nil
+}
+
+// DisableUserMFA 强制关闭用户的两步验证（用户丢失验证器和恢复码时）
+func (s *adminService) DisableUserMFA(ctx context.Context, userID string) error {
+	return s.mfaService.ForceDisable(ctx, userID)
+}
+
+// ListFoods 按过滤条件分页查询公共食物库
+func (s *adminService) ListFoods(ctx context.Context, req *ListFoodsRequest) (*query.Page[model.Food], error) {
+	q, err := repository.FoodQuery.Parse(&req.Request)
+	if err != nil {
+		return nil, err
+	}
+	if keyword := strings.TrimSpace(req.Query); keyword != "" {
+		if err := q.Where("name~" + keyword); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := s.foodRepo.List(ctx, q)
+	if err != nil {
+		return nil, apperror.Internal("查询食物失败", err)
+	}
+	return page, nil
+}
+
+// CreateFood 向公共食物库添加食物，名称不能重复
+func (s *adminService) CreateFood(ctx context.Context, req *FoodRequest) (*model.Food, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkFoodName(ctx, name, ""); err != nil {
+		return nil, err
+	}
+
+	food := &model.Food{
+		Name:          name,
+		Calories:      req.Calories,
+		Protein:       req.Protein,
+		Carbohydrates: req.Carbohydrates,
+		Fat:           req.Fat,
+	}
+	if err := s.foodRepo.Create(ctx, food); err != nil {
+		return nil, apperror.Internal("创建食物失败", err)
+	}
+	return food, nil
+}
+
+// UpdateFood 修改食物的名称和营养成分；已有的食物记录保存了当时的营养数据，修改份量时才按新数据重新计算
+func (s *adminService) UpdateFood(ctx context.Context, foodID string, req *FoodRequest) (*model.Food, error) {
+	food, err := s.foodRepo.FindByID(ctx, foodID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("获取食物失败", err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkFoodName(ctx, name, food.ID); err != nil {
+		return nil, err
+	}
+
+	food.Name = name
+	food.Calories = req.Calories
+	food.Protein = req.Protein
+	food.Carbohydrates = req.Carbohydrates
+	food.Fat = req.Fat
+	if err := s.foodRepo.Update(ctx, food); err != nil {
+		return nil, apperror.Internal("更新食物失败", err)
+	}
+	return food, nil
+}
+
+// DeleteFood 从公共食物库删除食物
+// 外键为 ON DELETE SET NULL（迁移 0016），引用该食物的记录 food_id 被置空，保存的食物名称和营养数据仍然可以查看
+func (s *adminService) DeleteFood(ctx context.Context, foodID string) error {
+	if err := s.foodRepo.Delete(ctx, foodID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("删除食物失败", err)
+	}
+	return nil
+}
+
+// checkFoodName 检查食物名称是否已被其他食物使用
+func (s *adminService) checkFoodName(ctx context.Context, name string, exceptID string) error {
+	existing, err := s.foodRepo.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return apperror.Internal("查询食物失败", err)
+	}
+	if existing.ID != exceptID {
+		return apperror.Conflict(apperror.CodeFoodExists, "食物名称已存在")
+	}
+	return nil
+}
+
+// ListAuditLogs 按实体、数据所属用户、操作用户、操作类型、请求ID和日期查询数据变更审计日志，默认按时间倒序
+func (s *adminService) ListAuditLogs(ctx context.Context, req *ListAuditLogsRequest) (*query.Page[model.AuditLog], error) {
+	q, err := repository.AuditLogQuery.Parse(&req.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 旧参数转换为过滤条件，格式已由请求校验保证
+	for _, param := range []struct{ field, op, value string }{
+		{"entity_type", "=", req.EntityType},
+		{"entity_id", "=", strings.TrimSpace(req.EntityID)},
+		{"owner_id", "=", strings.TrimSpace(req.OwnerID)},
+		{"actor_id", "=", strings.TrimSpace(req.ActorID)},
+		{"action", "=", req.Action},
+		{"request_id", "=", strings.TrimSpace(req.RequestID)},
+		{"created_at", ">=", req.From},
+		{"created_at", "<=", req.To},
+	} {
+		if param.value == "" {
+			continue
+		}
+		if err := q.Where(param.field + param.op + param.value); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := s.auditLogRepo.List(ctx, q)
+	if err != nil {
+		return nil, apperror.Internal("查询审计日志失败", err)
+	}
+	return page, nil
+}
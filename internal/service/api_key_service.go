@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+const (
+	// apiKeyPrefix 个人 API 密钥的固定前缀，便于识别和在代码仓库中扫描泄露的密钥
+	apiKeyPrefix = "ntk_"
+	// apiKeyPrefixLen 列表中显示的密钥开头长度
+	apiKeyPrefixLen = 12
+	// maxAPIKeysPerUser 每个用户最多拥有的密钥数量
+	maxAPIKeysPerUser = 20
+	// defaultAPIKeyDays 未指定有效期时的默认天数
+	defaultAPIKeyDays = 90
+	// apiKeyTouchInterval 最近使用时间的更新间隔
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService 个人 API 密钥服务接口
+type APIKeyService interface {
+	Create(ctx context.Context, userID string, req *CreateAPIKeyRequest) (*CreatedAPIKey, error)
+	List(ctx context.Context, userID string) ([]*model.APIKey, error)
+	Revoke(ctx context.Context, userID string, id string) error
+	auth.APIKeyAuthenticator
+}
+
+// apiKeyService 个人 API 密钥服务实现
+type apiKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+}
+
+// NewAPIKeyService 创建个人 API 密钥服务实例
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// CreateAPIKeyRequest 创建 API 密钥请求
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=records:read records:write goals:read goals:write"`
+	// ExpiresInDays 有效天数，默认 90 天，最长 365 天
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// CreatedAPIKey 新创建的密钥，Key 只在此时返回一次
+type CreatedAPIKey struct {
+	*model.APIKey
+	Key string `json:"key"`
+}
+
+// Create 生成新的 API 密钥，数据库中只保存摘要
+func (s *apiKeyService) Create(ctx context.Context, userID string, req *CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	count, err := s.apiKeyRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("查询 API 密钥失败", err)
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, apperror.Conflict(apperror.CodeAPIKeyLimit, "API 密钥数量已达上限")
+	}
+
+	random, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, apperror.Internal("生成 API 密钥失败", err)
+	}
+	key := apiKeyPrefix + random
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPIKeyDays
+	}
+
+	apiKey := &model.APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    key[:apiKeyPrefixLen],
+		KeyHash:   auth.HashToken(key),
+		Scopes:    uniqueScopes(req.Scopes),
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, apperror.Internal("保存 API 密钥失败", err)
+	}
+
+	log.Printf("🔑 用户 %s 创建了 API 密钥 %s (%s)", userID, apiKey.ID, strings.Join(apiKey.Scopes, " "))
+	return &CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// List 列出用户的全部密钥，不包含密钥本身
+func (s *apiKeyService) List(ctx context.Context, userID string) ([]*model.APIKey, error) {
+	keys, err := s.apiKeyRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("获取 API 密钥失败", err)
+	}
+	return keys, nil
+}
+
+// Revoke 删除密钥，使用该密钥的请求立即失效
+func (s *apiKeyService) Revoke(ctx context.Context, userID string, id string) error {
+	if err := s.apiKeyRepo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("删除 API 密钥失败", err)
+	}
+	log.Printf("🔒 用户 %s 删除了 API 密钥 %s", userID, id)
+	return nil
+}
+
+// AuthenticateAPIKey 验证 API 密钥，返回密钥所属用户和授权范围
+// 用户的角色和禁用状态每次从数据库读取，修改后立即生效
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*auth.APIKeyPrincipal, error) {
+	invalid := apperror.Unauthorized(apperror.CodeInvalidAPIKey, "API 密钥无效或已过期")
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, invalid
+	}
+
+	apiKey, err := s.apiKeyRepo.FindByHash(ctx, auth.HashToken(key))
+	if err != nil {
+		if errors.Is(err, apperror.ErrUnauthorized) {
+			return nil, err
+		}
+		return nil, apperror.Internal("查询 API 密钥失败", err)
+	}
+	now := time.Now()
+	if !apiKey.Active(now) {
+		return nil, invalid
+	}
+
+	// 已注销的用户查不到，其密钥随之失效
+	user, err := s.userRepo.FindByID(ctx, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, invalid
+		}
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+	if err := checkNotDisabled(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.apiKeyRepo.Touch(ctx, apiKey.ID, now, apiKeyTouchInterval); err != nil {
+		log.Printf("⚠️ 更新 API 密钥使用时间失败: %v", err)
+	}
+
+	return &auth.APIKeyPrincipal{
+		KeyID:    apiKey.ID,
+		UserID:   user.ID,
+		Email:    user.Email,
+		Nickname: user.Nickname,
+		Role:     user.Role,
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
+
+// uniqueScopes 去除重复的授权范围并按固定顺序排列
+func uniqueScopes(scopes []string) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range auth.Scopes {
+		for _, s := range scopes {
+			if s == scope {
+				result = append(result, scope)
+				break
+			}
+		}
+	}
+	return result
+}
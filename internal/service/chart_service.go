@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/svg"
+)
+
+// 图表类型
+const (
+	ChartCalories   = "calories"    // 每日热量折线和目标线
+	ChartMacros     = "macros"      // 每日三大营养素热量堆叠柱状图
+	ChartMacroRatio = "macro-ratio" // 三大营养素热量占比饼图
+	ChartWeight     = "weight"      // 体重变化折线
+)
+
+// ChartService 统计图表服务接口
+type ChartService interface {
+	Render(ctx context.Context, userID, chart string, req *ChartRequest, locale string) ([]byte, error)
+}
+
+// chartService 统计图表服务实现，数据来自营养报告的汇总
+type chartService struct {
+	reportService ReportService
+}
+
+// NewChartService 创建统计图表服务实例
+func NewChartService(reportService ReportService) ChartService {
+	return &chartService{reportService: reportService}
+}
+
+// ChartRequest 图表参数
+type ChartRequest struct {
+	Range  string `form:"range,default=7d" binding:"oneof=7d 30d 90d week month"` // 截至 date 的最近 N 天，或 date 所在的周、自然月
+	Date   string `form:"date" binding:"omitempty,datetime=2006-01-02"`           // 默认今天
+	Width  int    `form:"width,default=600" binding:"min=240,max=2000"`
+	Height int    `form:"height,default=300" binding:"min=160,max=1200"`
+	Theme  string `form:"theme,default=light" binding:"oneof=light dark"`
+}
+
+// chartTheme 图表配色
+type chartTheme struct {
+	Background    string
+	Text          string
+	Muted         string
+	Grid          string
+	Line          string
+	Goal          string
+	Protein       string
+	Carbohydrates string
+	Fat           string
+}
+
+var chartThemes = map[string]chartTheme{
+	"light": {
+		Background: "#ffffff", Text: "#212121", Muted: "#757575", Grid: "#e0e0e0",
+		Line: "#1e88e5", Goal: "#2e7d32", Protein: "#4285f4", Carbohydrates: "#fbbc05", Fat: "#ea4335",
+	},
+	"dark": {
+		Background: "#121212", Text: "#eeeeee", Muted: "#9e9e9e", Grid: "#333333",
+		Line: "#64b5f6", Goal: "#66bb6a", Protein: "#8ab4f8", Carbohydrates: "#fdd663", Fat: "#f28b82",
+	},
+}
+
+// Render 绘制图表，返回 SVG 文档。相同的数据和参数总是得到相同的输出，可以用内容摘要作为 ETag
+func (s *chartService) Render(ctx context.Context, userID, chart string, req *ChartRequest, locale string) ([]byte, error) {
+	t := func(code string) string { return i18n.T(locale, code, code) }
+
+	var title string
+	switch chart {
+	case ChartCalories:
+		title = t(i18n.ReportDailyCalories)
+	case ChartMacros:
+		title = t(i18n.ChartDailyMacros)
+	case ChartMacroRatio:
+		title = t(i18n.ReportMacros)
+	case ChartWeight:
+		title = t(i18n.ChartWeightTrend)
+	default:
+		return nil, apperror.Validation(apperror.CodeInvalidChart, "图表类型无效，应为 calories、macros、macro-ratio 或 weight",
+			apperror.FieldError{Field: "chart", Message: chart})
+	}
+
+	from, to, err := chartRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newChartPlot(float64(req.Width), float64(req.Height), chartThemes[req.Theme], title)
+	if chart == ChartWeight {
+		points, err := s.reportService.GetWeightTrend(ctx, userID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		p.weight(points, from, to, t)
+		return p.canvas.Bytes(), nil
+	}
+
+	report, err := s.reportService.Summarize(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	switch chart {
+	case ChartCalories:
+		p.calories(report, t)
+	case ChartMacros:
+		p.macros(report, t)
+	case ChartMacroRatio:
+		p.macroRatio(report, t)
+	}
+	return p.canvas.Bytes(), nil
+}
+
+// chartRange 返回图表的日期范围，from 和 to 都包含在内
+func chartRange(req *ChartRequest) (time.Time, time.Time, error) {
+	dateStr := req.Date
+	if dateStr == "" {
+		dateStr = time.Now().Format("2006-01-02")
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+	}
+
+	switch req.Range {
+	case "week":
+		return ReportRange(ReportWeekly, date)
+	case "month":
+		return ReportRange(ReportMonthly, date)
+	default:
+		days, err := strconv.Atoi(strings.TrimSuffix(req.Range, "d"))
+		if err != nil || days <= 0 {
+			days = 7
+		}
+		return date.AddDate(0, 0, 1-days), date, nil
+	}
+}
+
+// chartPlot 一张图表：顶部为标题和图例，中间为绘图区，底部为横轴标签
+type chartPlot struct {
+	canvas *svg.Canvas
+	theme  chartTheme
+
+	left, top, right, bottom float64 // 绘图区边界
+}
+
+// 图表的版式
+const (
+	chartTitleSize = 13.0
+	chartLabelSize = 10.0
+)
+
+func newChartPlot(width, height float64, theme chartTheme, title string) *chartPlot {
+	p := &chartPlot{
+		canvas: svg.New(width, height, title),
+		theme:  theme,
+		left:   48,
+		top:    40,
+		right:  width - 16,
+		bottom: height - 26,
+	}
+	p.canvas.Rect(0, 0, width, height, theme.Background)
+	p.canvas.Text(12, 22, title, chartTitleSize, theme.Text, svg.Start, true)
+	return p
+}
+
+// empty 没有数据时在绘图区中间显示提示
+func (p *chartPlot) empty(t func(string) string) {
+	p.canvas.Text((p.left+p.right)/2, (p.top+p.bottom)/2, t(i18n.ChartNoData), chartTitleSize, p.theme.Muted, svg.Middle, false)
+}
+
+// chartLegendItem 图例中的一项
+type chartLegendItem struct {
+	label  string
+	color  string
+	dashed bool // 用虚线表示的参考线
+}
+
+// legend 在右上角从右向左排列图例
+func (p *chartPlot) legend(items []chartLegendItem) {
+	x := p.canvas.Width - 12
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		x -= textWidth(item.label, chartLabelSize)
+		p.canvas.Text(x, 22, item.label, chartLabelSize, p.theme.Text, svg.Start, false)
+		x -= 18
+		if item.dashed {
+			p.canvas.Line(x, 18, x+14, 18, item.color, 2, true)
+		} else {
+			p.canvas.Rect(x+2, 13, 10, 10, item.color)
+		}
+		x -= 12
+	}
+}
+
+// yAxis 绘制纵轴网格和刻度，返回把数值换算为纵坐标的函数
+func (p *chartPlot) yAxis(min, max float64) func(float64) float64 {
+	step := niceStep(max-min, 4)
+	low := math.Floor(min/step) * step
+	ticks := int(math.Ceil((max - low) / step))
+	if ticks < 1 {
+		ticks = 1
+	}
+	high := low + float64(ticks)*step
+
+	format := "%.0f"
+	if step != math.Trunc(step) {
+		format = "%.1f"
+	}
+	y := func(v float64) float64 {
+		return p.bottom - (v-low)/(high-low)*(p.bottom-p.top)
+	}
+	for i := 0; i <= ticks; i++ {
+		v := low + float64(i)*step
+		p.canvas.Line(p.left, y(v), p.right, y(v), p.theme.Grid, 1, false)
+		p.canvas.Text(p.left-6, y(v)+3.5, fmt.Sprintf(format, v), chartLabelSize, p.theme.Muted, svg.End, false)
+	}
+	return y
+}
+
+// dayAxis 横轴按天等分，返回第 i 天所在格子的中心横坐标和格子宽度；标签过密时间隔显示
+func (p *chartPlot) dayAxis(days []time.Time) (func(int) float64, float64) {
+	slot := (p.right - p.left) / float64(len(days))
+	x := func(i int) float64 { return p.left + (float64(i)+0.5)*slot }
+
+	every := int(math.Ceil(44 / slot))
+	for i, day := range days {
+		if i%every == 0 {
+			p.canvas.Text(x(i), p.bottom+16, day.Format("01-02"), chartLabelSize, p.theme.Muted, svg.Middle, false)
+		}
+	}
+	return x, slot
+}
+
+// goalLine 热量目标参考线
+func (p *chartPlot) goalLine(y float64) {
+	p.canvas.Line(p.left, y, p.right, y, p.theme.Goal, 1.5, true)
+}
+
+// calories 每日热量折线，没有记录的日期不画点，有目标时画出目标线
+func (p *chartPlot) calories(r *NutritionReport, t func(string) string) {
+	if r.Adherence.DaysLogged == 0 {
+		p.empty(t)
+		return
+	}
+
+	legend := []chartLegendItem{{label: t(i18n.ReportCalories) + " (kcal)", color: p.theme.Line}}
+	max := 0.0
+	for _, day := range r.Days {
+		max = math.Max(max, day.Totals.Calories)
+	}
+	hasGoal := r.Goal != nil && r.Goal.Calories > 0
+	if hasGoal {
+		max = math.Max(max, r.Goal.Calories)
+		legend = append(legend, chartLegendItem{label: t(i18n.ReportGoal), color: p.theme.Goal, dashed: true})
+	}
+	p.legend(legend)
+
+	y := p.yAxis(0, max)
+	x, _ := p.dayAxis(reportDates(r))
+	if hasGoal {
+		p.goalLine(y(r.Goal.Calories))
+	}
+
+	var points [][2]float64
+	for i, day := range r.Days {
+		if day.Records > 0 {
+			points = append(points, [2]float64{x(i), y(day.Totals.Calories)})
+		}
+	}
+	p.canvas.Polyline(points, p.theme.Line, 2)
+	for _, point := range points {
+		p.canvas.Circle(point[0], point[1], 3, p.theme.Line)
+	}
+}
+
+// macros 每日三大营养素提供的热量，自下而上依次为蛋白质、碳水、脂肪
+func (p *chartPlot) macros(r *NutritionReport, t func(string) string) {
+	if r.Adherence.DaysLogged == 0 {
+		p.empty(t)
+		return
+	}
+	colors := []string{p.theme.Protein, p.theme.Carbohydrates, p.theme.Fat}
+	p.legend([]chartLegendItem{
+		{label: t(i18n.ReportProtein), color: colors[0]},
+		{label: t(i18n.ReportCarbohydrates), color: colors[1]},
+		{label: t(i18n.ReportFat), color: colors[2]},
+	})
+
+	max := 0.0
+	for _, day := range r.Days {
+		max = math.Max(max, day.Totals.Protein*4+day.Totals.Carbohydrates*4+day.Totals.Fat*9)
+	}
+	if r.Goal != nil {
+		max = math.Max(max, r.Goal.Calories)
+	}
+
+	y := p.yAxis(0, max)
+	x, slot := p.dayAxis(reportDates(r))
+	width := math.Max(slot*0.7, 1)
+	for i, day := range r.Days {
+		base := 0.0
+		for j, kcal := range []float64{day.Totals.Protein * 4, day.Totals.Carbohydrates * 4, day.Totals.Fat * 9} {
+			p.canvas.Rect(x(i)-width/2, y(base+kcal), width, y(base)-y(base+kcal), colors[j])
+			base += kcal
+		}
+	}
+	if r.Goal != nil && r.Goal.Calories > 0 {
+		p.goalLine(y(r.Goal.Calories))
+	}
+}
+
+// macroRatio 范围内三大营养素的热量占比饼图，图例中列出占比和目标占比
+func (p *chartPlot) macroRatio(r *NutritionReport, t func(string) string) {
+	intake := r.Macros.Intake
+	if intake.Protein+intake.Carbohydrates+intake.Fat == 0 {
+		p.empty(t)
+		return
+	}
+
+	width := p.right - p.left
+	radius := math.Min(p.bottom-p.top, width/2) / 2
+	cx, cy := p.left+width/4, (p.top+p.bottom)/2
+
+	labels := []string{t(i18n.ReportProtein), t(i18n.ReportCarbohydrates), t(i18n.ReportFat)}
+	colors := []string{p.theme.Protein, p.theme.Carbohydrates, p.theme.Fat}
+	shares := []float64{intake.Protein, intake.Carbohydrates, intake.Fat}
+	var goal []float64
+	if r.Macros.Goal != nil {
+		goal = []float64{r.Macros.Goal.Protein, r.Macros.Goal.Carbohydrates, r.Macros.Goal.Fat}
+	}
+
+	angle := 0.0
+	x, y := p.left+width/2+16, cy-22
+	for i, share := range shares {
+		end := angle + share/100*2*math.Pi
+		p.canvas.Sector(cx, cy, radius, angle, end, colors[i])
+		angle = end
+
+		p.canvas.Rect(x, y+float64(i)*22-10, 12, 12, colors[i])
+		text := fmt.Sprintf("%s %.0f%%", labels[i], share)
+		if goal != nil {
+			text += fmt.Sprintf(" (%s %.0f%%)", t(i18n.ReportGoal), goal[i])
+		}
+		p.canvas.Text(x+18, y+float64(i)*22, text, chartLabelSize+1, p.theme.Text, svg.Start, false)
+	}
+}
+
+// weight 体重变化折线，横轴按日期比例排列
+func (p *chartPlot) weight(points []WeightPoint, from, to time.Time, t func(string) string) {
+	if len(points) == 0 {
+		p.empty(t)
+		return
+	}
+	p.legend([]chartLegendItem{{label: t(i18n.ReportWeight) + " (kg)", color: p.theme.Line}})
+
+	min, max := points[0].Weight, points[0].Weight
+	for _, point := range points {
+		min = math.Min(min, point.Weight)
+		max = math.Max(max, point.Weight)
+	}
+	y := p.yAxis(math.Max(min-1, 0), max+1)
+
+	var days []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	x, _ := p.dayAxis(days)
+
+	coords := make([][2]float64, len(points))
+	for i, point := range points {
+		coords[i] = [2]float64{x(int(point.Date.Sub(from).Hours() / 24)), y(point.Weight)}
+	}
+	p.canvas.Polyline(coords, p.theme.Line, 2)
+	for _, point := range coords {
+		p.canvas.Circle(point[0], point[1], 3, p.theme.Line)
+	}
+}
+
+// reportDates 报告中的每一天
+func reportDates(r *NutritionReport) []time.Time {
+	dates := make([]time.Time, len(r.Days))
+	for i, day := range r.Days {
+		dates[i] = day.Date
+	}
+	return dates
+}
+
+// textWidth 估算文字宽度：SVG 由浏览器排版，这里只用于图例布局，ASCII 按半个字号、其他字符按一个字号估算
+func textWidth(s string, size float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			width += size * 0.55
+		} else {
+			width += size
+		}
+	}
+	return width
+}
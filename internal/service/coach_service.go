@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// CoachScopes 教练可以申请的授权范围：查看饮食记录和营养统计、查看营养目标、设置营养目标
+var CoachScopes = []string{auth.ScopeRecordsRead, auth.ScopeGoalsRead, auth.ScopeGoalsWrite}
+
+// CoachService 教练与客户关系服务接口
+type CoachService interface {
+	Invite(ctx context.Context, coachID string, req *InviteClientRequest) (*model.CoachInvitation, error)
+	ListSentInvitations(ctx context.Context, coachID string) ([]*model.CoachInvitation, error)
+	RevokeInvitation(ctx context.Context, coachID string, invitationID string) error
+	ListClients(ctx context.Context, coachID string) ([]*CoachClientView, error)
+	ListCoaches(ctx context.Context, clientID string) ([]*CoachClientView, error)
+	ListInvitations(ctx context.Context, userID string) ([]*CoachInvitationView, error)
+	Accept(ctx context.Context, userID string, invitationID string) (*CoachClientView, error)
+	Decline(ctx context.Context, userID string, invitationID string) error
+	End(ctx context.Context, userID string, id string) error
+	ListAccessLogs(ctx context.Context, clientID string, req *query.Request) (*query.Page[model.CoachAccessLog], error)
+	ActiveGrant(ctx context.Context, coachID string, clientID string) (*model.CoachClient, error)
+	RecordAccess(ctx context.Context, entry *model.CoachAccessLog)
+}
+
+// coachService 教练与客户关系服务实现
+type coachService struct {
+	coachRepo repository.CoachRepository
+	userRepo  repository.UserRepository
+}
+
+// NewCoachService 创建教练与客户关系服务实例
+func NewCoachService(coachRepo repository.CoachRepository, userRepo repository.UserRepository) CoachService {
+	return &coachService{
+		coachRepo: coachRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// InviteClientRequest 教练邀请客户请求，邮箱不要求已注册
+type InviteClientRequest struct {
+	Email   string   `json:"email" binding:"required,email"`
+	Scopes  []string `json:"scopes" binding:"required,min=1,dive,oneof=records:read goals:read goals:write"`
+	Message string   `json:"message" binding:"max=500"`
+}
+
+// CoachClientView 教练关系及对方的基本信息
+type CoachClientView struct {
+	*model.CoachClient
+	Coach  *CoachPartyView `json:"coach,omitempty"`
+	Client *CoachPartyView `json:"client,omitempty"` // 教练代为查看时，client.id 作为 X-Profile-ID 请求头的值
+}
+
+// CoachInvitationView 发给当前用户的教练邀请
+type CoachInvitationView struct {
+	*model.CoachInvitation
+	Coach *CoachPartyView `json:"coach"`
+}
+
+// CoachPartyView 教练或客户的基本信息
+type CoachPartyView struct {
+	ID       string `json:"id"`
+	Nickname string `json:"nickname"`
+	Email    string `json:"email"`
+}
+
+// Invite 教练按邮箱邀请客户并申请授权范围，对方验证邮箱并接受后关系生效
+// 不查询该邮箱是否已注册，无论邮箱是否存在都返回相同的结果；已是客户时重新邀请用于修改授权范围
+func (s *coachService) Invite(ctx context.Context, coachID string, req *InviteClientRequest) (*model.CoachInvitation, error) {
+	coach, err := s.userRepo.FindByID(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == strings.ToLower(coach.Email) {
+		return nil, apperror.BadRequest(apperror.CodeCannotCoachSelf, "不能邀请自己")
+	}
+
+	invitation, err := s.coachRepo.FindInvitationByEmail(ctx, coachID, email)
+	switch {
+	case err == nil:
+		// 未处理的邀请更新授权范围和附言，已接受或拒绝的重新邀请
+		invitation.Status = model.CoachInvitationPending
+	case errors.Is(err, apperror.ErrNotFound):
+		invitation = &model.CoachInvitation{
+			CoachID: coachID,
+			Email:   email,
+			Status:  model.CoachInvitationPending,
+		}
+	default:
+		return nil, apperror.Internal("查询教练邀请失败", err)
+	}
+	invitation.Scopes = coachScopes(req.Scopes)
+	invitation.Message = strings.TrimSpace(req.Message)
+
+	if err := s.coachRepo.SaveInvitation(ctx, invitation); err != nil {
+		return nil, apperror.Internal("保存邀请失败", err)
+	}
+	log.Printf("🧑‍🏫 教练 %s 发出了邀请 %s (%s)", coachID, invitation.ID, strings.Join(invitation.Scopes, " "))
+	return invitation, nil
+}
+
+// ListSentInvitations 教练发出的、等待接受的邀请
+func (s *coachService) ListSentInvitations(ctx context.Context, coachID string) ([]*model.CoachInvitation, error) {
+	invitations, err := s.coachRepo.FindPendingInvitations(ctx, coachID)
+	if err != nil {
+		return nil, apperror.Internal("获取教练邀请失败", err)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation 教练撤回尚未接受的邀请
+func (s *coachService) RevokeInvitation(ctx context.Context, coachID string, invitationID string) error {
+	if err := s.coachRepo.DeleteInvitation(ctx, coachID, invitationID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("撤回教练邀请失败", err)
+	}
+	return nil
+}
+
+// ListClients 教练的客户，尚未接受的邀请见 ListSentInvitations
+func (s *coachService) ListClients(ctx context.Context, coachID string) ([]*CoachClientView, error) {
+	links, err := s.coachRepo.FindByCoach(ctx, coachID)
+	if err != nil {
+		return nil, apperror.Internal("获取客户列表失败", err)
+	}
+
+	views := make([]*CoachClientView, 0, len(links))
+	for _, link := range links {
+		views = append(views, clientView(link))
+	}
+	return views, nil
+}
+
+// ListCoaches 客户的教练
+func (s *coachService) ListCoaches(ctx context.Context, clientID string) ([]*CoachClientView, error) {
+	links, err := s.coachRepo.FindByClient(ctx, clientID)
+	if err != nil {
+		return nil, apperror.Internal("获取教练列表失败", err)
+	}
+
+	views := make([]*CoachClientView, 0, len(links))
+	for _, link := range links {
+		views = append(views, coachView(link))
+	}
+	return views, nil
+}
+
+// ListInvitations 发给当前用户邮箱、等待接受的教练邀请
+func (s *coachService) ListInvitations(ctx context.Context, userID string) ([]*CoachInvitationView, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.coachRepo.FindInvitationsForEmail(ctx, strings.ToLower(user.Email))
+	if err != nil {
+		return nil, apperror.Internal("获取教练邀请失败", err)
+	}
+
+	views := make([]*CoachInvitationView, 0, len(invitations))
+	for _, invitation := range invitations {
+		views = append(views, &CoachInvitationView{CoachInvitation: invitation, Coach: partyView(invitation.Coach)})
+	}
+	return views, nil
+}
+
+// Accept 接受邀请，教练从此可以按邀请中的授权范围查看客户的数据；已是客户时更新授权范围
+func (s *coachService) Accept(ctx context.Context, userID string, invitationID string) (*CoachClientView, error) {
+	invitation, err := s.pendingInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.coachRepo.FindByPair(ctx, invitation.CoachID, userID)
+	switch {
+	case err == nil:
+	case errors.Is(err, apperror.ErrNotFound):
+		link = &model.CoachClient{CoachID: invitation.CoachID, ClientID: userID}
+	default:
+		return nil, apperror.Internal("查询教练关系失败", err)
+	}
+	now := time.Now()
+	link.Status = model.CoachClientActive
+	link.Scopes = invitation.Scopes
+	link.Message = invitation.Message
+	link.AcceptedAt = &now
+	link.EndedAt = nil
+
+	if err := s.coachRepo.AcceptInvitation(ctx, invitation, link); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("接受邀请失败", err)
+	}
+
+	log.Printf("🧑‍🏫 客户 %s 接受了教练 %s 的邀请 (%s)", userID, link.CoachID, strings.Join(link.Scopes, " "))
+	link.Coach = invitation.Coach
+	return coachView(link), nil
+}
+
+// Decline 拒绝邀请，教练可以重新邀请
+func (s *coachService) Decline(ctx context.Context, userID string, invitationID string) error {
+	invitation, err := s.pendingInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	invitation.Status = model.CoachInvitationDeclined
+	if err := s.coachRepo.SaveInvitation(ctx, invitation); err != nil {
+		return apperror.Internal("拒绝教练邀请失败", err)
+	}
+	return nil
+}
+
+// End 教练或客户结束关系（或撤回、拒绝邀请），教练立即失去访问权限
+func (s *coachService) End(ctx context.Context, userID string, id string) error {
+	link, err := s.coachRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("查询教练关系失败", err)
+	}
+	// 不属于自己的关系按不存在处理
+	if (link.CoachID != userID && link.ClientID != userID) || link.Status == model.CoachClientEnded {
+		return apperror.NotFound(apperror.CodeCoachClientNotFound, "教练关系不存在")
+	}
+	return s.end(ctx, link)
+}
+
+// ListAccessLogs 客户查看教练对自己数据的访问记录
+func (s *coachService) ListAccessLogs(ctx context.Context, clientID string, req *query.Request) (*query.Page[model.CoachAccessLog], error) {
+	q, err := repository.CoachAccessLogQuery.Parse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.coachRepo.FindAccessLogs(ctx, clientID, q)
+	if err != nil {
+		return nil, apperror.Internal("获取访问记录失败", err)
+	}
+	return page, nil
+}
+
+// ActiveGrant 返回教练对客户生效中的授权；没有授权或对方已不是教练时返回 nil
+func (s *coachService) ActiveGrant(ctx context.Context, coachID string, clientID string) (*model.CoachClient, error) {
+	link, err := s.coachRepo.FindByPair(ctx, coachID, clientID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if link.Status != model.CoachClientActive {
+		return nil, nil
+	}
+
+	// 角色被管理员撤销后授权随之失效
+	coach, err := s.userRepo.FindByID(ctx, coachID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if coach.Role != model.RoleCoach {
+		return nil, nil
+	}
+	return link, nil
+}
+
+// RecordAccess 记录一次教练访问，写入失败只记录日志，不影响请求
+func (s *coachService) RecordAccess(ctx context.Context, entry *model.CoachAccessLog) {
+	if err := s.coachRepo.CreateAccessLog(ctx, entry); err != nil {
+		log.Printf("⚠️ 记录教练 %s 访问客户 %s 失败: %v", entry.CoachID, entry.ClientID, err)
+	}
+}
+
+// pendingInvitation 查找发给当前用户邮箱、等待接受的邀请；发给其他邮箱的邀请按不存在处理
+// 注册不能证明拥有该邮箱，邮箱验证之前不能接受或拒绝邀请，避免他人抢先注册被邀请的邮箱获得教练关系
+func (s *coachService) pendingInvitation(ctx context.Context, userID string, invitationID string) (*model.CoachInvitation, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.coachRepo.FindInvitation(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("查询教练邀请失败", err)
+	}
+	if invitation.Email != strings.ToLower(user.Email) || invitation.CoachID == userID || invitation.Coach == nil {
+		return nil, apperror.NotFound(apperror.CodeCoachInvitationNotFound, "教练邀请不存在")
+	}
+	if invitation.Status != model.CoachInvitationPending {
+		return nil, apperror.Conflict(apperror.CodeCoachInvitationNotPending, "该邀请已处理")
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, apperror.Forbidden(apperror.CodeEmailNotVerified, "请先验证邮箱后再接受邀请")
+	}
+	return invitation, nil
+}
+
+// end 结束关系，记录保留以便重新邀请和审计
+func (s *coachService) end(ctx context.Context, link *model.CoachClient) error {
+	now := time.Now()
+	link.Status = model.CoachClientEnded
+	link.EndedAt = &now
+	if err := s.coachRepo.Update(ctx, link); err != nil {
+		return apperror.Internal("结束教练关系失败", err)
+	}
+	log.Printf("🧑‍🏫 教练 %s 与客户 %s 的关系已结束", link.CoachID, link.ClientID)
+	return nil
+}
+
+// coachScopes 去重并按 CoachScopes 的顺序排列授权范围
+func coachScopes(scopes []string) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range CoachScopes {
+		for _, s := range scopes {
+			if s == scope {
+				result = append(result, scope)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// clientView 教练看到的关系，附带客户信息
+func clientView(link *model.CoachClient) *CoachClientView {
+	return &CoachClientView{CoachClient: link, Client: partyView(link.Client)}
+}
+
+// coachView 客户看到的关系，附带教练信息
+func coachView(link *model.CoachClient) *CoachClientView {
+	return &CoachClientView{CoachClient: link, Coach: partyView(link.Coach)}
+}
+
+// partyView 用户的基本信息
+func partyView(user *model.User) *CoachPartyView {
+	if user == nil {
+		return nil
+	}
+	return &CoachPartyView{ID: user.ID, Nickname: user.Nickname, Email: user.Email}
+}
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// inviteResponse 邀请响应中除ID、邮箱和时间以外的字段
+func inviteResponse(t *testing.T, invitation *model.CoachInvitation) map[string]interface{} {
+	t.Helper()
+
+	body, err := json.Marshal(invitation)
+	if err != nil {
+		t.Fatalf("序列化邀请失败: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatalf("解析邀请失败: %v", err)
+	}
+	for _, key := range []string{"id", "email", "created_at", "updated_at"} {
+		delete(fields, key)
+	}
+	return fields
+}
+
+func TestCoachInviteDoesNotRevealRegisteredEmail(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	svc := service.NewCoachService(repository.NewCoachRepository(db), repository.NewUserRepository(db))
+
+	coach := createUser(t, db, "coach@example.com", true)
+	createUser(t, db, "client@example.com", true)
+	req := func(email string) *service.InviteClientRequest {
+		return &service.InviteClientRequest{Email: email, Scopes: []string{auth.ScopeRecordsRead}, Message: "你好"}
+	}
+
+	registered, err := svc.Invite(ctx, coach.ID, req("client@example.com"))
+	if err != nil {
+		t.Fatalf("邀请已注册邮箱失败: %v", err)
+	}
+	unregistered, err := svc.Invite(ctx, coach.ID, req("nobody@example.com"))
+	if err != nil {
+		t.Fatalf("邀请未注册邮箱失败: %v", err)
+	}
+	if a, b := inviteResponse(t, registered), inviteResponse(t, unregistered); !reflect.DeepEqual(a, b) {
+		t.Errorf("已注册和未注册邮箱的邀请响应不同:\n%v\n%v", a, b)
+	}
+}
+
+func TestCoachAcceptInvitationRequiresVerifiedEmail(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	userRepo := repository.NewUserRepository(db)
+	svc := service.NewCoachService(repository.NewCoachRepository(db), userRepo)
+
+	coach := createUser(t, db, "coach@example.com", true)
+	coach.Role = model.RoleCoach
+	if err := userRepo.Update(ctx, coach); err != nil {
+		t.Fatalf("设置教练角色失败: %v", err)
+	}
+	// 邀请发出时该邮箱尚未注册，随后有人用它注册但没有验证邮箱
+	invitation, err := svc.Invite(ctx, coach.ID, &service.InviteClientRequest{
+		Email:  "Client@Example.com",
+		Scopes: []string{auth.ScopeRecordsRead, auth.ScopeGoalsRead},
+	})
+	if err != nil {
+		t.Fatalf("邀请客户失败: %v", err)
+	}
+	client := createUser(t, db, "client@example.com", false)
+
+	invitations, err := svc.ListInvitations(ctx, client.ID)
+	if err != nil {
+		t.Fatalf("查询邀请失败: %v", err)
+	}
+	if len(invitations) != 1 || invitations[0].ID != invitation.ID {
+		t.Fatalf("注册后应看到发给该邮箱的邀请，实际 %d 条", len(invitations))
+	}
+
+	_, err = svc.Accept(ctx, client.ID, invitation.ID)
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeEmailNotVerified {
+		t.Fatalf("未验证邮箱接受邀请: err = %v，期望 %s", err, apperror.CodeEmailNotVerified)
+	}
+	if grant, err := svc.ActiveGrant(ctx, coach.ID, client.ID); err != nil || grant != nil {
+		t.Fatalf("未验证邮箱的用户不应成为客户: grant = %v, err = %v", grant, err)
+	}
+
+	// 验证邮箱后接受，关系生效并带上邀请中的授权范围
+	now := time.Now()
+	client.EmailVerifiedAt = &now
+	if err := userRepo.Update(ctx, client); err != nil {
+		t.Fatalf("更新用户失败: %v", err)
+	}
+	if _, err := svc.Accept(ctx, client.ID, invitation.ID); err != nil {
+		t.Fatalf("验证邮箱后接受邀请失败: %v", err)
+	}
+	grant, err := svc.ActiveGrant(ctx, coach.ID, client.ID)
+	if err != nil || grant == nil {
+		t.Fatalf("接受邀请后没有生效的教练关系: %v", err)
+	}
+	if !reflect.DeepEqual(grant.Scopes, invitation.Scopes) {
+		t.Errorf("scopes = %v，期望 %v", grant.Scopes, invitation.Scopes)
+	}
+	if _, err := svc.Accept(ctx, client.ID, invitation.ID); !errors.As(err, &appErr) || appErr.Code != apperror.CodeCoachInvitationNotPending {
+		t.Errorf("重复接受邀请: err = %v，期望 %s", err, apperror.CodeCoachInvitationNotPending)
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/export"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// DiaryColumns 饮食日记导出的列，三种格式相同。供下游工具使用，只能在末尾追加新列，不要修改或删除已有的列
+var DiaryColumns = []export.Column{
+	{Name: "date", Kind: export.Date},
+	{Name: "meal_type", Kind: export.Text},
+	{Name: "food_record_id", Kind: export.Text},
+	{Name: "food_id", Kind: export.Text},
+	{Name: "food_name", Kind: export.Text},
+	{Name: "quantity", Kind: export.Number},
+	{Name: "unit", Kind: export.Text},
+	{Name: "calories", Kind: export.Number},
+	{Name: "protein", Kind: export.Number},
+	{Name: "carbohydrates", Kind: export.Number},
+	{Name: "fat", Kind: export.Number},
+	{Name: "day_calories", Kind: export.Number},
+	{Name: "day_protein", Kind: export.Number},
+	{Name: "day_carbohydrates", Kind: export.Number},
+	{Name: "day_fat", Kind: export.Number},
+	{Name: "goal_calories", Kind: export.Number},
+	{Name: "goal_protein", Kind: export.Number},
+	{Name: "goal_carbohydrates", Kind: export.Number},
+	{Name: "goal_fat", Kind: export.Number},
+	{Name: "recorded_at", Kind: export.Time},
+}
+
+// DiaryExportService 饮食日记导出服务接口
+type DiaryExportService interface {
+	Prepare(ctx context.Context, userID string, req *DiaryExportRequest) (*DiaryExport, error)
+}
+
+// diaryExportService 饮食日记导出服务实现
+type diaryExportService struct {
+	foodRecordRepo repository.FoodRecordRepository
+	goalRepo       repository.NutritionGoalRepository
+	userRepo       repository.UserRepository
+}
+
+// NewDiaryExportService 创建饮食日记导出服务实例
+func NewDiaryExportService(
+	foodRecordRepo repository.FoodRecordRepository,
+	goalRepo repository.NutritionGoalRepository,
+	userRepo repository.UserRepository,
+) DiaryExportService {
+	return &diaryExportService{
+		foodRecordRepo: foodRecordRepo,
+		goalRepo:       goalRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// DiaryExportRequest 饮食日记导出请求，from 和 to 都包含在内
+type DiaryExportRequest struct {
+	From   string `form:"from" binding:"required,datetime=2006-01-02"`
+	To     string `form:"to" binding:"required,datetime=2006-01-02"`
+	Format string `form:"format,default=csv" binding:"oneof=csv json xlsx"`
+}
+
+// DiaryExport 校验通过、可以开始写出的导出任务
+type DiaryExport struct {
+	From   time.Time
+	To     time.Time
+	Format string
+
+	userID         string
+	goal           *model.NutritionGoal
+	foodRecordRepo repository.FoodRecordRepository
+}
+
+// Prepare 校验导出参数并读取营养目标。在写出响应头之前调用，出错时还可以返回错误响应
+func (s *diaryExportService) Prepare(ctx context.Context, userID string, req *DiaryExportRequest) (*DiaryExport, error) {
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return nil, apperror.Validation(apperror.CodeInvalidRange, "结束日期不能早于开始日期",
+			apperror.FieldError{Field: "to", Message: req.To})
+	}
+
+	// 检查用户是否存在
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// 营养目标没有历史记录，每一行都使用当前的目标；未设置时目标列为空
+	goal, err := s.goalRepo.FindByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, apperror.Internal("获取营养目标失败", err)
+	}
+
+	return &DiaryExport{
+		From:           from,
+		To:             to,
+		Format:         req.Format,
+		userID:         userID,
+		goal:           goal,
+		foodRecordRepo: s.foodRecordRepo,
+	}, nil
+}
+
+// Filename 下载文件名
+func (e *DiaryExport) Filename() string {
+	return fmt.Sprintf("nutrition-diary-%s-%s.%s", e.From.Format("20060102"), e.To.Format("20060102"), e.Format)
+}
+
+// WriteTo 逐条读取食物记录并写出，每条记录带有当天的合计和营养目标
+// 记录按日期排序读取，只缓存同一天的记录用于计算当天合计
+func (e *DiaryExport) WriteTo(ctx context.Context, w io.Writer) error {
+	ew, err := export.NewWriter(e.Format, w, DiaryColumns)
+	if err != nil {
+		return err
+	}
+
+	var goal []interface{}
+	if e.goal != nil {
+		goal = []interface{}{e.goal.Calories, e.goal.Protein, e.goal.Carbohydrates, e.goal.Fat}
+	} else {
+		goal = []interface{}{nil, nil, nil, nil}
+	}
+
+	var day []*repository.DiaryEntry
+	row := make([]interface{}, len(DiaryColumns))
+	flush := func() error {
+		var total NutritionTotals
+		for _, entry := range day {
+			total.Calories += entry.Calories
+			total.Protein += entry.Protein
+			total.Carbohydrates += entry.Carbohydrates
+			total.Fat += entry.Fat
+		}
+		for _, entry := range day {
+			var foodID interface{}
+			if entry.FoodID != nil {
+				foodID = *entry.FoodID
+			}
+			row = append(row[:0],
+				entry.Date, model.MealTypeStrings[entry.MealType], entry.ID, foodID, entry.FoodName,
+				entry.Quantity, entry.Unit, entry.Calories, entry.Protein, entry.Carbohydrates, entry.Fat,
+				total.Calories, total.Protein, total.Carbohydrates, total.Fat)
+			row = append(row, goal...)
+			row = append(row, entry.CreatedAt)
+			if err := ew.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		day = day[:0]
+		return nil
+	}
+
+	err = e.foodRecordRepo.EachInRange(ctx, e.userID, e.From, e.To, func(entry *repository.DiaryEntry) error {
+		if len(day) > 0 && !day[0].Date.Equal(entry.Date) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		day = append(day, entry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return ew.Close()
+}
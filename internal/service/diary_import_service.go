@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/importer"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// importPreviewLimit 导入结果中预览的记录条数
+const importPreviewLimit = 50
+
+// DiaryImportService 饮食日记导入服务接口
+type DiaryImportService interface {
+	Import(ctx context.Context, userID string, req *DiaryImportRequest, file io.Reader) (*DiaryImportResult, error)
+}
+
+// diaryImportService 饮食日记导入服务实现
+type diaryImportService struct {
+	mealRepo       repository.MealRecordRepository
+	foodRecordRepo repository.FoodRecordRepository
+	foodRepo       repository.FoodRepository
+	userRepo       repository.UserRepository
+}
+
+// NewDiaryImportService 创建饮食日记导入服务实例
+func NewDiaryImportService(
+	mealRepo repository.MealRecordRepository,
+	foodRecordRepo repository.FoodRecordRepository,
+	foodRepo repository.FoodRepository,
+	userRepo repository.UserRepository,
+) DiaryImportService {
+	return &diaryImportService{
+		mealRepo:       mealRepo,
+		foodRecordRepo: foodRecordRepo,
+		foodRepo:       foodRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// DiaryImportRequest 饮食日记导入请求，文件通过 multipart 表单的 file 字段上传
+type DiaryImportRequest struct {
+	Source string `form:"source" binding:"required,oneof=myfitnesspal cronometer"` // 来源应用
+	DryRun bool   `form:"dry_run"`                                                 // 只预览，不写入
+}
+
+// DiaryImportResult 导入结果
+type DiaryImportResult struct {
+	Source       string                `json:"source"`
+	DryRun       bool                  `json:"dry_run"`
+	From         string                `json:"from,omitempty"` // 文件中最早的日期
+	To           string                `json:"to,omitempty"`   // 文件中最晚的日期
+	Rows         int                   `json:"rows"`           // 解析成功的行数
+	Imported     int                   `json:"imported"`       // 新导入（预览时为将要导入）的记录数
+	Duplicates   int                   `json:"duplicates"`     // 之前已经导入过而跳过的记录数
+	Matched      int                   `json:"matched"`        // 新记录中关联到食物库的条数
+	QuickAdded   int                   `json:"quick_added"`    // 新记录中没有对应食物、作为快速添加条目的条数
+	MealsCreated int                   `json:"meals_created"`  // 新建的餐次记录数
+	Errors       []importer.RowError   `json:"errors"`         // 无法解析而跳过的行
+	Preview      []*DiaryImportPreview `json:"preview"`        // 前若干条记录
+}
+
+// DiaryImportPreview 预览中的一条记录
+type DiaryImportPreview struct {
+	Line          int            `json:"line"`
+	Date          string         `json:"date"`
+	MealType      model.MealType `json:"meal_type"`
+	FoodName      string         `json:"food_name"`
+	FoodID        *string        `json:"food_id"` // 匹配到的食物，快速添加条目为空
+	Quantity      float64        `json:"quantity"`
+	Unit          string         `json:"unit"`
+	Calories      float64        `json:"calories"`
+	Protein       float64        `json:"protein"`
+	Carbohydrates float64        `json:"carbohydrates"`
+	Fat           float64        `json:"fat"`
+	Duplicate     bool           `json:"duplicate"` // 之前已经导入过
+}
+
+// importRow 一条待导入的记录
+type importRow struct {
+	entry     *importer.Entry
+	key       string
+	food      *model.Food
+	duplicate bool
+}
+
+// Import 解析导入文件，匹配食物库，跳过已经导入过的行，按日期和餐次写入餐次记录和食物记录
+func (s *diaryImportService) Import(ctx context.Context, userID string, req *DiaryImportRequest, file io.Reader) (*DiaryImportResult, error) {
+	parser, ok := importer.ParserFor(req.Source)
+	if !ok {
+		return nil, apperror.BadRequest(apperror.CodeInvalidRequest, "不支持的导入来源")
+	}
+	entries, rowErrs, err := parser.Parse(file)
+	if err != nil {
+		if errors.Is(err, importer.ErrInvalidFile) {
+			return nil, apperror.Validation(apperror.CodeImportInvalidFile, err.Error(),
+				apperror.FieldError{Field: "file", Message: err.Error()})
+		}
+		return nil, apperror.Internal("读取导入文件失败", err)
+	}
+
+	// 检查用户是否存在
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	result := &DiaryImportResult{
+		Source:  req.Source,
+		DryRun:  req.DryRun,
+		Rows:    len(entries),
+		Errors:  rowErrs,
+		Preview: []*DiaryImportPreview{},
+	}
+	if result.Errors == nil {
+		result.Errors = []importer.RowError{}
+	}
+	if len(entries) == 0 {
+		return result, nil
+	}
+
+	rows, err := s.prepareRows(ctx, userID, req.Source, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := importRange(rows)
+	var newRows []*importRow
+	for _, row := range rows {
+		if len(result.Preview) < importPreviewLimit {
+			result.Preview = append(result.Preview, previewOf(row))
+		}
+		if row.duplicate {
+			result.Duplicates++
+			continue
+		}
+		newRows = append(newRows, row)
+		if row.food != nil {
+			result.Matched++
+		} else {
+			result.QuickAdded++
+		}
+	}
+	result.From = from.Format("2006-01-02")
+	result.To = to.Format("2006-01-02")
+	result.Imported = len(newRows)
+	if len(newRows) == 0 {
+		return result, nil
+	}
+
+	portions, created, err := s.groupByMeal(ctx, userID, newRows)
+	if err != nil {
+		return nil, err
+	}
+	result.MealsCreated = created
+	if req.DryRun {
+		return result, nil
+	}
+
+	if err := s.mealRepo.CreateWithFoods(ctx, portions); err != nil {
+		return nil, apperror.Internal("导入饮食日记失败", err)
+	}
+	return result, nil
+}
+
+// prepareRows 计算每行的导入标识，标记已经导入过的行，并按名称匹配食物库
+func (s *diaryImportService) prepareRows(ctx context.Context, userID string, source string, entries []*importer.Entry) ([]*importRow, error) {
+	rows := make([]*importRow, len(entries))
+	keys := make([]string, len(entries))
+	seen := make(map[string]int)
+	names := make(map[string]string)
+	for i, entry := range entries {
+		// 文件中完全相同的行（如同一餐吃了两份相同的食物）按出现次序区分
+		content := importContent(userID, source, entry)
+		seen[content]++
+		keys[i] = importKey(content, seen[content])
+		rows[i] = &importRow{entry: entry, key: keys[i]}
+		names[strings.ToLower(entry.FoodName)] = entry.FoodName
+	}
+
+	existing, err := s.foodRecordRepo.FindImportKeys(ctx, keys)
+	if err != nil {
+		return nil, apperror.Internal("查询导入记录失败", err)
+	}
+	imported := make(map[string]bool, len(existing))
+	for _, key := range existing {
+		imported[key] = true
+	}
+
+	list := make([]string, 0, len(names))
+	for _, name := range names {
+		list = append(list, name)
+	}
+	foods, err := s.foodRepo.FindByNames(ctx, list)
+	if err != nil {
+		return nil, apperror.Internal("匹配食物失败", err)
+	}
+	catalog := make(map[string]*model.Food, len(foods))
+	for _, food := range foods {
+		// 同名食物取最早创建的一个
+		if _, ok := catalog[strings.ToLower(food.Name)]; !ok {
+			catalog[strings.ToLower(food.Name)] = food
+		}
+	}
+
+	for _, row := range rows {
+		row.duplicate = imported[row.key]
+		row.food = catalog[strings.ToLower(row.entry.FoodName)]
+	}
+	return rows, nil
+}
+
+// groupByMeal 按日期和餐次把记录分组，已有的餐次记录直接追加，返回分组和需要新建的餐次数
+func (s *diaryImportService) groupByMeal(ctx context.Context, userID string, rows []*importRow) ([]*repository.MealPortion, int, error) {
+	from, to := importRange(rows)
+	meals, err := s.mealRepo.FindByUserIDInRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, 0, apperror.Internal("获取餐次记录失败", err)
+	}
+	existing := make(map[string]*model.MealRecord, len(meals))
+	for _, meal := range meals {
+		key := mealKey(meal.Date, meal.MealType)
+		if _, ok := existing[key]; !ok {
+			existing[key] = meal
+		}
+	}
+
+	var (
+		portions []*repository.MealPortion
+		created  int
+	)
+	byMeal := make(map[string]*repository.MealPortion)
+	for _, row := range rows {
+		key := mealKey(row.entry.Date, row.entry.MealType)
+		portion, ok := byMeal[key]
+		if !ok {
+			meal := existing[key]
+			if meal == nil {
+				meal = &model.MealRecord{UserID: userID, Date: row.entry.Date, MealType: row.entry.MealType}
+				created++
+			}
+			portion = &repository.MealPortion{Meal: meal}
+			byMeal[key] = portion
+			portions = append(portions, portion)
+		}
+		portion.Foods = append(portion.Foods, newImportedFoodRecord(row))
+	}
+	return portions, created, nil
+}
+
+// newImportedFoodRecord 导入的食物记录保留来源中的份量和营养数据，匹配到食物时只关联食物ID
+func newImportedFoodRecord(row *importRow) *model.FoodRecord {
+	key := row.key
+	record := &model.FoodRecord{
+		FoodName:      row.entry.FoodName,
+		Quantity:      row.entry.Quantity,
+		Unit:          row.entry.Unit,
+		Calories:      row.entry.Calories,
+		Protein:       row.entry.Protein,
+		Carbohydrates: row.entry.Carbohydrates,
+		Fat:           row.entry.Fat,
+		ImportKey:     &key,
+	}
+	if row.food != nil {
+		record.FoodID = &row.food.ID
+	}
+	return record
+}
+
+func previewOf(row *importRow) *DiaryImportPreview {
+	preview := &DiaryImportPreview{
+		Line:          row.entry.Line,
+		Date:          row.entry.Date.Format("2006-01-02"),
+		MealType:      row.entry.MealType,
+		FoodName:      row.entry.FoodName,
+		Quantity:      row.entry.Quantity,
+		Unit:          row.entry.Unit,
+		Calories:      row.entry.Calories,
+		Protein:       row.entry.Protein,
+		Carbohydrates: row.entry.Carbohydrates,
+		Fat:           row.entry.Fat,
+		Duplicate:     row.duplicate,
+	}
+	if row.food != nil {
+		preview.FoodID = &row.food.ID
+	}
+	return preview
+}
+
+// importContent 一行记录的内容，用于生成导入标识；不包含行号，文件重新导出后行的顺序变化也能识别
+func importContent(userID string, source string, entry *importer.Entry) string {
+	return strings.Join([]string{
+		userID, source, entry.Date.Format("2006-01-02"), strconv.Itoa(int(entry.MealType)), entry.FoodName,
+		formatFloat(entry.Quantity), entry.Unit,
+		formatFloat(entry.Calories), formatFloat(entry.Protein), formatFloat(entry.Carbohydrates), formatFloat(entry.Fat),
+	}, "\x1f")
+}
+
+// importKey 导入标识：记录内容和在文件中是第几条相同记录的 SHA-256
+func importKey(content string, occurrence int) string {
+	sum := sha256.Sum256([]byte(content + "\x1f" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:])
+}
+
+// importRange 记录中最早和最晚的日期
+func importRange(rows []*importRow) (time.Time, time.Time) {
+	from, to := rows[0].entry.Date, rows[0].entry.Date
+	for _, row := range rows[1:] {
+		if row.entry.Date.Before(from) {
+			from = row.entry.Date
+		}
+		if row.entry.Date.After(to) {
+			to = row.entry.Date
+		}
+	}
+	return from, to
+}
+
+func mealKey(date time.Time, mealType model.MealType) string {
+	return date.Format("2006-01-02") + "/" + strconv.Itoa(int(mealType))
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// ExerciseRecordService 运动记录服务接口
+type ExerciseRecordService interface {
+	CreateExerciseRecord(ctx context.Context, userID string, req *CreateExerciseRecordRequest) (*model.ExerciseRecord, error)
+	GetExerciseRecordsByDate(ctx context.Context, userID string, date time.Time) ([]*model.ExerciseRecord, error)
+	DeleteExerciseRecord(ctx context.Context, userID string, exerciseID string) error
+}
+
+// exerciseRecordService 运动记录服务实现
+type exerciseRecordService struct {
+	exerciseRepo repository.ExerciseRecordRepository
+	userRepo     repository.UserRepository
+	access       AccessChecker
+}
+
+// NewExerciseRecordService 创建运动记录服务实例
+func NewExerciseRecordService(
+	exerciseRepo repository.ExerciseRecordRepository,
+	userRepo repository.UserRepository,
+	access AccessChecker,
+) ExerciseRecordService {
+	return &exerciseRecordService{
+		exerciseRepo: exerciseRepo,
+		userRepo:     userRepo,
+		access:       access,
+	}
+}
+
+// metTable 常见运动在不同强度下的代谢当量（MET），数据参考 Compendium of Physical Activities
+var metTable = map[string]map[model.ExerciseIntensity]float64{
+	"walking":    {model.IntensityLow: 2.8, model.IntensityModerate: 3.5, model.IntensityHigh: 5.0},
+	"running":    {model.IntensityLow: 7.0, model.IntensityModerate: 9.8, model.IntensityHigh: 11.5},
+	"cycling":    {model.IntensityLow: 4.0, model.IntensityModerate: 6.8, model.IntensityHigh: 10.0},
+	"swimming":   {model.IntensityLow: 5.8, model.IntensityModerate: 7.0, model.IntensityHigh: 9.8},
+	"strength":   {model.IntensityLow: 3.5, model.IntensityModerate: 5.0, model.IntensityHigh: 6.0},
+	"yoga":       {model.IntensityLow: 2.5, model.IntensityModerate: 3.0, model.IntensityHigh: 4.0},
+	"hiit":       {model.IntensityLow: 6.0, model.IntensityModerate: 8.0, model.IntensityHigh: 10.0},
+	"basketball": {model.IntensityLow: 4.5, model.IntensityModerate: 6.5, model.IntensityHigh: 8.0},
+	"other":      {model.IntensityLow: 3.0, model.IntensityModerate: 4.5, model.IntensityHigh: 6.0},
+}
+
+// CreateExerciseRecordRequest 创建运动记录请求
+type CreateExerciseRecordRequest struct {
+	Date           string                  `json:"record_date" binding:"required,datetime=2006-01-02"`   // 日期格式：YYYY-MM-DD
+	ExerciseType   string                  `json:"exercise_type" binding:"required"`                     // 运动类型：walking/running/cycling 等
+	Duration       int                     `json:"duration" binding:"required,gt=0"`                     // 运动时长（分钟）
+	Intensity      model.ExerciseIntensity `json:"intensity" binding:"required,oneof=low moderate high"` // 运动强度
+	CaloriesBurned float64                 `json:"calories_burned" binding:"omitempty,gt=0"`             // 手动填写的消耗热量，不填则按 MET 估算
+}
+
+// CreateExerciseRecord 创建运动记录
+func (s *exerciseRecordService) CreateExerciseRecord(ctx context.Context, userID string, req *CreateExerciseRecordRequest) (*model.ExerciseRecord, error) {
+	// 检查用户是否存在
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析日期
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+	}
+
+	exerciseRecord := &model.ExerciseRecord{
+		UserID:       userID,
+		Date:         date,
+		ExerciseType: req.ExerciseType,
+		Duration:     req.Duration,
+		Intensity:    req.Intensity,
+	}
+
+	if req.CaloriesBurned > 0 {
+		// 用户手动填写消耗热量
+		exerciseRecord.CaloriesBurned = req.CaloriesBurned
+		exerciseRecord.IsManual = true
+	} else {
+		calories, err := estimateCaloriesBurned(req.ExerciseType, req.Intensity, req.Duration, user.Weight)
+		if err != nil {
+			return nil, err
+		}
+		exerciseRecord.CaloriesBurned = calories
+	}
+
+	if err := s.exerciseRepo.Create(ctx, exerciseRecord); err != nil {
+		return nil, apperror.Internal("创建运动记录失败", err)
+	}
+
+	return exerciseRecord, nil
+}
+
+// GetExerciseRecordsByDate 获取指定日期的所有运动记录
+func (s *exerciseRecordService) GetExerciseRecordsByDate(ctx context.Context, userID string, date time.Time) ([]*model.ExerciseRecord, error) {
+	// 检查用户是否存在
+	_, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exerciseRecords, err := s.exerciseRepo.FindByUserIDAndDate(ctx, userID, date)
+	if err != nil {
+		return nil, apperror.Internal("获取运动记录失败", err)
+	}
+
+	return exerciseRecords, nil
+}
+
+// DeleteExerciseRecord 删除运动记录
+func (s *exerciseRecordService) DeleteExerciseRecord(ctx context.Context, userID string, exerciseID string) error {
+	exerciseRecord, err := s.exerciseRepo.FindByID(ctx, exerciseID)
+	if err != nil {
+		return err
+	}
+
+	// 检查权限
+	if err := checkAccess(ctx, s.access, userID, exerciseRecord.UserID, apperror.CodeExerciseForbidden, "无权限删除该运动记录"); err != nil {
+		return err
+	}
+
+	if err := s.exerciseRepo.Delete(ctx, exerciseID); err != nil {
+		return apperror.Internal("删除运动记录失败", err)
+	}
+
+	return nil
+}
+
+// estimateCaloriesBurned 根据 MET 估算运动消耗：热量(kcal) = MET × 体重(kg) × 时长(小时)
+func estimateCaloriesBurned(exerciseType string, intensity model.ExerciseIntensity, duration int, weight float64) (float64, error) {
+	if weight <= 0 {
+		return 0, apperror.Validation(apperror.CodeWeightRequired, "缺少体重信息，请先完善个人资料或手动填写消耗热量")
+	}
+
+	mets, exists := metTable[exerciseType]
+	if !exists {
+		mets = metTable["other"]
+	}
+
+	met, exists := mets[intensity]
+	if !exists {
+		return 0, apperror.Validation(apperror.CodeValidation, "无效的运动强度")
+	}
+
+	return met * weight * float64(duration) / 60, nil
+}
+
+// includeExerciseCalories 判断用户的运动消耗是否应计入每日热量预算
+// auto 模式下只有久坐用户（活动水平为1）计入，其余活动水平在计算 TDEE 时已包含日常运动，避免重复计算
+func includeExerciseCalories(user *model.User) bool {
+	switch user.ExerciseMode {
+	case "add":
+		return true
+	case "ignore":
+		return false
+	default:
+		return user.ActivityLevel <= 1
+	}
+}
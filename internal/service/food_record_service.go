@@ -2,10 +2,10 @@ package service
 
 import (
 	"context"
-	"errors"
-	"time"
 
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
 	"github.com/ljk20041215/nutrition-tracker/internal/repository"
 )
 
@@ -13,8 +13,8 @@ import (
 type FoodRecordService interface {
 	CreateFoodRecord(ctx context.Context, userID string, req *CreateFoodRecordRequest) (*model.FoodRecord, error)
 	GetFoodRecord(ctx context.Context, userID string, foodID string) (*model.FoodRecord, error)
-	GetFoodRecordsByMeal(ctx context.Context, userID string, mealID string) ([]*model.FoodRecord, error)
-	GetFoodRecordsByDate(ctx context.Context, userID string, date time.Time) ([]*model.FoodRecord, error)
+	ListFoodRecordsByMeal(ctx context.Context, userID string, mealID string, req *query.Request) (*query.Page[model.FoodRecord], error)
+	ListFoodRecords(ctx context.Context, userID string, req *query.Request) (*query.Page[model.FoodRecord], error)
 	UpdateFoodRecord(ctx context.Context, userID string, foodID string, req *UpdateFoodRecordRequest) (*model.FoodRecord, error)
 	DeleteFoodRecord(ctx context.Context, userID string, foodID string) error
 }
@@ -25,6 +25,7 @@ type foodRecordService struct {
 	mealRepo       repository.MealRecordRepository
 	userRepo       repository.UserRepository
 	foodRepo       repository.FoodRepository
+	access         AccessChecker
 }
 
 // NewFoodRecordService 创建食物记录服务实例
@@ -33,12 +34,14 @@ func NewFoodRecordService(
 	mealRepo repository.MealRecordRepository,
 	userRepo repository.UserRepository,
 	foodRepo repository.FoodRepository,
+	access AccessChecker,
 ) FoodRecordService {
 	return &foodRecordService{
 		foodRecordRepo: foodRecordRepo,
 		mealRepo:       mealRepo,
 		userRepo:       userRepo,
 		foodRepo:       foodRepo,
+		access:         access,
 	}
 }
 
@@ -60,46 +63,31 @@ func (s *foodRecordService) CreateFoodRecord(ctx context.Context, userID string,
 	// 检查用户是否存在
 	_, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, err
 	}
 
-	// 检查餐次记录是否存在且属于该用户
+	// 检查餐次记录是否存在且可以访问（本人或家庭中受抚养成员的记录）
 	mealRecord, err := s.mealRepo.FindByID(ctx, req.MealRecordID)
 	if err != nil {
-		return nil, errors.New("餐次记录不存在")
+		return nil, err
 	}
 
-	if mealRecord.UserID != userID {
-		return nil, errors.New("无权限访问该餐次记录")
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeMealRecordForbidden, "无权限访问该餐次记录"); err != nil {
+		return nil, err
 	}
 
 	// 获取食物信息
 	food, err := s.foodRepo.FindByID(ctx, req.FoodID)
 	if err != nil {
-		return nil, errors.New("食物不存在")
+		return nil, err
 	}
 
-	// 计算实际摄入的营养成分（假设基础数据是每100g的含量）
-	calories := (req.Quantity / 100) * food.Calories
-	protein := (req.Quantity / 100) * food.Protein
-	carbohydrates := (req.Quantity / 100) * food.Carbohydrates
-	fat := (req.Quantity / 100) * food.Fat
-
 	// 创建食物记录
-	foodRecord := &model.FoodRecord{
-		MealRecordID:  req.MealRecordID,
-		FoodID:        req.FoodID,
-		FoodName:      food.Name,
-		Quantity:      req.Quantity,
-		Unit:          req.Unit,
-		Calories:      calories,
-		Protein:       protein,
-		Carbohydrates: carbohydrates,
-		Fat:           fat,
-	}
+	foodRecord := newFoodRecord(food, req.Quantity, req.Unit)
+	foodRecord.MealRecordID = req.MealRecordID
 
 	if err := s.foodRecordRepo.Create(ctx, foodRecord); err != nil {
-		return nil, errors.New("创建食物记录失败")
+		return nil, apperror.Internal("创建食物记录失败", err)
 	}
 
 	return foodRecord, nil
@@ -110,37 +98,47 @@ func (s *foodRecordService) UpdateFoodRecord(ctx context.Context, userID string,
 	// 获取食物记录
 	foodRecord, err := s.foodRecordRepo.FindByID(ctx, foodID)
 	if err != nil {
-		return nil, errors.New("食物记录不存在")
+		return nil, err
 	}
 
-	// 检查餐次记录是否属于该用户
+	// 检查能否访问餐次记录（本人或家庭中受抚养成员的记录）
 	mealRecord, err := s.mealRepo.FindByID(ctx, foodRecord.MealRecordID)
 	if err != nil {
-		return nil, errors.New("餐次记录不存在")
+		return nil, err
 	}
 
-	if mealRecord.UserID != userID {
-		return nil, errors.New("无权限访问该食物记录")
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeFoodRecordForbidden, "无权限访问该食物记录"); err != nil {
+		return nil, err
 	}
 
-	// 获取食物信息
-	food, err := s.foodRepo.FindByID(ctx, foodRecord.FoodID)
-	if err != nil {
-		return nil, errors.New("食物不存在")
-	}
+	if foodRecord.FoodID == nil {
+		// 导入的快速添加条目没有关联食物，按份量比例缩放营养成分
+		ratio := req.Quantity / foodRecord.Quantity
+		foodRecord.Calories *= ratio
+		foodRecord.Protein *= ratio
+		foodRecord.Carbohydrates *= ratio
+		foodRecord.Fat *= ratio
+		foodRecord.Quantity = req.Quantity
+	} else {
+		// 获取食物信息
+		food, err := s.foodRepo.FindByID(ctx, *foodRecord.FoodID)
+		if err != nil {
+			return nil, err
+		}
 
-	// 更新份量
-	foodRecord.Quantity = req.Quantity
+		// 更新份量
+		foodRecord.Quantity = req.Quantity
 
-	// 重新计算营养成分（基于食物的基础营养数据和新的份量）
-	foodRecord.Calories = (req.Quantity / 100) * food.Calories
-	foodRecord.Protein = (req.Quantity / 100) * food.Protein
-	foodRecord.Carbohydrates = (req.Quantity / 100) * food.Carbohydrates
-	foodRecord.Fat = (req.Quantity / 100) * food.Fat
+		// 重新计算营养成分（基于食物的基础营养数据和新的份量）
+		foodRecord.Calories = (req.Quantity / 100) * food.Calories
+		foodRecord.Protein = (req.Quantity / 100) * food.Protein
+		foodRecord.Carbohydrates = (req.Quantity / 100) * food.Carbohydrates
+		foodRecord.Fat = (req.Quantity / 100) * food.Fat
+	}
 
 	// 更新记录
 	if err := s.foodRecordRepo.Update(ctx, foodRecord); err != nil {
-		return nil, errors.New("更新食物记录失败")
+		return nil, apperror.Internal("更新食物记录失败", err)
 	}
 
 	return foodRecord, nil
@@ -151,58 +149,67 @@ func (s *foodRecordService) GetFoodRecord(ctx context.Context, userID string, fo
 	// 获取食物记录
 	foodRecord, err := s.foodRecordRepo.FindByID(ctx, foodID)
 	if err != nil {
-		return nil, errors.New("食物记录不存在")
+		return nil, err
 	}
 
-	// 检查餐次记录是否属于该用户
+	// 检查能否访问餐次记录（本人或家庭中受抚养成员的记录）
 	mealRecord, err := s.mealRepo.FindByID(ctx, foodRecord.MealRecordID)
 	if err != nil {
-		return nil, errors.New("餐次记录不存在")
+		return nil, err
 	}
 
-	if mealRecord.UserID != userID {
-		return nil, errors.New("无权限访问该食物记录")
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeFoodRecordForbidden, "无权限访问该食物记录"); err != nil {
+		return nil, err
 	}
 
 	return foodRecord, nil
 }
 
-// GetFoodRecordsByMeal 获取餐次下的所有食物记录
-func (s *foodRecordService) GetFoodRecordsByMeal(ctx context.Context, userID string, mealID string) ([]*model.FoodRecord, error) {
-	// 检查餐次记录是否存在且属于该用户
+// ListFoodRecordsByMeal 按过滤条件分页查询餐次下的食物记录
+func (s *foodRecordService) ListFoodRecordsByMeal(ctx context.Context, userID string, mealID string, req *query.Request) (*query.Page[model.FoodRecord], error) {
+	q, err := repository.FoodRecordQuery.Parse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查餐次记录是否存在且可以访问（本人或家庭中受抚养成员的记录）
 	mealRecord, err := s.mealRepo.FindByID(ctx, mealID)
 	if err != nil {
-		return nil, errors.New("餐次记录不存在")
+		return nil, err
 	}
 
-	if mealRecord.UserID != userID {
-		return nil, errors.New("无权限访问该餐次记录")
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeMealRecordForbidden, "无权限访问该餐次记录"); err != nil {
+		return nil, err
 	}
 
 	// 获取食物记录
-	foodRecords, err := s.foodRecordRepo.FindByMealRecordID(ctx, mealID)
+	page, err := s.foodRecordRepo.ListByMealRecordID(ctx, mealID, q)
 	if err != nil {
-		return nil, errors.New("获取食物记录失败")
+		return nil, apperror.Internal("获取食物记录失败", err)
 	}
 
-	return foodRecords, nil
+	return page, nil
 }
 
-// GetFoodRecordsByDate 获取指定日期的所有食物记录
-func (s *foodRecordService) GetFoodRecordsByDate(ctx context.Context, userID string, date time.Time) ([]*model.FoodRecord, error) {
-	// 检查用户是否存在
-	_, err := s.userRepo.FindByID(ctx, userID)
+// ListFoodRecords 按过滤条件分页查询用户的食物记录
+func (s *foodRecordService) ListFoodRecords(ctx context.Context, userID string, req *query.Request) (*query.Page[model.FoodRecord], error) {
+	q, err := repository.FoodRecordQuery.Parse(req)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, err
+	}
+
+	// 检查用户是否存在
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
 	}
 
 	// 获取食物记录
-	foodRecords, err := s.foodRecordRepo.FindByUserIDAndDate(ctx, userID, date)
+	page, err := s.foodRecordRepo.ListByUser(ctx, userID, q)
 	if err != nil {
-		return nil, errors.New("获取食物记录失败")
+		return nil, apperror.Internal("获取食物记录失败", err)
 	}
 
-	return foodRecords, nil
+	return page, nil
 }
 
 // DeleteFoodRecord 删除食物记录
@@ -210,24 +217,38 @@ func (s *foodRecordService) DeleteFoodRecord(ctx context.Context, userID string,
 	// 获取食物记录
 	foodRecord, err := s.foodRecordRepo.FindByID(ctx, foodID)
 	if err != nil {
-		return errors.New("食物记录不存在")
+		return err
 	}
 
-	// 检查餐次记录是否属于该用户
+	// 检查能否访问餐次记录（本人或家庭中受抚养成员的记录）
 	mealRecord, err := s.mealRepo.FindByID(ctx, foodRecord.MealRecordID)
 	if err != nil {
-		return errors.New("餐次记录不存在")
+		return err
 	}
 
-	if mealRecord.UserID != userID {
-		return errors.New("无权限删除该食物记录")
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeFoodRecordForbidden, "无权限删除该食物记录"); err != nil {
+		return err
 	}
 
 	// 删除食物记录
 	if err := s.foodRecordRepo.Delete(ctx, foodID); err != nil {
-		return errors.New("删除食物记录失败")
+		return apperror.Internal("删除食物记录失败", err)
 	}
 
 	return nil
 }
 
+
+// newFoodRecord 按份量计算实际摄入的营养成分（食物的基础数据是每100g的含量）
+func newFoodRecord(food *model.Food, quantity float64, unit string) *model.FoodRecord {
+	return &model.FoodRecord{
+		FoodID:        &food.ID,
+		FoodName:      food.Name,
+		Quantity:      quantity,
+		Unit:          unit,
+		Calories:      (quantity / 100) * food.Calories,
+		Protein:       (quantity / 100) * food.Protein,
+		Carbohydrates: (quantity / 100) * food.Carbohydrates,
+		Fat:           (quantity / 100) * food.Fat,
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// FoodCatalogService 食物库查询服务接口，供普通用户浏览和筛选食物
+type FoodCatalogService interface {
+	ListFoods(ctx context.Context, req *query.Request) (*query.Page[model.Food], error)
+}
+
+// foodCatalogService 食物库查询服务实现
+type foodCatalogService struct {
+	foodRepo repository.FoodRepository
+}
+
+// NewFoodCatalogService 创建食物库查询服务实例
+func NewFoodCatalogService(foodRepo repository.FoodRepository) FoodCatalogService {
+	return &foodCatalogService{foodRepo: foodRepo}
+}
+
+// ListFoods 按过滤条件分页查询食物库
+func (s *foodCatalogService) ListFoods(ctx context.Context, req *query.Request) (*query.Page[model.Food], error) {
+	q, err := repository.FoodQuery.Parse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.foodRepo.List(ctx, q)
+	if err != nil {
+		return nil, apperror.Internal("获取食物列表失败", err)
+	}
+	return page, nil
+}
@@ -0,0 +1,517 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// dependentEmailDomain 受抚养成员档案的占位邮箱域名（.invalid 是保留的顶级域名，不会收到邮件）
+const dependentEmailDomain = "@household.invalid"
+
+// HouseholdService 家庭共享服务接口
+type HouseholdService interface {
+	Create(ctx context.Context, userID string, req *CreateHouseholdRequest) (*HouseholdView, error)
+	Get(ctx context.Context, userID string) (*HouseholdView, error)
+	Delete(ctx context.Context, userID string) error
+	Invite(ctx context.Context, userID string, req *InviteHouseholdMemberRequest) (*model.HouseholdInvitation, error)
+	RevokeInvitation(ctx context.Context, userID string, invitationID string) error
+	ListInvitations(ctx context.Context, userID string) ([]*HouseholdInvitationView, error)
+	AcceptInvitation(ctx context.Context, userID string, invitationID string) (*HouseholdView, error)
+	DeclineInvitation(ctx context.Context, userID string, invitationID string) error
+	RemoveMember(ctx context.Context, userID string, memberID string) error
+	Leave(ctx context.Context, userID string) error
+	CreateDependent(ctx context.Context, userID string, req *CreateDependentRequest) (*model.User, error)
+	UpdateDependent(ctx context.Context, userID string, dependentID string, req *UpdateProfileRequest) (*model.User, error)
+	DeleteDependent(ctx context.Context, userID string, dependentID string) error
+	AccessChecker
+}
+
+// householdService 家庭共享服务实现
+type householdService struct {
+	householdRepo repository.HouseholdRepository
+	userRepo      repository.UserRepository
+	userService   UserService
+}
+
+// NewHouseholdService 创建家庭共享服务实例
+func NewHouseholdService(
+	householdRepo repository.HouseholdRepository,
+	userRepo repository.UserRepository,
+	userService UserService,
+) HouseholdService {
+	return &householdService{
+		householdRepo: householdRepo,
+		userRepo:      userRepo,
+		userService:   userService,
+	}
+}
+
+// CreateHouseholdRequest 创建家庭请求
+type CreateHouseholdRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// InviteHouseholdMemberRequest 邀请成年成员请求，邮箱不要求已注册
+type InviteHouseholdMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// CreateDependentRequest 创建受抚养成员档案请求，身体数据用于计算营养目标
+type CreateDependentRequest struct {
+	Nickname      string  `json:"nickname" binding:"required,max=50"`
+	Gender        int     `json:"gender" binding:"omitempty,oneof=0 1 2"`
+	Age           int     `json:"age" binding:"omitempty,min=1,max=150"`
+	Height        float64 `json:"height" binding:"omitempty,gt=0"`
+	Weight        float64 `json:"weight" binding:"omitempty,gt=0"`
+	ActivityLevel int     `json:"activity_level" binding:"omitempty,min=1,max=5"`
+}
+
+// HouseholdView 家庭及其成员
+type HouseholdView struct {
+	*model.Household
+	MyRole      string                       `json:"my_role"`
+	Members     []*HouseholdMemberView       `json:"members"`
+	Invitations []*model.HouseholdInvitation `json:"invitations,omitempty"` // 等待接受的邀请，只有创建者可以看到
+}
+
+// HouseholdInvitationView 发给当前用户的家庭邀请
+type HouseholdInvitationView struct {
+	*model.HouseholdInvitation
+	HouseholdName string `json:"household_name"`
+	InviterName   string `json:"inviter_name"`
+}
+
+// HouseholdMemberView 家庭成员，受抚养成员附带身体数据
+type HouseholdMemberView struct {
+	UserID        string  `json:"user_id"` // 代为操作时作为 X-Profile-ID 请求头的值
+	Nickname      string  `json:"nickname"`
+	Role          string  `json:"role"`
+	Gender        int     `json:"gender,omitempty"`
+	Age           int     `json:"age,omitempty"`
+	Height        float64 `json:"height,omitempty"`
+	Weight        float64 `json:"weight,omitempty"`
+	ActivityLevel int     `json:"activity_level,omitempty"`
+}
+
+// Create 创建家庭，每个用户只能属于一个家庭
+func (s *householdService) Create(ctx context.Context, userID string, req *CreateHouseholdRequest) (*HouseholdView, error) {
+	if err := s.ensureNoHousehold(ctx, userID, "你已加入家庭"); err != nil {
+		return nil, err
+	}
+
+	household := &model.Household{
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+	}
+	owner := &model.HouseholdMember{
+		UserID: userID,
+		Role:   model.HouseholdRoleOwner,
+	}
+	if err := s.householdRepo.Create(ctx, household, owner); err != nil {
+		return nil, apperror.Internal("创建家庭失败", err)
+	}
+
+	log.Printf("🏠 用户 %s 创建了家庭 %s", userID, household.ID)
+	return s.view(ctx, owner)
+}
+
+// Get 获取当前用户所在的家庭
+func (s *householdService) Get(ctx context.Context, userID string) (*HouseholdView, error) {
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, member)
+}
+
+// Delete 删除家庭，需要先移除其他成年成员和受抚养成员
+func (s *householdService) Delete(ctx context.Context, userID string) error {
+	owner, err := s.ownerMembership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.householdRepo.FindMembers(ctx, owner.HouseholdID)
+	if err != nil {
+		return apperror.Internal("获取家庭成员失败", err)
+	}
+	if len(members) > 1 {
+		return apperror.Conflict(apperror.CodeHouseholdNotEmpty, "请先移除家庭中的其他成员")
+	}
+
+	if err := s.householdRepo.Delete(ctx, owner.HouseholdID); err != nil {
+		return apperror.Internal("删除家庭失败", err)
+	}
+	log.Printf("🏠 用户 %s 删除了家庭 %s", userID, owner.HouseholdID)
+	return nil
+}
+
+// Invite 创建者按邮箱邀请成年成员，对方登录后接受才会加入家庭
+// 不查询该邮箱是否已注册或已加入其他家庭，无论邮箱是否存在都返回相同的结果
+func (s *householdService) Invite(ctx context.Context, userID string, req *InviteHouseholdMemberRequest) (*model.HouseholdInvitation, error) {
+	owner, err := s.ownerMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	invitation, err := s.householdRepo.FindInvitationByEmail(ctx, owner.HouseholdID, email)
+	switch {
+	case err == nil:
+		// 已拒绝或接受后又离开的，重新邀请
+		if invitation.Status == model.HouseholdInvitationPending {
+			return invitation, nil
+		}
+		invitation.Status = model.HouseholdInvitationPending
+		invitation.InvitedBy = userID
+	case errors.Is(err, apperror.ErrNotFound):
+		invitation = &model.HouseholdInvitation{
+			HouseholdID: owner.HouseholdID,
+			Email:       email,
+			InvitedBy:   userID,
+			Status:      model.HouseholdInvitationPending,
+		}
+	default:
+		return nil, apperror.Internal("查询家庭邀请失败", err)
+	}
+
+	if err := s.householdRepo.SaveInvitation(ctx, invitation); err != nil {
+		return nil, apperror.Internal("保存家庭邀请失败", err)
+	}
+	log.Printf("🏠 用户 %s 发出了家庭 %s 的邀请 %s", userID, owner.HouseholdID, invitation.ID)
+	return invitation, nil
+}
+
+// RevokeInvitation 创建者撤回尚未接受的邀请
+func (s *householdService) RevokeInvitation(ctx context.Context, userID string, invitationID string) error {
+	owner, err := s.ownerMembership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.householdRepo.DeleteInvitation(ctx, owner.HouseholdID, invitationID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("撤回家庭邀请失败", err)
+	}
+	return nil
+}
+
+// ListInvitations 发给当前用户邮箱、等待接受的家庭邀请
+func (s *householdService) ListInvitations(ctx context.Context, userID string) ([]*HouseholdInvitationView, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.householdRepo.FindInvitationsForEmail(ctx, strings.ToLower(user.Email))
+	if err != nil {
+		return nil, apperror.Internal("获取家庭邀请失败", err)
+	}
+
+	views := make([]*HouseholdInvitationView, 0, len(invitations))
+	for _, invitation := range invitations {
+		view := &HouseholdInvitationView{HouseholdInvitation: invitation}
+		if invitation.Household != nil {
+			view.HouseholdName = invitation.Household.Name
+		}
+		if inviter, err := s.userRepo.FindByID(ctx, invitation.InvitedBy); err == nil {
+			view.InviterName = inviter.Nickname
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// AcceptInvitation 接受邀请，成为家庭的成年成员；每个用户只能属于一个家庭
+func (s *householdService) AcceptInvitation(ctx context.Context, userID string, invitationID string) (*HouseholdView, error) {
+	invitation, err := s.pendingInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNoHousehold(ctx, userID, "你已加入家庭"); err != nil {
+		return nil, err
+	}
+
+	member := &model.HouseholdMember{
+		HouseholdID: invitation.HouseholdID,
+		UserID:      userID,
+		Role:        model.HouseholdRoleMember,
+	}
+	if err := s.householdRepo.AcceptInvitation(ctx, invitation, member); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("加入家庭失败", err)
+	}
+
+	log.Printf("🏠 用户 %s 接受邀请加入了家庭 %s", userID, invitation.HouseholdID)
+	return s.view(ctx, member)
+}
+
+// DeclineInvitation 拒绝邀请，创建者可以重新邀请
+func (s *householdService) DeclineInvitation(ctx context.Context, userID string, invitationID string) error {
+	invitation, err := s.pendingInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	invitation.Status = model.HouseholdInvitationDeclined
+	if err := s.householdRepo.SaveInvitation(ctx, invitation); err != nil {
+		return apperror.Internal("拒绝家庭邀请失败", err)
+	}
+	return nil
+}
+
+// RemoveMember 移除成年成员，只有创建者可以移除；受抚养成员通过 DeleteDependent 删除
+func (s *householdService) RemoveMember(ctx context.Context, userID string, memberID string) error {
+	owner, err := s.ownerMembership(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if memberID == userID {
+		return apperror.Conflict(apperror.CodeHouseholdOwnerLeave, "家庭创建者不能退出家庭")
+	}
+
+	member, err := s.householdRepo.FindMembership(ctx, memberID)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return apperror.Internal("获取家庭成员失败", err)
+	}
+	if member == nil || member.HouseholdID != owner.HouseholdID || member.Role != model.HouseholdRoleMember {
+		return apperror.NotFound(apperror.CodeHouseholdMemberNotFound, "家庭成员不存在")
+	}
+
+	if err := s.householdRepo.RemoveMember(ctx, owner.HouseholdID, memberID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("移除家庭成员失败", err)
+	}
+	return nil
+}
+
+// Leave 成年成员退出家庭，创建者需要删除家庭
+func (s *householdService) Leave(ctx context.Context, userID string) error {
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == model.HouseholdRoleOwner {
+		return apperror.Conflict(apperror.CodeHouseholdOwnerLeave, "家庭创建者不能退出家庭，请删除家庭")
+	}
+
+	if err := s.householdRepo.RemoveMember(ctx, member.HouseholdID, userID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("退出家庭失败", err)
+	}
+	return nil
+}
+
+// CreateDependent 创建受抚养成员档案，档案拥有独立的营养目标和饮食记录，但不能登录
+func (s *householdService) CreateDependent(ctx context.Context, userID string, req *CreateDependentRequest) (*model.User, error) {
+	member, err := s.adultMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	activityLevel := req.ActivityLevel
+	if activityLevel == 0 {
+		activityLevel = 3
+	}
+	dependent := &model.User{
+		Email:         "dependent-" + uuid.NewString() + dependentEmailDomain,
+		Nickname:      strings.TrimSpace(req.Nickname),
+		Gender:        req.Gender,
+		Age:           req.Age,
+		Height:        req.Height,
+		Weight:        req.Weight,
+		ActivityLevel: activityLevel,
+		Role:          model.RoleUser,
+		Dependent:     true,
+	}
+	dependentMember := &model.HouseholdMember{
+		HouseholdID: member.HouseholdID,
+		Role:        model.HouseholdRoleDependent,
+	}
+	if err := s.householdRepo.CreateDependent(ctx, dependent, dependentMember); err != nil {
+		return nil, apperror.Internal("创建成员档案失败", err)
+	}
+
+	log.Printf("🏠 用户 %s 在家庭 %s 中创建了成员档案 %s", userID, member.HouseholdID, dependent.ID)
+	return dependent, nil
+}
+
+// UpdateDependent 修改受抚养成员的档案，家庭中的成年成员都可以修改
+func (s *householdService) UpdateDependent(ctx context.Context, userID string, dependentID string, req *UpdateProfileRequest) (*model.User, error) {
+	if err := s.checkDependent(ctx, userID, dependentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.UpdateProfile(ctx, dependentID, req); err != nil {
+		return nil, err
+	}
+	return s.userService.GetProfile(ctx, dependentID)
+}
+
+// DeleteDependent 删除受抚养成员，档案和记录在保留期结束后永久删除
+func (s *householdService) DeleteDependent(ctx context.Context, userID string, dependentID string) error {
+	member, err := s.adultMembership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.householdRepo.DeleteDependent(ctx, member.HouseholdID, dependentID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("删除成员档案失败", err)
+	}
+
+	log.Printf("🏠 用户 %s 删除了成员档案 %s", userID, dependentID)
+	return nil
+}
+
+// CanAccess 家庭中的成年成员可以读写受抚养成员的记录
+func (s *householdService) CanAccess(ctx context.Context, subjectID string, ownerID string) (bool, error) {
+	if subjectID == ownerID {
+		return true, nil
+	}
+	return s.householdRepo.CanAccess(ctx, subjectID, ownerID)
+}
+
+// pendingInvitation 查找发给当前用户邮箱、等待接受的邀请；发给其他邮箱的邀请按不存在处理
+// 注册不能证明拥有该邮箱，邮箱验证之前不能接受或拒绝邀请，避免他人抢先注册被邀请的邮箱加入家庭
+func (s *householdService) pendingInvitation(ctx context.Context, userID string, invitationID string) (*model.HouseholdInvitation, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.householdRepo.FindInvitation(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("查询家庭邀请失败", err)
+	}
+	if invitation.Email != strings.ToLower(user.Email) {
+		return nil, apperror.NotFound(apperror.CodeHouseholdInvitationNotFound, "家庭邀请不存在")
+	}
+	if invitation.Status != model.HouseholdInvitationPending {
+		return nil, apperror.Conflict(apperror.CodeHouseholdInvitationNotPending, "该邀请已处理")
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, apperror.Forbidden(apperror.CodeEmailNotVerified, "请先验证邮箱后再接受邀请")
+	}
+	return invitation, nil
+}
+
+// checkDependent 检查 dependentID 是否为 userID 所在家庭的受抚养成员
+func (s *householdService) checkDependent(ctx context.Context, userID string, dependentID string) error {
+	allowed, err := s.householdRepo.CanAccess(ctx, userID, dependentID)
+	if err != nil {
+		return apperror.Internal("检查访问权限失败", err)
+	}
+	if !allowed {
+		return apperror.NotFound(apperror.CodeHouseholdMemberNotFound, "家庭成员不存在")
+	}
+	return nil
+}
+
+// membership 获取用户的成员记录
+func (s *householdService) membership(ctx context.Context, userID string) (*model.HouseholdMember, error) {
+	member, err := s.householdRepo.FindMembership(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("获取家庭失败", err)
+	}
+	return member, nil
+}
+
+// adultMembership 获取用户的成员记录，要求是成年成员
+func (s *householdService) adultMembership(ctx context.Context, userID string) (*model.HouseholdMember, error) {
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Adult() {
+		return nil, apperror.Forbidden(apperror.CodeProfileForbidden, "受抚养成员不能管理家庭")
+	}
+	return member, nil
+}
+
+// ownerMembership 获取用户的成员记录，要求是家庭创建者
+func (s *householdService) ownerMembership(ctx context.Context, userID string) (*model.HouseholdMember, error) {
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != model.HouseholdRoleOwner {
+		return nil, apperror.Forbidden(apperror.CodeNotHouseholdOwner, "只有家庭创建者可以执行该操作")
+	}
+	return member, nil
+}
+
+// ensureNoHousehold 检查用户尚未加入家庭
+func (s *householdService) ensureNoHousehold(ctx context.Context, userID string, message string) error {
+	_, err := s.householdRepo.FindMembership(ctx, userID)
+	if err == nil {
+		return apperror.Conflict(apperror.CodeAlreadyInHousehold, message)
+	}
+	if !errors.Is(err, apperror.ErrNotFound) {
+		return apperror.Internal("获取家庭失败", err)
+	}
+	return nil
+}
+
+// view 组装家庭及成员信息
+func (s *householdService) view(ctx context.Context, member *model.HouseholdMember) (*HouseholdView, error) {
+	household, err := s.householdRepo.FindByID(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.householdRepo.FindMembers(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, apperror.Internal("获取家庭成员失败", err)
+	}
+
+	view := &HouseholdView{
+		Household: household,
+		MyRole:    member.Role,
+		Members:   make([]*HouseholdMemberView, 0, len(members)),
+	}
+	for _, m := range members {
+		mv := &HouseholdMemberView{
+			UserID:   m.UserID,
+			Nickname: m.User.Nickname,
+			Role:     m.Role,
+		}
+		if m.Role == model.HouseholdRoleDependent {
+			mv.Gender = m.User.Gender
+			mv.Age = m.User.Age
+			mv.Height = m.User.Height
+			mv.Weight = m.User.Weight
+			mv.ActivityLevel = m.User.ActivityLevel
+		}
+		view.Members = append(view.Members, mv)
+	}
+
+	if member.Role == model.HouseholdRoleOwner {
+		if view.Invitations, err = s.householdRepo.FindPendingInvitations(ctx, member.HouseholdID); err != nil {
+			return nil, apperror.Internal("获取家庭邀请失败", err)
+		}
+	}
+	return view, nil
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+	"github.com/ljk20041215/nutrition-tracker/pkg/database"
+	"gorm.io/gorm"
+)
+
+// openTestDB 创建 SQLite 数据库并执行全部迁移
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)"
+	if err := database.Connect(database.DriverSQLite, dsn); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewMigrator()
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	return database.DB
+}
+
+// createUser 创建用户，verified 表示邮箱是否已验证
+func createUser(t *testing.T, db *gorm.DB, email string, verified bool) *model.User {
+	t.Helper()
+
+	user := &model.User{Email: email, PasswordHash: "x", Nickname: email, Role: model.RoleUser}
+	if verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := repository.NewUserRepository(db).Create(context.Background(), user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return user
+}
+
+// inviteToHousehold 创建家庭并邀请 email 加入
+func inviteToHousehold(t *testing.T, svc service.HouseholdService, ownerID string, email string) *model.HouseholdInvitation {
+	t.Helper()
+
+	ctx := context.Background()
+	if _, err := svc.Create(ctx, ownerID, &service.CreateHouseholdRequest{Name: "我的家"}); err != nil {
+		t.Fatalf("创建家庭失败: %v", err)
+	}
+	invitation, err := svc.Invite(ctx, ownerID, &service.InviteHouseholdMemberRequest{Email: email})
+	if err != nil {
+		t.Fatalf("邀请成员失败: %v", err)
+	}
+	return invitation
+}
+
+func TestHouseholdAcceptInvitationRequiresVerifiedEmail(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	householdRepo := repository.NewHouseholdRepository(db)
+	svc := service.NewHouseholdService(householdRepo, repository.NewUserRepository(db), nil)
+
+	owner := createUser(t, db, "owner@example.com", true)
+	// 邀请发出时该邮箱尚未注册，随后有人用它注册但没有验证邮箱
+	invitation := inviteToHousehold(t, svc, owner.ID, "Partner@Example.com")
+	impostor := createUser(t, db, "partner@example.com", false)
+
+	_, err := svc.AcceptInvitation(ctx, impostor.ID, invitation.ID)
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeEmailNotVerified {
+		t.Fatalf("未验证邮箱接受邀请: err = %v，期望 %s", err, apperror.CodeEmailNotVerified)
+	}
+	if err := svc.DeclineInvitation(ctx, impostor.ID, invitation.ID); !errors.As(err, &appErr) || appErr.Code != apperror.CodeEmailNotVerified {
+		t.Fatalf("未验证邮箱拒绝邀请: err = %v，期望 %s", err, apperror.CodeEmailNotVerified)
+	}
+	if _, err := householdRepo.FindMembership(ctx, impostor.ID); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("未验证邮箱的用户不应加入家庭: err = %v", err)
+	}
+
+	// 验证邮箱后可以接受
+	now := time.Now()
+	impostor.EmailVerifiedAt = &now
+	if err := repository.NewUserRepository(db).Update(ctx, impostor); err != nil {
+		t.Fatalf("更新用户失败: %v", err)
+	}
+	view, err := svc.AcceptInvitation(ctx, impostor.ID, invitation.ID)
+	if err != nil {
+		t.Fatalf("验证邮箱后接受邀请失败: %v", err)
+	}
+	if view.MyRole != model.HouseholdRoleMember {
+		t.Errorf("my_role = %q，期望 %q", view.MyRole, model.HouseholdRoleMember)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// MealCommentService 餐次评论服务接口
+// userID 为被访问的档案，authorID 为实际操作的用户（教练代为查看客户档案时两者不同）
+type MealCommentService interface {
+	List(ctx context.Context, userID string, mealID string) ([]*MealCommentView, error)
+	Create(ctx context.Context, userID string, authorID string, mealID string, req *CreateMealCommentRequest) (*MealCommentView, error)
+	Delete(ctx context.Context, userID string, authorID string, mealID string, commentID string) error
+}
+
+// mealCommentService 餐次评论服务实现
+type mealCommentService struct {
+	commentRepo repository.MealCommentRepository
+	mealRepo    repository.MealRecordRepository
+	userRepo    repository.UserRepository
+	access      AccessChecker
+}
+
+// NewMealCommentService 创建餐次评论服务实例
+func NewMealCommentService(
+	commentRepo repository.MealCommentRepository,
+	mealRepo repository.MealRecordRepository,
+	userRepo repository.UserRepository,
+	access AccessChecker,
+) MealCommentService {
+	return &mealCommentService{
+		commentRepo: commentRepo,
+		mealRepo:    mealRepo,
+		userRepo:    userRepo,
+		access:      access,
+	}
+}
+
+// CreateMealCommentRequest 发表评论请求
+type CreateMealCommentRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+// MealCommentView 评论及作者昵称
+type MealCommentView struct {
+	*model.MealComment
+	AuthorNickname string `json:"author_nickname"`
+}
+
+// List 获取餐次的全部评论
+func (s *mealCommentService) List(ctx context.Context, userID string, mealID string) ([]*MealCommentView, error) {
+	if _, err := s.meal(ctx, userID, mealID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.FindByMealRecordID(ctx, mealID)
+	if err != nil {
+		return nil, apperror.Internal("获取评论失败", err)
+	}
+
+	views := make([]*MealCommentView, 0, len(comments))
+	for _, comment := range comments {
+		views = append(views, commentView(comment))
+	}
+	return views, nil
+}
+
+// Create 在餐次下发表评论
+func (s *mealCommentService) Create(ctx context.Context, userID string, authorID string, mealID string, req *CreateMealCommentRequest) (*MealCommentView, error) {
+	if _, err := s.meal(ctx, userID, mealID); err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, apperror.Validation(apperror.CodeValidation, "评论内容不能为空")
+	}
+
+	author, err := s.userRepo.FindByID(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &model.MealComment{
+		MealRecordID: mealID,
+		AuthorID:     authorID,
+		Body:         body,
+		Author:       author,
+	}
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return nil, apperror.Internal("保存评论失败", err)
+	}
+	return commentView(comment), nil
+}
+
+// Delete 删除评论，只能删除自己发表的评论
+func (s *mealCommentService) Delete(ctx context.Context, userID string, authorID string, mealID string, commentID string) error {
+	if _, err := s.meal(ctx, userID, mealID); err != nil {
+		return err
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("查询评论失败", err)
+	}
+	if comment.MealRecordID != mealID {
+		return apperror.NotFound(apperror.CodeMealCommentNotFound, "评论不存在")
+	}
+	if comment.AuthorID != authorID {
+		return apperror.Forbidden(apperror.CodeNotCommentAuthor, "只能删除自己的评论")
+	}
+
+	if err := s.commentRepo.Delete(ctx, commentID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return apperror.Internal("删除评论失败", err)
+	}
+	return nil
+}
+
+// meal 查找餐次并检查 userID 能否访问
+func (s *mealCommentService) meal(ctx context.Context, userID string, mealID string) (*model.MealRecord, error) {
+	mealRecord, err := s.mealRepo.FindByID(ctx, mealID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeMealRecordForbidden, "无权限访问该餐次记录"); err != nil {
+		return nil, err
+	}
+	return mealRecord, nil
+}
+
+// commentView 评论及作者昵称，作者已注销时昵称为空
+func commentView(comment *model.MealComment) *MealCommentView {
+	view := &MealCommentView{MealComment: comment}
+	if comment.Author != nil {
+		view.AuthorNickname = comment.Author.Nickname
+	}
+	return view
+}
@@ -1,131 +1,251 @@
-package service
-
-import (
-	"context"
-	"errors"
-	"time"
-
-	"github.com/ljk20041215/nutrition-tracker/internal/model"
-	"github.com/ljk20041215/nutrition-tracker/internal/repository"
-)
-
-// MealRecordService 餐次记录服务接口
-type MealRecordService interface {
-	CreateMealRecord(ctx context.Context, userID string, req *CreateMealRecordRequest) (*model.MealRecord, error)
-	GetMealRecord(ctx context.Context, userID string, mealID string) (*model.MealRecord, error)
-	GetMealRecordsByDate(ctx context.Context, userID string, date time.Time) ([]*model.MealRecord, error)
-	DeleteMealRecord(ctx context.Context, userID string, mealID string) error
-}
-
-// mealRecordService 餐次记录服务实现
-type mealRecordService struct {
-	mealRepo repository.MealRecordRepository
-	userRepo repository.UserRepository
-}
-
-// NewMealRecordService 创建餐次记录服务实例
-func NewMealRecordService(
-	mealRepo repository.MealRecordRepository,
-	userRepo repository.UserRepository,
-) MealRecordService {
-	return &mealRecordService{
-		mealRepo: mealRepo,
-		userRepo: userRepo,
-	}
-}
-
-// CreateMealRecordRequest 创建餐次记录请求
-type CreateMealRecordRequest struct {
-	Date     string       `json:"record_date" binding:"required,datetime=2006-01-02"` // 日期格式：YYYY-MM-DD
-	MealType model.MealType `json:"meal_type" binding:"required"` // 餐次类型：breakfast/lunch/dinner/snack 或 1/2/3/4
-}
-
-// CreateMealRecord 创建餐次记录
-func (s *mealRecordService) CreateMealRecord(ctx context.Context, userID string, req *CreateMealRecordRequest) (*model.MealRecord, error) {
-	// 检查用户是否存在
-	_, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		return nil, errors.New("用户不存在")
-	}
-
-	// 解析日期
-	date, err := time.Parse("2006-01-02", req.Date)
-	if err != nil {
-		return nil, errors.New("日期格式错误，应为 YYYY-MM-DD")
-	}
-
-	// 检查该用户在该日期该餐次是否已存在
-	existing, _ := s.mealRepo.FindByUserIDDateAndType(ctx, userID, date, req.MealType)
-	if existing != nil {
-		return nil, errors.New("该餐次记录已存在")
-	}
-
-	// 创建餐次记录
-	mealRecord := &model.MealRecord{
-		UserID:   userID,
-		Date:     date,
-		MealType: req.MealType,
-	}
-
-	if err := s.mealRepo.Create(ctx, mealRecord); err != nil {
-		return nil, errors.New("创建餐次记录失败")
-	}
-
-	return mealRecord, nil
-}
-
-// GetMealRecord 获取餐次记录
-func (s *mealRecordService) GetMealRecord(ctx context.Context, userID string, mealID string) (*model.MealRecord, error) {
-	// 获取餐次记录
-	mealRecord, err := s.mealRepo.FindByID(ctx, mealID)
-	if err != nil {
-		return nil, errors.New("餐次记录不存在")
-	}
-
-	// 检查权限
-	if mealRecord.UserID != userID {
-		return nil, errors.New("无权限访问该餐次记录")
-	}
-
-	return mealRecord, nil
-}
-
-// GetMealRecordsByDate 获取指定日期的所有餐次记录
-func (s *mealRecordService) GetMealRecordsByDate(ctx context.Context, userID string, date time.Time) ([]*model.MealRecord, error) {
-	// 检查用户是否存在
-	_, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		return nil, errors.New("用户不存在")
-	}
-
-	// 获取餐次记录
-	mealRecords, err := s.mealRepo.FindByUserIDAndDate(ctx, userID, date)
-	if err != nil {
-		return nil, errors.New("获取餐次记录失败")
-	}
-
-	return mealRecords, nil
-}
-
-// DeleteMealRecord 删除餐次记录
-func (s *mealRecordService) DeleteMealRecord(ctx context.Context, userID string, mealID string) error {
-	// 获取餐次记录
-	mealRecord, err := s.mealRepo.FindByID(ctx, mealID)
-	if err != nil {
-		return errors.New("餐次记录不存在")
-	}
-
-	// 检查权限
-	if mealRecord.UserID != userID {
-		return errors.New("无权限删除该餐次记录")
-	}
-
-	// 删除餐次记录
-	if err := s.mealRepo.Delete(ctx, mealID); err != nil {
-		return errors.New("删除餐次记录失败")
-	}
-
-	return nil
-}
-
-
\ No newline at end of file
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/query"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// MealRecordService 餐次记录服务接口
+type MealRecordService interface {
+	CreateMealRecord(ctx context.Context, userID string, req *CreateMealRecordRequest) (*model.MealRecord, error)
+	GetMealRecord(ctx context.Context, userID string, mealID string) (*model.MealRecord, error)
+	ListMealRecords(ctx context.Context, userID string, req *query.Request) (*query.Page[model.MealRecord], error)
+	DeleteMealRecord(ctx context.Context, userID string, mealID string) error
+	CreateSharedMeal(ctx context.Context, userID string, req *CreateSharedMealRequest) ([]*repository.MealPortion, error)
+}
+
+// mealRecordService 餐次记录服务实现
+type mealRecordService struct {
+	mealRepo repository.MealRecordRepository
+	userRepo repository.UserRepository
+	foodRepo repository.FoodRepository
+	access   AccessChecker
+}
+
+// NewMealRecordService 创建餐次记录服务实例
+func NewMealRecordService(
+	mealRepo repository.MealRecordRepository,
+	userRepo repository.UserRepository,
+	foodRepo repository.FoodRepository,
+	access AccessChecker,
+) MealRecordService {
+	return &mealRecordService{
+		mealRepo: mealRepo,
+		userRepo: userRepo,
+		foodRepo: foodRepo,
+		access:   access,
+	}
+}
+
+// CreateMealRecordRequest 创建餐次记录请求
+type CreateMealRecordRequest struct {
+	Date     string       `json:"record_date" binding:"required,datetime=2006-01-02"` // 日期格式：YYYY-MM-DD
+	MealType model.MealType `json:"meal_type" binding:"required"` // 餐次类型：breakfast/lunch/dinner/snack 或 1/2/3/4
+}
+
+// CreateSharedMealRequest 共享餐次请求：一顿饭的食物按份数分给多个家庭成员
+type CreateSharedMealRequest struct {
+	Date     string             `json:"record_date" binding:"required,datetime=2006-01-02"`
+	MealType model.MealType     `json:"meal_type" binding:"required"`
+	Foods    []SharedMealFood   `json:"foods" binding:"required,min=1,max=50,dive"`
+	Members  []SharedMealMember `json:"members" binding:"required,min=1,max=20,dive"`
+}
+
+// SharedMealFood 共享餐次中的食物，份量为整顿饭的总量
+type SharedMealFood struct {
+	FoodID   string  `json:"food_id" binding:"required"`
+	Quantity float64 `json:"quantity" binding:"required,gt=0"`
+	Unit     string  `json:"unit" binding:"required"`
+}
+
+// SharedMealMember 共享餐次的成员和份数，每种食物按份数占比分配
+type SharedMealMember struct {
+	ProfileID string  `json:"profile_id" binding:"required"` // 本人或家庭中受抚养成员的用户ID
+	Portion   float64 `json:"portion" binding:"required,gt=0"`
+}
+
+// CreateMealRecord 创建餐次记录
+func (s *mealRecordService) CreateMealRecord(ctx context.Context, userID string, req *CreateMealRecordRequest) (*model.MealRecord, error) {
+	// 检查用户是否存在
+	_, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析日期
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+	}
+
+	// 检查该用户在该日期该餐次是否已存在
+	existing, err := s.mealRepo.FindByUserIDDateAndType(ctx, userID, date, req.MealType)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, apperror.Internal("获取餐次记录失败", err)
+	}
+	if existing != nil {
+		return nil, apperror.Conflict(apperror.CodeMealRecordExists, "该餐次记录已存在")
+	}
+
+	// 创建餐次记录
+	mealRecord := &model.MealRecord{
+		UserID:   userID,
+		Date:     date,
+		MealType: req.MealType,
+	}
+
+	if err := s.mealRepo.Create(ctx, mealRecord); err != nil {
+		return nil, apperror.Internal("创建餐次记录失败", err)
+	}
+
+	return mealRecord, nil
+}
+
+// GetMealRecord 获取餐次记录
+func (s *mealRecordService) GetMealRecord(ctx context.Context, userID string, mealID string) (*model.MealRecord, error) {
+	// 获取餐次记录
+	mealRecord, err := s.mealRepo.FindByID(ctx, mealID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查权限（本人或家庭中受抚养成员的记录）
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeMealRecordForbidden, "无权限访问该餐次记录"); err != nil {
+		return nil, err
+	}
+
+	return mealRecord, nil
+}
+
+// ListMealRecords 按过滤条件分页查询餐次记录
+func (s *mealRecordService) ListMealRecords(ctx context.Context, userID string, req *query.Request) (*query.Page[model.MealRecord], error) {
+	q, err := repository.MealRecordQuery.Parse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查用户是否存在
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// 获取餐次记录
+	page, err := s.mealRepo.List(ctx, userID, q)
+	if err != nil {
+		return nil, apperror.Internal("获取餐次记录失败", err)
+	}
+
+	return page, nil
+}
+
+// DeleteMealRecord 删除餐次记录
+func (s *mealRecordService) DeleteMealRecord(ctx context.Context, userID string, mealID string) error {
+	// 获取餐次记录
+	mealRecord, err := s.mealRepo.FindByID(ctx, mealID)
+	if err != nil {
+		return err
+	}
+
+	// 检查权限（本人或家庭中受抚养成员的记录）
+	if err := checkAccess(ctx, s.access, userID, mealRecord.UserID, apperror.CodeMealRecordForbidden, "无权限删除该餐次记录"); err != nil {
+		return err
+	}
+
+	// 删除餐次记录
+	if err := s.mealRepo.Delete(ctx, mealID); err != nil {
+		return apperror.Internal("删除餐次记录失败", err)
+	}
+
+	return nil
+}
+
+
+
+// CreateSharedMeal 记录多人共享的一顿饭：为每个成员创建（或沿用当天同一餐次的）餐次记录，
+// 并按份数占比写入各自的食物记录
+func (s *mealRecordService) CreateSharedMeal(ctx context.Context, userID string, req *CreateSharedMealRequest) ([]*repository.MealPortion, error) {
+	// 解析日期
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+	}
+
+	// 检查成员：不能重复，且只能是本人或家庭中的受抚养成员
+	var totalPortion float64
+	seen := make(map[string]bool, len(req.Members))
+	for _, m := range req.Members {
+		if seen[m.ProfileID] {
+			return nil, apperror.Validation(apperror.CodeDuplicateMealMember, "同一成员不能重复出现")
+		}
+		seen[m.ProfileID] = true
+		if err := checkAccess(ctx, s.access, userID, m.ProfileID, apperror.CodeProfileForbidden, "无权限代为记录该成员的饮食"); err != nil {
+			return nil, err
+		}
+		totalPortion += m.Portion
+	}
+
+	// 获取食物信息
+	foods := make([]*model.Food, 0, len(req.Foods))
+	for _, f := range req.Foods {
+		food, err := s.foodRepo.FindByID(ctx, f.FoodID)
+		if err != nil {
+			return nil, err
+		}
+		foods = append(foods, food)
+	}
+
+	// 按份数占比分配每种食物的份量
+	quantities := make([][]float64, len(req.Foods))
+	for i, f := range req.Foods {
+		quantities[i] = splitQuantity(f.Quantity, req.Members, totalPortion)
+		for _, quantity := range quantities[i] {
+			if quantity <= 0 {
+				return nil, apperror.Validation(apperror.CodeMealShareTooSmall, "有成员分到的份量不足 0.1，请增加食物份量或调整份数")
+			}
+		}
+	}
+
+	portions := make([]*repository.MealPortion, 0, len(req.Members))
+	for j, m := range req.Members {
+		// 当天已有该餐次时把食物追加到原记录
+		meal, err := s.mealRepo.FindByUserIDDateAndType(ctx, m.ProfileID, date, req.MealType)
+		if err != nil {
+			if !errors.Is(err, apperror.ErrNotFound) {
+				return nil, apperror.Internal("获取餐次记录失败", err)
+			}
+			meal = &model.MealRecord{UserID: m.ProfileID, Date: date, MealType: req.MealType}
+		}
+
+		portion := &repository.MealPortion{Meal: meal}
+		for i, f := range req.Foods {
+			portion.Foods = append(portion.Foods, newFoodRecord(foods[i], quantities[i][j], f.Unit))
+		}
+		portions = append(portions, portion)
+	}
+
+	if err := s.mealRepo.CreateWithFoods(ctx, portions); err != nil {
+		return nil, apperror.Internal("创建共享餐次失败", err)
+	}
+
+	return portions, nil
+}
+
+// splitQuantity 按份数占比把食物的总量分给各成员，份量保留一位小数；
+// 舍入的误差计入最后一个成员，各成员的份量之和总是等于总量
+func splitQuantity(total float64, members []SharedMealMember, totalPortion float64) []float64 {
+	quantities := make([]float64, len(members))
+	var assigned float64
+	for i, m := range members[:len(members)-1] {
+		quantities[i] = math.Round(total*m.Portion/totalPortion*10) / 10
+		assigned += quantities[i]
+	}
+	quantities[len(members)-1] = math.Round((total-assigned)*10) / 10
+	return quantities
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/internal/service"
+)
+
+// allowAll 允许访问任何档案的 AccessChecker
+type allowAll struct{}
+
+func (allowAll) CanAccess(context.Context, string, string) (bool, error) { return true, nil }
+
+func TestCreateSharedMealSplitsQuantity(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	svc := service.NewMealRecordService(repository.NewMealRecordRepository(db), repository.NewUserRepository(db),
+		repository.NewFoodRepository(db), allowAll{})
+
+	food := &model.Food{Name: "米饭", Calories: 116, Protein: 2.6, Carbohydrates: 25.9, Fat: 0.3}
+	if err := db.Create(food).Error; err != nil {
+		t.Fatalf("创建食物失败: %v", err)
+	}
+	var members []service.SharedMealMember
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		members = append(members, service.SharedMealMember{ProfileID: createUser(t, db, email, true).ID, Portion: 1})
+	}
+	req := func(quantity float64) *service.CreateSharedMealRequest {
+		return &service.CreateSharedMealRequest{
+			Date:     "2024-01-15",
+			MealType: model.Dinner,
+			Foods:    []service.SharedMealFood{{FoodID: food.ID, Quantity: quantity, Unit: "g"}},
+			Members:  members,
+		}
+	}
+
+	// 三人平分 100g，舍入的误差计入最后一个成员，份量之和等于总量
+	portions, err := svc.CreateSharedMeal(ctx, members[0].ProfileID, req(100))
+	if err != nil {
+		t.Fatalf("创建共享餐次失败: %v", err)
+	}
+	want := []float64{33.3, 33.3, 33.4}
+	var sum float64
+	for i, portion := range portions {
+		if got := portion.Foods[0].Quantity; got != want[i] {
+			t.Errorf("成员 %d 的份量 = %v，期望 %v", i, got, want[i])
+		}
+		sum += portion.Foods[0].Quantity
+	}
+	if math.Abs(sum-100) > 1e-9 {
+		t.Errorf("份量之和 = %v，期望 100", sum)
+	}
+
+	// 0.2g 分给三人时有成员分不到份量，拒绝创建且不写入任何记录
+	var before int64
+	db.Model(&model.FoodRecord{}).Count(&before)
+	_, err = svc.CreateSharedMeal(ctx, members[0].ProfileID, req(0.2))
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeMealShareTooSmall {
+		t.Fatalf("err = %v，期望 %s", err, apperror.CodeMealShareTooSmall)
+	}
+	var after int64
+	db.Model(&model.FoodRecord{}).Count(&after)
+	if after != before {
+		t.Errorf("食物记录数 = %d，期望仍为 %d", after, before)
+	}
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"github.com/ljk20041215/nutrition-tracker/internal/totp"
+)
+
+const (
+	// mfaIssuer 验证器应用中显示的服务名称
+	mfaIssuer = "Nutrition Tracker"
+	// mfaChallengeTTL 登录挑战令牌有效期
+	mfaChallengeTTL = 5 * time.Minute
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// recoveryCodeAlphabet 恢复码字符集，去掉了容易混淆的 0/o、1/l/i
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// MFAService 两步验证服务接口
+type MFAService interface {
+	Status(ctx context.Context, userID string) (*MFAStatus, error)
+	Setup(ctx context.Context, userID string) (*MFASetup, error)
+	Enable(ctx context.Context, userID string, req *MFACodeRequest) (*RecoveryCodes, error)
+	Disable(ctx context.Context, userID string, req *DisableMFARequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, req *MFACodeRequest) (*RecoveryCodes, error)
+	Challenge(ctx context.Context, user *model.User) (*MFAChallenge, error)
+	Verify(ctx context.Context, req *VerifyMFARequest, client ClientInfo) (*LoginResponse, error)
+	ForceDisable(ctx context.Context, userID string) error
+}
+
+// mfaService 两步验证服务实现
+type mfaService struct {
+	userRepo       repository.UserRepository
+	mfaRepo        repository.MFARepository
+	tokenRepo      repository.UserTokenRepository
+	attemptRepo    repository.LoginAttemptRepository
+	sessionService SessionService
+	accountService AccountService
+	guard          *loginguard.Guard
+}
+
+// NewMFAService 创建两步验证服务实例
+func NewMFAService(
+	userRepo repository.UserRepository,
+	mfaRepo repository.MFARepository,
+	tokenRepo repository.UserTokenRepository,
+	attemptRepo repository.LoginAttemptRepository,
+	sessionService SessionService,
+	accountService AccountService,
+	guard *loginguard.Guard,
+) MFAService {
+	return &mfaService{
+		userRepo:       userRepo,
+		mfaRepo:        mfaRepo,
+		tokenRepo:      tokenRepo,
+		attemptRepo:    attemptRepo,
+		sessionService: sessionService,
+		accountService: accountService,
+		guard:          guard,
+	}
+}
+
+// MFAStatus 两步验证状态
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// MFASetup 待确认的两步验证密钥
+type MFASetup struct {
+	Secret     string `json:"secret"`      // 无法扫码时手动输入
+	OTPAuthURI string `json:"otpauth_uri"` // 生成二维码供验证器应用扫描
+}
+
+// RecoveryCodes 恢复码明文，只在生成时返回一次
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallenge 登录挑战，密码正确后返回，用于提交验证码
+type MFAChallenge struct {
+	ChallengeToken string   `json:"challenge_token"`
+	ExpiresIn      int      `json:"expires_in"` // 有效期（秒）
+	Methods        []string `json:"methods"`    // 可用的验证方式
+}
+
+// MFACodeRequest 验证码请求
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableMFARequest 关闭两步验证请求，需要密码和验证码（或恢复码）
+type DisableMFARequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// VerifyMFARequest 登录时提交两步验证码的请求，code 可以是验证器应用的 6 位验证码或恢复码
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	// DeviceName 设备名称，显示在会话列表中
+	DeviceName string `json:"device_name"`
+}
+
+// Status 查询两步验证状态
+func (s *mfaService) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+
+	status := &MFAStatus{Enabled: user.TOTPEnabledAt != nil, EnabledAt: user.TOTPEnabledAt}
+	if status.Enabled {
+		status.RecoveryCodesRemaining, err = s.mfaRepo.CountRecoveryCodes(ctx, userID)
+		if err != nil {
+			return nil, apperror.Internal("查询恢复码失败", err)
+		}
+	}
+	return status, nil
+}
+
+// Setup 生成新的待确认密钥，输入验证码开启前可以重复调用
+func (s *mfaService) Setup(ctx context.Context, userID string) (*MFASetup, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, apperror.Conflict(apperror.CodeMFAAlreadyEnabled, "两步验证已开启")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, apperror.Internal("生成密钥失败", err)
+	}
+	if err := s.mfaRepo.SetSecret(ctx, userID, secret); err != nil {
+		return nil, apperror.Internal("保存密钥失败", err)
+	}
+
+	return &MFASetup{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// Enable 校验验证器应用生成的验证码后开启两步验证，返回恢复码
+func (s *mfaService) Enable(ctx context.Context, userID string, req *MFACodeRequest) (*RecoveryCodes, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, apperror.Conflict(apperror.CodeMFAAlreadyEnabled, "两步验证已开启")
+	}
+	if user.TOTPSecret == "" {
+		return nil, apperror.Conflict(apperror.CodeMFASetupRequired, "请先生成两步验证密钥")
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(user.TOTPSecret, req.Code, now)
+	if !ok {
+		return nil, apperror.Validation(apperror.CodeInvalidMFACode, "验证码错误")
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(ctx, userID, step, now, records); err != nil {
+		return nil, apperror.Internal("开启两步验证失败", err)
+	}
+	return &RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Disable 校验密码和验证码后关闭两步验证
+func (s *mfaService) Disable(ctx context.Context, userID string, req *DisableMFARequest) error {
+	user, err := s.enabledUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !checkPasswordHash(req.Password, user.PasswordHash) {
+		return apperror.Validation(apperror.CodeWrongPassword, "当前密码错误")
+	}
+	if err := s.checkCode(ctx, user, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.Disable(ctx, userID); err != nil {
+		return apperror.Internal("关闭两步验证失败", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧恢复码全部失效
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID string, req *MFACodeRequest) (*RecoveryCodes, error) {
+	user, err := s.enabledUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCode(ctx, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, apperror.Internal("保存恢复码失败", err)
+	}
+	return &RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Challenge 为密码验证通过的用户签发登录挑战令牌
+func (s *mfaService) Challenge(ctx context.Context, user *model.User) (*MFAChallenge, error) {
+	token, err := newUserToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeMFAChallenge, mfaChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAChallenge{
+		ChallengeToken: token,
+		ExpiresIn:      int(mfaChallengeTTL / time.Second),
+		Methods:        []string{"totp", "recovery_code"},
+	}, nil
+}
+
+// Verify 校验登录挑战和验证码，通过后创建会话并签发令牌
+// 验证码错误与密码错误一样计入登录失败次数，防止暴力猜测
+func (s *mfaService) Verify(ctx context.Context, req *VerifyMFARequest, client ClientInfo) (*LoginResponse, error) {
+	invalid := apperror.Unauthorized(apperror.CodeInvalidMFAChallenge, "两步验证已过期，请重新登录")
+
+	// 1. 校验挑战令牌
+	now := time.Now()
+	token, err := s.tokenRepo.FindByHash(ctx, model.TokenPurposeMFAChallenge, auth.HashToken(req.ChallengeToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, invalid
+		}
+		return nil, apperror.Internal("查询令牌失败", err)
+	}
+	if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		return nil, invalid
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, invalid
+		}
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, invalid
+	}
+
+	// 2. 检查登录限制，允许时预占一次尝试，并发猜测验证码的请求不能同时绕过锁定
+	reservation, err := s.guard.Reserve(ctx, user.Email, client.IP)
+	if err != nil {
+		return nil, apperror.Internal("检查登录限制失败", err)
+	}
+	if !reservation.Allowed() {
+		if reservation.Locked {
+			recordLoginAttempt(ctx, s.attemptRepo, user, user.Email, client, model.LoginFailedLocked)
+			return nil, apperror.TooManyRequests(apperror.CodeAccountLocked, "账户因多次登录失败已被临时锁定，请稍后再试或通过邮件中的链接解锁", reservation.RetryAfter)
+		}
+		recordLoginAttempt(ctx, s.attemptRepo, user, user.Email, client, model.LoginFailedThrottled)
+		return nil, apperror.TooManyRequests(apperror.CodeTooManyLoginAttempts, "登录失败次数过多，请稍后再试", reservation.RetryAfter)
+	}
+
+	// 3. 校验验证码或恢复码
+	if err := s.checkCode(ctx, user, req.Code); err != nil {
+		if e := apperror.As(err); e != nil && e.Code == apperror.CodeInvalidMFACode {
+			s.verifyFailed(ctx, user, client, reservation)
+			return nil, apperror.Unauthorized(apperror.CodeInvalidMFACode, "验证码或恢复码错误")
+		}
+		s.releaseAttempt(ctx, reservation)
+		return nil, err
+	}
+
+	// 4. 挑战令牌只能使用一次
+	if err := s.tokenRepo.MarkUsed(ctx, token.ID, now); err != nil {
+		s.releaseAttempt(ctx, reservation)
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, invalid
+		}
+		return nil, apperror.Internal("更新令牌失败", err)
+	}
+
+	if err := s.guard.Succeed(ctx, reservation); err != nil {
+		log.Printf("⚠️ 清除登录失败记录失败: %v", err)
+	}
+	recordLoginAttempt(ctx, s.attemptRepo, user, user.Email, client, "")
+
+	// 5. 创建会话并签发令牌
+	client.DeviceName = req.DeviceName
+	tokens, err := s.sessionService.Start(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{User: user, TokenPair: tokens}, nil
+}
+
+// ForceDisable 管理员强制关闭用户的两步验证（用户丢失验证器和恢复码时）
+func (s *mfaService) ForceDisable(ctx context.Context, userID string) error {
+	if _, err := s.enabledUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Disable(ctx, userID); err != nil {
+		return apperror.Internal("关闭两步验证失败", err)
+	}
+	log.Printf("🔓 用户 %s 的两步验证已被管理员关闭", userID)
+	return nil
+}
+
+// enabledUser 获取已开启两步验证的用户
+func (s *mfaService) enabledUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, apperror.Internal("获取用户失败", err)
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, apperror.Conflict(apperror.CodeMFANotEnabled, "两步验证未开启")
+	}
+	return user, nil
+}
+
+// checkCode 校验 6 位验证码或恢复码，验证码和恢复码都只能使用一次
+func (s *mfaService) checkCode(ctx context.Context, user *model.User, code string) error {
+	invalid := apperror.Validation(apperror.CodeInvalidMFACode, "验证码或恢复码错误")
+	now := time.Now()
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(user.TOTPSecret, code, now)
+		if !ok || step <= user.TOTPLastStep {
+			return invalid
+		}
+		if err := s.mfaRepo.UseStep(ctx, user.ID, step); err != nil {
+			if errors.Is(err, repository.ErrTOTPStepUsed) {
+				return invalid
+			}
+			return apperror.Internal("更新验证码记录失败", err)
+		}
+		return nil
+	}
+
+	if err := s.mfaRepo.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code), now); err != nil {
+		if errors.Is(err, repository.ErrRecoveryCodeInvalid) {
+			return invalid
+		}
+		return apperror.Internal("更新恢复码失败", err)
+	}
+	log.Printf("🔑 用户 %s 使用了一个恢复码", user.ID)
+	return nil
+}
+
+// verifyFailed 记录错误的验证码，账户因此被锁定时发送解锁邮件
+func (s *mfaService) verifyFailed(ctx context.Context, user *model.User, client ClientInfo, reservation *loginguard.Reservation) {
+	recordLoginAttempt(ctx, s.attemptRepo, user, user.Email, client, model.LoginFailedInvalidMFACode)
+
+	locked, err := s.guard.Fail(ctx, reservation)
+	if err != nil {
+		log.Printf("⚠️ 记录登录失败次数失败: %v", err)
+		return
+	}
+	if locked {
+		log.Printf("🔒 账户 %s 因多次两步验证失败被锁定", user.ID)
+		if err := s.accountService.SendUnlock(ctx, user); err != nil {
+			log.Printf("⚠️ 发送解锁邮件失败（用户 %s）: %v", user.ID, err)
+		}
+	}
+}
+
+// releaseAttempt 撤销预占的验证尝试
+func (s *mfaService) releaseAttempt(ctx context.Context, reservation *loginguard.Reservation) {
+	if err := s.guard.Release(ctx, reservation); err != nil {
+		log.Printf("⚠️ 撤销登录尝试失败: %v", err)
+	}
+}
+
+// newRecoveryCodes 生成一组恢复码，返回明文（格式 xxxxx-xxxxx）和只包含摘要的记录
+func newRecoveryCodes(userID string) ([]string, []*model.MFARecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*model.MFARecoveryCode, 0, recoveryCodeCount)
+
+	buf := make([]byte, 10)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, apperror.Internal("生成恢复码失败", err)
+		}
+		var b strings.Builder
+		for j, c := range buf {
+			if j == 5 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(recoveryCodeAlphabet[int(c)%len(recoveryCodeAlphabet)])
+		}
+		code := b.String()
+		codes = append(codes, code)
+		records = append(records, &model.MFARecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)})
+	}
+	return codes, records, nil
+}
+
+// hashRecoveryCode 恢复码忽略大小写、空格和连字符后计算摘要
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return auth.HashToken(code)
+}
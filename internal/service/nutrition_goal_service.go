@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
 	"github.com/ljk20041215/nutrition-tracker/internal/model"
 	"github.com/ljk20041215/nutrition-tracker/internal/repository"
 )
@@ -52,7 +53,7 @@ type CalculateGoalRequest struct {
 func (s *nutritionGoalService) GetNutritionGoal(ctx context.Context, userID string) (*model.NutritionGoal, error) {
 	goal, err := s.goalRepo.FindByUserID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("获取营养目标失败")
+		return nil, apperror.Internal("获取营养目标失败", err)
 	}
 	return goal, nil
 }
@@ -61,12 +62,15 @@ func (s *nutritionGoalService) SetNutritionGoal(ctx context.Context, userID stri
 	// 检查用户是否存在
 	_, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, err
 	}
 
 	// 查找用户是否已有营养目标
 	goal, err := s.goalRepo.FindByUserID(ctx, userID)
-	if err != nil {
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, apperror.Internal("获取营养目标失败", err)
+	}
+	if goal == nil {
 		// 如果不存在，则创建新目标
 		goal = &model.NutritionGoal{
 			UserID:        userID,
@@ -76,7 +80,7 @@ func (s *nutritionGoalService) SetNutritionGoal(ctx context.Context, userID stri
 			Fat:           req.Fat,
 		}
 		if err := s.goalRepo.Create(ctx, goal); err != nil {
-			return nil, errors.New("创建营养目标失败")
+			return nil, apperror.Internal("创建营养目标失败", err)
 		}
 	} else {
 		// 如果存在，则更新目标
@@ -85,7 +89,7 @@ func (s *nutritionGoalService) SetNutritionGoal(ctx context.Context, userID stri
 		goal.Carbohydrates = req.Carbohydrates
 		goal.Fat = req.Fat
 		if err := s.goalRepo.Update(ctx, goal); err != nil {
-			return nil, errors.New("更新营养目标失败")
+			return nil, apperror.Internal("更新营养目标失败", err)
 		}
 	}
 
@@ -96,7 +100,7 @@ func (s *nutritionGoalService) CalculateNutritionGoal(ctx context.Context, userI
 	// 获取用户当前资料
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, err
 	}
 
 	// 使用请求参数覆盖用户当前资料（如果提供）
@@ -124,7 +128,7 @@ func (s *nutritionGoalService) CalculateNutritionGoal(ctx context.Context, userI
 
 	// 验证必要参数
 	if gender == 0 || age <= 0 || height <= 0 || weight <= 0 || activityLevel == 0 {
-		return nil, errors.New("缺少必要的用户信息，请先完善个人资料")
+		return nil, apperror.Validation(apperror.CodeProfileIncomplete, "缺少必要的用户信息，请先完善个人资料")
 	}
 
 	// 计算BMR（基础代谢率）使用Mifflin-St Jeor公式
@@ -164,7 +168,7 @@ func (s *nutritionGoalService) CalculateNutritionGoal(ctx context.Context, userI
 	case "gain":
 		calories = tdee + 500 // 每天增加500卡路里，每周预计增重0.5kg
 	default:
-		return nil, errors.New("无效的目标类型")
+		return nil, apperror.Validation(apperror.CodeInvalidGoalType, "无效的目标类型")
 	}
 
 	// 计算宏量营养素目标（基于热量百分比）
@@ -189,7 +193,10 @@ func (s *nutritionGoalService) CalculateNutritionGoal(ctx context.Context, userI
 
 	// 查找用户是否已有营养目标
 	goal, err := s.goalRepo.FindByUserID(ctx, userID)
-	if err != nil {
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, apperror.Internal("获取营养目标失败", err)
+	}
+	if goal == nil {
 		// 如果不存在，则创建新目标
 		goal = &model.NutritionGoal{
 			UserID:        userID,
@@ -199,7 +206,7 @@ func (s *nutritionGoalService) CalculateNutritionGoal(ctx context.Context, userI
 			Fat:           fat,
 		}
 		if err := s.goalRepo.Create(ctx, goal); err != nil {
-			return nil, errors.New("创建营养目标失败")
+			return nil, apperror.Internal("创建营养目标失败", err)
 		}
 	} else {
 		// 如果存在，则更新目标
@@ -208,7 +215,7 @@ func (s *nutritionGoalService) CalculateNutritionGoal(ctx context.Context, userI
 		goal.Carbohydrates = carbs
 		goal.Fat = fat
 		if err := s.goalRepo.Update(ctx, goal); err != nil {
-			return nil, errors.New("更新营养目标失败")
+			return nil, apperror.Internal("更新营养目标失败", err)
 		}
 	}
 
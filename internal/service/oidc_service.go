@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/oidc"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// oidcStateTTL 从跳转到提供方到回调的最长时间
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCService 第三方登录服务接口
+type OIDCService interface {
+	Providers() []*OIDCProviderInfo
+	Begin(ctx context.Context, provider string, loginHint string) (string, error)
+	Complete(ctx context.Context, provider string, req *OIDCCallbackRequest, client ClientInfo) (*LoginResponse, error)
+}
+
+// oidcService 第三方登录服务实现
+type oidcService struct {
+	providers      *oidc.Registry
+	oidcRepo       repository.OIDCRepository
+	userRepo       repository.UserRepository
+	attemptRepo    repository.LoginAttemptRepository
+	sessionService SessionService
+	mfaService     MFAService
+}
+
+// NewOIDCService 创建第三方登录服务实例
+func NewOIDCService(
+	providers *oidc.Registry,
+	oidcRepo repository.OIDCRepository,
+	userRepo repository.UserRepository,
+	attemptRepo repository.LoginAttemptRepository,
+	sessionService SessionService,
+	mfaService MFAService,
+) OIDCService {
+	return &oidcService{
+		providers:      providers,
+		oidcRepo:       oidcRepo,
+		userRepo:       userRepo,
+		attemptRepo:    attemptRepo,
+		sessionService: sessionService,
+		mfaService:     mfaService,
+	}
+}
+
+// OIDCProviderInfo 可用的第三方登录方式
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	LoginURL    string `json:"login_url"`
+}
+
+// OIDCCallbackRequest 提供方回调参数，GET 回调从查询参数读取，POST 回调从请求体读取
+type OIDCCallbackRequest struct {
+	Code             string `json:"code" form:"code"`
+	State            string `json:"state" form:"state"`
+	Error            string `json:"error" form:"error"`
+	ErrorDescription string `json:"error_description" form:"error_description"`
+	// DeviceName 设备名称，显示在会话列表中
+	DeviceName string `json:"device_name" form:"device_name"`
+}
+
+// Providers 返回已配置的第三方登录方式
+func (s *oidcService) Providers() []*OIDCProviderInfo {
+	infos := make([]*OIDCProviderInfo, 0, len(s.providers.List()))
+	for _, p := range s.providers.List() {
+		infos = append(infos, &OIDCProviderInfo{
+			Name:        p.Name(),
+			DisplayName: p.DisplayName(),
+			LoginURL:    "/api/v1/auth/oidc/" + p.Name() + "/login",
+		})
+	}
+	return infos
+}
+
+// Begin 生成 state、nonce 和 PKCE verifier 并保存，返回提供方的登录地址
+func (s *oidcService) Begin(ctx context.Context, provider string, loginHint string) (string, error) {
+	p, ok := s.providers.Get(provider)
+	if !ok {
+		return "", apperror.NotFound(apperror.CodeUnknownOIDCProvider, "不支持该登录方式")
+	}
+
+	var values [3]string
+	for i := range values {
+		v, err := oidc.RandomString()
+		if err != nil {
+			return "", apperror.Internal("生成登录状态失败", err)
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	now := time.Now()
+	err := s.oidcRepo.CreateState(ctx, &model.OIDCState{
+		StateHash:    auth.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(oidcStateTTL),
+	})
+	if err != nil {
+		return "", apperror.Internal("保存登录状态失败", err)
+	}
+
+	// 顺便清理已过期的登录状态
+	if _, err := s.oidcRepo.DeleteExpiredStates(ctx, now); err != nil {
+		log.Printf("⚠️ 清理过期的第三方登录状态失败: %v", err)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, oidc.Challenge(verifier), loginHint)
+	if err != nil {
+		return "", apperror.Internal("连接身份提供方失败", err)
+	}
+	return authURL, nil
+}
+
+// Complete 校验回调的 state，用授权码换取并校验 ID 令牌，找到或创建用户后登录
+// 与密码登录一样，开启了两步验证的用户只返回 MFA 挑战
+func (s *oidcService) Complete(ctx context.Context, provider string, req *OIDCCallbackRequest, client ClientInfo) (*LoginResponse, error) {
+	p, ok := s.providers.Get(provider)
+	if !ok {
+		return nil, apperror.NotFound(apperror.CodeUnknownOIDCProvider, "不支持该登录方式")
+	}
+
+	// 1. 用户在提供方拒绝授权或提供方返回错误
+	if req.Error != "" {
+		log.Printf("⚠️ %s 登录失败: %s %s", provider, req.Error, req.ErrorDescription)
+		return nil, apperror.Unauthorized(apperror.CodeOIDCLoginFailed, "第三方登录失败: "+req.Error)
+	}
+	if req.Code == "" || req.State == "" {
+		return nil, apperror.BadRequest(apperror.CodeInvalidOIDCState, "缺少 code 或 state 参数")
+	}
+
+	// 2. state 只能使用一次，防止 CSRF 和重放
+	state, err := s.oidcRepo.ConsumeState(ctx, provider, auth.HashToken(req.State), time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrOIDCStateNotFound) {
+			return nil, apperror.Unauthorized(apperror.CodeInvalidOIDCState, "登录请求无效或已过期，请重新登录")
+		}
+		return nil, apperror.Internal("查询登录状态失败", err)
+	}
+
+	// 3. 换取并校验 ID 令牌
+	identity, err := p.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("⚠️ %s 登录失败: %v", provider, err)
+		return nil, apperror.Unauthorized(apperror.CodeOIDCLoginFailed, "第三方登录失败，请重试")
+	}
+
+	// 4. 找到或创建用户
+	user, err := s.resolveUser(ctx, provider, identity)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotDisabled(user); err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		challenge, err := s.mfaService.Challenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResponse{MFA: challenge}, nil
+	}
+	recordLoginAttempt(ctx, s.attemptRepo, user, user.Email, client, "")
+
+	// 5. 创建会话并签发令牌
+	client.DeviceName = req.DeviceName
+	tokens, err := s.sessionService.Start(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{User: user, TokenPair: tokens}, nil
+}
+
+// resolveUser 按外部身份查找用户；首次登录时按已验证的邮箱关联已有用户，没有则创建新用户
+func (s *oidcService) resolveUser(ctx context.Context, provider string, identity *oidc.Identity) (*model.User, error) {
+	now := time.Now()
+
+	linked, err := s.oidcRepo.FindIdentity(ctx, provider, identity.Subject)
+	if err == nil {
+		user, err := s.userRepo.FindByID(ctx, linked.UserID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, apperror.Unauthorized(apperror.CodeOIDCLoginFailed, "关联的账户已注销")
+			}
+			return nil, apperror.Internal("获取用户失败", err)
+		}
+		if err := s.oidcRepo.TouchIdentity(ctx, linked.ID, now); err != nil {
+			log.Printf("⚠️ 更新第三方登录时间失败: %v", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrIdentityNotFound) {
+		return nil, apperror.Internal("查询第三方身份失败", err)
+	}
+
+	// 只按提供方确认过的邮箱关联，否则任何人都能用伪造邮箱的第三方账户登录他人账户
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, apperror.Forbidden(apperror.CodeOIDCEmailNotVerified, "第三方账户的邮箱未验证，无法登录")
+	}
+
+	newIdentity := &model.UserIdentity{
+		Provider:    provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: now,
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, identity.Email)
+	if err == nil {
+		if err := s.link(ctx, user, newIdentity, now); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, apperror.ErrNotFound) {
+		return nil, apperror.Internal("查询用户失败", err)
+	}
+
+	// 已注销但尚未永久删除的账户仍占用邮箱
+	exists, err := s.userRepo.ExistsByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, apperror.Internal("查询用户失败", err)
+	}
+	if exists {
+		return nil, apperror.Conflict(apperror.CodeEmailRegistered, "邮箱已被注册")
+	}
+
+	// 第三方登录创建的用户没有密码，需要时可以通过找回密码设置
+	user = &model.User{
+		Email:           identity.Email,
+		Nickname:        nicknameOf(identity),
+		Role:            model.RoleUser,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.oidcRepo.CreateUserWithIdentity(ctx, user, newIdentity); err != nil {
+		return nil, apperror.Internal("创建用户失败", err)
+	}
+	log.Printf("👤 通过 %s 创建了用户 %s", provider, user.ID)
+	return user, nil
+}
+
+// link 把外部身份关联到邮箱相同的已有用户
+// 已有用户的邮箱尚未验证时，说明注册者未必拥有该邮箱（可能是抢注），
+// 因此以提供方的验证为准：标记邮箱已验证，清除原密码并注销全部会话
+func (s *oidcService) link(ctx context.Context, user *model.User, identity *model.UserIdentity, now time.Time) error {
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+		user.PasswordHash = ""
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return apperror.Internal("更新用户失败", err)
+		}
+		if err := s.sessionService.RevokeAll(ctx, user.ID, ""); err != nil {
+			return err
+		}
+		log.Printf("⚠️ 用户 %s 的邮箱未验证，关联 %s 身份时清除了原密码", user.ID, identity.Provider)
+	}
+
+	identity.UserID = user.ID
+	if err := s.oidcRepo.CreateIdentity(ctx, identity); err != nil {
+		return apperror.Internal("关联第三方身份失败", err)
+	}
+	log.Printf("🔗 用户 %s 关联了 %s 身份", user.ID, identity.Provider)
+	return nil
+}
+
+// nicknameOf 使用提供方返回的姓名作为昵称，没有时使用邮箱前缀
+func nicknameOf(identity *oidc.Identity) string {
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	return truncate(name, 50)
+}
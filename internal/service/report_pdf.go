@@ -0,0 +1,388 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/i18n"
+	"github.com/ljk20041215/nutrition-tracker/internal/pdf"
+)
+
+// PDF 报告的版式
+const (
+	reportMargin   = 50.0
+	reportContentW = pdf.PageWidth - 2*reportMargin
+	reportRowH     = 16.0
+)
+
+// PDF 报告的配色
+var (
+	reportAccent   = pdf.RGB(46, 125, 50)
+	reportGrid     = pdf.RGB(224, 224, 224)
+	reportShade    = pdf.RGB(245, 245, 245)
+	reportProtein  = pdf.RGB(66, 133, 244)
+	reportCarbs    = pdf.RGB(251, 188, 5)
+	reportFat      = pdf.RGB(234, 67, 53)
+	reportOnTarget = pdf.RGB(67, 160, 71)
+	reportOver     = pdf.RGB(239, 108, 0)
+	reportUnder    = pdf.RGB(144, 164, 174)
+)
+
+var (
+	reportBody    = pdf.Font{Size: 10, Color: pdf.Black}
+	reportBold    = pdf.Font{Size: 10, Bold: true, Color: pdf.Black}
+	reportLabel   = pdf.Font{Size: 10, Color: pdf.Gray}
+	reportSmall   = pdf.Font{Size: 7, Color: pdf.Gray}
+	reportHeading = pdf.Font{Size: 13, Bold: true, Color: reportAccent}
+)
+
+// WritePDF 把报告排版为 PDF 写出，文字使用 locale 对应的语言
+func (r *NutritionReport) WritePDF(w io.Writer, locale string) error {
+	t := func(code string) string { return i18n.T(locale, code, code) }
+
+	title := t(i18n.ReportWeekly)
+	if r.Period == ReportMonthly {
+		title = t(i18n.ReportMonthly)
+	}
+	l := &reportLayout{doc: pdf.New(t(i18n.ReportTitle) + " - " + title)}
+
+	// 标题
+	l.ensure(60)
+	l.page.Rect(reportMargin, l.y, reportContentW, 3, reportAccent)
+	l.y += 30
+	l.page.Text(reportMargin, l.y, t(i18n.ReportTitle), pdf.Font{Size: 20, Bold: true, Color: pdf.Black})
+	l.page.TextRight(pdf.PageWidth-reportMargin, l.y, title, pdf.Font{Size: 12, Bold: true, Color: reportAccent})
+	l.y += 18
+	l.page.Text(reportMargin, l.y, fmt.Sprintf(t(i18n.ReportRange), r.From.Format("2006-01-02"), r.To.Format("2006-01-02")), reportLabel)
+	l.page.TextRight(pdf.PageWidth-reportMargin, l.y, fmt.Sprintf(t(i18n.ReportGenerated), time.Now().Format("2006-01-02 15:04")), reportLabel)
+	l.y += 10
+
+	// 个人资料
+	gender := "-"
+	switch r.User.Gender {
+	case 1:
+		gender = t(i18n.ReportMale)
+	case 2:
+		gender = t(i18n.ReportFemale)
+	}
+	l.heading(t(i18n.ReportProfile))
+	l.pairs([][2]string{
+		{t(i18n.ReportNickname), orDash(r.User.Nickname)},
+		{t(i18n.ReportGender), gender},
+		{t(i18n.ReportAge), orDashf("%.0f", float64(r.User.Age), "")},
+		{t(i18n.ReportHeight), orDashf("%.0f", r.User.Height, " cm")},
+		{t(i18n.ReportWeight), orDashf("%.1f", r.User.Weight, " kg")},
+		{t(i18n.ReportActivity), orDashf("%.0f", float64(r.User.ActivityLevel), " / 5")},
+	})
+
+	// 营养目标
+	l.heading(t(i18n.ReportGoal))
+	if r.Goal != nil {
+		l.pairs([][2]string{
+			{t(i18n.ReportCalories), fmt.Sprintf("%.0f kcal", r.Goal.Calories)},
+			{t(i18n.ReportProtein), fmt.Sprintf("%.1f g", r.Goal.Protein)},
+			{t(i18n.ReportCarbohydrates), fmt.Sprintf("%.1f g", r.Goal.Carbohydrates)},
+			{t(i18n.ReportFat), fmt.Sprintf("%.1f g", r.Goal.Fat)},
+		})
+	} else {
+		l.note(t(i18n.ReportNoGoal))
+	}
+
+	// 执行情况
+	l.heading(t(i18n.ReportAdherence))
+	average := r.Adherence.Average
+	adherence := [][2]string{
+		{t(i18n.ReportDaysLogged), fmt.Sprintf("%d / %d", r.Adherence.DaysLogged, len(r.Days))},
+		{t(i18n.ReportAverage) + " " + t(i18n.ReportCalories), fmt.Sprintf("%.0f kcal", average.Calories)},
+		{t(i18n.ReportAverage) + " " + t(i18n.ReportProtein), fmt.Sprintf("%.1f g", average.Protein)},
+		{t(i18n.ReportAverage) + " " + t(i18n.ReportCarbohydrates), fmt.Sprintf("%.1f g", average.Carbohydrates)},
+		{t(i18n.ReportAverage) + " " + t(i18n.ReportFat), fmt.Sprintf("%.1f g", average.Fat)},
+	}
+	if r.Goal != nil {
+		adherence = append(adherence,
+			[2]string{t(i18n.ReportOnTarget), fmt.Sprintf("%d", r.Adherence.DaysOnTarget)},
+			[2]string{t(i18n.ReportOver), fmt.Sprintf("%d", r.Adherence.DaysOver)},
+			[2]string{t(i18n.ReportUnder), fmt.Sprintf("%d", r.Adherence.DaysUnder)},
+		)
+	}
+	l.pairs(adherence)
+
+	// 营养素占比
+	l.heading(t(i18n.ReportMacros))
+	l.macroBar(t(i18n.ReportIntake), r.Macros.Intake)
+	if r.Macros.Goal != nil {
+		l.macroBar(t(i18n.ReportGoal), *r.Macros.Goal)
+	}
+	l.legend([]string{t(i18n.ReportProtein), t(i18n.ReportCarbohydrates), t(i18n.ReportFat)},
+		[]pdf.Color{reportProtein, reportCarbs, reportFat})
+
+	// 每日热量
+	l.heading(t(i18n.ReportDailyCalories))
+	if r.Adherence.DaysLogged == 0 {
+		l.note(t(i18n.ReportNoData))
+	} else {
+		l.calorieChart(r)
+	}
+
+	// 每日合计
+	l.dailyTable(r, t)
+
+	// 页码
+	pages := l.doc.Pages()
+	for i, page := range pages {
+		page.TextCenter(pdf.PageWidth/2, pdf.PageHeight-25, fmt.Sprintf(t(i18n.ReportPage), i+1, len(pages)), reportSmall)
+	}
+
+	_, err := l.doc.WriteTo(w)
+	return err
+}
+
+// reportLayout 自上而下排版，当前页放不下时换页
+type reportLayout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+// ensure 保证当前页还有 h 的高度，不够时换页
+func (l *reportLayout) ensure(h float64) {
+	if l.page == nil || l.y+h > pdf.PageHeight-reportMargin {
+		l.page = l.doc.AddPage()
+		l.y = reportMargin
+	}
+}
+
+// heading 小节标题，和下面的第一行内容放在同一页
+func (l *reportLayout) heading(text string) {
+	l.ensure(60)
+	l.y += 28
+	l.page.Text(reportMargin, l.y, text, reportHeading)
+	l.y += 6
+	l.page.Line(reportMargin, l.y, pdf.PageWidth-reportMargin, l.y, 0.5, reportGrid)
+	l.y += 4
+}
+
+// note 一行说明文字
+func (l *reportLayout) note(text string) {
+	l.ensure(reportRowH)
+	l.y += reportRowH
+	l.page.Text(reportMargin, l.y, text, reportLabel)
+}
+
+// pairs 两列排列的名称和值
+func (l *reportLayout) pairs(items [][2]string) {
+	column := reportContentW / 2
+	for i := 0; i < len(items); i += 2 {
+		l.ensure(reportRowH)
+		l.y += reportRowH
+		for j := i; j < i+2 && j < len(items); j++ {
+			x := reportMargin + float64(j-i)*column
+			l.page.Text(x, l.y, items[j][0], reportLabel)
+			l.page.TextRight(x+column-20, l.y, items[j][1], reportBody)
+		}
+	}
+}
+
+// macroBar 一条按营养素热量占比分段的横条
+func (l *reportLayout) macroBar(label string, ratio MacroRatio) {
+	const labelW, barH = 90.0, 16.0
+	l.ensure(barH + 8)
+	l.y += 8
+	l.page.Text(reportMargin, l.y+barH-4, label, reportLabel)
+
+	x, width := reportMargin+labelW, reportContentW-labelW
+	l.page.Rect(x, l.y, width, barH, reportShade)
+	for i, share := range []float64{ratio.Protein, ratio.Carbohydrates, ratio.Fat} {
+		w := width * share / 100
+		l.page.Rect(x, l.y, w, barH, []pdf.Color{reportProtein, reportCarbs, reportFat}[i])
+		if text := fmt.Sprintf("%.0f%%", share); w > pdf.Width(text, reportBold)+6 {
+			l.page.TextCenter(x+w/2, l.y+barH-4, text, pdf.Font{Size: 10, Bold: true, Color: pdf.White})
+		}
+		x += w
+	}
+	l.y += barH
+}
+
+// legend 图例
+func (l *reportLayout) legend(labels []string, colors []pdf.Color) {
+	l.ensure(reportRowH)
+	l.y += reportRowH
+	x := reportMargin
+	for i, label := range labels {
+		l.page.Rect(x, l.y-8, 8, 8, colors[i])
+		l.page.Text(x+12, l.y, label, reportSmall)
+		x += 12 + pdf.Width(label, reportSmall) + 16
+	}
+}
+
+// calorieChart 每日热量柱状图，有目标时画出目标线，柱子颜色表示是否达标
+func (l *reportLayout) calorieChart(r *NutritionReport) {
+	const axisW, chartH = 40.0, 150.0
+	l.ensure(chartH + 30)
+	top := l.y + 12
+	bottom := top + chartH
+	left, width := reportMargin+axisW, reportContentW-axisW
+
+	max := 0.0
+	for _, day := range r.Days {
+		max = math.Max(max, day.Totals.Calories)
+	}
+	if r.Goal != nil {
+		max = math.Max(max, r.Goal.Calories)
+	}
+	step := niceStep(max, 4)
+	scale := chartH / (step * 4)
+
+	for i := 0; i <= 4; i++ {
+		y := bottom - float64(i)*step*scale
+		l.page.Line(left, y, left+width, y, 0.5, reportGrid)
+		l.page.TextRight(left-6, y+2.5, fmt.Sprintf("%.0f", float64(i)*step), reportSmall)
+	}
+
+	slot := width / float64(len(r.Days))
+	for i, day := range r.Days {
+		x := left + float64(i)*slot
+		h := day.Totals.Calories * scale
+		l.page.Rect(x+slot*0.2, bottom-h, slot*0.6, h, r.dayColor(day))
+
+		label := day.Date.Format("01-02")
+		if r.Period == ReportMonthly {
+			label = day.Date.Format("2")
+		}
+		l.page.TextCenter(x+slot/2, bottom+10, label, reportSmall)
+	}
+
+	if r.Goal != nil && r.Goal.Calories > 0 {
+		y := bottom - r.Goal.Calories*scale
+		l.page.DashedLine(left, y, left+width, y, 1, reportAccent)
+		l.page.TextRight(left+width, y-3, fmt.Sprintf("%.0f kcal", r.Goal.Calories), pdf.Font{Size: 7, Color: reportAccent})
+	}
+	l.y = bottom + 14
+}
+
+// dayColor 柱子和表格中表示当天是否达标的颜色
+func (r *NutritionReport) dayColor(day ReportDay) pdf.Color {
+	if r.Goal == nil || r.Goal.Calories <= 0 {
+		return reportAccent
+	}
+	switch deviation := day.Totals.Calories/r.Goal.Calories - 1; {
+	case deviation > adherenceTolerance:
+		return reportOver
+	case deviation < -adherenceTolerance:
+		return reportUnder
+	default:
+		return reportOnTarget
+	}
+}
+
+// dailyTable 每日合计小节，表格换页时重复表头
+func (l *reportLayout) dailyTable(r *NutritionReport, t func(string) string) {
+	// 整张表能放进一页时和小节标题一起放到同一页，不从中间断开
+	if height := 40 + float64(len(r.Days)+2)*reportRowH + 6; height <= pdf.PageHeight-2*reportMargin {
+		l.ensure(height)
+	}
+	l.heading(t(i18n.ReportDailyTotals))
+
+	headers := []string{
+		t(i18n.ReportDate), t(i18n.ReportCalories) + " (kcal)", t(i18n.ReportProtein) + " (g)",
+		t(i18n.ReportCarbohydrates) + " (g)", t(i18n.ReportFat) + " (g)", t(i18n.ReportRecords), t(i18n.ReportOfGoal),
+	}
+	// 每列的右边界，第一列左对齐
+	edges := []float64{0, 175, 250, 345, 405, 450, reportContentW}
+
+	row := func(cells []string, font pdf.Font, fill *pdf.Color) {
+		if fill != nil {
+			l.page.Rect(reportMargin, l.y, reportContentW, reportRowH, *fill)
+		}
+		baseline := l.y + reportRowH - 4.5
+		for i, cell := range cells {
+			if i == 0 {
+				l.page.Text(reportMargin+4, baseline, cell, font)
+			} else {
+				l.page.TextRight(reportMargin+edges[i]-4, baseline, cell, font)
+			}
+		}
+		l.y += reportRowH
+	}
+	header := func() {
+		l.y += 6
+		row(headers, pdf.Font{Size: 9, Bold: true, Color: pdf.Black}, &reportGrid)
+	}
+
+	header()
+	body := pdf.Font{Size: 9, Color: pdf.Black}
+	for i, day := range r.Days {
+		if l.y+reportRowH > pdf.PageHeight-reportMargin {
+			l.ensure(pdf.PageHeight)
+			header()
+		}
+		var fill *pdf.Color
+		if i%2 == 1 {
+			fill = &reportShade
+		}
+		cells := []string{day.Date.Format("2006-01-02"), "-", "-", "-", "-", "0", "-"}
+		if day.Records > 0 {
+			cells = []string{
+				cells[0],
+				fmt.Sprintf("%.0f", day.Totals.Calories),
+				fmt.Sprintf("%.1f", day.Totals.Protein),
+				fmt.Sprintf("%.1f", day.Totals.Carbohydrates),
+				fmt.Sprintf("%.1f", day.Totals.Fat),
+				fmt.Sprintf("%d", day.Records),
+				"-",
+			}
+			if r.Goal != nil && r.Goal.Calories > 0 {
+				cells[6] = fmt.Sprintf("%.0f%%", day.Totals.Calories/r.Goal.Calories*100)
+			}
+		}
+		row(cells, body, fill)
+		if day.Records > 0 && r.Goal != nil {
+			l.page.Rect(reportMargin, l.y-reportRowH, 2, reportRowH, r.dayColor(day))
+		}
+	}
+
+	average := r.Adherence.Average
+	cells := []string{t(i18n.ReportAverage), "-", "-", "-", "-", "", ""}
+	if r.Adherence.DaysLogged > 0 {
+		cells[1] = fmt.Sprintf("%.0f", average.Calories)
+		cells[2] = fmt.Sprintf("%.1f", average.Protein)
+		cells[3] = fmt.Sprintf("%.1f", average.Carbohydrates)
+		cells[4] = fmt.Sprintf("%.1f", average.Fat)
+	}
+	l.ensure(reportRowH)
+	row(cells, pdf.Font{Size: 9, Bold: true, Color: pdf.Black}, nil)
+	l.page.Line(reportMargin, l.y-reportRowH, pdf.PageWidth-reportMargin, l.y-reportRowH, 0.5, pdf.Gray)
+}
+
+// niceStep 返回把 0 到 max 分成 n 格时便于阅读的刻度间隔（1、2、2.5、5 乘以 10 的幂）
+func niceStep(max float64, n int) float64 {
+	if max <= 0 {
+		return 1
+	}
+	raw := max / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 2.5, 5, 10} {
+		if step := factor * magnitude; step >= raw {
+			return step
+		}
+	}
+	return 10 * magnitude
+}
+
+// orDash 空值显示为短横线
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// orDashf 格式化数值并加上单位，零值显示为短横线
+func orDashf(format string, v float64, unit string) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf(format, v) + unit
+}
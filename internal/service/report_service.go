@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// 报告周期
+const (
+	ReportWeekly  = "weekly"  // 日期所在的周（周一至周日）
+	ReportMonthly = "monthly" // 日期所在的自然月
+)
+
+// adherenceTolerance 当天热量在目标上下该比例以内视为达标
+const adherenceTolerance = 0.1
+
+// ReportService 营养报告服务接口
+type ReportService interface {
+	GetReport(ctx context.Context, userID, period string, date time.Time) (*NutritionReport, error)
+	Summarize(ctx context.Context, userID string, from, to time.Time) (*NutritionReport, error)
+	GetWeightTrend(ctx context.Context, userID string, from, to time.Time) ([]WeightPoint, error)
+}
+
+// reportService 营养报告服务实现
+type reportService struct {
+	foodRecordRepo repository.FoodRecordRepository
+	goalRepo       repository.NutritionGoalRepository
+	userRepo       repository.UserRepository
+	auditLogRepo   repository.AuditLogRepository
+}
+
+// NewReportService 创建营养报告服务实例
+func NewReportService(
+	foodRecordRepo repository.FoodRecordRepository,
+	goalRepo repository.NutritionGoalRepository,
+	userRepo repository.UserRepository,
+	auditLogRepo repository.AuditLogRepository,
+) ReportService {
+	return &reportService{
+		foodRecordRepo: foodRecordRepo,
+		goalRepo:       goalRepo,
+		userRepo:       userRepo,
+		auditLogRepo:   auditLogRepo,
+	}
+}
+
+// NutritionReport 一段时间内的营养汇总
+type NutritionReport struct {
+	Period    string               `json:"period,omitempty"` // 任意日期范围的汇总为空
+	From      time.Time            `json:"from"`
+	To        time.Time            `json:"to"`
+	User      *model.User          `json:"user"`
+	Goal      *model.NutritionGoal `json:"goal,omitempty"`
+	Days      []ReportDay          `json:"days"` // 范围内的每一天，没有记录的日期合计为零
+	Adherence ReportAdherence      `json:"adherence"`
+	Macros    MacroDistribution    `json:"macros"`
+}
+
+// ReportDay 一天的合计
+type ReportDay struct {
+	Date    time.Time       `json:"date"`
+	Totals  NutritionTotals `json:"totals"`
+	Records int             `json:"records"` // 食物记录条数，为零表示当天未记录
+}
+
+// ReportAdherence 目标执行情况，只统计有记录的日期
+type ReportAdherence struct {
+	DaysLogged   int             `json:"days_logged"`
+	Average      NutritionTotals `json:"average"`        // 有记录日期的日均摄入
+	DaysOnTarget int             `json:"days_on_target"` // 热量在目标 ±10% 以内的天数
+	DaysOver     int             `json:"days_over"`
+	DaysUnder    int             `json:"days_under"`
+}
+
+// MacroDistribution 三大营养素提供的热量占比（百分比），按蛋白质和碳水 4 kcal/g、脂肪 9 kcal/g 计算
+type MacroDistribution struct {
+	Intake MacroRatio  `json:"intake"`
+	Goal   *MacroRatio `json:"goal,omitempty"`
+}
+
+// MacroRatio 蛋白质、碳水、脂肪的热量占比，合计为 100，没有数据时都为零
+type MacroRatio struct {
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+}
+
+// ReportRange 返回报告周期包含的日期范围，from 和 to 都包含在内
+func ReportRange(period string, date time.Time) (time.Time, time.Time, error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case ReportWeekly:
+		from := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		return from, from.AddDate(0, 0, 6), nil
+	case ReportMonthly:
+		from := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, -1), nil
+	default:
+		return time.Time{}, time.Time{}, apperror.Validation(apperror.CodeInvalidReportPeriod, "报告周期无效，应为 weekly 或 monthly",
+			apperror.FieldError{Field: "period", Message: period})
+	}
+}
+
+// GetReport 汇总 date 所在周期的营养数据
+func (s *reportService) GetReport(ctx context.Context, userID, period string, date time.Time) (*NutritionReport, error) {
+	from, to, err := ReportRange(period, date)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.Summarize(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Period = period
+	return report, nil
+}
+
+// Summarize 汇总 from 到 to（都包含在内）的营养数据
+func (s *reportService) Summarize(ctx context.Context, userID string, from, to time.Time) (*NutritionReport, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 营养目标没有历史记录，整个周期都使用当前的目标
+	goal, err := s.goalRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.Internal("获取营养目标失败", err)
+		}
+		goal = nil
+	}
+
+	report := &NutritionReport{From: from, To: to, User: user, Goal: goal}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		report.Days = append(report.Days, ReportDay{Date: day})
+	}
+
+	err = s.foodRecordRepo.EachInRange(ctx, userID, from, to, func(entry *repository.DiaryEntry) error {
+		date := time.Date(entry.Date.Year(), entry.Date.Month(), entry.Date.Day(), 0, 0, 0, 0, time.UTC)
+		index := int(date.Sub(from).Hours() / 24)
+		if index < 0 || index >= len(report.Days) {
+			return nil
+		}
+		day := &report.Days[index]
+		day.Records++
+		day.Totals.Calories += entry.Calories
+		day.Totals.Protein += entry.Protein
+		day.Totals.Carbohydrates += entry.Carbohydrates
+		day.Totals.Fat += entry.Fat
+		return nil
+	})
+	if err != nil {
+		return nil, apperror.Internal("获取食物记录失败", err)
+	}
+
+	report.summarize()
+	return report, nil
+}
+
+// summarize 计算执行情况和营养素占比
+func (r *NutritionReport) summarize() {
+	var total NutritionTotals
+	for _, day := range r.Days {
+		if day.Records == 0 {
+			continue
+		}
+		r.Adherence.DaysLogged++
+		total.Calories += day.Totals.Calories
+		total.Protein += day.Totals.Protein
+		total.Carbohydrates += day.Totals.Carbohydrates
+		total.Fat += day.Totals.Fat
+
+		if r.Goal == nil || r.Goal.Calories <= 0 {
+			continue
+		}
+		switch deviation := day.Totals.Calories/r.Goal.Calories - 1; {
+		case deviation > adherenceTolerance:
+			r.Adherence.DaysOver++
+		case deviation < -adherenceTolerance:
+			r.Adherence.DaysUnder++
+		default:
+			r.Adherence.DaysOnTarget++
+		}
+	}
+
+	if n := float64(r.Adherence.DaysLogged); n > 0 {
+		r.Adherence.Average = NutritionTotals{
+			Calories:      total.Calories / n,
+			Protein:       total.Protein / n,
+			Carbohydrates: total.Carbohydrates / n,
+			Fat:           total.Fat / n,
+		}
+	}
+
+	r.Macros.Intake = macroRatio(total.Protein, total.Carbohydrates, total.Fat)
+	if r.Goal != nil {
+		ratio := macroRatio(r.Goal.Protein, r.Goal.Carbohydrates, r.Goal.Fat)
+		r.Macros.Goal = &ratio
+	}
+}
+
+// macroRatio 按三大营养素的克数计算热量占比
+func macroRatio(protein, carbohydrates, fat float64) MacroRatio {
+	p, c, f := protein*4, carbohydrates*4, fat*9
+	sum := p + c + f
+	if sum <= 0 {
+		return MacroRatio{}
+	}
+	return MacroRatio{Protein: p / sum * 100, Carbohydrates: c / sum * 100, Fat: f / sum * 100}
+}
+
+// WeightPoint 体重变化曲线上的一个点
+type WeightPoint struct {
+	Date   time.Time `json:"date"`
+	Weight float64   `json:"weight"` // kg
+}
+
+// GetWeightTrend 返回 from 到 to 的体重变化，每天最多一个点（取当天最后一次修改的值）
+// 体重没有单独的历史记录，从用户资料的审计日志中读取每次修改；范围开始时的体重作为第一个点，
+// 最后的体重延续到范围结束（不超过今天）。没有任何修改记录时使用资料中的当前体重
+func (s *reportService) GetWeightTrend(ctx context.Context, userID string, from, to time.Time) ([]WeightPoint, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	end := to
+	if today := dateOf(time.Now()); today.Before(end) {
+		end = today
+	}
+	if end.Before(from) {
+		return []WeightPoint{}, nil
+	}
+
+	changes, err := s.auditLogRepo.FindColumnChanges(ctx, "users", userID, "weight", end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, apperror.Internal("获取体重记录失败", err)
+	}
+
+	var start float64
+	points := []WeightPoint{}
+	for _, entry := range changes {
+		change := entry.Changes["weight"]
+		if change == nil {
+			continue
+		}
+		weight, _ := change.New.(float64)
+		if weight <= 0 {
+			continue
+		}
+		day := dateOf(entry.CreatedAt)
+		if day.Before(from) {
+			start = weight
+			continue
+		}
+		if start == 0 && len(points) == 0 {
+			// 范围开始前没有记录时，用第一次修改前的值作为起点
+			if old, _ := change.Old.(float64); old > 0 && day.After(from) {
+				start = old
+			}
+		}
+		if start > 0 && len(points) == 0 && day.After(from) {
+			points = append(points, WeightPoint{Date: from, Weight: start})
+		}
+		if n := len(points); n > 0 && points[n-1].Date.Equal(day) {
+			points[n-1].Weight = weight
+		} else {
+			points = append(points, WeightPoint{Date: day, Weight: weight})
+		}
+	}
+
+	if len(points) == 0 {
+		if start == 0 {
+			start = user.Weight
+		}
+		if start <= 0 {
+			return points, nil
+		}
+		points = append(points, WeightPoint{Date: from, Weight: start})
+	}
+	if last := points[len(points)-1]; last.Date.Before(end) {
+		points = append(points, WeightPoint{Date: end, Weight: last.Weight})
+	}
+	return points, nil
+}
+
+// dateOf 返回时间在本地时区的日期，与记录日期一样以 UTC 零点表示
+func dateOf(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
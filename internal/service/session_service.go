@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/auth"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// SessionService 登录会话服务接口
+type SessionService interface {
+	Start(ctx context.Context, user *model.User, client ClientInfo) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	ListSessions(ctx context.Context, userID string, currentSessionID string) ([]*SessionInfo, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	RevokeAll(ctx context.Context, userID string, exceptSessionID string) error
+}
+
+// sessionService 登录会话服务实现
+type sessionService struct {
+	sessionRepo   repository.SessionRepository
+	revokedRepo   repository.RevokedTokenRepository
+	userRepo      repository.UserRepository
+	refreshExpiry time.Duration
+}
+
+// NewSessionService 创建登录会话服务实例，refreshExpiry 为刷新令牌有效期
+func NewSessionService(
+	sessionRepo repository.SessionRepository,
+	revokedRepo repository.RevokedTokenRepository,
+	userRepo repository.UserRepository,
+	refreshExpiry time.Duration,
+) SessionService {
+	return &sessionService{
+		sessionRepo:   sessionRepo,
+		revokedRepo:   revokedRepo,
+		userRepo:      userRepo,
+		refreshExpiry: refreshExpiry,
+	}
+}
+
+// ClientInfo 发起登录的客户端信息，用于会话列表展示
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
+
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	Token        string `json:"token"`         // 访问令牌
+	RefreshToken string `json:"refresh_token"` // 刷新令牌，仅在签发时返回一次
+	ExpiresIn    int    `json:"expires_in"`    // 访问令牌有效期（秒）
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// SessionInfo 会话列表项
+type SessionInfo struct {
+	*model.Session
+	Current bool `json:"current"` // 是否为发起请求的会话
+}
+
+// Start 为登录成功的用户创建会话并签发令牌
+func (s *sessionService) Start(ctx context.Context, user *model.User, client ClientInfo) (*TokenPair, error) {
+	if err := checkNotDisabled(user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refreshToken, token, err := s.newRefreshToken(now)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{
+		UserID:     user.ID,
+		DeviceName: truncate(client.DeviceName, 100),
+		UserAgent:  truncate(client.UserAgent, 255),
+		IP:         client.IP,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: now,
+	}
+	if err := s.sessionRepo.Create(ctx, session, token); err != nil {
+		return nil, apperror.Internal("创建会话失败", err)
+	}
+
+	return s.issue(ctx, user, session.ID, refreshToken)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧刷新令牌随即失效
+// 已使用过的刷新令牌再次出现说明令牌可能被盗用，整个会话（令牌族）会被撤销
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	now := time.Now()
+	oldToken, err := s.sessionRepo.FindTokenByHash(ctx, auth.HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, oldToken.SessionID)
+	if err != nil {
+		return nil, apperror.Internal("获取会话失败", err)
+	}
+
+	if oldToken.UsedAt != nil {
+		return nil, s.revokeReusedSession(ctx, session, now)
+	}
+	if !session.Active(now) || !now.Before(oldToken.ExpiresAt) {
+		return nil, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "刷新令牌无效或已过期")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, apperror.Unauthorized(apperror.CodeInvalidRefreshToken, "刷新令牌无效或已过期")
+	}
+	if err := checkNotDisabled(user); err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, newToken, err := s.newRefreshToken(now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Rotate(ctx, oldToken, newToken, now); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			return nil, s.revokeReusedSession(ctx, session, now)
+		}
+		return nil, apperror.Internal("刷新令牌失败", err)
+	}
+
+	return s.issue(ctx, user, session.ID, newRefreshToken)
+}
+
+// Logout 注销刷新令牌所属的会话，会话已注销时视为成功
+func (s *sessionService) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.sessionRepo.FindTokenByHash(ctx, auth.HashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, token.SessionID)
+	if err != nil {
+		return apperror.Internal("获取会话失败", err)
+	}
+	return s.revoke(ctx, session, time.Now())
+}
+
+// ListSessions 获取用户的有效会话，currentSessionID 对应的会话标记为当前会话
+func (s *sessionService) ListSessions(ctx context.Context, userID string, currentSessionID string) ([]*SessionInfo, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return nil, apperror.Internal("获取会话列表失败", err)
+	}
+
+	infos := make([]*SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, &SessionInfo{
+			Session: session,
+			Current: session.ID == currentSessionID,
+		})
+	}
+	return infos, nil
+}
+
+// RevokeSession 撤销用户的某个会话（例如丢失的设备）
+func (s *sessionService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// 检查会话是否属于当前用户
+	if session.UserID != userID {
+		return apperror.Forbidden(apperror.CodeSessionForbidden, "无权限操作该会话")
+	}
+
+	return s.revoke(ctx, session, time.Now())
+}
+
+// RevokeAll 撤销用户的全部会话（修改或重置密码后），exceptSessionID 指定保留的当前会话
+func (s *sessionService) RevokeAll(ctx context.Context, userID string, exceptSessionID string) error {
+	now := time.Now()
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, userID, now)
+	if err != nil {
+		return apperror.Internal("获取会话列表失败", err)
+	}
+
+	for _, session := range sessions {
+		if session.ID == exceptSessionID {
+			continue
+		}
+		if err := s.revoke(ctx, session, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRefreshToken 生成刷新令牌，返回明文和待保存的记录
+func (s *sessionService) newRefreshToken(now time.Time) (string, *model.RefreshToken, error) {
+	raw, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return "", nil, apperror.Internal("生成令牌失败", err)
+	}
+	return raw, &model.RefreshToken{
+		TokenHash: auth.HashToken(raw),
+		ExpiresAt: now.Add(s.refreshExpiry),
+	}, nil
+}
+
+// issue 为会话签发访问令牌并与刷新令牌一起返回，会话记录访问令牌的 jti 以便撤销
+func (s *sessionService) issue(ctx context.Context, user *model.User, sessionID string, refreshToken string) (*TokenPair, error) {
+	claims := auth.NewClaims(user.ID, user.Email, user.Nickname, user.Role, sessionID)
+	accessToken, err := auth.SignClaims(claims)
+	if err != nil {
+		return nil, apperror.Internal("生成令牌失败", err)
+	}
+	if err := s.sessionRepo.SetAccessToken(ctx, sessionID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, apperror.Internal("更新会话失败", err)
+	}
+	return &TokenPair{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(auth.TokenExpiry.Seconds()),
+	}, nil
+}
+
+// revoke 撤销会话，并将会话最近签发且未过期的访问令牌加入拒绝名单；会话已撤销时视为成功
+func (s *sessionService) revoke(ctx context.Context, session *model.Session, now time.Time) error {
+	if err := s.sessionRepo.Revoke(ctx, session.ID, now); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return apperror.Internal("撤销会话失败", err)
+	}
+
+	if session.AccessTokenID != "" && session.AccessExpiresAt != nil && now.Before(*session.AccessExpiresAt) {
+		if err := s.revokedRepo.Revoke(ctx, session.AccessTokenID, *session.AccessExpiresAt); err != nil {
+			return apperror.Internal("撤销访问令牌失败", err)
+		}
+	}
+
+	// 顺便清理已过期的拒绝名单记录
+	if _, err := s.revokedRepo.DeleteExpired(ctx, now); err != nil {
+		log.Printf("⚠️ 清理过期的撤销令牌失败: %v", err)
+	}
+	return nil
+}
+
+// revokeReusedSession 检测到刷新令牌重用时撤销整个会话
+func (s *sessionService) revokeReusedSession(ctx context.Context, session *model.Session, now time.Time) error {
+	log.Printf("⚠️ 检测到刷新令牌重用，撤销会话 %s（用户 %s）", session.ID, session.UserID)
+	if err := s.revoke(ctx, session, now); err != nil {
+		return err
+	}
+	return apperror.Unauthorized(apperror.CodeRefreshTokenReused, "刷新令牌已被使用，该会话已被注销，请重新登录")
+}
+
+// checkNotDisabled 被管理员禁用的账户不能登录或刷新令牌
+func checkNotDisabled(user *model.User) error {
+	if user.DisabledAt != nil {
+		return apperror.Forbidden(apperror.CodeAccountDisabled, "账户已被禁用，请联系管理员")
+	}
+	return nil
+}
+
+// truncate 按字符截断字符串，避免超出数据库字段长度
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// 食物推荐的参数
+const (
+	portionMin       = 25.0  // 推荐份量的下限（克）
+	portionMax       = 300.0 // 推荐份量的上限（克）
+	portionStep      = 25.0  // 推荐份量的步长（克）
+	overshootPenalty = 0.5   // 超出缺口的热量按该比例扣分
+	frequentBonus    = 0.05  // 常吃食物的加分
+	frequentDays     = 30    // 统计常吃食物的天数
+	frequentLimit    = 20    // 常吃食物的数量
+	candidateLimit   = 200   // 从食物库预筛选后参与打分的食物数量上限
+)
+
+// SuggestionService 营养缺口分析和食物推荐服务接口
+type SuggestionService interface {
+	SuggestFoods(ctx context.Context, userID string, req *SuggestionRequest) (*NutrientGapAnalysis, error)
+}
+
+// suggestionService 营养缺口分析和食物推荐服务实现
+type suggestionService struct {
+	summaryService SummaryService
+	foodRepo       repository.FoodRepository
+	foodRecordRepo repository.FoodRecordRepository
+}
+
+// NewSuggestionService 创建营养缺口分析和食物推荐服务实例
+func NewSuggestionService(
+	summaryService SummaryService,
+	foodRepo repository.FoodRepository,
+	foodRecordRepo repository.FoodRecordRepository,
+) SuggestionService {
+	return &suggestionService{
+		summaryService: summaryService,
+		foodRepo:       foodRepo,
+		foodRecordRepo: foodRecordRepo,
+	}
+}
+
+// SuggestionRequest 食物推荐请求
+type SuggestionRequest struct {
+	Date  string `form:"date" binding:"omitempty,datetime=2006-01-02"` // 默认今天
+	Limit int    `form:"limit,default=10" binding:"min=1,max=50"`
+}
+
+// NutrientGapAnalysis 当天的营养缺口和推荐食物
+type NutrientGapAnalysis struct {
+	Date        string               `json:"date"`
+	Goal        *model.NutritionGoal `json:"goal"`
+	Intake      NutritionTotals      `json:"intake"`
+	Remaining   NutritionTotals      `json:"remaining"` // 剩余热量预算（与每日统计相同，按设置计入运动消耗）和各营养素距离目标的缺口，已达标的为 0
+	Suggestions []FoodSuggestion     `json:"suggestions"`
+}
+
+// FoodSuggestion 一种推荐食物及其推荐份量
+type FoodSuggestion struct {
+	Food           *model.Food     `json:"food"`
+	Quantity       float64         `json:"quantity"`
+	Unit           string          `json:"unit"`
+	Nutrients      NutritionTotals `json:"nutrients"`       // 推荐份量提供的营养
+	RemainingAfter NutritionTotals `json:"remaining_after"` // 吃完后剩余的热量预算和营养素缺口
+	Score          float64         `json:"score"`           // 越高越好，1 表示恰好补齐全部缺口
+	Frequent       bool            `json:"frequent"`        // 最近 30 天常吃的食物
+}
+
+// SuggestFoods 分析当天剩余的热量预算和营养素缺口，从食物库中选出最能补齐缺口的食物
+//
+// 先在数据库中预筛选 200 种候选食物：最小份量不超出剩余热量、含有有缺口的营养素，
+// 按每千卡热量中能补齐缺口的营养素热量排序；最近 30 天常吃的食物总是参与打分。
+// 对每种候选食物，在 25-300 克之间按 25 克的步长尝试份量，跳过热量超出剩余预算的份量，
+// 按得分选出最佳份量（得分相同时取较小的份量）：
+//
+//	得分 = (Σ min(提供, 缺口) - 0.5 × Σ max(提供 - 缺口, 0)) / Σ 缺口
+//
+// 其中提供和缺口都换算为热量（蛋白质和碳水 4 kcal/g、脂肪 9 kcal/g），缺口大的营养素权重更高。
+// 最近 30 天常吃的食物加 0.05 分。按得分、热量、名称和 ID 排序，相同的数据总是得到相同的结果
+func (s *suggestionService) SuggestFoods(ctx context.Context, userID string, req *SuggestionRequest) (*NutrientGapAnalysis, error) {
+	dateStr := req.Date
+	if dateStr == "" {
+		dateStr = time.Now().Format("2006-01-02")
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidDate, "日期格式错误，应为 YYYY-MM-DD")
+	}
+
+	summary, err := s.summaryService.GetDailySummary(ctx, userID, date)
+	if err != nil {
+		return nil, err
+	}
+	goal := summary.Goal
+	if goal == nil {
+		return nil, apperror.NotFound(apperror.CodeNutritionGoalNotFound, "营养目标不存在")
+	}
+
+	intake := summary.TotalIntake
+	analysis := &NutrientGapAnalysis{
+		Date:   summary.Date,
+		Goal:   goal,
+		Intake: intake,
+		Remaining: NutritionTotals{
+			Calories:      summary.RemainingCalories,
+			Protein:       math.Max(goal.Protein-intake.Protein, 0),
+			Carbohydrates: math.Max(goal.Carbohydrates-intake.Carbohydrates, 0),
+			Fat:           math.Max(goal.Fat-intake.Fat, 0),
+		},
+		Suggestions: []FoodSuggestion{},
+	}
+	remaining := analysis.Remaining
+	if remaining.Calories <= 0 || macroCalories(remaining) == 0 {
+		return analysis, nil
+	}
+
+	usage, err := s.foodRecordRepo.CountFoodUsage(ctx, userID, date.AddDate(0, 0, 1-frequentDays), date, frequentLimit)
+	if err != nil {
+		return nil, apperror.Internal("获取常吃食物失败", err)
+	}
+	frequent := make(map[string]bool, len(usage))
+	include := make([]string, 0, len(usage))
+	for _, u := range usage {
+		frequent[u.FoodID] = true
+		include = append(include, u.FoodID)
+	}
+	gap := repository.NutrientGap{
+		Calories:      remaining.Calories,
+		Protein:       remaining.Protein,
+		Carbohydrates: remaining.Carbohydrates,
+		Fat:           remaining.Fat,
+	}
+	foods, err := s.foodRepo.FindForGap(ctx, gap, portionMin, candidateLimit, include)
+	if err != nil {
+		return nil, apperror.Internal("获取食物失败", err)
+	}
+
+	var candidates []FoodSuggestion
+	for _, food := range foods {
+		suggestion, ok := bestPortion(food, remaining)
+		if !ok {
+			continue
+		}
+		if frequent[food.ID] {
+			suggestion.Frequent = true
+			suggestion.Score += frequentBonus
+		}
+		suggestion.Score = math.Round(suggestion.Score*1000) / 1000
+		candidates = append(candidates, suggestion)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Nutrients.Calories != b.Nutrients.Calories {
+			return a.Nutrients.Calories < b.Nutrients.Calories
+		}
+		if a.Food.Name != b.Food.Name {
+			return a.Food.Name < b.Food.Name
+		}
+		return a.Food.ID < b.Food.ID
+	})
+	if len(candidates) > req.Limit {
+		candidates = candidates[:req.Limit]
+	}
+	analysis.Suggestions = append(analysis.Suggestions, candidates...)
+	return analysis, nil
+}
+
+// bestPortion 选出食物得分最高且不超出剩余热量的份量，没有得分为正的份量时返回 false
+func bestPortion(food *model.Food, remaining NutritionTotals) (FoodSuggestion, bool) {
+	var best FoodSuggestion
+	found := false
+	if food.Calories <= 0 {
+		return best, false
+	}
+
+	for quantity := portionMin; quantity <= portionMax; quantity += portionStep {
+		nutrients := NutritionTotals{
+			Calories:      quantity / 100 * food.Calories,
+			Protein:       quantity / 100 * food.Protein,
+			Carbohydrates: quantity / 100 * food.Carbohydrates,
+			Fat:           quantity / 100 * food.Fat,
+		}
+		if nutrients.Calories > remaining.Calories {
+			break
+		}
+		score := gapScore(nutrients, remaining)
+		if score <= 0 || (found && score <= best.Score) {
+			continue
+		}
+		best = FoodSuggestion{
+			Food:      food,
+			Quantity:  quantity,
+			Unit:      "g",
+			Nutrients: nutrients,
+			RemainingAfter: NutritionTotals{
+				Calories:      remaining.Calories - nutrients.Calories,
+				Protein:       math.Max(remaining.Protein-nutrients.Protein, 0),
+				Carbohydrates: math.Max(remaining.Carbohydrates-nutrients.Carbohydrates, 0),
+				Fat:           math.Max(remaining.Fat-nutrients.Fat, 0),
+			},
+			Score: score,
+		}
+		found = true
+	}
+	return best, found
+}
+
+// gapScore 份量补齐营养素缺口的程度，超出缺口的部分扣分
+func gapScore(nutrients, gap NutritionTotals) float64 {
+	provided := [3]float64{nutrients.Protein * 4, nutrients.Carbohydrates * 4, nutrients.Fat * 9}
+	needed := [3]float64{gap.Protein * 4, gap.Carbohydrates * 4, gap.Fat * 9}
+
+	var closed, over float64
+	for i := range needed {
+		closed += math.Min(provided[i], needed[i])
+		over += math.Max(provided[i]-needed[i], 0)
+	}
+	return (closed - overshootPenalty*over) / macroCalories(gap)
+}
+
+// macroCalories 三大营养素折合的热量
+func macroCalories(n NutritionTotals) float64 {
+	return n.Protein*4 + n.Carbohydrates*4 + n.Fat*9
+}
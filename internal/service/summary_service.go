@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// SummaryService 每日营养统计服务接口
+type SummaryService interface {
+	GetDailySummary(ctx context.Context, userID string, date time.Time) (*DailySummary, error)
+}
+
+// summaryService 每日营养统计服务实现
+type summaryService struct {
+	foodRecordRepo repository.FoodRecordRepository
+	goalRepo       repository.NutritionGoalRepository
+	exerciseRepo   repository.ExerciseRecordRepository
+	waterRepo      repository.WaterRecordRepository
+	userRepo       repository.UserRepository
+}
+
+// NewSummaryService 创建每日营养统计服务实例
+func NewSummaryService(
+	foodRecordRepo repository.FoodRecordRepository,
+	goalRepo repository.NutritionGoalRepository,
+	exerciseRepo repository.ExerciseRecordRepository,
+	waterRepo repository.WaterRecordRepository,
+	userRepo repository.UserRepository,
+) SummaryService {
+	return &summaryService{
+		foodRecordRepo: foodRecordRepo,
+		goalRepo:       goalRepo,
+		exerciseRepo:   exerciseRepo,
+		waterRepo:      waterRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// NutritionTotals 营养素合计
+type NutritionTotals struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+}
+
+// DailySummary 每日营养统计
+type DailySummary struct {
+	Date              string               `json:"date"`
+	TotalIntake       NutritionTotals      `json:"total_intake"`
+	Goal              *model.NutritionGoal `json:"goal,omitempty"`
+	ExerciseCalories  float64              `json:"exercise_calories"`  // 当日运动消耗
+	ExerciseIncluded  bool                 `json:"exercise_included"`  // 运动消耗是否计入热量预算
+	CalorieBudget     float64              `json:"calorie_budget"`     // 当日热量预算（目标 + 计入的运动消耗）
+	RemainingCalories float64              `json:"remaining_calories"` // 剩余可摄入热量
+	WaterIntake       float64              `json:"water_intake"`       // 当日饮水量（含饮品，ml）
+	WaterTarget       float64              `json:"water_target"`       // 每日目标饮水量（ml）
+}
+
+// GetDailySummary 获取指定日期的营养统计
+func (s *summaryService) GetDailySummary(ctx context.Context, userID string, date time.Time) (*DailySummary, error) {
+	// 检查用户是否存在
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	foodRecords, err := s.foodRecordRepo.FindByUserIDAndDate(ctx, userID, date)
+	if err != nil {
+		return nil, apperror.Internal("获取食物记录失败", err)
+	}
+
+	summary := &DailySummary{Date: date.Format("2006-01-02")}
+	for _, record := range foodRecords {
+		summary.TotalIntake.Calories += record.Calories
+		summary.TotalIntake.Protein += record.Protein
+		summary.TotalIntake.Carbohydrates += record.Carbohydrates
+		summary.TotalIntake.Fat += record.Fat
+	}
+
+	exerciseCalories, err := s.exerciseRepo.SumCaloriesByUserIDAndDate(ctx, userID, date)
+	if err != nil {
+		return nil, apperror.Internal("获取运动记录失败", err)
+	}
+	summary.ExerciseCalories = exerciseCalories
+
+	waterRecords, err := s.waterRepo.FindByUserIDAndDate(ctx, userID, date)
+	if err != nil {
+		return nil, apperror.Internal("获取饮水记录失败", err)
+	}
+	for _, record := range waterRecords {
+		summary.WaterIntake += record.Amount
+	}
+	summary.WaterIntake += sumBeverageVolume(foodRecords)
+	summary.WaterTarget = calculateWaterTarget(user)
+
+	// 未设置营养目标时只返回摄入统计
+	goal, err := s.goalRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return summary, nil
+	}
+	if err != nil {
+		return nil, apperror.Internal("获取营养目标失败", err)
+	}
+	summary.Goal = goal
+	summary.CalorieBudget = goal.Calories
+
+	if includeExerciseCalories(user) {
+		summary.ExerciseIncluded = true
+		summary.CalorieBudget += exerciseCalories
+	}
+	summary.RemainingCalories = summary.CalorieBudget - summary.TotalIntake.Calories
+
+	return summary, nil
+}
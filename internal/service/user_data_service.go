@@ -0,0 +1,270 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// UserDataService 个人数据导出服务接口
+type UserDataService interface {
+	Export(ctx context.Context, userID string) (*model.UserData, error)
+}
+
+// userDataService 个人数据导出服务实现
+type userDataService struct {
+	userDataRepo repository.UserDataRepository
+}
+
+// NewUserDataService 创建个人数据导出服务实例
+func NewUserDataService(userDataRepo repository.UserDataRepository) UserDataService {
+	return &userDataService{userDataRepo: userDataRepo}
+}
+
+// Export 读取用户存储在系统中的全部数据
+func (s *userDataService) Export(ctx context.Context, userID string) (*model.UserData, error) {
+	data, err := s.userDataRepo.Load(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal("读取用户数据失败", err)
+	}
+	return data, nil
+}
+
+// WriteExportArchive 把用户数据写成 zip 压缩包：data.json 包含全部数据，每类记录另有一个 CSV 文件
+func WriteExportArchive(w io.Writer, data *model.UserData) error {
+	zw := zip.NewWriter(w)
+
+	f, err := zw.Create("data.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+
+	for _, table := range exportTables(data) {
+		if err := writeCSV(zw, table.name, table.header, table.rows); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// exportTable 导出压缩包中的一个 CSV 文件
+type exportTable struct {
+	name   string
+	header []string
+	rows   [][]string
+}
+
+// exportTables 按数据类型生成 CSV 内容
+func exportTables(data *model.UserData) []exportTable {
+	user := data.User
+	tables := []exportTable{{
+		name: "profile.csv",
+		header: []string{"id", "email", "nickname", "gender", "age", "height", "weight",
+			"activity_level", "exercise_mode", "language", "email_verified_at", "totp_enabled_at", "role", "dependent",
+			"disabled_at", "created_at", "updated_at"},
+		rows: [][]string{{user.ID, user.Email, user.Nickname, strconv.Itoa(user.Gender), strconv.Itoa(user.Age),
+			formatFloat(user.Height), formatFloat(user.Weight), strconv.Itoa(user.ActivityLevel), user.ExerciseMode,
+			user.Language, formatTimePtr(user.EmailVerifiedAt), formatTimePtr(user.TOTPEnabledAt), user.Role,
+			strconv.FormatBool(user.Dependent), formatTimePtr(user.DisabledAt), formatTime(user.CreatedAt), formatTime(user.UpdatedAt)}},
+	}}
+
+	goals := exportTable{name: "nutrition_goals.csv",
+		header: []string{"id", "calories", "protein", "carbohydrates", "fat", "created_at", "updated_at"}}
+	for _, g := range data.NutritionGoals {
+		goals.rows = append(goals.rows, []string{g.ID, formatFloat(g.Calories), formatFloat(g.Protein),
+			formatFloat(g.Carbohydrates), formatFloat(g.Fat), formatTime(g.CreatedAt), formatTime(g.UpdatedAt)})
+	}
+
+	meals := exportTable{name: "meal_records.csv",
+		header: []string{"id", "date", "meal_type", "created_at", "updated_at"}}
+	for _, m := range data.MealRecords {
+		meals.rows = append(meals.rows, []string{m.ID, formatDate(m.Date), model.MealTypeStrings[m.MealType],
+			formatTime(m.CreatedAt), formatTime(m.UpdatedAt)})
+	}
+
+	foods := exportTable{name: "food_records.csv",
+		header: []string{"id", "meal_record_id", "food_id", "food_name", "quantity", "unit",
+			"calories", "protein", "carbohydrates", "fat", "created_at", "updated_at"}}
+	for _, r := range data.FoodRecords {
+		foods.rows = append(foods.rows, []string{r.ID, r.MealRecordID, formatStringPtr(r.FoodID), r.FoodName, formatFloat(r.Quantity), r.Unit,
+			formatFloat(r.Calories), formatFloat(r.Protein), formatFloat(r.Carbohydrates), formatFloat(r.Fat),
+			formatTime(r.CreatedAt), formatTime(r.UpdatedAt)})
+	}
+
+	exercises := exportTable{name: "exercise_records.csv",
+		header: []string{"id", "date", "exercise_type", "duration", "intensity", "calories_burned", "is_manual", "created_at", "updated_at"}}
+	for _, e := range data.ExerciseRecords {
+		exercises.rows = append(exercises.rows, []string{e.ID, formatDate(e.Date), e.ExerciseType, strconv.Itoa(e.Duration),
+			string(e.Intensity), formatFloat(e.CaloriesBurned), strconv.FormatBool(e.IsManual),
+			formatTime(e.CreatedAt), formatTime(e.UpdatedAt)})
+	}
+
+	water := exportTable{name: "water_records.csv",
+		header: []string{"id", "date", "amount", "recorded_at", "created_at"}}
+	for _, w := range data.WaterRecords {
+		water.rows = append(water.rows, []string{w.ID, formatDate(w.Date), formatFloat(w.Amount),
+			formatTime(w.RecordedAt), formatTime(w.CreatedAt)})
+	}
+
+	sessions := exportTable{name: "sessions.csv",
+		header: []string{"id", "device_name", "user_agent", "ip", "created_at", "last_used_at", "expires_at", "revoked_at"}}
+	for _, s := range data.Sessions {
+		sessions.rows = append(sessions.rows, []string{s.ID, s.DeviceName, s.UserAgent, s.IP, formatTime(s.CreatedAt),
+			formatTime(s.LastUsedAt), formatTime(s.ExpiresAt), formatTimePtr(s.RevokedAt)})
+	}
+
+	identities := exportTable{name: "identities.csv",
+		header: []string{"id", "provider", "subject", "email", "last_login_at", "created_at"}}
+	for _, i := range data.Identities {
+		identities.rows = append(identities.rows, []string{i.ID, i.Provider, i.Subject, i.Email,
+			formatTime(i.LastLoginAt), formatTime(i.CreatedAt)})
+	}
+
+	apiKeys := exportTable{name: "api_keys.csv",
+		header: []string{"id", "name", "prefix", "scopes", "created_at", "last_used_at", "expires_at"}}
+	for _, k := range data.APIKeys {
+		apiKeys.rows = append(apiKeys.rows, []string{k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, " "),
+			formatTime(k.CreatedAt), formatTimePtr(k.LastUsedAt), formatTime(k.ExpiresAt)})
+	}
+
+	coachClients := exportTable{name: "coach_clients.csv",
+		header: []string{"id", "coach_id", "client_id", "status", "scopes", "message", "created_at", "accepted_at", "ended_at"}}
+	for _, l := range data.CoachClients {
+		coachClients.rows = append(coachClients.rows, []string{l.ID, l.CoachID, l.ClientID, l.Status, strings.Join(l.Scopes, " "),
+			l.Message, formatTime(l.CreatedAt), formatTimePtr(l.AcceptedAt), formatTimePtr(l.EndedAt)})
+	}
+
+	coachInvitations := exportTable{name: "coach_invitations.csv",
+		header: []string{"id", "coach_id", "email", "status", "scopes", "message", "created_at", "updated_at"}}
+	for _, i := range data.CoachInvitations {
+		coachInvitations.rows = append(coachInvitations.rows, []string{i.ID, i.CoachID, i.Email, i.Status,
+			strings.Join(i.Scopes, " "), i.Message, formatTime(i.CreatedAt), formatTime(i.UpdatedAt)})
+	}
+
+	comments := exportTable{name: "meal_comments.csv",
+		header: []string{"id", "meal_record_id", "author_id", "body", "created_at"}}
+	for _, c := range data.MealComments {
+		comments.rows = append(comments.rows, []string{c.ID, c.MealRecordID, c.AuthorID, c.Body, formatTime(c.CreatedAt)})
+	}
+
+	accessLogs := exportTable{name: "coach_access_logs.csv",
+		header: []string{"id", "coach_id", "method", "path", "status", "created_at"}}
+	for _, a := range data.CoachAccessLogs {
+		accessLogs.rows = append(accessLogs.rows, []string{a.ID, a.CoachID, a.Method, a.Path, strconv.Itoa(a.Status),
+			formatTime(a.CreatedAt)})
+	}
+
+	householdNames := make(map[string]string, len(data.Households))
+	for _, h := range data.Households {
+		householdNames[h.ID] = h.Name
+	}
+	households := exportTable{name: "household_members.csv",
+		header: []string{"id", "household_id", "household_name", "role", "created_at"}}
+	for _, m := range data.HouseholdMembers {
+		households.rows = append(households.rows, []string{m.ID, m.HouseholdID, householdNames[m.HouseholdID], m.Role,
+			formatTime(m.CreatedAt)})
+	}
+
+	invitations := exportTable{name: "household_invitations.csv",
+		header: []string{"id", "household_id", "email", "invited_by", "status", "created_at", "updated_at"}}
+	for _, i := range data.HouseholdInvitations {
+		invitations.rows = append(invitations.rows, []string{i.ID, i.HouseholdID, i.Email, i.InvitedBy, i.Status,
+			formatTime(i.CreatedAt), formatTime(i.UpdatedAt)})
+	}
+
+	loginAttempts := exportTable{name: "login_attempts.csv",
+		header: []string{"id", "email", "ip", "user_agent", "success", "reason", "created_at"}}
+	for _, a := range data.LoginAttempts {
+		loginAttempts.rows = append(loginAttempts.rows, []string{a.ID, a.Email, a.IP, a.UserAgent, strconv.FormatBool(a.Success),
+			a.Reason, formatTime(a.CreatedAt)})
+	}
+
+	recoveryCodes := exportTable{name: "mfa_recovery_codes.csv",
+		header: []string{"id", "used_at", "created_at"}}
+	for _, c := range data.MFARecoveryCodes {
+		recoveryCodes.rows = append(recoveryCodes.rows, []string{c.ID, formatTimePtr(c.UsedAt), formatTime(c.CreatedAt)})
+	}
+
+	userTokens := exportTable{name: "user_tokens.csv",
+		header: []string{"id", "purpose", "expires_at", "used_at", "created_at"}}
+	for _, t := range data.UserTokens {
+		userTokens.rows = append(userTokens.rows, []string{t.ID, string(t.Purpose), formatTime(t.ExpiresAt),
+			formatTimePtr(t.UsedAt), formatTime(t.CreatedAt)})
+	}
+
+	refreshTokens := exportTable{name: "refresh_tokens.csv",
+		header: []string{"id", "session_id", "expires_at", "used_at", "created_at"}}
+	for _, t := range data.RefreshTokens {
+		refreshTokens.rows = append(refreshTokens.rows, []string{t.ID, t.SessionID, formatTime(t.ExpiresAt),
+			formatTimePtr(t.UsedAt), formatTime(t.CreatedAt)})
+	}
+
+	auditLogs := exportTable{name: "audit_logs.csv",
+		header: []string{"id", "entity_type", "entity_id", "action", "owner_id", "actor_id", "request_id", "changes", "created_at"}}
+	for _, l := range data.AuditLogs {
+		changes, _ := json.Marshal(l.Changes)
+		auditLogs.rows = append(auditLogs.rows, []string{l.ID, l.EntityType, l.EntityID, l.Action, l.OwnerID, l.ActorID,
+			l.RequestID, string(changes), formatTime(l.CreatedAt)})
+	}
+
+	return append(tables, goals, meals, foods, exercises, water, sessions, identities, apiKeys,
+		coachClients, coachInvitations, comments, accessLogs, households, invitations, loginAttempts, recoveryCodes,
+		userTokens, refreshTokens, auditLogs)
+}
+
+// writeCSV 在压缩包中写入一个 CSV 文件
+func writeCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func formatStringPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
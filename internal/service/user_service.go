@@ -1,167 +1,285 @@
-package service
-
-import (
-	"context"
-	"errors"
-
-	"github.com/ljk20041215/nutrition-tracker/internal/auth"
-	"github.com/ljk20041215/nutrition-tracker/internal/model"
-	"github.com/ljk20041215/nutrition-tracker/internal/repository"
-	"golang.org/x/crypto/bcrypt"
-)
-
-type UserService interface {
-	Register(ctx context.Context, req *RegisterRequest) (*model.User, error)
-	Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error)
-	GetProfile(ctx context.Context, userID string) (*model.User, error)
-	UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) error
-}
-
-type userService struct {
-	userRepo repository.UserRepository
-}
-
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
-}
-
-// RegisterRequest 注册请求
-type RegisterRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	Nickname string `json:"nickname" binding:"required"`
-}
-
-func (s *userService) Register(ctx context.Context, req *RegisterRequest) (*model.User, error) {
-	// 1. 检查邮箱是否已存在
-	existing, _ := s.userRepo.FindByEmail(ctx, req.Email)
-	if existing != nil {
-		return nil, errors.New("邮箱已被注册")
-	}
-
-	// 2. 加密密码
-	passwordHash, err := hashPassword(req.Password)
-	if err != nil {
-		return nil, err
-	}
-
-	// 3. 创建用户
-	user := &model.User{
-		Email:        req.Email,
-		PasswordHash: passwordHash,
-		Nickname:     req.Nickname,
-	}
-
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, err
-	}
-
-	return user, nil
-}
-
-// LoginRequest 登录请求
-type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
-}
-
-// LoginResponse 登录响应
-type LoginResponse struct {
-	User  *model.User `json:"user"`
-	Token string      `json:"token"`
-}
-
-func (s *userService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
-	// 1. 查找用户
-	user, err := s.userRepo.FindByEmail(ctx, req.Email)
-	if err != nil {
-		return nil, errors.New("用户不存在或密码错误")
-	}
-
-	// 2. 验证密码
-	if !checkPasswordHash(req.Password, user.PasswordHash) {
-		return nil, errors.New("用户不存在或密码错误")
-	}
-
-	// 3. 生成JWT令牌
-	token, err := auth.GenerateJWT(user.ID, user.Email, user.Nickname)
-	if err != nil {
-		return nil, errors.New("生成令牌失败")
-	}
-
-	// 4. 返回响应
-	return &LoginResponse{
-		User:  user,
-		Token: token,
-	}, nil
-}
-
-// UpdateProfileRequest 更新资料请求
-type UpdateProfileRequest struct {
-	Nickname      string  `json:"nickname"`
-	Gender        int     `json:"gender"`
-	Age           int     `json:"age"`
-	Height        float64 `json:"height"`
-	Weight        float64 `json:"weight"`
-	ActivityLevel int     `json:"activity_level"`
-}
-
-// internal/service/user_service.go 中的相关方法
-func (s *userService) GetProfile(ctx context.Context, userID string) (*model.User, error) {
-	user, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	// 隐藏敏感信息
-	user.PasswordHash = ""
-	return user, nil
-}
-
-func (s *userService) UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) error {
-	// 1. 获取现有用户
-	user, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		return err
-	}
-
-	// 2. 更新字段
-	if req.Nickname != "" {
-		user.Nickname = req.Nickname
-	}
-	// 更新性别
-	if req.Gender != 0 {
-		user.Gender = req.Gender
-	}
-	// 更新年龄 (年龄应为正数)
-	if req.Age > 0 {
-		user.Age = req.Age
-	}
-	// 更新身高 (身高应为正数)
-	if req.Height > 0 {
-		user.Height = req.Height
-	}
-	// 更新体重 (体重应为正数)
-	if req.Weight > 0 {
-		user.Weight = req.Weight
-	}
-	// 更新活动水平 (活动水平应为1-5)
-	if req.ActivityLevel >= 1 && req.ActivityLevel <= 5 {
-		user.ActivityLevel = req.ActivityLevel
-	}
-
-	// 3. 保存更新
-	return s.userRepo.Update(ctx, user)
-}
-
-// 密码加密和验证辅助函数
-func hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
-}
-
-func checkPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
-
\ No newline at end of file
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/loginguard"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type UserService interface {
+	Register(ctx context.Context, req *RegisterRequest) (*model.User, error)
+	Login(ctx context.Context, req *LoginRequest, client ClientInfo) (*LoginResponse, error)
+	GetProfile(ctx context.Context, userID string) (*model.User, error)
+	UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) error
+}
+
+type userService struct {
+	userRepo       repository.UserRepository
+	attemptRepo    repository.LoginAttemptRepository
+	sessionService SessionService
+	accountService AccountService
+	mfaService     MFAService
+	guard          *loginguard.Guard
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	attemptRepo repository.LoginAttemptRepository,
+	sessionService SessionService,
+	accountService AccountService,
+	mfaService MFAService,
+	guard *loginguard.Guard,
+) UserService {
+	return &userService{
+		userRepo:       userRepo,
+		attemptRepo:    attemptRepo,
+		sessionService: sessionService,
+		accountService: accountService,
+		mfaService:     mfaService,
+		guard:          guard,
+	}
+}
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Nickname string `json:"nickname" binding:"required"`
+}
+
+func (s *userService) Register(ctx context.Context, req *RegisterRequest) (*model.User, error) {
+	// 1. 检查邮箱是否已存在（已注销的账户在永久删除前仍占用邮箱）
+	exists, err := s.userRepo.ExistsByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, apperror.Internal("查询用户失败", err)
+	}
+	if exists {
+		return nil, apperror.Conflict(apperror.CodeEmailRegistered, "邮箱已被注册")
+	}
+
+	// 2. 加密密码
+	passwordHash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 创建用户
+	user := &model.User{
+		Email:        req.Email,
+		PasswordHash: passwordHash,
+		Nickname:     req.Nickname,
+		Role:         model.RoleUser,
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	// DeviceName 设备名称，显示在会话列表中
+	DeviceName string `json:"device_name"`
+}
+
+// LoginResponse 登录响应
+// 开启两步验证的用户密码正确后只返回 MFA 挑战，需要调用 /auth/2fa/verify 完成登录
+type LoginResponse struct {
+	User *model.User `json:"user,omitempty"`
+	*TokenPair
+	MFA *MFAChallenge `json:"mfa,omitempty"`
+}
+
+func (s *userService) Login(ctx context.Context, req *LoginRequest, client ClientInfo) (*LoginResponse, error) {
+	// 1. 检查账户和 IP 是否因连续登录失败被限制，允许时预占一次尝试
+	reservation, err := s.guard.Reserve(ctx, req.Email, client.IP)
+	if err != nil {
+		return nil, apperror.Internal("检查登录限制失败", err)
+	}
+	if !reservation.Allowed() {
+		if reservation.Locked {
+			recordLoginAttempt(ctx, s.attemptRepo, nil, req.Email, client, model.LoginFailedLocked)
+			return nil, apperror.TooManyRequests(apperror.CodeAccountLocked, "账户因多次登录失败已被临时锁定，请稍后再试或通过邮件中的链接解锁", reservation.RetryAfter)
+		}
+		recordLoginAttempt(ctx, s.attemptRepo, nil, req.Email, client, model.LoginFailedThrottled)
+		return nil, apperror.TooManyRequests(apperror.CodeTooManyLoginAttempts, "登录失败次数过多，请稍后再试", reservation.RetryAfter)
+	}
+
+	// 2. 查找用户
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		s.releaseAttempt(ctx, reservation)
+		return nil, apperror.Internal("查询用户失败", err)
+	}
+
+	// 3. 验证密码
+	if user == nil || !checkPasswordHash(req.Password, user.PasswordHash) {
+		s.loginFailed(ctx, user, req.Email, client, reservation)
+		return nil, apperror.Unauthorized(apperror.CodeInvalidCredentials, "用户不存在或密码错误")
+	}
+
+	// 密码正确但还不能完成登录时撤销预占，不计入失败次数
+	if err := checkNotDisabled(user); err != nil {
+		s.releaseAttempt(ctx, reservation)
+		return nil, err
+	}
+
+	// 4. 开启了两步验证时先签发挑战令牌，验证码通过后再创建会话
+	client.DeviceName = req.DeviceName
+	if user.TOTPEnabledAt != nil {
+		s.releaseAttempt(ctx, reservation)
+		challenge, err := s.mfaService.Challenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResponse{MFA: challenge}, nil
+	}
+
+	if err := s.guard.Succeed(ctx, reservation); err != nil {
+		log.Printf("⚠️ 清除登录失败记录失败: %v", err)
+	}
+	recordLoginAttempt(ctx, s.attemptRepo, user, req.Email, client, "")
+
+	// 5. 创建会话并签发令牌
+	tokens, err := s.sessionService.Start(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// 6. 返回响应
+	return &LoginResponse{
+		User:      user,
+		TokenPair: tokens,
+	}, nil
+}
+
+// loginFailed 记录失败的登录，账户因此被锁定时发送解锁邮件
+func (s *userService) loginFailed(ctx context.Context, user *model.User, email string, client ClientInfo, reservation *loginguard.Reservation) {
+	recordLoginAttempt(ctx, s.attemptRepo, user, email, client, model.LoginFailedInvalidCredentials)
+
+	locked, err := s.guard.Fail(ctx, reservation)
+	if err != nil {
+		log.Printf("⚠️ 记录登录失败次数失败: %v", err)
+		return
+	}
+	if locked && user != nil {
+		log.Printf("🔒 账户 %s 因多次登录失败被锁定", user.ID)
+		if err := s.accountService.SendUnlock(ctx, user); err != nil {
+			log.Printf("⚠️ 发送解锁邮件失败（用户 %s）: %v", user.ID, err)
+		}
+	}
+}
+
+// releaseAttempt 撤销预占的登录尝试
+func (s *userService) releaseAttempt(ctx context.Context, reservation *loginguard.Reservation) {
+	if err := s.guard.Release(ctx, reservation); err != nil {
+		log.Printf("⚠️ 撤销登录尝试失败: %v", err)
+	}
+}
+
+// recordLoginAttempt 写入登录审计记录，reason 为空表示登录成功；写入失败不影响登录
+func recordLoginAttempt(ctx context.Context, attemptRepo repository.LoginAttemptRepository, user *model.User, email string, client ClientInfo, reason string) {
+	attempt := &model.LoginAttempt{
+		Email:     truncate(email, 255),
+		IP:        client.IP,
+		UserAgent: truncate(client.UserAgent, 255),
+		Success:   reason == "",
+		Reason:    reason,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	if err := attemptRepo.Create(ctx, attempt); err != nil {
+		log.Printf("⚠️ 写入登录审计记录失败: %v", err)
+	}
+}
+
+// UpdateProfileRequest 更新资料请求
+type UpdateProfileRequest struct {
+	Nickname      string  `json:"nickname"`
+	Gender        int     `json:"gender"`
+	Age           int     `json:"age"`
+	Height        float64 `json:"height"`
+	Weight        float64 `json:"weight"`
+	ActivityLevel int     `json:"activity_level"`
+	ExerciseMode  string  `json:"exercise_mode" binding:"omitempty,oneof=auto add ignore"` // 运动消耗计入预算方式
+	Language      string  `json:"language" binding:"omitempty,oneof=zh-CN en-US"`          // 接口消息语言偏好
+}
+
+// internal/service/user_service.go 中的相关方法
+func (s *userService) GetProfile(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 隐藏敏感信息
+	user.PasswordHash = ""
+	return user, nil
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) error {
+	// 1. 获取现有用户
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// 2. 更新字段
+	if req.Nickname != "" {
+		user.Nickname = req.Nickname
+	}
+	// 更新性别
+	if req.Gender != 0 {
+		user.Gender = req.Gender
+	}
+	// 更新年龄 (年龄应为正数)
+	if req.Age > 0 {
+		user.Age = req.Age
+	}
+	// 更新身高 (身高应为正数)
+	if req.Height > 0 {
+		user.Height = req.Height
+	}
+	// 更新体重 (体重应为正数)
+	if req.Weight > 0 {
+		user.Weight = req.Weight
+	}
+	// 更新活动水平 (活动水平应为1-5)
+	if req.ActivityLevel >= 1 && req.ActivityLevel <= 5 {
+		user.ActivityLevel = req.ActivityLevel
+	}
+	// 更新运动消耗计入方式
+	if req.ExerciseMode != "" {
+		user.ExerciseMode = req.ExerciseMode
+	}
+	// 更新语言偏好
+	if req.Language != "" {
+		user.Language = req.Language
+	}
+
+	// 3. 保存更新
+	return s.userRepo.Update(ctx, user)
+}
+
+// 密码加密和验证辅助函数
+func hashPassword(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(bytes), err
+}
+
+func checkPasswordHash(password, hash string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return err == nil
+}
+
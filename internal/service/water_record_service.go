@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/apperror"
+	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/ljk20041215/nutrition-tracker/internal/repository"
+)
+
+// WaterRecordService 饮水记录服务接口
+type WaterRecordService interface {
+	CreateWaterRecord(ctx context.Context, userID string, req *CreateWaterRecordRequest) (*model.WaterRecord, error)
+	GetHydration(ctx context.Context, userID string, date time.Time) (*HydrationSummary, error)
+	DeleteWaterRecord(ctx context.Context, userID string, waterID string) error
+	GetPresets() map[string]float64
+}
+
+// waterRecordService 饮水记录服务实现
+type waterRecordService struct {
+	waterRepo      repository.WaterRecordRepository
+	foodRecordRepo repository.FoodRecordRepository
+	userRepo       repository.UserRepository
+	access         AccessChecker
+}
+
+// NewWaterRecordService 创建饮水记录服务实例
+func NewWaterRecordService(
+	waterRepo repository.WaterRecordRepository,
+	foodRecordRepo repository.FoodRecordRepository,
+	userRepo repository.UserRepository,
+	access AccessChecker,
+) WaterRecordService {
+	return &waterRecordService{
+		waterRepo:      waterRepo,
+		foodRecordRepo: foodRecordRepo,
+		userRepo:       userRepo,
+		access:         access,
+	}
+}
+
+// waterPresets 快捷饮水量预设（ml）
+var waterPresets = map[string]float64{
+	"cup":    250,
+	"bottle": 500,
+}
+
+// volumeUnits 容量单位换算为毫升的系数，食物记录使用这些单位时计入饮水量
+var volumeUnits = map[string]float64{
+	"ml": 1,
+	"毫升": 1,
+	"l":  1000,
+	"升":  1000,
+}
+
+// CreateWaterRecordRequest 创建饮水记录请求
+type CreateWaterRecordRequest struct {
+	Amount     float64 `json:"amount" binding:"omitempty,gt=0"`             // 饮水量（ml），与 preset 二选一
+	Preset     string  `json:"preset" binding:"omitempty,oneof=cup bottle"` // 快捷预设：cup(250ml)/bottle(500ml)
+	RecordedAt string  `json:"recorded_at"`                                 // 饮水时间（RFC3339），默认当前时间
+}
+
+// HydrationSummary 每日饮水统计
+type HydrationSummary struct {
+	Date           string               `json:"date"`
+	Target         float64              `json:"target"`          // 每日目标饮水量（ml）
+	WaterIntake    float64              `json:"water_intake"`    // 饮水记录合计（ml）
+	BeverageIntake float64              `json:"beverage_intake"` // 以容量单位记录的饮品合计（ml）
+	TotalIntake    float64              `json:"total_intake"`    // 总饮水量（ml）
+	Remaining      float64              `json:"remaining"`       // 距离目标的剩余量（ml）
+	Records        []*model.WaterRecord `json:"records"`
+}
+
+// CreateWaterRecord 创建饮水记录
+func (s *waterRecordService) CreateWaterRecord(ctx context.Context, userID string, req *CreateWaterRecordRequest) (*model.WaterRecord, error) {
+	// 检查用户是否存在
+	_, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := req.Amount
+	if req.Preset != "" {
+		amount = waterPresets[req.Preset]
+	}
+	if amount <= 0 {
+		return nil, apperror.Validation(apperror.CodeWaterAmountRequired, "请填写饮水量或选择快捷预设")
+	}
+
+	// 解析饮水时间
+	recordedAt := time.Now()
+	if req.RecordedAt != "" {
+		recordedAt, err = time.Parse(time.RFC3339, req.RecordedAt)
+		if err != nil {
+			return nil, apperror.Validation(apperror.CodeInvalidDate, "时间格式错误，应为 RFC3339 格式")
+		}
+	}
+
+	waterRecord := &model.WaterRecord{
+		UserID:     userID,
+		Date:       time.Date(recordedAt.Year(), recordedAt.Month(), recordedAt.Day(), 0, 0, 0, 0, time.UTC),
+		Amount:     amount,
+		RecordedAt: recordedAt,
+	}
+
+	if err := s.waterRepo.Create(ctx, waterRecord); err != nil {
+		return nil, apperror.Internal("创建饮水记录失败", err)
+	}
+
+	return waterRecord, nil
+}
+
+// GetHydration 获取指定日期的饮水统计
+func (s *waterRecordService) GetHydration(ctx context.Context, userID string, date time.Time) (*HydrationSummary, error) {
+	// 检查用户是否存在
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	waterRecords, err := s.waterRepo.FindByUserIDAndDate(ctx, userID, date)
+	if err != nil {
+		return nil, apperror.Internal("获取饮水记录失败", err)
+	}
+
+	foodRecords, err := s.foodRecordRepo.FindByUserIDAndDate(ctx, userID, date)
+	if err != nil {
+		return nil, apperror.Internal("获取食物记录失败", err)
+	}
+
+	summary := &HydrationSummary{
+		Date:    date.Format("2006-01-02"),
+		Target:  calculateWaterTarget(user),
+		Records: waterRecords,
+	}
+	for _, record := range waterRecords {
+		summary.WaterIntake += record.Amount
+	}
+	summary.BeverageIntake = sumBeverageVolume(foodRecords)
+	summary.TotalIntake = summary.WaterIntake + summary.BeverageIntake
+	summary.Remaining = summary.Target - summary.TotalIntake
+	if summary.Remaining < 0 {
+		summary.Remaining = 0
+	}
+
+	return summary, nil
+}
+
+// DeleteWaterRecord 删除饮水记录
+func (s *waterRecordService) DeleteWaterRecord(ctx context.Context, userID string, waterID string) error {
+	waterRecord, err := s.waterRepo.FindByID(ctx, waterID)
+	if err != nil {
+		return err
+	}
+
+	// 检查权限
+	if err := checkAccess(ctx, s.access, userID, waterRecord.UserID, apperror.CodeWaterRecordForbidden, "无权限删除该饮水记录"); err != nil {
+		return err
+	}
+
+	if err := s.waterRepo.Delete(ctx, waterID); err != nil {
+		return apperror.Internal("删除饮水记录失败", err)
+	}
+
+	return nil
+}
+
+// GetPresets 获取快捷饮水量预设
+func (s *waterRecordService) GetPresets() map[string]float64 {
+	return waterPresets
+}
+
+// calculateWaterTarget 根据体重和活动水平计算每日目标饮水量（ml）
+// 基础需水量按每公斤体重35ml计算，活动水平每提高一级增加250ml；未填写体重时使用2000ml
+func calculateWaterTarget(user *model.User) float64 {
+	if user.Weight <= 0 {
+		return 2000
+	}
+
+	target := user.Weight * 35
+	if user.ActivityLevel > 1 {
+		target += float64(user.ActivityLevel-1) * 250
+	}
+
+	return target
+}
+
+// sumBeverageVolume 统计以容量单位记录的饮品总量（ml）
+func sumBeverageVolume(foodRecords []*model.FoodRecord) float64 {
+	var total float64
+	for _, record := range foodRecords {
+		if factor, exists := volumeUnits[strings.ToLower(record.Unit)]; exists {
+			total += record.Quantity * factor
+		}
+	}
+	return total
+}
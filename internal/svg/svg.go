@@ -0,0 +1,129 @@
+// Package svg 生成简单的 SVG 图形：矩形、线条、折线、扇形和文字，足以绘制常见的统计图表
+//
+// 坐标以像素为单位，原点在左上角。颜色使用 CSS 颜色字符串，如 #2e7d32。
+package svg
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 文字对齐方式，对应 text-anchor 属性
+const (
+	Start  = "start"
+	Middle = "middle"
+	End    = "end"
+)
+
+// Canvas 一张 SVG 图，元素按调用顺序绘制，后绘制的在上层
+type Canvas struct {
+	Width, Height float64
+	body          bytes.Buffer
+}
+
+// New 创建指定尺寸的画布，title 作为图形的标题供屏幕阅读器使用
+func New(width, height float64, title string) *Canvas {
+	c := &Canvas{Width: width, Height: height}
+	fmt.Fprintf(&c.body, `<title>%s</title>`, html.EscapeString(title))
+	return c
+}
+
+// Rect 填充矩形
+func (c *Canvas) Rect(x, y, w, h float64, fill string) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	fmt.Fprintf(&c.body, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`,
+		num(x), num(y), num(w), num(h), attr(fill))
+}
+
+// Line 线段，dashed 为 true 时绘制虚线
+func (c *Canvas) Line(x1, y1, x2, y2 float64, stroke string, width float64, dashed bool) {
+	fmt.Fprintf(&c.body, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s"%s/>`,
+		num(x1), num(y1), num(x2), num(y2), attr(stroke), num(width), dash(dashed))
+}
+
+// Polyline 折线，点的坐标依次为 x、y
+func (c *Canvas) Polyline(points [][2]float64, stroke string, width float64) {
+	if len(points) < 2 {
+		return
+	}
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = num(p[0]) + "," + num(p[1])
+	}
+	fmt.Fprintf(&c.body, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round" stroke-linecap="round"/>`,
+		strings.Join(coords, " "), attr(stroke), num(width))
+}
+
+// Circle 填充圆
+func (c *Canvas) Circle(cx, cy, r float64, fill string) {
+	fmt.Fprintf(&c.body, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`, num(cx), num(cy), num(r), attr(fill))
+}
+
+// Sector 扇形，角度以弧度为单位，从 12 点方向开始顺时针计算
+func (c *Canvas) Sector(cx, cy, r, start, end float64, fill string) {
+	if end-start <= 0 {
+		return
+	}
+	if end-start >= 2*math.Pi-1e-9 {
+		c.Circle(cx, cy, r, fill)
+		return
+	}
+	point := func(angle float64) (float64, float64) {
+		return cx + r*math.Sin(angle), cy - r*math.Cos(angle)
+	}
+	x1, y1 := point(start)
+	x2, y2 := point(end)
+	large := 0
+	if end-start > math.Pi {
+		large = 1
+	}
+	fmt.Fprintf(&c.body, `<path d="M%s %sL%s %sA%s %s 0 %d 1 %s %sZ" fill="%s"/>`,
+		num(cx), num(cy), num(x1), num(y1), num(r), num(r), large, num(x2), num(y2), attr(fill))
+}
+
+// Text 文字，y 为基线位置
+func (c *Canvas) Text(x, y float64, s string, size float64, fill, anchor string, bold bool) {
+	weight := ""
+	if bold {
+		weight = ` font-weight="bold"`
+	}
+	fmt.Fprintf(&c.body, `<text x="%s" y="%s" font-size="%s" fill="%s" text-anchor="%s"%s>%s</text>`,
+		num(x), num(y), num(size), attr(fill), attr(anchor), weight, html.EscapeString(s))
+}
+
+// Bytes 返回完整的 SVG 文档
+func (c *Canvas) Bytes() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" role="img" font-family="sans-serif">`,
+		num(c.Width), num(c.Height), num(c.Width), num(c.Height))
+	b.Write(c.body.Bytes())
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
+
+func dash(dashed bool) string {
+	if dashed {
+		return ` stroke-dasharray="6 4"`
+	}
+	return ""
+}
+
+func attr(s string) string {
+	return html.EscapeString(s)
+}
+
+// num 格式化坐标，保留两位小数并去掉末尾的零
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
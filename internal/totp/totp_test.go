@@ -0,0 +1,113 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ljk20041215/nutrition-tracker/internal/totp"
+)
+
+// rfcSecret RFC 6238 附录 B 的 SHA1 密钥 "12345678901234567890"（base32 编码）
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// RFC 6238 附录 B 的 SHA1 测试向量，验证码取 8 位结果的后 6 位
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},          // 94287082
+	{1111111109, "081804"},  // 07081804
+	{1111111111, "050471"},  // 14050471
+	{1234567890, "005924"},  // 89005924
+	{2000000000, "279037"},  // 69279037
+	{20000000000, "353130"}, // 65353130
+}
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	for _, v := range rfcVectors {
+		now := time.Unix(v.unix, 0)
+		code, err := totp.Code(rfcSecret, totp.Step(now))
+		if err != nil {
+			t.Fatalf("计算验证码失败: %v", err)
+		}
+		if code != v.code {
+			t.Errorf("T=%d: 验证码 = %s，期望 %s", v.unix, code, v.code)
+		}
+
+		// 小写密钥同样可用
+		if lower, _ := totp.Code(strings.ToLower(rfcSecret), totp.Step(now)); lower != v.code {
+			t.Errorf("T=%d: 小写密钥的验证码 = %s，期望 %s", v.unix, lower, v.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := totp.Step(now)
+
+	tests := []struct {
+		name     string
+		step     int64
+		wantOK   bool
+		wantStep int64
+	}{
+		{"当前步长", current, true, current},
+		{"上一个步长", current - 1, true, current - 1},
+		{"下一个步长", current + 1, true, current + 1},
+		{"超出偏差", current - 2, false, 0},
+		{"超出偏差（未来）", current + 2, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totp.Code(rfcSecret, tt.step)
+			if err != nil {
+				t.Fatalf("计算验证码失败: %v", err)
+			}
+			step, ok := totp.Validate(rfcSecret, " "+code+" ", now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate = (%d, %v)，期望 (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	for _, code := range []string{"", "05047", "0504710", "abcdef"} {
+		if _, ok := totp.Validate(rfcSecret, code, now); ok {
+			t.Errorf("验证码 %q 不应通过", code)
+		}
+	}
+	if _, ok := totp.Validate("not base32!", "050471", now); ok {
+		t.Error("无效密钥不应通过")
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("密钥长度 = %d，期望 32（160 位）", len(secret))
+	}
+	if _, err := totp.Code(secret, 1); err != nil {
+		t.Errorf("生成的密钥无法使用: %v", err)
+	}
+
+	uri, err := url.Parse(totp.URI("Nutrition Tracker", "user@example.com", secret))
+	if err != nil {
+		t.Fatalf("解析链接失败: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Nutrition Tracker:user@example.com" {
+		t.Errorf("链接 = %s", uri)
+	}
+	if strings.Contains(uri.RawQuery, "+") {
+		t.Errorf("查询参数中的空格应编码为 %%20: %s", uri.RawQuery)
+	}
+	params := uri.Query()
+	if params.Get("secret") != secret || params.Get("issuer") != "Nutrition Tracker" ||
+		params.Get("digits") != "6" || params.Get("period") != "30" || params.Get("algorithm") != "SHA1" {
+		t.Errorf("查询参数 = %v", params)
+	}
+}
@@ -0,0 +1,12 @@
+// Package migrations 内嵌按数据库驱动划分的版本化 SQL 迁移文件
+//
+// 文件命名格式：<版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql，
+// 例如 0001_init.up.sql。新增迁移请使用 `server migrate create <name>` 生成。
+package migrations
+
+import "embed"
+
+// FS 包含 postgres/ 与 sqlite/ 两个目录下的迁移文件
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
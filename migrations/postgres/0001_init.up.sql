@@ -0,0 +1,99 @@
+-- 初始表结构，与之前 AutoMigrate 生成的结构保持一致
+-- 使用 IF NOT EXISTS，已由 AutoMigrate 建表的数据库可以直接纳入迁移管理
+
+CREATE TABLE IF NOT EXISTS users (
+    id             uuid PRIMARY KEY,
+    email          varchar(255) NOT NULL,
+    password_hash  text NOT NULL,
+    nickname       varchar(50),
+    gender         int DEFAULT 0,
+    age            int,
+    height         float,
+    weight         float,
+    activity_level int DEFAULT 3,
+    exercise_mode  varchar(10) DEFAULT 'auto',
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS nutrition_goals (
+    id            uuid PRIMARY KEY,
+    user_id       uuid NOT NULL,
+    calories      decimal,
+    protein       decimal,
+    carbohydrates decimal,
+    fat           decimal,
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_nutrition_goals_user_id ON nutrition_goals (user_id);
+
+CREATE TABLE IF NOT EXISTS foods (
+    id            uuid PRIMARY KEY,
+    name          text NOT NULL,
+    calories      decimal,
+    protein       decimal,
+    carbohydrates decimal,
+    fat           decimal,
+    created_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_foods_name ON foods (name);
+
+CREATE TABLE IF NOT EXISTS meal_records (
+    id         uuid PRIMARY KEY,
+    user_id    uuid NOT NULL,
+    date       date NOT NULL,
+    meal_type  int NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_meal_records_user_id ON meal_records (user_id);
+CREATE INDEX IF NOT EXISTS idx_meal_records_date ON meal_records (date);
+
+CREATE TABLE IF NOT EXISTS food_records (
+    id             uuid PRIMARY KEY,
+    meal_record_id uuid NOT NULL,
+    food_id        uuid NOT NULL,
+    food_name      varchar(100) NOT NULL,
+    quantity       float NOT NULL,
+    unit           varchar(20) NOT NULL,
+    calories       decimal,
+    protein        decimal,
+    carbohydrates  decimal,
+    fat            decimal,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    CONSTRAINT fk_food_records_meal_record FOREIGN KEY (meal_record_id) REFERENCES meal_records (id),
+    CONSTRAINT fk_food_records_food FOREIGN KEY (food_id) REFERENCES foods (id)
+);
+CREATE INDEX IF NOT EXISTS idx_food_records_meal_record_id ON food_records (meal_record_id);
+CREATE INDEX IF NOT EXISTS idx_food_records_food_id ON food_records (food_id);
+
+CREATE TABLE IF NOT EXISTS exercise_records (
+    id              uuid PRIMARY KEY,
+    user_id         uuid NOT NULL,
+    date            date NOT NULL,
+    exercise_type   varchar(50) NOT NULL,
+    duration        int NOT NULL,
+    intensity       varchar(20) NOT NULL,
+    calories_burned decimal,
+    is_manual       boolean DEFAULT false,
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_exercise_records_user_id ON exercise_records (user_id);
+CREATE INDEX IF NOT EXISTS idx_exercise_records_date ON exercise_records (date);
+
+CREATE TABLE IF NOT EXISTS water_records (
+    id          uuid PRIMARY KEY,
+    user_id     uuid NOT NULL,
+    date        date NOT NULL,
+    amount      float NOT NULL,
+    recorded_at timestamptz NOT NULL,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_water_records_user_id ON water_records (user_id);
+CREATE INDEX IF NOT EXISTS idx_water_records_date ON water_records (date);
@@ -0,0 +1,6 @@
+ALTER TABLE water_records DROP CONSTRAINT IF EXISTS chk_water_records_amount;
+ALTER TABLE exercise_records DROP CONSTRAINT IF EXISTS chk_exercise_records_duration;
+ALTER TABLE food_records DROP CONSTRAINT IF EXISTS chk_food_records_quantity;
+ALTER TABLE meal_records DROP CONSTRAINT IF EXISTS chk_meal_records_meal_type;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_exercise_mode;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_activity_level;
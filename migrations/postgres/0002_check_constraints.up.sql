@@ -0,0 +1,7 @@
+-- 数据合法性约束（AutoMigrate 无法添加）
+ALTER TABLE users ADD CONSTRAINT chk_users_activity_level CHECK (activity_level BETWEEN 1 AND 5);
+ALTER TABLE users ADD CONSTRAINT chk_users_exercise_mode CHECK (exercise_mode IN ('auto', 'add', 'ignore'));
+ALTER TABLE meal_records ADD CONSTRAINT chk_meal_records_meal_type CHECK (meal_type BETWEEN 1 AND 4);
+ALTER TABLE food_records ADD CONSTRAINT chk_food_records_quantity CHECK (quantity > 0);
+ALTER TABLE exercise_records ADD CONSTRAINT chk_exercise_records_duration CHECK (duration > 0);
+ALTER TABLE water_records ADD CONSTRAINT chk_water_records_amount CHECK (amount > 0);
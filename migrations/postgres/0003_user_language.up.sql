@@ -0,0 +1,2 @@
+-- 用户接口消息语言偏好
+ALTER TABLE users ADD COLUMN language varchar(10);
@@ -0,0 +1,25 @@
+-- 登录会话和刷新令牌
+CREATE TABLE IF NOT EXISTS sessions (
+    id           uuid PRIMARY KEY,
+    user_id      uuid NOT NULL,
+    device_name  varchar(100),
+    user_agent   varchar(255),
+    ip           varchar(45),
+    expires_at   timestamptz NOT NULL,
+    last_used_at timestamptz NOT NULL,
+    revoked_at   timestamptz,
+    created_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         uuid PRIMARY KEY,
+    session_id uuid NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz,
+    CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
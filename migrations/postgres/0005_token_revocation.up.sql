@@ -0,0 +1,10 @@
+-- 访问令牌撤销：会话记录最近签发的访问令牌，撤销的 jti 写入拒绝名单
+ALTER TABLE sessions ADD COLUMN access_token_id varchar(64);
+ALTER TABLE sessions ADD COLUMN access_expires_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti        varchar(64) PRIMARY KEY,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
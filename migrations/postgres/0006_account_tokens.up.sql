@@ -0,0 +1,14 @@
+-- 邮箱验证状态和一次性令牌（密码重置、邮箱验证）
+ALTER TABLE users ADD COLUMN email_verified_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id         uuid PRIMARY KEY,
+    user_id    uuid NOT NULL,
+    purpose    varchar(30) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tokens_token_hash ON user_tokens (token_hash);
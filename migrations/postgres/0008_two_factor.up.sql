@@ -0,0 +1,13 @@
+-- 两步验证（TOTP）密钥和恢复码
+ALTER TABLE users ADD COLUMN totp_secret varchar(64);
+ALTER TABLE users ADD COLUMN totp_enabled_at timestamptz;
+ALTER TABLE users ADD COLUMN totp_last_step bigint DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id         uuid PRIMARY KEY,
+    user_id    uuid NOT NULL,
+    code_hash  varchar(64) NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
@@ -0,0 +1,4 @@
+-- 用户角色（user/coach/admin）和账户禁用
+ALTER TABLE users ADD COLUMN role varchar(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN disabled_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role);
@@ -0,0 +1,20 @@
+-- 家庭共享：家庭、成员和受抚养成员档案
+ALTER TABLE users ADD COLUMN dependent boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS households (
+    id         uuid PRIMARY KEY,
+    name       varchar(100) NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+    id           uuid PRIMARY KEY,
+    household_id uuid NOT NULL,
+    user_id      uuid NOT NULL,
+    role         varchar(20) NOT NULL,
+    created_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_household_members_household_id ON household_members (household_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_household_members_user_id ON household_members (user_id);
@@ -0,0 +1,15 @@
+-- 数据变更审计日志
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          uuid PRIMARY KEY,
+    entity_type varchar(50) NOT NULL,
+    entity_id   varchar(64) NOT NULL,
+    action      varchar(10) NOT NULL,
+    actor_id    varchar(64),
+    request_id  varchar(64),
+    changes     text NOT NULL,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs (request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
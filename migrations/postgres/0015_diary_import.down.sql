@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_food_records_import_key;
+ALTER TABLE food_records DROP COLUMN IF EXISTS import_key;
+DELETE FROM food_records WHERE food_id IS NULL;
+ALTER TABLE food_records ALTER COLUMN food_id SET NOT NULL;
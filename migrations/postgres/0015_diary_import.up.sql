@@ -0,0 +1,5 @@
+-- 导入其他应用的饮食日记：找不到对应食物的记录作为快速添加条目保存，food_id 为空；
+-- import_key 标识导入来源中的一行，重复导入时跳过已导入的行
+ALTER TABLE food_records ALTER COLUMN food_id DROP NOT NULL;
+ALTER TABLE food_records ADD COLUMN import_key varchar(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_food_records_import_key ON food_records (import_key);
@@ -0,0 +1,2 @@
+ALTER TABLE food_records DROP CONSTRAINT IF EXISTS fk_food_records_food;
+ALTER TABLE food_records ADD CONSTRAINT fk_food_records_food FOREIGN KEY (food_id) REFERENCES foods (id);
@@ -0,0 +1,3 @@
+-- 管理员删除公共食物后，引用该食物的记录保留食物名称和营养数据，food_id 置空
+ALTER TABLE food_records DROP CONSTRAINT IF EXISTS fk_food_records_food;
+ALTER TABLE food_records ADD CONSTRAINT fk_food_records_food FOREIGN KEY (food_id) REFERENCES foods (id) ON DELETE SET NULL;
@@ -0,0 +1,40 @@
+-- 审计日志记录的数据所属的用户，永久清除用户时按该字段删除与其数据有关的审计日志
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS owner_id varchar(64);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_owner_id ON audit_logs (owner_id);
+
+-- 回填已有的日志：创建和删除日志的 changes 保存了完整的行数据，只有部分字段的更新日志从现有数据或同一记录的其他日志中取得
+UPDATE audit_logs SET owner_id = entity_id WHERE entity_type = 'users';
+
+UPDATE audit_logs
+SET owner_id = COALESCE(changes::jsonb #>> '{user_id,new}', changes::jsonb #>> '{user_id,old}')
+WHERE entity_type IN ('nutrition_goals', 'meal_records');
+UPDATE audit_logs
+SET owner_id = (SELECT user_id::text FROM nutrition_goals WHERE nutrition_goals.id::text = audit_logs.entity_id)
+WHERE entity_type = 'nutrition_goals' AND owner_id IS NULL;
+UPDATE audit_logs
+SET owner_id = (SELECT user_id::text FROM meal_records WHERE meal_records.id::text = audit_logs.entity_id)
+WHERE entity_type = 'meal_records' AND owner_id IS NULL;
+
+-- 食物记录属于所在餐次的用户，餐次已经删除时从餐次的审计日志中取得
+UPDATE audit_logs
+SET owner_id = COALESCE(
+    (SELECT user_id::text FROM meal_records
+     WHERE meal_records.id::text = COALESCE(audit_logs.changes::jsonb #>> '{meal_record_id,new}', audit_logs.changes::jsonb #>> '{meal_record_id,old}')),
+    (SELECT meals.owner_id FROM audit_logs AS meals
+     WHERE meals.entity_type = 'meal_records' AND meals.owner_id IS NOT NULL
+       AND meals.entity_id = COALESCE(audit_logs.changes::jsonb #>> '{meal_record_id,new}', audit_logs.changes::jsonb #>> '{meal_record_id,old}')
+     LIMIT 1))
+WHERE entity_type = 'food_records';
+UPDATE audit_logs
+SET owner_id = (SELECT meal_records.user_id::text FROM food_records JOIN meal_records ON meal_records.id = food_records.meal_record_id
+                WHERE food_records.id::text = audit_logs.entity_id)
+WHERE entity_type = 'food_records' AND owner_id IS NULL;
+
+UPDATE audit_logs
+SET owner_id = (SELECT other.owner_id FROM audit_logs AS other
+                WHERE other.entity_type = audit_logs.entity_type AND other.entity_id = audit_logs.entity_id AND other.owner_id IS NOT NULL
+                LIMIT 1)
+WHERE entity_type IN ('nutrition_goals', 'meal_records', 'food_records') AND owner_id IS NULL;
+
+-- 公共食物等没有所属用户的日志与回调写入的一致，为空字符串
+UPDATE audit_logs SET owner_id = '' WHERE owner_id IS NULL;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS water_records;
+DROP TABLE IF EXISTS exercise_records;
+DROP TABLE IF EXISTS food_records;
+DROP TABLE IF EXISTS meal_records;
+DROP TABLE IF EXISTS foods;
+DROP TABLE IF EXISTS nutrition_goals;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,96 @@
+-- 初始表结构（SQLite），CHECK 约束直接在建表时定义
+
+CREATE TABLE IF NOT EXISTS users (
+    id             text PRIMARY KEY,
+    email          varchar(255) NOT NULL,
+    password_hash  text NOT NULL,
+    nickname       varchar(50),
+    gender         integer DEFAULT 0,
+    age            integer,
+    height         real,
+    weight         real,
+    activity_level integer DEFAULT 3 CHECK (activity_level BETWEEN 1 AND 5),
+    exercise_mode  varchar(10) DEFAULT 'auto' CHECK (exercise_mode IN ('auto', 'add', 'ignore')),
+    created_at     datetime,
+    updated_at     datetime,
+    deleted_at     datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS nutrition_goals (
+    id            text PRIMARY KEY,
+    user_id       text NOT NULL,
+    calories      real,
+    protein       real,
+    carbohydrates real,
+    fat           real,
+    created_at    datetime,
+    updated_at    datetime
+);
+CREATE INDEX IF NOT EXISTS idx_nutrition_goals_user_id ON nutrition_goals (user_id);
+
+CREATE TABLE IF NOT EXISTS foods (
+    id            text PRIMARY KEY,
+    name          text NOT NULL,
+    calories      real,
+    protein       real,
+    carbohydrates real,
+    fat           real,
+    created_at    datetime
+);
+CREATE INDEX IF NOT EXISTS idx_foods_name ON foods (name);
+
+CREATE TABLE IF NOT EXISTS meal_records (
+    id         text PRIMARY KEY,
+    user_id    text NOT NULL,
+    date       date NOT NULL,
+    meal_type  integer NOT NULL CHECK (meal_type BETWEEN 1 AND 4),
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_meal_records_user_id ON meal_records (user_id);
+CREATE INDEX IF NOT EXISTS idx_meal_records_date ON meal_records (date);
+
+CREATE TABLE IF NOT EXISTS food_records (
+    id             text PRIMARY KEY,
+    meal_record_id text NOT NULL REFERENCES meal_records (id),
+    food_id        text NOT NULL REFERENCES foods (id),
+    food_name      varchar(100) NOT NULL,
+    quantity       real NOT NULL CHECK (quantity > 0),
+    unit           varchar(20) NOT NULL,
+    calories       real,
+    protein        real,
+    carbohydrates  real,
+    fat            real,
+    created_at     datetime,
+    updated_at     datetime
+);
+CREATE INDEX IF NOT EXISTS idx_food_records_meal_record_id ON food_records (meal_record_id);
+CREATE INDEX IF NOT EXISTS idx_food_records_food_id ON food_records (food_id);
+
+CREATE TABLE IF NOT EXISTS exercise_records (
+    id              text PRIMARY KEY,
+    user_id         text NOT NULL,
+    date            date NOT NULL,
+    exercise_type   varchar(50) NOT NULL,
+    duration        integer NOT NULL CHECK (duration > 0),
+    intensity       varchar(20) NOT NULL,
+    calories_burned real,
+    is_manual       numeric DEFAULT false,
+    created_at      datetime,
+    updated_at      datetime
+);
+CREATE INDEX IF NOT EXISTS idx_exercise_records_user_id ON exercise_records (user_id);
+CREATE INDEX IF NOT EXISTS idx_exercise_records_date ON exercise_records (date);
+
+CREATE TABLE IF NOT EXISTS water_records (
+    id          text PRIMARY KEY,
+    user_id     text NOT NULL,
+    date        date NOT NULL,
+    amount      real NOT NULL CHECK (amount > 0),
+    recorded_at datetime NOT NULL,
+    created_at  datetime
+);
+CREATE INDEX IF NOT EXISTS idx_water_records_user_id ON water_records (user_id);
+CREATE INDEX IF NOT EXISTS idx_water_records_date ON water_records (date);
@@ -0,0 +1 @@
+-- SQLite 的 CHECK 约束随 0001_init 的表一起删除
@@ -0,0 +1 @@
+-- SQLite 不支持 ALTER TABLE ADD CONSTRAINT，CHECK 约束已在 0001_init 建表时定义
@@ -0,0 +1,24 @@
+-- 登录会话和刷新令牌
+CREATE TABLE IF NOT EXISTS sessions (
+    id           text PRIMARY KEY,
+    user_id      text NOT NULL,
+    device_name  varchar(100),
+    user_agent   varchar(255),
+    ip           varchar(45),
+    expires_at   datetime NOT NULL,
+    last_used_at datetime NOT NULL,
+    revoked_at   datetime,
+    created_at   datetime
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         text PRIMARY KEY,
+    session_id text NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    token_hash varchar(64) NOT NULL,
+    expires_at datetime NOT NULL,
+    used_at    datetime,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
@@ -0,0 +1,21 @@
+-- 登录失败计数（多实例共享的暴力破解保护）和登录审计记录
+CREATE TABLE IF NOT EXISTS login_throttles (
+    throttle_key    varchar(320) PRIMARY KEY,
+    failures        integer NOT NULL DEFAULT 0,
+    last_failure_at datetime NOT NULL,
+    locked_until    datetime
+);
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id         text PRIMARY KEY,
+    user_id    text,
+    email      varchar(255) NOT NULL,
+    ip         varchar(45),
+    user_agent varchar(255),
+    success    boolean NOT NULL,
+    reason     varchar(30),
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts (user_id);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts (email);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_created_at ON login_attempts (created_at);
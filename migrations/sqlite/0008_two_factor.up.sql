@@ -0,0 +1,13 @@
+-- 两步验证（TOTP）密钥和恢复码
+ALTER TABLE users ADD COLUMN totp_secret varchar(64);
+ALTER TABLE users ADD COLUMN totp_enabled_at datetime;
+ALTER TABLE users ADD COLUMN totp_last_step integer DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id         text PRIMARY KEY,
+    user_id    text NOT NULL,
+    code_hash  varchar(64) NOT NULL,
+    used_at    datetime,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
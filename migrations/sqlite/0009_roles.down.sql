@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN disabled_at;
+ALTER TABLE users DROP COLUMN role;
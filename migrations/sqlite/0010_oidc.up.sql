@@ -0,0 +1,22 @@
+-- 第三方登录（OIDC）：外部身份和进行中的登录状态
+CREATE TABLE IF NOT EXISTS user_identities (
+    id            text PRIMARY KEY,
+    user_id       text NOT NULL,
+    provider      varchar(50) NOT NULL,
+    subject       varchar(255) NOT NULL,
+    email         varchar(255),
+    last_login_at datetime,
+    created_at    datetime
+);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_provider_subject ON user_identities (provider, subject);
+
+CREATE TABLE IF NOT EXISTS oidc_states (
+    state_hash    varchar(64) PRIMARY KEY,
+    provider      varchar(50) NOT NULL,
+    nonce         varchar(64) NOT NULL,
+    code_verifier varchar(128) NOT NULL,
+    expires_at    datetime NOT NULL,
+    created_at    datetime
+);
+CREATE INDEX IF NOT EXISTS idx_oidc_states_expires_at ON oidc_states (expires_at);
@@ -0,0 +1,14 @@
+-- 个人 API 密钥
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           text PRIMARY KEY,
+    user_id      text NOT NULL,
+    name         varchar(100) NOT NULL,
+    prefix       varchar(16) NOT NULL,
+    key_hash     varchar(64) NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   datetime NOT NULL,
+    last_used_at datetime,
+    created_at   datetime
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
+ALTER TABLE users DROP COLUMN dependent;
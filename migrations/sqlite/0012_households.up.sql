@@ -0,0 +1,20 @@
+-- 家庭共享：家庭、成员和受抚养成员档案
+ALTER TABLE users ADD COLUMN dependent boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS households (
+    id         text PRIMARY KEY,
+    name       varchar(100) NOT NULL,
+    created_by text NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+    id           text PRIMARY KEY,
+    household_id text NOT NULL,
+    user_id      text NOT NULL,
+    role         varchar(20) NOT NULL,
+    created_at   datetime
+);
+CREATE INDEX IF NOT EXISTS idx_household_members_household_id ON household_members (household_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_household_members_user_id ON household_members (user_id);
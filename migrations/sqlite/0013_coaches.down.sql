@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS meal_comments;
+DROP TABLE IF EXISTS coach_access_logs;
+DROP TABLE IF EXISTS coach_clients;
@@ -0,0 +1,38 @@
+-- 教练与客户关系、访问记录和餐次评论
+CREATE TABLE IF NOT EXISTS coach_clients (
+    id          text PRIMARY KEY,
+    coach_id    text NOT NULL,
+    client_id   text NOT NULL,
+    status      varchar(20) NOT NULL,
+    scopes      text NOT NULL,
+    message     varchar(500),
+    accepted_at datetime,
+    ended_at    datetime,
+    created_at  datetime,
+    updated_at  datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_clients_pair ON coach_clients (coach_id, client_id);
+CREATE INDEX IF NOT EXISTS idx_coach_clients_client_id ON coach_clients (client_id);
+
+CREATE TABLE IF NOT EXISTS coach_access_logs (
+    id         text PRIMARY KEY,
+    coach_id   text NOT NULL,
+    client_id  text NOT NULL,
+    method     varchar(10) NOT NULL,
+    path       varchar(255) NOT NULL,
+    status     integer NOT NULL,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_coach_access_logs_client_id ON coach_access_logs (client_id);
+CREATE INDEX IF NOT EXISTS idx_coach_access_logs_created_at ON coach_access_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS meal_comments (
+    id             text PRIMARY KEY,
+    meal_record_id text NOT NULL,
+    author_id      text NOT NULL,
+    body           text NOT NULL,
+    created_at     datetime,
+    updated_at     datetime
+);
+CREATE INDEX IF NOT EXISTS idx_meal_comments_meal_record_id ON meal_comments (meal_record_id);
+CREATE INDEX IF NOT EXISTS idx_meal_comments_author_id ON meal_comments (author_id);
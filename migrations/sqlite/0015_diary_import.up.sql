@@ -0,0 +1,25 @@
+-- 导入其他应用的饮食日记：找不到对应食物的记录作为快速添加条目保存，food_id 为空；
+-- import_key 标识导入来源中的一行，重复导入时跳过已导入的行
+-- SQLite 不支持修改列的 NOT NULL 约束，需要重建表
+CREATE TABLE food_records_new (
+    id             text PRIMARY KEY,
+    meal_record_id text NOT NULL REFERENCES meal_records (id),
+    food_id        text REFERENCES foods (id),
+    food_name      varchar(100) NOT NULL,
+    quantity       real NOT NULL CHECK (quantity > 0),
+    unit           varchar(20) NOT NULL,
+    calories       real,
+    protein        real,
+    carbohydrates  real,
+    fat            real,
+    created_at     datetime,
+    updated_at     datetime,
+    import_key     varchar(64)
+);
+INSERT INTO food_records_new (id, meal_record_id, food_id, food_name, quantity, unit, calories, protein, carbohydrates, fat, created_at, updated_at)
+SELECT id, meal_record_id, food_id, food_name, quantity, unit, calories, protein, carbohydrates, fat, created_at, updated_at FROM food_records;
+DROP TABLE food_records;
+ALTER TABLE food_records_new RENAME TO food_records;
+CREATE INDEX IF NOT EXISTS idx_food_records_meal_record_id ON food_records (meal_record_id);
+CREATE INDEX IF NOT EXISTS idx_food_records_food_id ON food_records (food_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_food_records_import_key ON food_records (import_key);
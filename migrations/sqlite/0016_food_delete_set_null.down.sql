@@ -0,0 +1,22 @@
+CREATE TABLE food_records_old (
+    id             text PRIMARY KEY,
+    meal_record_id text NOT NULL REFERENCES meal_records (id),
+    food_id        text REFERENCES foods (id),
+    food_name      varchar(100) NOT NULL,
+    quantity       real NOT NULL CHECK (quantity > 0),
+    unit           varchar(20) NOT NULL,
+    calories       real,
+    protein        real,
+    carbohydrates  real,
+    fat            real,
+    created_at     datetime,
+    updated_at     datetime,
+    import_key     varchar(64)
+);
+INSERT INTO food_records_old (id, meal_record_id, food_id, food_name, quantity, unit, calories, protein, carbohydrates, fat, created_at, updated_at, import_key)
+SELECT id, meal_record_id, food_id, food_name, quantity, unit, calories, protein, carbohydrates, fat, created_at, updated_at, import_key FROM food_records;
+DROP TABLE food_records;
+ALTER TABLE food_records_old RENAME TO food_records;
+CREATE INDEX IF NOT EXISTS idx_food_records_meal_record_id ON food_records (meal_record_id);
+CREATE INDEX IF NOT EXISTS idx_food_records_food_id ON food_records (food_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_food_records_import_key ON food_records (import_key);
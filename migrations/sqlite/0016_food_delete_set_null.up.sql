@@ -0,0 +1,24 @@
+-- 管理员删除公共食物后，引用该食物的记录保留食物名称和营养数据，food_id 置空
+-- SQLite 不支持修改外键约束，需要重建表
+CREATE TABLE food_records_new (
+    id             text PRIMARY KEY,
+    meal_record_id text NOT NULL REFERENCES meal_records (id),
+    food_id        text REFERENCES foods (id) ON DELETE SET NULL,
+    food_name      varchar(100) NOT NULL,
+    quantity       real NOT NULL CHECK (quantity > 0),
+    unit           varchar(20) NOT NULL,
+    calories       real,
+    protein        real,
+    carbohydrates  real,
+    fat            real,
+    created_at     datetime,
+    updated_at     datetime,
+    import_key     varchar(64)
+);
+INSERT INTO food_records_new (id, meal_record_id, food_id, food_name, quantity, unit, calories, protein, carbohydrates, fat, created_at, updated_at, import_key)
+SELECT id, meal_record_id, food_id, food_name, quantity, unit, calories, protein, carbohydrates, fat, created_at, updated_at, import_key FROM food_records;
+DROP TABLE food_records;
+ALTER TABLE food_records_new RENAME TO food_records;
+CREATE INDEX IF NOT EXISTS idx_food_records_meal_record_id ON food_records (meal_record_id);
+CREATE INDEX IF NOT EXISTS idx_food_records_food_id ON food_records (food_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_food_records_import_key ON food_records (import_key);
@@ -0,0 +1,12 @@
+-- 家庭邀请：创建者按邮箱邀请成年成员，对方登录后接受才会加入家庭
+CREATE TABLE IF NOT EXISTS household_invitations (
+    id           text PRIMARY KEY,
+    household_id text NOT NULL,
+    email        varchar(255) NOT NULL,
+    invited_by   text NOT NULL,
+    status       varchar(20) NOT NULL,
+    created_at   datetime,
+    updated_at   datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_household_invitations_pair ON household_invitations (household_id, email);
+CREATE INDEX IF NOT EXISTS idx_household_invitations_email ON household_invitations (email);
@@ -0,0 +1,9 @@
+-- 发给已注册用户、尚未接受的邀请恢复为待接受的教练关系
+INSERT INTO coach_clients (id, coach_id, client_id, status, scopes, message, created_at, updated_at)
+SELECT coach_invitations.id, coach_invitations.coach_id, users.id, 'pending', coach_invitations.scopes, coach_invitations.message,
+       coach_invitations.created_at, coach_invitations.updated_at
+FROM coach_invitations JOIN users ON lower(users.email) = coach_invitations.email AND users.deleted_at IS NULL
+WHERE coach_invitations.status = 'pending'
+  AND NOT EXISTS (SELECT 1 FROM coach_clients
+                  WHERE coach_clients.coach_id = coach_invitations.coach_id AND coach_clients.client_id = users.id);
+DROP TABLE IF EXISTS coach_invitations;
@@ -0,0 +1,21 @@
+-- 教练邀请：教练按邮箱邀请客户，对方验证邮箱并接受后才建立教练关系
+CREATE TABLE IF NOT EXISTS coach_invitations (
+    id         text PRIMARY KEY,
+    coach_id   text NOT NULL,
+    email      varchar(255) NOT NULL,
+    scopes     text NOT NULL,
+    message    varchar(500),
+    status     varchar(20) NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_invitations_pair ON coach_invitations (coach_id, email);
+CREATE INDEX IF NOT EXISTS idx_coach_invitations_email ON coach_invitations (email);
+
+-- 尚未接受的邀请从教练关系中移出
+INSERT INTO coach_invitations (id, coach_id, email, scopes, message, status, created_at, updated_at)
+SELECT coach_clients.id, coach_clients.coach_id, lower(users.email), coach_clients.scopes, coach_clients.message, 'pending',
+       coach_clients.created_at, coach_clients.updated_at
+FROM coach_clients JOIN users ON users.id = coach_clients.client_id
+WHERE coach_clients.status = 'pending';
+DELETE FROM coach_clients WHERE status = 'pending';
@@ -1,32 +1,91 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"time"
 
-	"github.com/ljk20041215/nutrition-tracker/internal/model"
+	"github.com/glebarez/sqlite"
+	"github.com/ljk20041215/nutrition-tracker/migrations"
+	"github.com/ljk20041215/nutrition-tracker/pkg/migrate"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// 支持的数据库驱动
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 var DB *gorm.DB
 
-func Init(host, port, username, password, dbname string) error {
+// driverName 当前连接使用的驱动
+var driverName string
+
+// Init 连接数据库并检查表结构版本
+// autoMigrate 为 true 时先执行所有未执行的迁移（适用于本地开发和内存数据库），
+// 否则表结构落后于代码时直接返回错误，需要先执行 `server migrate up`
+func Init(driver, dsn string, autoMigrate bool) error {
+	if err := Connect(driver, dsn); err != nil {
+		return err
+	}
+
+	migrator, err := NewMigrator()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if autoMigrate {
+		executed, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+		for _, m := range executed {
+			log.Printf("✅ 已执行迁移 %04d_%s", m.Version, m.Name)
+		}
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("数据库结构版本落后，还有 %d 个迁移未执行，请先运行 `server migrate up`", pending)
+	}
+
+	log.Printf("✅ %s connection established, schema is up to date", driver)
+	return nil
+}
+
+// Connect 根据驱动类型连接数据库，不检查表结构
+// postgres 使用 key=value 格式的 DSN；sqlite 的 DSN 为数据库文件路径（":memory:" 表示内存数据库）
+func Connect(driver, dsn string) error {
 	var err error
 
-	// 构建 PostgreSQL DSN
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, username, password, dbname)
+	dialector, err := openDialector(driver, dsn)
+	if err != nil {
+		return err
+	}
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
+	driverName = driver
+
+	// 在 Go 侧生成主键，不依赖 PostgreSQL 的 gen_random_uuid()
+	if err := registerUUIDCallback(DB); err != nil {
+		return fmt.Errorf("failed to register uuid callback: %w", err)
+	}
 
 	// 获取底层连接配置连接池
 	sqlDB, err := DB.DB()
@@ -34,33 +93,56 @@ func Init(host, port, username, password, dbname string) error {
 		return fmt.Errorf("failed to get sql.DB: %w", err)
 	}
 
-	// PostgreSQL连接池配置
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(2 * time.Hour)
+	if driver == DriverSQLite {
+		// SQLite 同一时间只允许一个写连接，避免 database is locked
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		// PostgreSQL连接池配置
+		sqlDB.SetMaxIdleConns(10)
+		sqlDB.SetMaxOpenConns(100)
+		sqlDB.SetConnMaxLifetime(2 * time.Hour)
+	}
 
 	// 测试连接
 	if err := sqlDB.Ping(); err != nil {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// 自动迁移
-	err = DB.AutoMigrate(
-		&model.User{},
-		&model.NutritionGoal{},
-		&model.Food{},
-		&model.MealRecord{},
-		&model.FoodRecord{},
-	)
+	return nil
+}
+
+// NewMigrator 使用内嵌的迁移文件为当前连接创建迁移执行器
+func NewMigrator() (*migrate.Migrator, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	sqlDB, err := DB.DB()
 	if err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
 	}
 
-	log.Println("✅ PostgreSQL connection established and migrated successfully!")
-	return nil
+	return migrate.New(sqlDB, driverName, migrations.FS)
+}
+
+// openDialector 根据驱动类型创建 GORM Dialector
+func openDialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case DriverPostgres:
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		// 确保数据库文件所在目录存在
+		if dsn != ":memory:" {
+			if err := os.MkdirAll(filepath.Dir(dsn), 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create sqlite directory: %w", err)
+			}
+		}
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
 }
 
 func GetDB() *gorm.DB {
 	return DB
-}
-
\ No newline at end of file
+}
\ No newline at end of file
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// registerUUIDCallback 注册创建前回调：主键为空的字符串 ID 自动填充 UUID
+func registerUUIDCallback(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("app:generate_uuid", generateUUID)
+}
+
+// generateUUID 为模型（或模型切片）中为空的字符串主键生成 UUID
+func generateUUID(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil || field.FieldType.Kind() != reflect.String {
+		return
+	}
+
+	ctx := db.Statement.Context
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			elem := reflect.Indirect(rv.Index(i))
+			if _, isZero := field.ValueOf(ctx, elem); isZero {
+				_ = field.Set(ctx, elem, uuid.NewString())
+			}
+		}
+	case reflect.Struct:
+		if _, isZero := field.ValueOf(ctx, rv); isZero {
+			_ = field.Set(ctx, rv, uuid.NewString())
+		}
+	}
+}
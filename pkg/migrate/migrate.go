@@ -0,0 +1,332 @@
+// Package migrate 实现基于版本号的 SQL 迁移：
+// 迁移文件按驱动存放在 migrations/<driver>/ 下，已执行的版本记录在 schema_migrations 表中。
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// advisoryLockKey PostgreSQL 咨询锁的键，保证多个副本同时启动时只有一个在执行迁移
+const advisoryLockKey = 72_700_001
+
+// fileNamePattern 迁移文件名格式：0001_init.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 单个迁移
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// Status 迁移执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// New 从迁移文件系统中加载指定驱动的迁移，source 的根目录下应包含以驱动命名的子目录
+func New(db *sql.DB, driver string, source fs.FS) (*Migrator, error) {
+	migrations, err := load(source, driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+// load 读取并解析迁移文件
+func load(source fs.FS, driver string) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, driver)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移目录失败: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, err := fs.ReadFile(source, driver+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件 %s 失败: %w", entry.Name(), err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("迁移版本 %d 存在多个名称: %s, %s", version, m.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up 执行所有未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var executed []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			executed = append(executed, migration)
+		}
+		return nil
+	})
+	return executed, err
+}
+
+// Down 回滚最近执行的一个迁移，没有可回滚的迁移时返回 nil
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var rolledBack *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			rolledBack = &migration
+			return nil
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status 返回所有迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending 返回尚未执行的迁移数量
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// withLock 在独占连接上获取迁移锁后执行 fn
+// PostgreSQL 使用会话级咨询锁；SQLite 连接池只有一个连接，写入本身是串行的
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.driver == "postgres" {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey); err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey)
+	}
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// ensureTable 创建 schema_migrations 表
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    applied_at timestamp NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("创建 schema_migrations 表失败: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions 查询已执行的迁移版本及执行时间
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("查询 schema_migrations 失败: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// apply 在事务中执行单个迁移并更新 schema_migrations
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	script := migration.UpSQL
+	if !up {
+		script = migration.DownSQL
+		if strings.TrimSpace(script) == "" {
+			return fmt.Errorf("迁移 %04d_%s 缺少 down 脚本", migration.Version, migration.Name)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("执行迁移 %04d_%s 失败: %w", migration.Version, migration.Name, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, m.rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+			migration.Version, migration.Name, time.Now().UTC())
+	} else {
+		_, err = tx.ExecContext(ctx, m.rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("更新 schema_migrations 失败: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// rebind 把 ? 占位符转换为驱动对应的格式
+func (m *Migrator) rebind(query string) string {
+	if m.driver != "postgres" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Create 在 dir/<driver>/ 下为每个驱动生成下一个版本号的空白 up/down 迁移文件
+func Create(dir string, drivers []string, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		return nil, errors.New("迁移名称只能包含小写字母、数字和下划线")
+	}
+
+	// 所有驱动共用同一个版本号
+	var next int64 = 1
+	for _, driver := range drivers {
+		migrations, err := load(os.DirFS(dir), driver)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(migrations); n > 0 && migrations[n-1].Version >= next {
+			next = migrations[n-1].Version + 1
+		}
+	}
+
+	var created []string
+	for _, driver := range drivers {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, driver, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+			content := fmt.Sprintf("-- %04d_%s (%s, %s)\n", next, name, driver, direction)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return created, err
+			}
+			created = append(created, path)
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,229 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"github.com/ljk20041215/nutrition-tracker/migrations"
+	"github.com/ljk20041215/nutrition-tracker/pkg/migrate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openSQLite 创建临时 SQLite 数据库
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB
+}
+
+// schema 返回数据库中的表和索引定义（不含迁移记录表）
+func schema(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+
+	rows, err := db.Query("SELECT type, name, COALESCE(sql, '') FROM sqlite_master WHERE name NOT LIKE 'sqlite_%' AND name != 'schema_migrations' ORDER BY type, name")
+	if err != nil {
+		t.Fatalf("读取表结构失败: %v", err)
+	}
+	defer rows.Close()
+
+	var objects []string
+	for rows.Next() {
+		var kind, name, ddl string
+		if err := rows.Scan(&kind, &name, &ddl); err != nil {
+			t.Fatalf("读取表结构失败: %v", err)
+		}
+		objects = append(objects, kind+" "+name+": "+ddl)
+	}
+	return objects
+}
+
+// versions 返回迁移的版本号
+func versions(migrations []migrate.Migration) []int64 {
+	var vs []int64
+	for _, m := range migrations {
+		vs = append(vs, m.Version)
+	}
+	return vs
+}
+
+func TestUpDownAndStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	source := fstest.MapFS{
+		"sqlite/0002_add_notes.up.sql":       {Data: []byte("ALTER TABLE items ADD COLUMN notes text;")},
+		"sqlite/0002_add_notes.down.sql":     {Data: []byte("ALTER TABLE items DROP COLUMN notes;")},
+		"sqlite/0001_init.up.sql":            {Data: []byte("CREATE TABLE items (id integer PRIMARY KEY);")},
+		"sqlite/0001_init.down.sql":          {Data: []byte("DROP TABLE items;")},
+		"sqlite/README.md":                   {Data: []byte("不是迁移文件")},
+		"postgres/0001_init.up.sql":          {Data: []byte("其他驱动的迁移不会执行")},
+		"sqlite/0003_no_down.up.sql":         {Data: []byte("CREATE INDEX idx_items_notes ON items (notes);")},
+		"sqlite/0010_later_version.up.sql":   {Data: []byte("SELECT 1;")},
+		"sqlite/0010_later_version.down.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	m, err := migrate.New(db, "sqlite", source)
+	if err != nil {
+		t.Fatalf("加载迁移失败: %v", err)
+	}
+	if n, err := m.Pending(ctx); err != nil || n != 4 {
+		t.Fatalf("Pending = %d, %v，期望 4", n, err)
+	}
+
+	executed, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if got := versions(executed); !reflect.DeepEqual(got, []int64{1, 2, 3, 10}) {
+		t.Errorf("执行的迁移 = %v，期望按版本号顺序执行", got)
+	}
+	if executed, err := m.Up(ctx); err != nil || len(executed) != 0 {
+		t.Errorf("重复执行 Up: %v, %v，期望没有迁移执行", versions(executed), err)
+	}
+	if _, err := db.Exec("INSERT INTO items (id, notes) VALUES (1, 'x')"); err != nil {
+		t.Errorf("迁移后的表结构不对: %v", err)
+	}
+
+	// 回滚最近的迁移；没有 down 脚本的迁移不能回滚
+	if rolledBack, err := m.Down(ctx); err != nil || rolledBack == nil || rolledBack.Version != 10 {
+		t.Fatalf("回滚 0010: %v, %v", rolledBack, err)
+	}
+	if _, err := m.Down(ctx); err == nil || !strings.Contains(err.Error(), "缺少 down 脚本") {
+		t.Fatalf("回滚没有 down 脚本的迁移: err = %v", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("读取状态失败: %v", err)
+	}
+	applied := make(map[int64]bool)
+	for _, s := range statuses {
+		applied[s.Version] = s.Applied
+		if s.Applied != (s.AppliedAt != nil) {
+			t.Errorf("版本 %d: applied = %v，applied_at = %v", s.Version, s.Applied, s.AppliedAt)
+		}
+	}
+	if want := map[int64]bool{1: true, 2: true, 3: true, 10: false}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("状态 = %v，期望 %v", applied, want)
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	source := fstest.MapFS{
+		"sqlite/0001_init.up.sql":   {Data: []byte("CREATE TABLE items (id integer PRIMARY KEY);")},
+		"sqlite/0002_broken.up.sql": {Data: []byte("CREATE TABLE half_done (id integer); INSERT INTO missing VALUES (1);")},
+	}
+
+	m, err := migrate.New(db, "sqlite", source)
+	if err != nil {
+		t.Fatalf("加载迁移失败: %v", err)
+	}
+	if _, err := m.Up(ctx); err == nil || !strings.Contains(err.Error(), "0002_broken") {
+		t.Fatalf("执行失败的迁移: err = %v", err)
+	}
+
+	// 失败的迁移整体回滚，之前的迁移保留
+	if got := schema(t, db); len(got) != 1 || !strings.HasPrefix(got[0], "table items") {
+		t.Errorf("表结构 = %v，期望只有 items", got)
+	}
+	if n, err := m.Pending(ctx); err != nil || n != 1 {
+		t.Errorf("Pending = %d, %v，期望 1", n, err)
+	}
+}
+
+func TestLoadRejectsConflictingNames(t *testing.T) {
+	source := fstest.MapFS{
+		"sqlite/0001_init.up.sql":    {Data: []byte("SELECT 1;")},
+		"sqlite/0001_other.down.sql": {Data: []byte("SELECT 1;")},
+	}
+	if _, err := migrate.New(openSQLite(t), "sqlite", source); err == nil {
+		t.Error("同一版本有两个名称时应返回错误")
+	}
+	if _, err := migrate.New(openSQLite(t), "mysql", source); err == nil {
+		t.Error("没有驱动对应的目录时应返回错误")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, driver := range []string{"postgres", "sqlite"} {
+		if err := os.Mkdir(filepath.Join(dir, driver), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 各驱动共用下一个版本号
+	if err := os.WriteFile(filepath.Join(dir, "sqlite", "0007_init.up.sql"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := migrate.Create(dir, []string{"postgres", "sqlite"}, " Add_Index ")
+	if err != nil {
+		t.Fatalf("创建迁移文件失败: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "postgres", "0008_add_index.up.sql"),
+		filepath.Join(dir, "postgres", "0008_add_index.down.sql"),
+		filepath.Join(dir, "sqlite", "0008_add_index.up.sql"),
+		filepath.Join(dir, "sqlite", "0008_add_index.down.sql"),
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("created = %v\n期望 %v", created, want)
+	}
+
+	if _, err := migrate.Create(dir, []string{"sqlite"}, "drop table"); err == nil {
+		t.Error("名称中有空格时应返回错误")
+	}
+}
+
+// TestSQLiteMigrationsRoundTrip 全部 SQLite 迁移回滚到空库后重新执行，表结构与第一次执行相同
+func TestSQLiteMigrationsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m, err := migrate.New(db, "sqlite", migrations.FS)
+	if err != nil {
+		t.Fatalf("加载迁移失败: %v", err)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	want := schema(t, db)
+
+	for {
+		rolledBack, err := m.Down(ctx)
+		if err != nil {
+			t.Fatalf("回滚迁移失败: %v", err)
+		}
+		if rolledBack == nil {
+			break
+		}
+	}
+	if left := schema(t, db); len(left) != 0 {
+		t.Errorf("全部回滚后仍有: %v", left)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("回滚后重新执行迁移失败: %v", err)
+	}
+	if got := schema(t, db); !reflect.DeepEqual(got, want) {
+		t.Errorf("重新执行后的表结构不同:\n%v\n期望\n%v", got, want)
+	}
+}